@@ -49,6 +49,36 @@ type ImageUpdaterSpec struct {
 	// Can be overridden at the ApplicationRef or ImageConfig level.
 	// +optional
 	*ImagesVerification `json:"imagesVerification,omitempty"`
+
+	// AdmissionHook is an external HTTP endpoint that reviews every image
+	// update of the applications matched by this CR right before it is
+	// written, and may deny it.
+	// +optional
+	AdmissionHook *AdmissionHook `json:"admissionHook,omitempty"`
+}
+
+// AdmissionHook configures an external HTTP endpoint that allows or denies
+// image updates. The endpoint receives a POST request with a JSON document
+// describing the update, and must respond with a JSON document of the form
+// {"allowed": false, "reason": "..."}.
+type AdmissionHook struct {
+	// URL is the http or https URL of the endpoint.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Timeout is the maximum duration of a review, e.g. "5s". Defaults to
+	// 10 seconds.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// FailurePolicy defines how an endpoint that cannot be reached, times out
+	// or responds with an error is handled. "Fail" does not perform the
+	// update, and "Ignore" performs it as if it was allowed.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	// +kubebuilder:default:="Fail"
+	// +optional
+	FailurePolicy *string `json:"failurePolicy,omitempty"`
 }
 
 // ApplicationRef contains various criteria by which to include applications for managing by image updater
@@ -168,13 +198,54 @@ type ImageConfig struct {
 	// ImagesVerification.
 	// +optional
 	*ImagesVerification `json:"imagesVerification,omitempty"`
+
+	// Pin freezes this image at a specific version until the pin expires.
+	// While the pin is active, the image is updated to the pinned version
+	// instead of the version selected by the update strategy.
+	// +optional
+	Pin *ImagePin `json:"pin,omitempty"`
+
+	// SBOM requires the new version of this image to have a software bill of
+	// materials attached, and restricts the licenses it may contain.
+	// +optional
+	SBOM *SBOMPolicy `json:"sbom,omitempty"`
+}
+
+// ImagePin pins an image to a specific version until a point in time.
+type ImagePin struct {
+	// Version is the tag the image is pinned to, e.g. "1.2.3".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`
+	Version string `json:"version"`
+
+	// Until is the time at which the pin expires. After that, the image is
+	// updated according to its update strategy again.
+	// +kubebuilder:validation:Required
+	Until metav1.Time `json:"until"`
+
+	// Reason is a human-readable description of why the image is pinned.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// SBOMPolicy defines the software bill of materials an image must have to be
+// updated to. SPDX and CycloneDX SBOMs in JSON format are supported, attached
+// as OCI referrers, as attestations in a sigstore bundle, or with
+// "cosign attach sbom".
+type SBOMPolicy struct {
+	// DeniedLicenses are the SPDX license identifiers that must not appear in
+	// the SBOM, e.g. "AGPL-3.0-only". Glob patterns such as "AGPL-*" are
+	// supported, and identifiers are matched case-insensitively.
+	// +listType=atomic
+	// +optional
+	DeniedLicenses []string `json:"deniedLicenses,omitempty"`
 }
 
 // CommonUpdateSettings groups common update strategy settings that can be applied
 // globally, per ApplicationRef, or per ImageConfig.
 type CommonUpdateSettings struct {
 	// UpdateStrategy defines the update strategy to apply.
-	// Examples: "semver", "latest", "digest", "name".
+	// Examples: "semver", "newest-build", "digest", "alphabetical", "calver", "label".
 	// This acts as the default if not overridden at a more specific level.
 	// +optional
 	UpdateStrategy *string `json:"updateStrategy,omitempty"`
@@ -184,7 +255,10 @@ type CommonUpdateSettings struct {
 	// +optional
 	ForceUpdate *bool `json:"forceUpdate,omitempty"`
 
-	// AllowTags is a regex pattern for tags to allow.
+	// AllowTags is a match function for tags to allow, either "any",
+	// "regexp:<expression>" or "cel:<expression>". CEL expressions are compiled
+	// before any application is processed, and an invalid expression fails the
+	// reconciliation of the whole ImageUpdater.
 	// This acts as the default if not overridden.
 	// +optional
 	AllowTags *string `json:"allowTags,omitempty"`
@@ -205,6 +279,203 @@ type CommonUpdateSettings struct {
 	// +listType=atomic
 	// +optional
 	Platforms []string `json:"platforms,omitempty"`
+
+	// RequireAllPlatforms specifies whether a tag is only considered for an
+	// update once its manifest list contains an image for each of the
+	// Platforms. Tags still missing a platform, e.g. because not all of their
+	// builds have been pushed yet, are treated as not yet ready and the next
+	// best tag is used instead. By default, a tag is considered if it has an
+	// image for any of the Platforms.
+	// This acts as the default if not overridden.
+	// +optional
+	RequireAllPlatforms *bool `json:"requireAllPlatforms,omitempty"`
+
+	// SemVer configures the "semver" update strategy. It is ignored by all
+	// other update strategies.
+	// +optional
+	SemVer *SemVerSettings `json:"semver,omitempty"`
+
+	// CalVer configures the "calver" update strategy. It is ignored by all
+	// other update strategies.
+	// +optional
+	CalVer *CalVerSettings `json:"calver,omitempty"`
+
+	// Label configures the "label" update strategy. The calendar versioning
+	// layout and constraints of CalVer apply to label values of the "calver"
+	// format. It is ignored by all other update strategies.
+	// +optional
+	Label *LabelSettings `json:"label,omitempty"`
+
+	// MinAge is the minimum age a tag must have, based on the creation date of
+	// the image, before it is considered for an update (e.g., "48h"). Newer tags
+	// are held back, and the newest tag that is old enough is used instead.
+	// This acts as the default if not overridden.
+	// +optional
+	MinAge *metav1.Duration `json:"minAge,omitempty"`
+
+	// MaxBump is the largest change to the semantic version of the running tag
+	// that is allowed for an update: "patch" only allows updates within the
+	// running minor version, "minor" only allows updates within the running
+	// major version, and "major" allows any update. It only applies to the
+	// "semver" update strategy.
+	// This acts as the default if not overridden.
+	// +kubebuilder:validation:Enum=patch;minor;major
+	// +optional
+	MaxBump *string `json:"maxBump,omitempty"`
+
+	// PinDigest specifies whether the new tag should be written together with
+	// the digest it currently points to (e.g. "1.2.3@sha256:..."), so that the
+	// deployed image reference is immutable. It is ignored by the "digest"
+	// update strategy, which always writes the digest.
+	// This acts as the default if not overridden.
+	// +optional
+	PinDigest *bool `json:"pinDigest,omitempty"`
+
+	// Prerelease is the policy for pre-release versions, applied on top of the
+	// version constraint. It is either a single channel, or a list of allowed
+	// pre-release identifiers (e.g. ["rc", "preview"]). Valid channels are
+	// "none" (no pre-releases), "rc" (release candidates), "beta" (beta and
+	// rc), "alpha" (alpha, beta and rc) and "any" (all pre-releases). If not
+	// set, pre-release versions are only allowed by a constraint with a
+	// pre-release suffix. It only applies to the "semver" update strategy.
+	// This acts as the default if not overridden.
+	// +listType=atomic
+	// +optional
+	Prerelease []string `json:"prerelease,omitempty"`
+
+	// Vulnerabilities is the policy for the vulnerability reports that are
+	// attached to images as OCI referrers, e.g. by a scanner such as Trivy or
+	// Grype. Versions whose reports exceed the maximum number of
+	// vulnerabilities of a severity are not updated to.
+	// This acts as the default if not overridden.
+	// +optional
+	Vulnerabilities *VulnerabilitySettings `json:"vulnerabilities,omitempty"`
+
+	// RequiredLabels are image labels a tag must have to be considered for an
+	// update (e.g. a label marking the image as having passed QA). All of
+	// them must be present. Requiring labels makes the image updater fetch
+	// the metadata of each tag, for any update strategy.
+	// This acts as the default if not overridden.
+	// +listType=atomic
+	// +optional
+	RequiredLabels []LabelMatch `json:"requiredLabels,omitempty"`
+
+	// ForbiddenLabels are image labels a tag must not have to be considered
+	// for an update (e.g. a label marking the image as deprecated). None of
+	// them may be present. Forbidding labels makes the image updater fetch
+	// the metadata of each tag, for any update strategy.
+	// This acts as the default if not overridden.
+	// +listType=atomic
+	// +optional
+	ForbiddenLabels []LabelMatch `json:"forbiddenLabels,omitempty"`
+}
+
+// LabelMatch matches an image label by its key, and optionally its value.
+type LabelMatch struct {
+	// Key is the key of the label (e.g. "com.example.qa").
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// Value is a regular expression the whole value of the label must match
+	// (e.g. "passed|waived"). If not set, any value matches.
+	// +optional
+	Value string `json:"value,omitempty"`
+}
+
+// SemVerSettings configures how tags are parsed as semantic versions.
+type SemVerSettings struct {
+	// Relaxed enables parsing of tags that are not strictly semantic versions.
+	// A relaxed version may have a prefix such as "v" or "release-", a fourth
+	// numeric component (e.g. "1.2.3.4"), and a suffix such as a platform
+	// (e.g. "1.2.3-windowsservercore-ltsc2022"). Only tags with the same suffix
+	// as the running tag are considered for an update.
+	// +kubebuilder:default:=false
+	// +optional
+	Relaxed *bool `json:"relaxed,omitempty"`
+}
+
+// CalVerSettings configures how calendar versioned tags are parsed and
+// which of them are considered for an update.
+type CalVerSettings struct {
+	// Layout is the calendar versioning layout of the image's tags, composed of
+	// the components YYYY, YY, 0Y, MM, 0M, WW, 0W, DD, 0D and MICRO, separated by
+	// literal characters (e.g. "YYYY.0M.0D" or "YY.0M-MICRO").
+	// +kubebuilder:default:="YYYY.0M.0D"
+	// +optional
+	Layout *string `json:"layout,omitempty"`
+
+	// Constraints restrict the versions that are considered for an update.
+	// Valid entries are "same-year", "same-month" and "max-age:<n><d|w|m|y>".
+	// +listType=atomic
+	// +optional
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// LabelSettings configures the image label that the "label" update strategy
+// orders tags by.
+type LabelSettings struct {
+	// Name is the name of the image label holding the version of the image.
+	// +kubebuilder:default:="org.opencontainers.image.version"
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// Format is the versioning scheme of the label values, either "semver" or
+	// "calver".
+	// +kubebuilder:default:="semver"
+	// +kubebuilder:validation:Enum=semver;calver
+	// +optional
+	Format *string `json:"format,omitempty"`
+
+	// Constraint is a semantic version constraint the label value must satisfy
+	// (e.g. "~1.2"). It only applies to the "semver" format, and replaces any
+	// constraint given as part of the image name.
+	// +optional
+	Constraint *string `json:"constraint,omitempty"`
+}
+
+// VulnerabilitySettings configures which versions are considered for an
+// update based on their vulnerability reports. SARIF and CycloneDX reports,
+// and cosign vulnerability and CycloneDX attestations stored in a sigstore
+// bundle are supported. A vulnerability reported by several reports is
+// counted once.
+type VulnerabilitySettings struct {
+	// MaxCritical is the maximum number of critical vulnerabilities a version
+	// may have. Not limited if not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxCritical *int32 `json:"maxCritical,omitempty"`
+
+	// MaxHigh is the maximum number of high severity vulnerabilities a version
+	// may have. Not limited if not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxHigh *int32 `json:"maxHigh,omitempty"`
+
+	// MaxMedium is the maximum number of medium severity vulnerabilities a
+	// version may have. Not limited if not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxMedium *int32 `json:"maxMedium,omitempty"`
+
+	// MaxLow is the maximum number of low severity vulnerabilities a version
+	// may have. Not limited if not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxLow *int32 `json:"maxLow,omitempty"`
+
+	// RequireReport specifies whether versions without a vulnerability
+	// report are rejected.
+	// +kubebuilder:default:=true
+	// +optional
+	RequireReport *bool `json:"requireReport,omitempty"`
+
+	// Fallback specifies whether to update to the newest older version that
+	// satisfies the policy when the newest version does not. Versions older
+	// than the running one are never updated to.
+	// +kubebuilder:default:=false
+	// +optional
+	Fallback *bool `json:"fallback,omitempty"`
 }
 
 // WriteBackConfig defines how and where to write back image updates.
@@ -349,9 +620,16 @@ type PluginTarget struct {
 // ImagesVerification defines the image signature verification policy for one or more images.
 //
 // At least one verification method must be provided when enabled is true.
-// Currently, the only supported method is cosign key-based verification via cosignKey.
+// Supported methods are cosign key-based verification via cosignKey, cosign
+// verification with several signers via cosignKeys, cosign keyless verification
+// via cosignKeyless, and Notation verification via notation.
 //
-// +kubebuilder:validation:XValidation:rule="self.enabled == false || has(self.cosignKey)",message="at least one verification method (cosignKey) is required when verification is enabled"
+// Attestations, such as a SLSA provenance, can additionally be required with
+// attestations. They are verified with the configured cosign method.
+//
+// +kubebuilder:validation:XValidation:rule="self.enabled == false || has(self.cosignKey) || has(self.cosignKeys) || has(self.cosignKeyless) || has(self.notation)",message="at least one verification method (cosignKey, cosignKeys, cosignKeyless, notation) is required when verification is enabled"
+// +kubebuilder:validation:XValidation:rule="[has(self.cosignKey), has(self.cosignKeys), has(self.cosignKeyless), has(self.notation)].filter(x, x).size() <= 1",message="cosignKey, cosignKeys, cosignKeyless and notation are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.attestations) || has(self.cosignKey) || has(self.cosignKeys) || has(self.cosignKeyless)",message="attestations require a cosign verification method"
 type ImagesVerification struct {
 	// Enabled controls whether signature verification is active at this scope.
 	// Defaults to true when the ImagesVerification block is present.
@@ -365,6 +643,179 @@ type ImagesVerification struct {
 	// cosign signatures. Providing this field selects cosign key-based verification.
 	// +optional
 	CosignKey *SecretRef `json:"cosignKey,omitempty"`
+
+	// CosignKeys configures verification of cosign signatures made by several
+	// signers, of which a threshold must have signed the image. Providing this
+	// field selects cosign key-based verification with several signers.
+	// +optional
+	CosignKeys *CosignKeys `json:"cosignKeys,omitempty"`
+
+	// CosignKeyless configures verification of cosign signatures made with
+	// short-lived Fulcio certificates. Providing this field selects cosign
+	// keyless verification.
+	// +optional
+	CosignKeyless *CosignKeyless `json:"cosignKeyless,omitempty"`
+
+	// Notation configures verification of Notation signatures made with X.509
+	// certificates. Providing this field selects Notation verification.
+	// +optional
+	Notation *NotationVerification `json:"notation,omitempty"`
+
+	// Attestations configures attestations that must be present on an image,
+	// in addition to its signature, for it to be updated.
+	// +optional
+	Attestations *AttestationsVerification `json:"attestations,omitempty"`
+
+	// PlatformVerification selects which manifests of a multi-platform image
+	// must be signed: "index" verifies the signature of the image index, and
+	// that it lists a manifest for each platform in
+	// CommonUpdateSettings.Platforms. "per-platform" verifies the signature of
+	// the manifest of each of these platforms, or of all platforms of the
+	// image if none are configured. "both" verifies the signatures of the
+	// index and of the platform manifests. Defaults to "index".
+	// +kubebuilder:validation:Enum=index;per-platform;both
+	// +optional
+	PlatformVerification *string `json:"platformVerification,omitempty"`
+}
+
+// AttestationsVerification defines the attestations required on an image.
+// Attestations must be stored in a sigstore bundle, and signed with the
+// cosign method configured in the enclosing ImagesVerification.
+type AttestationsVerification struct {
+	// SLSAProvenance requires a SLSA provenance attestation (v0.2 or v1)
+	// whose subject is the image manifest and whose predicate matches the
+	// given expectations.
+	// +optional
+	SLSAProvenance *SLSAProvenancePolicy `json:"slsaProvenance,omitempty"`
+}
+
+// SLSAProvenancePolicy defines the expectations on the predicate of a SLSA
+// provenance attestation. Values are matched exactly, unless prefixed with
+// "regexp:", in which case the remainder is a regular expression that must
+// match the whole value.
+type SLSAProvenancePolicy struct {
+	// BuilderIDs are the allowed builder IDs (builder.id in SLSA v0.2,
+	// runDetails.builder.id in SLSA v1).
+	// +kubebuilder:validation:MinItems=1
+	BuilderIDs []string `json:"builderIDs"`
+
+	// SourceRepositories are the allowed source repositories, such as
+	// "https://github.com/org/repo". They are matched against
+	// invocation.configSource.uri in SLSA v0.2 and against the URIs of
+	// buildDefinition.resolvedDependencies in SLSA v1, without the "git+"
+	// prefix, the "@<ref>" suffix and the ".git" extension. Any source
+	// repository is allowed if empty.
+	// +optional
+	SourceRepositories []string `json:"sourceRepositories,omitempty"`
+
+	// BuildTypes are the allowed build types (buildType in SLSA v0.2,
+	// buildDefinition.buildType in SLSA v1). Any build type is allowed if
+	// empty.
+	// +optional
+	BuildTypes []string `json:"buildTypes,omitempty"`
+}
+
+// CosignKeys defines the signers whose cosign signatures are verified, and how
+// many of them must have signed an image for it to be updated.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.threshold) || self.threshold <= size(self.keys)",message="threshold must not exceed the number of keys"
+type CosignKeys struct {
+	// Keys are the public keys of the signers.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MinItems=1
+	Keys []CosignNamedKey `json:"keys"`
+
+	// Threshold is the number of signers that must have signed the image.
+	// Defaults to all signers.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Threshold *int32 `json:"threshold,omitempty"`
+}
+
+// CosignNamedKey defines the public key of a named signer.
+type CosignNamedKey struct {
+	// Name identifies the signer in logs and status (e.g. "build" or "scanner").
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// PublicKey references a Kubernetes Secret in the same namespace as the
+	// ImageUpdater CR that holds the PEM-encoded ECDSA public key of the signer.
+	PublicKey SecretRef `json:"publicKey"`
+}
+
+// CosignKeyless defines the trust anchors and the expected signer for cosign
+// keyless verification. Signatures must be stored in a sigstore bundle, which
+// is verified offline: the signing certificate must chain up to the trusted
+// roots, and the transparency log entry must carry a valid signed entry
+// timestamp and inclusion proof.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.identity) != has(self.identityRegexp)",message="exactly one of identity or identityRegexp must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.issuer) != has(self.issuerRegexp)",message="exactly one of issuer or issuerRegexp must be set"
+type CosignKeyless struct {
+	// TrustedRoots references a Kubernetes Secret in the same namespace as the
+	// ImageUpdater CR that holds the PEM-encoded Fulcio root and intermediate
+	// certificates. Self-signed certificates are used as trust anchors.
+	TrustedRoots SecretRef `json:"trustedRoots"`
+
+	// RekorPublicKey references a Kubernetes Secret in the same namespace as
+	// the ImageUpdater CR that holds the PEM-encoded ECDSA public key of the
+	// Rekor transparency log.
+	RekorPublicKey SecretRef `json:"rekorPublicKey"`
+
+	// Identity is the exact identity of the signer, i.e. an email address or
+	// URI in the subject alternative names of the signing certificate (e.g.
+	// "https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main").
+	// +optional
+	Identity *string `json:"identity,omitempty"`
+
+	// IdentityRegexp is a regular expression that must match the whole
+	// identity of the signer.
+	// +optional
+	IdentityRegexp *string `json:"identityRegexp,omitempty"`
+
+	// Issuer is the exact issuer of the OIDC token the signer authenticated
+	// with (e.g. "https://token.actions.githubusercontent.com").
+	// +optional
+	Issuer *string `json:"issuer,omitempty"`
+
+	// IssuerRegexp is a regular expression that must match the whole issuer
+	// of the OIDC token the signer authenticated with.
+	// +optional
+	IssuerRegexp *string `json:"issuerRegexp,omitempty"`
+}
+
+// NotationVerification defines the trust policy and trust stores for Notation
+// verification. Signatures are discovered as OCI referrers of the image, and
+// both JWS and COSE signature envelopes are supported.
+type NotationVerification struct {
+	// TrustPolicy references a Kubernetes Secret in the same namespace as the
+	// ImageUpdater CR that holds a Notation trust policy document in JSON
+	// format. The trust policy applying to the image's repository selects the
+	// verification level, trust stores and trusted identities.
+	TrustPolicy SecretRef `json:"trustPolicy"`
+
+	// TrustStores provides the certificates of the trust stores referenced
+	// by the trust policy.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MinItems=1
+	TrustStores []NotationTrustStore `json:"trustStores"`
+}
+
+// NotationTrustStore defines a named set of trusted certificates.
+type NotationTrustStore struct {
+	// Name is the name of the trust store as referenced by the trust policy,
+	// in the form "<type>:<name>". Supported types are "ca" and
+	// "signingAuthority".
+	// +kubebuilder:validation:Pattern=`^(ca|signingAuthority):[a-zA-Z0-9_.-]+$`
+	Name string `json:"name"`
+
+	// Certificates references a Kubernetes Secret in the same namespace as
+	// the ImageUpdater CR that holds the PEM-encoded certificates of the
+	// trust store.
+	Certificates SecretRef `json:"certificates"`
 }
 
 // SecretRef identifies a specific key within a Kubernetes Secret.
@@ -412,6 +863,16 @@ type ImageUpdaterStatus struct {
 	// +listType=atomic
 	RecentUpdates []RecentUpdate `json:"recentUpdates,omitempty"`
 
+	// HeldUpdates contains the list of available image updates that were held back during the last update cycle.
+	// +optional
+	// +listType=atomic
+	HeldUpdates []HeldUpdate `json:"heldUpdates,omitempty"`
+
+	// PinnedImages contains the list of images that were pinned to a version during the last update cycle.
+	// +optional
+	// +listType=atomic
+	PinnedImages []PinnedImage `json:"pinnedImages,omitempty"`
+
 	// Conditions represent the latest available observations of the resource's state.
 	// +optional
 	// +listType=map
@@ -442,6 +903,52 @@ type RecentUpdate struct {
 	Message string `json:"message,omitempty"`
 }
 
+// HeldUpdate records an available image update that was held back during the last update cycle.
+type HeldUpdate struct {
+	// Alias is the alias of the image configuration whose update was held back.
+	Alias string `json:"alias"`
+
+	// Image is the full image reference.
+	Image string `json:"image"`
+
+	// Version is the tag or digest that was held back.
+	Version string `json:"version"`
+
+	// Reason is a machine-readable identifier for why the update was held back.
+	Reason string `json:"reason"`
+
+	// ApplicationsAffected is the number of applications in which this update was held back.
+	// +kubebuilder:validation:Minimum=0
+	ApplicationsAffected int32 `json:"applicationsAffected"`
+
+	// Message provides a human-readable description of why the update was held back.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PinnedImage records an image that was pinned to a version during the last update cycle.
+type PinnedImage struct {
+	// Alias is the alias of the pinned image configuration.
+	Alias string `json:"alias"`
+
+	// Image is the full image reference.
+	Image string `json:"image"`
+
+	// Version is the tag the image is pinned to.
+	Version string `json:"version"`
+
+	// Until is the time at which the pin expires.
+	Until metav1.Time `json:"until"`
+
+	// Reason is the reason given for the pin.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// ApplicationsAffected is the number of applications in which this image is pinned.
+	// +kubebuilder:validation:Minimum=0
+	ApplicationsAffected int32 `json:"applicationsAffected"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Apps",type=integer,JSONPath=`.status.applicationsMatched`
@@ -25,6 +25,31 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdmissionHook) DeepCopyInto(out *AdmissionHook) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.FailurePolicy != nil {
+		in, out := &in.FailurePolicy, &out.FailurePolicy
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdmissionHook.
+func (in *AdmissionHook) DeepCopy() *AdmissionHook {
+	if in == nil {
+		return nil
+	}
+	out := new(AdmissionHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationRef) DeepCopyInto(out *ApplicationRef) {
 	*out = *in
@@ -72,6 +97,51 @@ func (in *ApplicationRef) DeepCopy() *ApplicationRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttestationsVerification) DeepCopyInto(out *AttestationsVerification) {
+	*out = *in
+	if in.SLSAProvenance != nil {
+		in, out := &in.SLSAProvenance, &out.SLSAProvenance
+		*out = new(SLSAProvenancePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttestationsVerification.
+func (in *AttestationsVerification) DeepCopy() *AttestationsVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(AttestationsVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalVerSettings) DeepCopyInto(out *CalVerSettings) {
+	*out = *in
+	if in.Layout != nil {
+		in, out := &in.Layout, &out.Layout
+		*out = new(string)
+		**out = **in
+	}
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalVerSettings.
+func (in *CalVerSettings) DeepCopy() *CalVerSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(CalVerSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommonUpdateSettings) DeepCopyInto(out *CommonUpdateSettings) {
 	*out = *in
@@ -105,6 +175,61 @@ func (in *CommonUpdateSettings) DeepCopyInto(out *CommonUpdateSettings) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RequireAllPlatforms != nil {
+		in, out := &in.RequireAllPlatforms, &out.RequireAllPlatforms
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SemVer != nil {
+		in, out := &in.SemVer, &out.SemVer
+		*out = new(SemVerSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CalVer != nil {
+		in, out := &in.CalVer, &out.CalVer
+		*out = new(CalVerSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Label != nil {
+		in, out := &in.Label, &out.Label
+		*out = new(LabelSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinAge != nil {
+		in, out := &in.MinAge, &out.MinAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBump != nil {
+		in, out := &in.MaxBump, &out.MaxBump
+		*out = new(string)
+		**out = **in
+	}
+	if in.PinDigest != nil {
+		in, out := &in.PinDigest, &out.PinDigest
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Prerelease != nil {
+		in, out := &in.Prerelease, &out.Prerelease
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Vulnerabilities != nil {
+		in, out := &in.Vulnerabilities, &out.Vulnerabilities
+		*out = new(VulnerabilitySettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequiredLabels != nil {
+		in, out := &in.RequiredLabels, &out.RequiredLabels
+		*out = make([]LabelMatch, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenLabels != nil {
+		in, out := &in.ForbiddenLabels, &out.ForbiddenLabels
+		*out = make([]LabelMatch, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonUpdateSettings.
@@ -117,6 +242,84 @@ func (in *CommonUpdateSettings) DeepCopy() *CommonUpdateSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CosignKeyless) DeepCopyInto(out *CosignKeyless) {
+	*out = *in
+	out.TrustedRoots = in.TrustedRoots
+	out.RekorPublicKey = in.RekorPublicKey
+	if in.Identity != nil {
+		in, out := &in.Identity, &out.Identity
+		*out = new(string)
+		**out = **in
+	}
+	if in.IdentityRegexp != nil {
+		in, out := &in.IdentityRegexp, &out.IdentityRegexp
+		*out = new(string)
+		**out = **in
+	}
+	if in.Issuer != nil {
+		in, out := &in.Issuer, &out.Issuer
+		*out = new(string)
+		**out = **in
+	}
+	if in.IssuerRegexp != nil {
+		in, out := &in.IssuerRegexp, &out.IssuerRegexp
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CosignKeyless.
+func (in *CosignKeyless) DeepCopy() *CosignKeyless {
+	if in == nil {
+		return nil
+	}
+	out := new(CosignKeyless)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CosignKeys) DeepCopyInto(out *CosignKeys) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]CosignNamedKey, len(*in))
+		copy(*out, *in)
+	}
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CosignKeys.
+func (in *CosignKeys) DeepCopy() *CosignKeys {
+	if in == nil {
+		return nil
+	}
+	out := new(CosignKeys)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CosignNamedKey) DeepCopyInto(out *CosignNamedKey) {
+	*out = *in
+	out.PublicKey = in.PublicKey
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CosignNamedKey.
+func (in *CosignNamedKey) DeepCopy() *CosignNamedKey {
+	if in == nil {
+		return nil
+	}
+	out := new(CosignNamedKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitConfig) DeepCopyInto(out *GitConfig) {
 	*out = *in
@@ -152,6 +355,21 @@ func (in *GitConfig) DeepCopy() *GitConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeldUpdate) DeepCopyInto(out *HeldUpdate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeldUpdate.
+func (in *HeldUpdate) DeepCopy() *HeldUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(HeldUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmTarget) DeepCopyInto(out *HelmTarget) {
 	*out = *in
@@ -200,6 +418,16 @@ func (in *ImageConfig) DeepCopyInto(out *ImageConfig) {
 		*out = new(ImagesVerification)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Pin != nil {
+		in, out := &in.Pin, &out.Pin
+		*out = new(ImagePin)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SBOM != nil {
+		in, out := &in.SBOM, &out.SBOM
+		*out = new(SBOMPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageConfig.
@@ -212,6 +440,22 @@ func (in *ImageConfig) DeepCopy() *ImageConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePin) DeepCopyInto(out *ImagePin) {
+	*out = *in
+	in.Until.DeepCopyInto(&out.Until)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePin.
+func (in *ImagePin) DeepCopy() *ImagePin {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePin)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageUpdater) DeepCopyInto(out *ImageUpdater) {
 	*out = *in
@@ -296,6 +540,11 @@ func (in *ImageUpdaterSpec) DeepCopyInto(out *ImageUpdaterSpec) {
 		*out = new(ImagesVerification)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AdmissionHook != nil {
+		in, out := &in.AdmissionHook, &out.AdmissionHook
+		*out = new(AdmissionHook)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageUpdaterSpec.
@@ -326,6 +575,18 @@ func (in *ImageUpdaterStatus) DeepCopyInto(out *ImageUpdaterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.HeldUpdates != nil {
+		in, out := &in.HeldUpdates, &out.HeldUpdates
+		*out = make([]HeldUpdate, len(*in))
+		copy(*out, *in)
+	}
+	if in.PinnedImages != nil {
+		in, out := &in.PinnedImages, &out.PinnedImages
+		*out = make([]PinnedImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -358,6 +619,31 @@ func (in *ImagesVerification) DeepCopyInto(out *ImagesVerification) {
 		*out = new(SecretRef)
 		**out = **in
 	}
+	if in.CosignKeys != nil {
+		in, out := &in.CosignKeys, &out.CosignKeys
+		*out = new(CosignKeys)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CosignKeyless != nil {
+		in, out := &in.CosignKeyless, &out.CosignKeyless
+		*out = new(CosignKeyless)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notation != nil {
+		in, out := &in.Notation, &out.Notation
+		*out = new(NotationVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Attestations != nil {
+		in, out := &in.Attestations, &out.Attestations
+		*out = new(AttestationsVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlatformVerification != nil {
+		in, out := &in.PlatformVerification, &out.PlatformVerification
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagesVerification.
@@ -390,6 +676,51 @@ func (in *KustomizeTarget) DeepCopy() *KustomizeTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelMatch) DeepCopyInto(out *LabelMatch) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelMatch.
+func (in *LabelMatch) DeepCopy() *LabelMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LabelSettings) DeepCopyInto(out *LabelSettings) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Format != nil {
+		in, out := &in.Format, &out.Format
+		*out = new(string)
+		**out = **in
+	}
+	if in.Constraint != nil {
+		in, out := &in.Constraint, &out.Constraint
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelSettings.
+func (in *LabelSettings) DeepCopy() *LabelSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(LabelSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManifestTarget) DeepCopyInto(out *ManifestTarget) {
 	*out = *in
@@ -420,6 +751,59 @@ func (in *ManifestTarget) DeepCopy() *ManifestTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotationTrustStore) DeepCopyInto(out *NotationTrustStore) {
+	*out = *in
+	out.Certificates = in.Certificates
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotationTrustStore.
+func (in *NotationTrustStore) DeepCopy() *NotationTrustStore {
+	if in == nil {
+		return nil
+	}
+	out := new(NotationTrustStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotationVerification) DeepCopyInto(out *NotationVerification) {
+	*out = *in
+	out.TrustPolicy = in.TrustPolicy
+	if in.TrustStores != nil {
+		in, out := &in.TrustStores, &out.TrustStores
+		*out = make([]NotationTrustStore, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotationVerification.
+func (in *NotationVerification) DeepCopy() *NotationVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(NotationVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinnedImage) DeepCopyInto(out *PinnedImage) {
+	*out = *in
+	in.Until.DeepCopyInto(&out.Until)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinnedImage.
+func (in *PinnedImage) DeepCopy() *PinnedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(PinnedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PluginTarget) DeepCopyInto(out *PluginTarget) {
 	*out = *in
@@ -526,6 +910,56 @@ func (in *RecentUpdate) DeepCopy() *RecentUpdate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SBOMPolicy) DeepCopyInto(out *SBOMPolicy) {
+	*out = *in
+	if in.DeniedLicenses != nil {
+		in, out := &in.DeniedLicenses, &out.DeniedLicenses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SBOMPolicy.
+func (in *SBOMPolicy) DeepCopy() *SBOMPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SBOMPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLSAProvenancePolicy) DeepCopyInto(out *SLSAProvenancePolicy) {
+	*out = *in
+	if in.BuilderIDs != nil {
+		in, out := &in.BuilderIDs, &out.BuilderIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SourceRepositories != nil {
+		in, out := &in.SourceRepositories, &out.SourceRepositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BuildTypes != nil {
+		in, out := &in.BuildTypes, &out.BuildTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLSAProvenancePolicy.
+func (in *SLSAProvenancePolicy) DeepCopy() *SLSAProvenancePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SLSAProvenancePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretRef) DeepCopyInto(out *SecretRef) {
 	*out = *in
@@ -541,6 +975,71 @@ func (in *SecretRef) DeepCopy() *SecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SemVerSettings) DeepCopyInto(out *SemVerSettings) {
+	*out = *in
+	if in.Relaxed != nil {
+		in, out := &in.Relaxed, &out.Relaxed
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SemVerSettings.
+func (in *SemVerSettings) DeepCopy() *SemVerSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(SemVerSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VulnerabilitySettings) DeepCopyInto(out *VulnerabilitySettings) {
+	*out = *in
+	if in.MaxCritical != nil {
+		in, out := &in.MaxCritical, &out.MaxCritical
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxHigh != nil {
+		in, out := &in.MaxHigh, &out.MaxHigh
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxMedium != nil {
+		in, out := &in.MaxMedium, &out.MaxMedium
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxLow != nil {
+		in, out := &in.MaxLow, &out.MaxLow
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RequireReport != nil {
+		in, out := &in.RequireReport, &out.RequireReport
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VulnerabilitySettings.
+func (in *VulnerabilitySettings) DeepCopy() *VulnerabilitySettings {
+	if in == nil {
+		return nil
+	}
+	out := new(VulnerabilitySettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WriteBackConfig) DeepCopyInto(out *WriteBackConfig) {
 	*out = *in
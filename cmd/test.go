@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/argoproj-labs/argocd-image-updater/pkg/argocd"
 	"github.com/argoproj-labs/argocd-image-updater/pkg/common"
@@ -13,6 +14,7 @@ import (
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/options"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -22,6 +24,7 @@ import (
 func newTestCommand() *cobra.Command {
 	var (
 		semverConstraint   string
+		relaxedSemVer      bool
 		strategy           string
 		registriesConfPath string
 		logLevel           string
@@ -31,6 +34,15 @@ func newTestCommand() *cobra.Command {
 		ignoreTags         []string
 		rateLimit          int
 		platforms          []string
+		calverLayout       string
+		calverConstraints  []string
+		labelName          string
+		labelFormat        string
+		labelConstraint    string
+		minAge             time.Duration
+		maxBump            string
+		pinDigest          bool
+		prerelease         []string
 	)
 	var runCmd = &cobra.Command{
 		Use:   "test IMAGE",
@@ -54,6 +66,21 @@ argocd-image-updater test nginx --semver-constraint v1.17.x
 
 # Check for the latest built image for a tag that matches a pattern
 argocd-image-updater test nginx --allow-tags '^1.19.\d+(\-.*)*$' --update-strategy latest
+
+# Check for the latest calendar versioned tag released within the same year
+argocd-image-updater test ubuntu --update-strategy calver --calver-layout YY.0M --calver-constraint same-year
+
+# Check for the image with the highest version in its OCI version label, only
+# considering versions within the 1.x branch
+argocd-image-updater test nginx --update-strategy label --label-constraint '~1'
+
+# Check to which version nginx 1.25.3 would be updated to, without leaving the
+# 1.25 minor version
+argocd-image-updater test nginx:1.25.3 --max-bump patch
+
+# Check for the latest release candidate or release of nginx, and show which
+# tags are excluded by the pre-release channel
+argocd-image-updater test nginx --prerelease rc
 `,
 		Run: func(cmd *cobra.Command, args []string) {
 			// Create a root context and logger for the command
@@ -91,11 +118,35 @@ argocd-image-updater test nginx --allow-tags '^1.19.\d+(\-.*)*$' --update-strate
 
 			vc.Strategy = img.ParseUpdateStrategy(imgCtx, strategy)
 
+			if vc.Strategy == image.StrategyCalVer || vc.Strategy == image.StrategyLabel {
+				vc.CalVer, err = image.ParseCalVerConstraint(calverLayout, calverConstraints)
+				if err != nil {
+					imgLogger.Fatalf("invalid calver configuration: %v", err)
+				}
+			}
+
+			if vc.Strategy == image.StrategyLabel {
+				vc.Label, err = image.ParseLabelConstraint(labelName, labelFormat, labelConstraint)
+				if err != nil {
+					imgLogger.Fatalf("invalid label configuration: %v", err)
+				}
+			}
+
 			if allowTags != "" {
 				vc.MatchFunc, vc.MatchArgs = img.ParseMatch(imgCtx, allowTags)
 			}
 
+			vc.RelaxedSemVer = relaxedSemVer
 			vc.IgnoreList = ignoreTags
+			vc.MinAge = minAge
+			vc.MaxBump, err = image.ParseVersionBump(maxBump)
+			if err != nil {
+				imgLogger.Fatalf("invalid max bump: %v", err)
+			}
+			vc.Prerelease, err = image.ParsePrereleasePolicy(prerelease)
+			if err != nil {
+				imgLogger.Fatalf("invalid pre-release policy: %v", err)
+			}
 
 			imgLogger.Infof("retrieving information about image")
 
@@ -110,7 +161,7 @@ argocd-image-updater test nginx --allow-tags '^1.19.\d+(\-.*)*$' --update-strate
 				}
 				vc.Options = vc.Options.WithPlatform(os, arch, variant)
 			}
-			vc.Options = vc.Options.WithMetadata(vc.Strategy.NeedsMetadata())
+			vc.Options = vc.Options.WithMetadata(vc.NeedsMetadata())
 
 			// registriesConfPath defaults to "" so we can tell whether the user set it
 			// explicitly. When unset, fall back to the well-known default path only if it
@@ -178,23 +229,48 @@ argocd-image-updater test nginx --allow-tags '^1.19.\d+(\-.*)*$' --update-strate
 
 			imgLogger.Infof("Found %d tags in registry", len(tags.Tags()))
 
-			upImg, err := img.GetNewestVersionFromTags(imgCtx, vc, tags)
+			candidate, err := img.GetUpdateCandidate(imgCtx, vc, tags)
 			if err != nil {
 				imgLogger.Fatalf("could not get updateable image from tags: %v", err)
 			}
+			if candidate.Soaking != nil {
+				imgLogger.Infof("image %s has not yet reached the minimum age of %s", img.WithTag(candidate.Soaking), minAge)
+			}
+			for _, excluded := range candidate.Excluded {
+				imgLogger.Infof("image %s is excluded by pre-release policy %s", img.WithTag(excluded), vc.Prerelease)
+			}
+			upImg := candidate.Tag
 			if upImg == nil {
 				imgLogger.Infof("no newer version of image found")
 				return
 			}
 
+			if pinDigest && vc.Strategy != image.StrategyDigest {
+				digest, err := registry.GetTagDigest(imgCtx, regClient, upImg.TagName, vc.Options)
+				if err != nil {
+					imgLogger.Fatalf("could not pin tag to its digest: %v", err)
+				}
+				upImg = tag.NewImageTag(upImg.TagName, *upImg.TagDate, digest)
+			}
+
 			imgLogger.Infof("latest image according to constraint is %s", img.WithTag(upImg))
 		},
 	}
 
 	runCmd.Flags().StringVar(&semverConstraint, "semver-constraint", "", "only consider tags matching semantic version constraint")
+	runCmd.Flags().BoolVar(&relaxedSemVer, "relaxed-semver", false, "parse tags with prefixes, suffixes or four version components for semver strategy")
 	runCmd.Flags().StringVar(&allowTags, "allow-tags", "", "only consider tags in registry that satisfy the match function")
 	runCmd.Flags().StringArrayVar(&ignoreTags, "ignore-tags", nil, "ignore tags in registry that match given glob pattern")
-	runCmd.Flags().StringVar(&strategy, "update-strategy", "semver", "update strategy to use (one of semver, newest-build, alphabetical, digest)")
+	runCmd.Flags().StringVar(&strategy, "update-strategy", "semver", "update strategy to use (one of semver, newest-build, alphabetical, digest, calver, label)")
+	runCmd.Flags().StringVar(&calverLayout, "calver-layout", tag.DefaultCalVerLayout, "layout of calendar versioned tags for the calver strategy")
+	runCmd.Flags().StringArrayVar(&calverConstraints, "calver-constraint", nil, "only consider calendar versions matching constraint (one of same-year, same-month, max-age:<n><d|w|m|y>)")
+	runCmd.Flags().StringVar(&labelName, "label-name", image.DefaultVersionLabel, "name of the image label holding the version for the label strategy")
+	runCmd.Flags().StringVar(&labelFormat, "label-format", "semver", "format of the version label for the label strategy (one of semver, calver)")
+	runCmd.Flags().StringVar(&labelConstraint, "label-constraint", "", "only consider images whose version label matches semantic version constraint for the label strategy")
+	runCmd.Flags().DurationVar(&minAge, "min-age", 0, "only consider tags that have been pushed at least this long ago")
+	runCmd.Flags().StringVar(&maxBump, "max-bump", "", "largest version bump from the image's tag to allow for semver strategy (one of patch, minor, major)")
+	runCmd.Flags().StringSliceVar(&prerelease, "prerelease", nil, "pre-release channel (one of none, rc, beta, alpha, any) or list of allowed pre-release identifiers for semver strategy")
+	runCmd.Flags().BoolVar(&pinDigest, "pin-digest", false, "pin the new tag to the digest it currently points to")
 	runCmd.Flags().StringVar(&registriesConfPath, "registries-conf-path", "", "path to registries configuration")
 	runCmd.Flags().StringVar(&logLevel, "loglevel", "debug", "log level to use (one of trace, debug, info, warn, error)")
 	runCmd.Flags().StringVar(&kubeConfig, "kubeconfig", "", "path to your Kubernetes client configuration")
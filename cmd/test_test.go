@@ -17,9 +17,19 @@ func TestNewTestCommand(t *testing.T) {
 	asser.Greater(len(testCmd.Long), 100)
 	asser.NotNil(testCmd.Run)
 	asser.Equal("", testCmd.Flag("semver-constraint").Value.String())
+	asser.Equal("false", testCmd.Flag("relaxed-semver").Value.String())
 	asser.Equal("", testCmd.Flag("allow-tags").Value.String())
 	asser.Equal("[]", testCmd.Flag("ignore-tags").Value.String())
 	asser.Equal("semver", testCmd.Flag("update-strategy").Value.String())
+	asser.Equal("YYYY.0M.0D", testCmd.Flag("calver-layout").Value.String())
+	asser.Equal("[]", testCmd.Flag("calver-constraint").Value.String())
+	asser.Equal("org.opencontainers.image.version", testCmd.Flag("label-name").Value.String())
+	asser.Equal("semver", testCmd.Flag("label-format").Value.String())
+	asser.Equal("", testCmd.Flag("label-constraint").Value.String())
+	asser.Equal("0s", testCmd.Flag("min-age").Value.String())
+	asser.Equal("", testCmd.Flag("max-bump").Value.String())
+	asser.Equal("[]", testCmd.Flag("prerelease").Value.String())
+	asser.Equal("false", testCmd.Flag("pin-digest").Value.String())
 	asser.Equal("", testCmd.Flag("registries-conf-path").Value.String())
 	asser.Equal("debug", testCmd.Flag("loglevel").Value.String())
 	asser.Equal("", testCmd.Flag("kubeconfig").Value.String())
@@ -44,6 +44,7 @@ func (r *ImageUpdaterReconciler) RunImageUpdater(ctx context.Context, cr *iuapi.
 	}
 
 	syncState := argocd.NewSyncIterationState()
+	admissionHook := argocd.NewAdmissionHook(cr.Spec.AdmissionHook)
 
 	// Allow a maximum of MaxConcurrentApps number of goroutines to exist at the
 	// same time. If in warm-up mode, set to 1 explicitly.
@@ -60,6 +61,8 @@ func (r *ImageUpdaterReconciler) RunImageUpdater(ctx context.Context, cr *iuapi.
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var allChanges []argocd.ChangeEntry
+	var allHeld []argocd.HeldEntry
+	var allPinned []argocd.PinnedEntry
 	wg.Add(len(appList))
 
 	for app, curApplication := range appList {
@@ -97,6 +100,7 @@ func (r *ImageUpdaterReconciler) RunImageUpdater(ctx context.Context, cr *iuapi.
 				GitCommitMethod:        r.Config.GitCommitMethod,
 				DisableKubeEvents:      r.Config.DisableKubeEvents,
 				GitCreds:               r.Config.GitCreds,
+				AdmissionHook:          admissionHook,
 			}
 			res := argocd.UpdateApplication(appCtx, upconf, syncState)
 
@@ -107,6 +111,8 @@ func (r *ImageUpdaterReconciler) RunImageUpdater(ctx context.Context, cr *iuapi.
 			result.NumImagesUpdated += res.NumImagesUpdated
 			result.NumSkipped += res.NumSkipped
 			allChanges = append(allChanges, res.Changes...)
+			allHeld = append(allHeld, res.Held...)
+			allPinned = append(allPinned, res.Pinned...)
 			mu.Unlock()
 
 			if !warmUp && r.Config != nil && r.Config.EnableCRMetrics && metrics.ImageUpdaterCR() != nil {
@@ -122,6 +128,8 @@ func (r *ImageUpdaterReconciler) RunImageUpdater(ctx context.Context, cr *iuapi.
 	wg.Wait()
 
 	result.Changes = allChanges
+	result.Held = allHeld
+	result.Pinned = allPinned
 
 	// Set images-watched gauge once here with the CR-wide aggregate. We cannot set it inside the
 	// per-application goroutines: each goroutine would overwrite the same gauge with that app's
@@ -81,6 +81,9 @@ func (r *ImageUpdaterReconciler) updateStatusAfterReconcile(
 			imageUpdater.Status.LastUpdatedAt = &now
 			imageUpdater.Status.RecentUpdates = buildRecentUpdates(result.Changes, now)
 		}
+		// Held updates always reflect the last cycle only.
+		imageUpdater.Status.HeldUpdates = buildHeldUpdates(result.Held)
+		imageUpdater.Status.PinnedImages = buildPinnedImages(result.Pinned)
 
 		setCompletionConditions(imageUpdater, result, reconcileErr)
 
@@ -136,6 +139,102 @@ func buildRecentUpdates(changes []argocd.ChangeEntry, now metav1.Time) []api.Rec
 	return result
 }
 
+// buildHeldUpdates converts the HeldEntry list from a reconciliation into
+// the HeldUpdate status slice, aggregating by image alias, version and reason.
+func buildHeldUpdates(held []argocd.HeldEntry) []api.HeldUpdate {
+	if len(held) == 0 {
+		return nil
+	}
+
+	type aggregateKey struct {
+		alias   string
+		version string
+		reason  argocd.HeldReason
+	}
+	aggregated := make(map[aggregateKey]*api.HeldUpdate)
+	var order []aggregateKey
+
+	for _, h := range held {
+		alias := h.Image.ImageAlias
+		if alias == "" {
+			alias = h.Image.ImageName
+		}
+
+		k := aggregateKey{
+			alias:   alias,
+			version: h.Tag.String(),
+			reason:  h.Reason,
+		}
+
+		if existing, ok := aggregated[k]; ok {
+			existing.ApplicationsAffected++
+		} else {
+			aggregated[k] = &api.HeldUpdate{
+				Alias:                alias,
+				Image:                h.Image.GetFullNameWithoutTag(),
+				Version:              h.Tag.String(),
+				Reason:               string(h.Reason),
+				ApplicationsAffected: 1,
+				Message:              h.Message,
+			}
+			order = append(order, k)
+		}
+	}
+
+	result := make([]api.HeldUpdate, 0, len(order))
+	for _, k := range order {
+		result = append(result, *aggregated[k])
+	}
+	return result
+}
+
+// buildPinnedImages converts the PinnedEntry list from a reconciliation into
+// the PinnedImage status slice, aggregating by image alias and version.
+func buildPinnedImages(pinned []argocd.PinnedEntry) []api.PinnedImage {
+	if len(pinned) == 0 {
+		return nil
+	}
+
+	type aggregateKey struct {
+		alias   string
+		version string
+	}
+	aggregated := make(map[aggregateKey]*api.PinnedImage)
+	var order []aggregateKey
+
+	for _, p := range pinned {
+		alias := p.Image.ImageAlias
+		if alias == "" {
+			alias = p.Image.ImageName
+		}
+
+		k := aggregateKey{
+			alias:   alias,
+			version: p.Tag.String(),
+		}
+
+		if existing, ok := aggregated[k]; ok {
+			existing.ApplicationsAffected++
+		} else {
+			aggregated[k] = &api.PinnedImage{
+				Alias:                alias,
+				Image:                p.Image.GetFullNameWithoutTag(),
+				Version:              p.Tag.String(),
+				Until:                metav1.NewTime(p.Until),
+				Reason:               p.Reason,
+				ApplicationsAffected: 1,
+			}
+			order = append(order, k)
+		}
+	}
+
+	result := make([]api.PinnedImage, 0, len(order))
+	for _, k := range order {
+		result = append(result, *aggregated[k])
+	}
+	return result
+}
+
 // setCompletionConditions sets Ready, Reconciling, and Error conditions
 // based on reconciliation results.
 func setCompletionConditions(
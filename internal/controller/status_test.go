@@ -135,6 +135,106 @@ func TestBuildRecentUpdates(t *testing.T) {
 	})
 }
 
+func TestBuildHeldUpdates(t *testing.T) {
+	t.Run("nil held returns nil", func(t *testing.T) {
+		result := buildHeldUpdates(nil)
+		assert.Nil(t, result)
+	})
+
+	t.Run("same image held in multiple apps aggregates", func(t *testing.T) {
+		held := []argocd.HeldEntry{
+			{
+				Image: &image.ContainerImage{
+					ImageName:  "nginx",
+					ImageAlias: "web",
+				},
+				Tag:     tag.NewImageTag("1.21", time.Now(), ""),
+				Reason:  argocd.HeldReasonMinAge,
+				Message: "Update to 1.21 deferred.",
+			},
+			{
+				Image: &image.ContainerImage{
+					ImageName:  "nginx",
+					ImageAlias: "web",
+				},
+				Tag:     tag.NewImageTag("1.21", time.Now(), ""),
+				Reason:  argocd.HeldReasonMinAge,
+				Message: "Update to 1.21 deferred.",
+			},
+		}
+
+		result := buildHeldUpdates(held)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "web", result[0].Alias)
+		assert.Equal(t, "nginx", result[0].Image)
+		assert.Equal(t, "1.21", result[0].Version)
+		assert.Equal(t, "MinAge", result[0].Reason)
+		assert.Equal(t, int32(2), result[0].ApplicationsAffected)
+		assert.Equal(t, "Update to 1.21 deferred.", result[0].Message)
+	})
+
+	t.Run("different versions produce separate entries", func(t *testing.T) {
+		held := []argocd.HeldEntry{
+			{
+				Image:  &image.ContainerImage{ImageName: "nginx"},
+				Tag:    tag.NewImageTag("1.21", time.Now(), ""),
+				Reason: argocd.HeldReasonMinAge,
+			},
+			{
+				Image:  &image.ContainerImage{ImageName: "nginx"},
+				Tag:    tag.NewImageTag("1.22", time.Now(), ""),
+				Reason: argocd.HeldReasonMinAge,
+			},
+		}
+
+		result := buildHeldUpdates(held)
+		assert.Len(t, result, 2)
+		assert.Equal(t, "nginx", result[0].Alias)
+		assert.Equal(t, "1.21", result[0].Version)
+		assert.Equal(t, "1.22", result[1].Version)
+	})
+}
+
+func TestBuildPinnedImages(t *testing.T) {
+	t.Run("nil pinned returns nil", func(t *testing.T) {
+		result := buildPinnedImages(nil)
+		assert.Nil(t, result)
+	})
+
+	t.Run("same image pinned in multiple apps aggregates", func(t *testing.T) {
+		until := time.Now().Add(time.Hour).Truncate(time.Second)
+		pinned := []argocd.PinnedEntry{
+			{
+				Image: &image.ContainerImage{
+					ImageName:  "nginx",
+					ImageAlias: "web",
+				},
+				Tag:    tag.NewImageTag("1.21", time.Unix(0, 0), ""),
+				Until:  until,
+				Reason: "INC-42",
+			},
+			{
+				Image: &image.ContainerImage{
+					ImageName:  "nginx",
+					ImageAlias: "web",
+				},
+				Tag:    tag.NewImageTag("1.21", time.Unix(0, 0), ""),
+				Until:  until,
+				Reason: "INC-42",
+			},
+		}
+
+		result := buildPinnedImages(pinned)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "web", result[0].Alias)
+		assert.Equal(t, "nginx", result[0].Image)
+		assert.Equal(t, "1.21", result[0].Version)
+		assert.True(t, until.Equal(result[0].Until.Time))
+		assert.Equal(t, "INC-42", result[0].Reason)
+		assert.Equal(t, int32(2), result[0].ApplicationsAffected)
+	})
+}
+
 func TestSetCompletionConditions(t *testing.T) {
 	t.Run("successful reconciliation with no errors", func(t *testing.T) {
 		iu := &api.ImageUpdater{
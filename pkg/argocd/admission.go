@@ -0,0 +1,135 @@
+package argocd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	iuapi "github.com/argoproj-labs/argocd-image-updater/api/v1alpha1"
+)
+
+// DefaultAdmissionHookTimeout is the maximum duration of a review by an
+// admission hook, unless configured otherwise
+const DefaultAdmissionHookTimeout = 10 * time.Second
+
+// Failure policies of an admission hook
+const (
+	// AdmissionFailurePolicyFail does not perform an update that could not be
+	// reviewed (the default)
+	AdmissionFailurePolicyFail = "Fail"
+	// AdmissionFailurePolicyIgnore performs an update that could not be
+	// reviewed as if it was allowed
+	AdmissionFailurePolicyIgnore = "Ignore"
+)
+
+// maxAdmissionResponseSize is the maximum size of a response we read from an
+// admission hook
+const maxAdmissionResponseSize = 64 * 1024
+
+// AdmissionHook reviews image updates with an external HTTP endpoint, right
+// before they are written. Use NewAdmissionHook to initialize a new object.
+type AdmissionHook struct {
+	// URL is the endpoint the reviews are posted to
+	URL string
+	// Timeout is the maximum duration of a review
+	Timeout time.Duration
+	// FailOpen allows updates that could not be reviewed
+	FailOpen bool
+
+	client *http.Client
+}
+
+// AdmissionApplication identifies the application of an AdmissionRequest
+type AdmissionApplication struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// AdmissionRequest is the JSON document posted to an admission hook
+type AdmissionRequest struct {
+	Application AdmissionApplication `json:"application"`
+	Alias       string               `json:"alias"`
+	Image       string               `json:"image"`
+	OldTag      string               `json:"oldTag,omitempty"`
+	NewTag      string               `json:"newTag"`
+	Digest      string               `json:"digest,omitempty"`
+	Labels      map[string]string    `json:"labels,omitempty"`
+}
+
+// AdmissionResponse is the JSON document an admission hook responds with
+type AdmissionResponse struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// NewAdmissionHook creates an admission hook from its configuration in the
+// ImageUpdater CR. Returns nil if spec is nil.
+func NewAdmissionHook(spec *iuapi.AdmissionHook) *AdmissionHook {
+	if spec == nil {
+		return nil
+	}
+	hook := &AdmissionHook{
+		URL:     spec.URL,
+		Timeout: DefaultAdmissionHookTimeout,
+	}
+	if spec.Timeout != nil && spec.Timeout.Duration > 0 {
+		hook.Timeout = spec.Timeout.Duration
+	}
+	if spec.FailurePolicy != nil {
+		hook.FailOpen = *spec.FailurePolicy == AdmissionFailurePolicyIgnore
+	}
+	hook.client = &http.Client{Timeout: hook.Timeout}
+	return hook
+}
+
+// Review posts req to the admission hook and returns its response. An error
+// is returned if the hook could not be reached, did not respond in time, or
+// did not respond with a valid response.
+func (h *AdmissionHook) Review(ctx context.Context, req *AdmissionRequest) (*AdmissionResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal admission request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not create admission request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	client := h.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("admission hook %s could not be reached: %w", h.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("admission hook %s responded with HTTP %d", h.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAdmissionResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("could not read response of admission hook %s: %w", h.URL, err)
+	}
+	var review struct {
+		Allowed *bool  `json:"allowed"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.Unmarshal(data, &review); err != nil {
+		return nil, fmt.Errorf("invalid response of admission hook %s: %w", h.URL, err)
+	}
+	if review.Allowed == nil {
+		return nil, fmt.Errorf("invalid response of admission hook %s: field allowed is missing", h.URL)
+	}
+	return &AdmissionResponse{Allowed: *review.Allowed, Reason: review.Reason}, nil
+}
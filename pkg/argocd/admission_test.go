@@ -0,0 +1,128 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	iuapi "github.com/argoproj-labs/argocd-image-updater/api/v1alpha1"
+)
+
+func Test_NewAdmissionHook(t *testing.T) {
+	t.Run("No hook configured", func(t *testing.T) {
+		assert.Nil(t, NewAdmissionHook(nil))
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		hook := NewAdmissionHook(&iuapi.AdmissionHook{URL: "https://admission.example.com/review"})
+		require.NotNil(t, hook)
+		assert.Equal(t, "https://admission.example.com/review", hook.URL)
+		assert.Equal(t, DefaultAdmissionHookTimeout, hook.Timeout)
+		assert.False(t, hook.FailOpen)
+	})
+
+	t.Run("Timeout and failure policy", func(t *testing.T) {
+		hook := NewAdmissionHook(&iuapi.AdmissionHook{
+			URL:           "https://admission.example.com/review",
+			Timeout:       &metav1.Duration{Duration: 3 * time.Second},
+			FailurePolicy: new(AdmissionFailurePolicyIgnore),
+		})
+		require.NotNil(t, hook)
+		assert.Equal(t, 3*time.Second, hook.Timeout)
+		assert.True(t, hook.FailOpen)
+	})
+}
+
+func Test_AdmissionHook_Review(t *testing.T) {
+	req := &AdmissionRequest{
+		Application: AdmissionApplication{Name: "guestbook", Namespace: "argocd"},
+		Alias:       "foobar",
+		Image:       "gcr.io/jannfis/foobar",
+		OldTag:      "1.0.1",
+		NewTag:      "1.0.2",
+		Digest:      "sha256:abcd",
+		Labels:      map[string]string{"org.opencontainers.image.version": "1.0.2"},
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    *AdmissionResponse
+		wantErr string
+	}{
+		{
+			name:   "Allowed",
+			status: http.StatusOK,
+			body:   `{"allowed": true}`,
+			want:   &AdmissionResponse{Allowed: true},
+		},
+		{
+			name:   "Denied with reason",
+			status: http.StatusOK,
+			body:   `{"allowed": false, "reason": "change freeze in effect"}`,
+			want:   &AdmissionResponse{Allowed: false, Reason: "change freeze in effect"},
+		},
+		{
+			name:    "Unexpected status",
+			status:  http.StatusServiceUnavailable,
+			body:    `{"allowed": true}`,
+			wantErr: "responded with HTTP 503",
+		},
+		{
+			name:    "Invalid response",
+			status:  http.StatusOK,
+			body:    `allowed`,
+			wantErr: "invalid response of admission hook",
+		},
+		{
+			name:    "Decision missing",
+			status:  http.StatusOK,
+			body:    `{"reason": "looks good"}`,
+			wantErr: "field allowed is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got AdmissionRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			hook := NewAdmissionHook(&iuapi.AdmissionHook{URL: srv.URL})
+			resp, err := hook.Review(context.Background(), req)
+			assert.Equal(t, *req, got)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+
+	t.Run("Timeout", func(t *testing.T) {
+		done := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-done
+		}))
+		defer srv.Close()
+		defer close(done)
+
+		hook := NewAdmissionHook(&iuapi.AdmissionHook{URL: srv.URL, Timeout: &metav1.Duration{Duration: 50 * time.Millisecond}})
+		_, err := hook.Review(context.Background(), req)
+		assert.ErrorContains(t, err, "could not be reached")
+	})
+}
@@ -388,6 +388,83 @@ func mergeCommonUpdateSettings(settings ...*iuapi.CommonUpdateSettings) *iuapi.C
 		if s.Platforms != nil {
 			merged.Platforms = s.Platforms
 		}
+		if s.RequireAllPlatforms != nil {
+			merged.RequireAllPlatforms = s.RequireAllPlatforms
+		}
+		if s.SemVer != nil {
+			if merged.SemVer == nil {
+				merged.SemVer = &iuapi.SemVerSettings{}
+			}
+			if s.SemVer.Relaxed != nil {
+				merged.SemVer.Relaxed = s.SemVer.Relaxed
+			}
+		}
+		if s.CalVer != nil {
+			if merged.CalVer == nil {
+				merged.CalVer = &iuapi.CalVerSettings{}
+			}
+			if s.CalVer.Layout != nil {
+				merged.CalVer.Layout = s.CalVer.Layout
+			}
+			if s.CalVer.Constraints != nil {
+				merged.CalVer.Constraints = s.CalVer.Constraints
+			}
+		}
+		if s.Label != nil {
+			if merged.Label == nil {
+				merged.Label = &iuapi.LabelSettings{}
+			}
+			if s.Label.Name != nil {
+				merged.Label.Name = s.Label.Name
+			}
+			if s.Label.Format != nil {
+				merged.Label.Format = s.Label.Format
+			}
+			if s.Label.Constraint != nil {
+				merged.Label.Constraint = s.Label.Constraint
+			}
+		}
+		if s.MinAge != nil {
+			merged.MinAge = s.MinAge
+		}
+		if s.MaxBump != nil {
+			merged.MaxBump = s.MaxBump
+		}
+		if s.PinDigest != nil {
+			merged.PinDigest = s.PinDigest
+		}
+		if s.Prerelease != nil {
+			merged.Prerelease = s.Prerelease
+		}
+		if s.Vulnerabilities != nil {
+			if merged.Vulnerabilities == nil {
+				merged.Vulnerabilities = &iuapi.VulnerabilitySettings{}
+			}
+			if s.Vulnerabilities.MaxCritical != nil {
+				merged.Vulnerabilities.MaxCritical = s.Vulnerabilities.MaxCritical
+			}
+			if s.Vulnerabilities.MaxHigh != nil {
+				merged.Vulnerabilities.MaxHigh = s.Vulnerabilities.MaxHigh
+			}
+			if s.Vulnerabilities.MaxMedium != nil {
+				merged.Vulnerabilities.MaxMedium = s.Vulnerabilities.MaxMedium
+			}
+			if s.Vulnerabilities.MaxLow != nil {
+				merged.Vulnerabilities.MaxLow = s.Vulnerabilities.MaxLow
+			}
+			if s.Vulnerabilities.RequireReport != nil {
+				merged.Vulnerabilities.RequireReport = s.Vulnerabilities.RequireReport
+			}
+			if s.Vulnerabilities.Fallback != nil {
+				merged.Vulnerabilities.Fallback = s.Vulnerabilities.Fallback
+			}
+		}
+		if s.RequiredLabels != nil {
+			merged.RequiredLabels = s.RequiredLabels
+		}
+		if s.ForbiddenLabels != nil {
+			merged.ForbiddenLabels = s.ForbiddenLabels
+		}
 	}
 	return merged
 }
@@ -405,8 +482,19 @@ func mergeImagesVerification(settings ...*iuapi.ImagesVerification) *iuapi.Image
 		if s.Enabled != nil {
 			merged.Enabled = s.Enabled
 		}
-		if s.CosignKey != nil {
+		// The verification methods are mutually exclusive, so a method set
+		// at a more specific scope replaces the one inherited.
+		if s.CosignKey != nil || s.CosignKeys != nil || s.CosignKeyless != nil || s.Notation != nil {
 			merged.CosignKey = s.CosignKey
+			merged.CosignKeys = s.CosignKeys
+			merged.CosignKeyless = s.CosignKeyless
+			merged.Notation = s.Notation
+		}
+		if s.Attestations != nil {
+			merged.Attestations = s.Attestations
+		}
+		if s.PlatformVerification != nil {
+			merged.PlatformVerification = s.PlatformVerification
 		}
 	}
 	if !anyNonNil {
@@ -462,10 +550,91 @@ func newImageFromCommonUpdateSettings(ctx context.Context, settings *iuapi.Commo
 	if settings.Platforms != nil {
 		img.Platforms = settings.Platforms
 	}
+	if settings.RequireAllPlatforms != nil {
+		img.RequireAllPlatforms = *settings.RequireAllPlatforms
+	}
+	if settings.SemVer != nil && settings.SemVer.Relaxed != nil {
+		img.RelaxedSemVer = *settings.SemVer.Relaxed
+	}
+	if settings.CalVer != nil {
+		if settings.CalVer.Layout != nil {
+			img.CalVerLayout = *settings.CalVer.Layout
+		}
+		if settings.CalVer.Constraints != nil {
+			img.CalVerConstraints = settings.CalVer.Constraints
+		}
+	}
+	if settings.Label != nil {
+		if settings.Label.Name != nil {
+			img.LabelName = *settings.Label.Name
+		}
+		if settings.Label.Format != nil {
+			img.LabelFormat = *settings.Label.Format
+		}
+		if settings.Label.Constraint != nil {
+			img.LabelConstraint = *settings.Label.Constraint
+		}
+	}
+	if settings.MinAge != nil {
+		img.MinAge = settings.MinAge.Duration
+	}
+	if settings.MaxBump != nil {
+		img.MaxBump = *settings.MaxBump
+	}
+	if settings.PinDigest != nil {
+		img.PinDigest = *settings.PinDigest
+	}
+	if settings.Prerelease != nil {
+		img.Prerelease = settings.Prerelease
+	}
+	if vs := settings.Vulnerabilities; vs != nil {
+		img.Vulnerabilities = &image.VulnerabilityPolicy{
+			MaxCount:      map[image.Severity]int{},
+			RequireReport: true,
+		}
+		for severity, limit := range map[image.Severity]*int32{
+			image.SeverityCritical: vs.MaxCritical,
+			image.SeverityHigh:     vs.MaxHigh,
+			image.SeverityMedium:   vs.MaxMedium,
+			image.SeverityLow:      vs.MaxLow,
+		} {
+			if limit != nil {
+				img.Vulnerabilities.MaxCount[severity] = int(*limit)
+			}
+		}
+		if vs.RequireReport != nil {
+			img.Vulnerabilities.RequireReport = *vs.RequireReport
+		}
+		if vs.Fallback != nil {
+			img.Vulnerabilities.Fallback = *vs.Fallback
+		}
+	}
+	for _, lm := range settings.RequiredLabels {
+		img.RequiredLabels = append(img.RequiredLabels, image.LabelMatch{Key: lm.Key, Value: lm.Value})
+	}
+	for _, lm := range settings.ForbiddenLabels {
+		img.ForbiddenLabels = append(img.ForbiddenLabels, image.LabelMatch{Key: lm.Key, Value: lm.Value})
+	}
 
 	return img
 }
 
+// validateAllowTags compiles the CEL match expression found in the allowTags
+// setting, if any, and returns an error if it is invalid.
+func validateAllowTags(settings *iuapi.CommonUpdateSettings) error {
+	if settings == nil || settings.AllowTags == nil {
+		return nil
+	}
+	opt := strings.SplitN(*settings.AllowTags, ":", 2)
+	if len(opt) != 2 || !strings.EqualFold(opt[0], "cel") {
+		return nil
+	}
+	if _, err := image.NewCELMatcher(opt[1]); err != nil {
+		return fmt.Errorf("invalid allowTags: %w", err)
+	}
+	return nil
+}
+
 // countPullRequestProviders returns the number of providers configured in a PullRequest.
 // A valid PullRequest must have exactly one provider set. When adding a new provider,
 // add a corresponding branch here to keep the count accurate.
@@ -732,19 +901,122 @@ func newImageFromImagesVerification(kubeClient *kube.ImageUpdaterKubernetesClien
 		img.Verify = &image.Verify{}
 	}
 
-	if settings.CosignKey != nil {
+	switch {
+	case settings.CosignKey != nil:
 		var err error
 		img.Verify.CosignKey, err = kubeClient.KubeClient.GetSecretField(appNamespace, settings.CosignKey.SecretName, settings.CosignKey.Key)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch public key secret field: %v", err)
 		}
-	} else {
-		return nil, fmt.Errorf("cosignKey is required when verification is enabled")
+	case settings.CosignKeys != nil:
+		img.Verify.CosignKeys = make([]image.NamedPublicKey, 0, len(settings.CosignKeys.Keys))
+		for _, key := range settings.CosignKeys.Keys {
+			publicKey, err := kubeClient.KubeClient.GetSecretField(appNamespace, key.PublicKey.SecretName, key.PublicKey.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch public key secret field of signer %s: %v", key.Name, err)
+			}
+			img.Verify.CosignKeys = append(img.Verify.CosignKeys, image.NamedPublicKey{Name: key.Name, Key: publicKey})
+		}
+		if settings.CosignKeys.Threshold != nil {
+			img.Verify.Threshold = int(*settings.CosignKeys.Threshold)
+		}
+	case settings.CosignKeyless != nil:
+		var err error
+		img.Verify.Keyless, err = newKeylessVerify(kubeClient, appNamespace, settings.CosignKeyless)
+		if err != nil {
+			return nil, err
+		}
+	case settings.Notation != nil:
+		var err error
+		img.Verify.Notation, err = newNotationVerify(kubeClient, appNamespace, settings.Notation)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cosignKey, cosignKeys, cosignKeyless or notation is required when verification is enabled")
 	}
 
+	if settings.Attestations != nil && settings.Attestations.SLSAProvenance != nil {
+		if settings.Notation != nil {
+			return nil, fmt.Errorf("attestations require a cosign verification method")
+		}
+		slsa := settings.Attestations.SLSAProvenance
+		var err error
+		img.Verify.Provenance, err = image.NewProvenancePolicy(slsa.BuilderIDs, slsa.SourceRepositories, slsa.BuildTypes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attestation settings: %v", err)
+		}
+	}
+
+	if settings.PlatformVerification != nil {
+		var err error
+		img.Verify.PlatformVerification, err = image.ParsePlatformVerification(*settings.PlatformVerification)
+		if err != nil {
+			return nil, err
+		}
+	}
+	img.Verify.Platforms = img.Platforms
+
 	return img, nil
 }
 
+// newKeylessVerify creates the keyless verification policy for the given
+// settings, fetching the trust anchors from their secrets.
+func newKeylessVerify(kubeClient *kube.ImageUpdaterKubernetesClient, appNamespace string, settings *iuapi.CosignKeyless) (*image.KeylessVerify, error) {
+	trustedRoots, err := kubeClient.KubeClient.GetSecretField(appNamespace, settings.TrustedRoots.SecretName, settings.TrustedRoots.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trusted roots secret field: %v", err)
+	}
+	rekorKey, err := kubeClient.KubeClient.GetSecretField(appNamespace, settings.RekorPublicKey.SecretName, settings.RekorPublicKey.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Rekor public key secret field: %v", err)
+	}
+
+	var identity image.CertificateIdentity
+	if settings.Identity != nil {
+		identity.Subject = *settings.Identity
+	}
+	if settings.IdentityRegexp != nil {
+		identity.SubjectRegexp = *settings.IdentityRegexp
+	}
+	if settings.Issuer != nil {
+		identity.Issuer = *settings.Issuer
+	}
+	if settings.IssuerRegexp != nil {
+		identity.IssuerRegexp = *settings.IssuerRegexp
+	}
+
+	kv, err := image.NewKeylessVerify(trustedRoots, rekorKey, identity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyless verification settings: %v", err)
+	}
+	return kv, nil
+}
+
+// newNotationVerify creates the Notation verification policy for the given
+// settings, fetching the trust policy and trust stores from their secrets.
+func newNotationVerify(kubeClient *kube.ImageUpdaterKubernetesClient, appNamespace string, settings *iuapi.NotationVerification) (*image.NotationVerify, error) {
+	trustPolicy, err := kubeClient.KubeClient.GetSecretField(appNamespace, settings.TrustPolicy.SecretName, settings.TrustPolicy.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch trust policy secret field: %v", err)
+	}
+
+	trustStores := make(map[string]string, len(settings.TrustStores))
+	for _, store := range settings.TrustStores {
+		certs, err := kubeClient.KubeClient.GetSecretField(appNamespace, store.Certificates.SecretName, store.Certificates.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch trust store %s secret field: %v", store.Name, err)
+		}
+		trustStores[store.Name] = certs
+	}
+
+	nv, err := image.NewNotationVerify(trustPolicy, trustStores)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notation verification settings: %v", err)
+	}
+	return nv, nil
+}
+
 // parseImageList parses a list of ImageConfig objects from the ImageUpdater CR
 // into a ImageList, which is used internally for image management.
 func parseImageList(ctx context.Context, kubeClient *kube.ImageUpdaterKubernetesClient, appNamespace string, images []iuapi.ImageConfig, appSettings *iuapi.CommonUpdateSettings, appImagesVerification *iuapi.ImagesVerification, webhookEvent *WebhookEvent) *ImageList {
@@ -754,6 +1026,10 @@ func parseImageList(ctx context.Context, kubeClient *kube.ImageUpdaterKubernetes
 		// For each image, calculate its final settings by layering its specific
 		// settings on top of the application-level settings.
 		finalCommonUpdateSettings := mergeCommonUpdateSettings(appSettings, im.CommonUpdateSettings)
+		if err := validateAllowTags(finalCommonUpdateSettings); err != nil {
+			log.Warnf("Could not set tag match expression for image %s, skipping: %v", im.ImageName, err)
+			continue
+		}
 		img := newImageFromCommonUpdateSettings(ctx, finalCommonUpdateSettings)
 
 		img, err := newImageFromManifestTargetSettings(im.ManifestTarget, img)
@@ -771,6 +1047,22 @@ func parseImageList(ctx context.Context, kubeClient *kube.ImageUpdaterKubernetes
 
 		img.ContainerImage = image.NewFromIdentifier(im.Alias + "=" + im.ImageName)
 
+		if im.Pin != nil {
+			img.Pin = &ImagePin{
+				Version: im.Pin.Version,
+				Until:   im.Pin.Until.Time,
+				Reason:  im.Pin.Reason,
+			}
+		}
+
+		if im.SBOM != nil {
+			img.SBOM, err = image.NewSBOMPolicy(im.SBOM.DeniedLicenses)
+			if err != nil {
+				log.Warnf("Could not set SBOM policy for image %s, skipping: %v", im.ImageName, err)
+				continue
+			}
+		}
+
 		// Check if any of the images match the webhook event
 		if webhookEvent != nil {
 			log.Debugf("Checking webhook match for image `%s`: event=(%s/%s), image=(%s/%s)",
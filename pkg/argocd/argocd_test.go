@@ -2,8 +2,16 @@ package argocd
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/argoproj/argo-cd/v3/pkg/apiclient/application"
 	"github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
@@ -2358,6 +2366,61 @@ func Test_parseImageList(t *testing.T) {
 		assert.ElementsMatch(t, expected, *got)
 	})
 
+	t.Run("Pin is taken from the image configuration", func(t *testing.T) {
+		until := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		images := []api.ImageConfig{{
+			Alias:     "web",
+			ImageName: "nginx:1.21.0",
+			Pin: &api.ImagePin{
+				Version: "1.21.3",
+				Until:   v1.NewTime(until),
+				Reason:  "INC-42",
+			},
+		}}
+		got := parseImageList(context.Background(), nil, "", images, nil, nil, nil)
+		require.NotNil(t, got)
+		require.Len(t, *got, 1)
+		assert.Equal(t, &ImagePin{Version: "1.21.3", Until: until, Reason: "INC-42"}, (*got)[0].Pin)
+	})
+
+	t.Run("SBOM policy is taken from the image configuration", func(t *testing.T) {
+		images := []api.ImageConfig{
+			{
+				Alias:     "web",
+				ImageName: "nginx:1.21.0",
+				SBOM:      &api.SBOMPolicy{DeniedLicenses: []string{"AGPL-*"}},
+			},
+			{
+				Alias:     "invalid",
+				ImageName: "nginx:1.21.0",
+				SBOM:      &api.SBOMPolicy{DeniedLicenses: []string{"GPL-[2"}},
+			},
+		}
+		got := parseImageList(context.Background(), nil, "", images, nil, nil, nil)
+		require.NotNil(t, got)
+		require.Len(t, *got, 1, "image with an invalid SBOM policy should be skipped")
+		require.NotNil(t, (*got)[0].SBOM)
+		assert.EqualError(t, (*got)[0].SBOM.Check(&image.SBOM{Licenses: []string{"AGPL-3.0-only", "MIT"}}), "denied licenses: AGPL-3.0-only")
+	})
+
+	t.Run("Image with an invalid CEL allowTags expression is skipped", func(t *testing.T) {
+		images := []api.ImageConfig{
+			{
+				Alias:                "web",
+				ImageName:            "nginx:1.21.0",
+				CommonUpdateSettings: &api.CommonUpdateSettings{AllowTags: new("cel:tag + 1")},
+			},
+			{
+				Alias:     "sidecar",
+				ImageName: "busybox:1.36.0",
+			},
+		}
+		got := parseImageList(context.Background(), nil, "", images, &api.CommonUpdateSettings{AllowTags: new(`cel:tag != "latest"`)}, nil, nil)
+		require.NotNil(t, got)
+		require.Len(t, *got, 1, "image with an invalid allowTags expression should be skipped")
+		assert.Equal(t, "sidecar", (*got)[0].ContainerImage.ImageAlias)
+	})
+
 	// Image signature verification behavior
 	makeVerifyKubeClient := func(secrets ...runtime.Object) *kube.ImageUpdaterKubernetesClient {
 		clientset := fake.NewFakeClientsetWithResources(secrets...)
@@ -2532,6 +2595,136 @@ func Test_mergeCommonUpdateSettings(t *testing.T) {
 		assert.True(t, *merged.ForceUpdate)
 		assert.Equal(t, "rc-*", *merged.AllowTags)
 	})
+
+	t.Run("should merge calver settings field by field", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{
+			CalVer: &api.CalVerSettings{
+				Layout:      new("YY.0M.MICRO"),
+				Constraints: []string{"same-year"},
+			},
+		}
+		imageSettings := &api.CommonUpdateSettings{
+			UpdateStrategy: new("calver"),
+			CalVer: &api.CalVerSettings{
+				Constraints: []string{"max-age:6m"},
+			},
+		}
+		merged := mergeCommonUpdateSettings(global, imageSettings)
+		assert.Equal(t, "calver", *merged.UpdateStrategy)
+		assert.Equal(t, "YY.0M.MICRO", *merged.CalVer.Layout)
+		assert.Equal(t, []string{"max-age:6m"}, merged.CalVer.Constraints)
+		// the inputs must not be modified
+		assert.Equal(t, []string{"same-year"}, global.CalVer.Constraints)
+		assert.Nil(t, imageSettings.CalVer.Layout)
+	})
+
+	t.Run("should merge label settings field by field", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{
+			Label: &api.LabelSettings{
+				Name:       new("org.example.version"),
+				Constraint: new("~1"),
+			},
+		}
+		imageSettings := &api.CommonUpdateSettings{
+			UpdateStrategy: new("label"),
+			Label: &api.LabelSettings{
+				Constraint: new("~2"),
+			},
+		}
+		merged := mergeCommonUpdateSettings(global, imageSettings)
+		assert.Equal(t, "label", *merged.UpdateStrategy)
+		assert.Equal(t, "org.example.version", *merged.Label.Name)
+		assert.Nil(t, merged.Label.Format)
+		assert.Equal(t, "~2", *merged.Label.Constraint)
+		// the inputs must not be modified
+		assert.Equal(t, "~1", *global.Label.Constraint)
+		assert.Nil(t, imageSettings.Label.Name)
+	})
+
+	t.Run("should override minAge at a more specific level", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{MinAge: &v1.Duration{Duration: 24 * time.Hour}}
+		app := &api.CommonUpdateSettings{}
+		imageSettings := &api.CommonUpdateSettings{MinAge: &v1.Duration{Duration: 0}}
+		merged := mergeCommonUpdateSettings(global, app)
+		assert.Equal(t, 24*time.Hour, merged.MinAge.Duration)
+		merged = mergeCommonUpdateSettings(global, app, imageSettings)
+		assert.Equal(t, time.Duration(0), merged.MinAge.Duration)
+	})
+
+	t.Run("should override maxBump at a more specific level", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{MaxBump: new("minor")}
+		imageSettings := &api.CommonUpdateSettings{MaxBump: new("patch")}
+		merged := mergeCommonUpdateSettings(global, &api.CommonUpdateSettings{})
+		assert.Equal(t, "minor", *merged.MaxBump)
+		merged = mergeCommonUpdateSettings(global, imageSettings)
+		assert.Equal(t, "patch", *merged.MaxBump)
+	})
+
+	t.Run("should override pinDigest at a more specific level", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{PinDigest: new(true)}
+		imageSettings := &api.CommonUpdateSettings{PinDigest: new(false)}
+		merged := mergeCommonUpdateSettings(global, &api.CommonUpdateSettings{})
+		assert.True(t, *merged.PinDigest)
+		merged = mergeCommonUpdateSettings(global, imageSettings)
+		assert.False(t, *merged.PinDigest)
+	})
+
+	t.Run("should override required and forbidden labels at a more specific level", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{
+			RequiredLabels:  []api.LabelMatch{{Key: "com.example.qa", Value: "passed"}},
+			ForbiddenLabels: []api.LabelMatch{{Key: "com.example.deprecated"}},
+		}
+		appSettings := &api.CommonUpdateSettings{RequiredLabels: []api.LabelMatch{}}
+		merged := mergeCommonUpdateSettings(global, &api.CommonUpdateSettings{})
+		assert.Equal(t, global.RequiredLabels, merged.RequiredLabels)
+		assert.Equal(t, global.ForbiddenLabels, merged.ForbiddenLabels)
+		merged = mergeCommonUpdateSettings(global, appSettings)
+		assert.Empty(t, merged.RequiredLabels)
+		assert.Equal(t, global.ForbiddenLabels, merged.ForbiddenLabels)
+	})
+
+	t.Run("should override requireAllPlatforms at a more specific level", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{RequireAllPlatforms: new(true)}
+		imageSettings := &api.CommonUpdateSettings{RequireAllPlatforms: new(false)}
+		merged := mergeCommonUpdateSettings(global, &api.CommonUpdateSettings{})
+		assert.True(t, *merged.RequireAllPlatforms)
+		merged = mergeCommonUpdateSettings(global, imageSettings)
+		assert.False(t, *merged.RequireAllPlatforms)
+	})
+
+	t.Run("should override prerelease policy at a more specific level", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{Prerelease: []string{"none"}}
+		appSettings := &api.CommonUpdateSettings{Prerelease: []string{"rc", "preview"}}
+		merged := mergeCommonUpdateSettings(global, &api.CommonUpdateSettings{})
+		assert.Equal(t, []string{"none"}, merged.Prerelease)
+		merged = mergeCommonUpdateSettings(global, appSettings, &api.CommonUpdateSettings{})
+		assert.Equal(t, []string{"rc", "preview"}, merged.Prerelease)
+	})
+
+	t.Run("should merge semver settings field by field", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{SemVer: &api.SemVerSettings{Relaxed: new(true)}}
+		imageSettings := &api.CommonUpdateSettings{SemVer: &api.SemVerSettings{}}
+		merged := mergeCommonUpdateSettings(global, imageSettings)
+		assert.True(t, *merged.SemVer.Relaxed)
+
+		imageSettings = &api.CommonUpdateSettings{SemVer: &api.SemVerSettings{Relaxed: new(false)}}
+		merged = mergeCommonUpdateSettings(global, imageSettings)
+		assert.False(t, *merged.SemVer.Relaxed)
+		// The global settings must not be modified
+		assert.True(t, *global.SemVer.Relaxed)
+	})
+
+	t.Run("should merge vulnerability settings field by field", func(t *testing.T) {
+		global := &api.CommonUpdateSettings{Vulnerabilities: &api.VulnerabilitySettings{MaxCritical: new(int32(0)), MaxHigh: new(int32(5))}}
+		imageSettings := &api.CommonUpdateSettings{Vulnerabilities: &api.VulnerabilitySettings{MaxHigh: new(int32(10)), Fallback: new(true)}}
+		merged := mergeCommonUpdateSettings(global, imageSettings)
+		assert.Equal(t, int32(0), *merged.Vulnerabilities.MaxCritical)
+		assert.Equal(t, int32(10), *merged.Vulnerabilities.MaxHigh)
+		assert.True(t, *merged.Vulnerabilities.Fallback)
+		assert.Nil(t, merged.Vulnerabilities.RequireReport)
+		// The global settings must not be modified
+		assert.Equal(t, int32(5), *global.Vulnerabilities.MaxHigh)
+	})
 }
 
 func Test_mergeImagesVerification(t *testing.T) {
@@ -2623,6 +2816,115 @@ func Test_mergeImagesVerification(t *testing.T) {
 		assert.Equal(t, "image-key", merged.CosignKey.SecretName)
 	})
 
+	t.Run("image level cosignKeyless replaces global cosignKey", func(t *testing.T) {
+		global := &api.ImagesVerification{
+			Enabled:   new(true),
+			CosignKey: secretRef("org-key", "cosign.pub"),
+		}
+		imageLevel := &api.ImagesVerification{
+			CosignKeyless: &api.CosignKeyless{
+				TrustedRoots:   *secretRef("sigstore", "fulcio.pem"),
+				RekorPublicKey: *secretRef("sigstore", "rekor.pub"),
+				Identity:       new("release@example.com"),
+				Issuer:         new("https://accounts.google.com"),
+			},
+		}
+		merged := mergeImagesVerification(global, imageLevel)
+
+		assert.True(t, *merged.Enabled)
+		assert.Nil(t, merged.CosignKey)
+		assert.Equal(t, "release@example.com", *merged.CosignKeyless.Identity)
+
+		// and vice versa
+		merged = mergeImagesVerification(imageLevel, global)
+		assert.Nil(t, merged.CosignKeyless)
+		assert.Equal(t, "org-key", merged.CosignKey.SecretName)
+	})
+
+	t.Run("image level notation replaces global cosignKeyless", func(t *testing.T) {
+		global := &api.ImagesVerification{
+			CosignKeyless: &api.CosignKeyless{
+				TrustedRoots:   *secretRef("sigstore", "fulcio.pem"),
+				RekorPublicKey: *secretRef("sigstore", "rekor.pub"),
+				Identity:       new("release@example.com"),
+				Issuer:         new("https://accounts.google.com"),
+			},
+		}
+		imageLevel := &api.ImagesVerification{
+			Notation: &api.NotationVerification{
+				TrustPolicy: *secretRef("notation", "trustpolicy.json"),
+				TrustStores: []api.NotationTrustStore{{Name: "ca:acme", Certificates: *secretRef("notation", "ca.pem")}},
+			},
+		}
+		merged := mergeImagesVerification(global, imageLevel)
+
+		assert.Nil(t, merged.CosignKeyless)
+		assert.Nil(t, merged.CosignKey)
+		assert.Equal(t, "notation", merged.Notation.TrustPolicy.SecretName)
+
+		// and vice versa
+		merged = mergeImagesVerification(imageLevel, global)
+		assert.Nil(t, merged.Notation)
+		assert.NotNil(t, merged.CosignKeyless)
+	})
+
+	t.Run("image level cosignKeys replaces global notation", func(t *testing.T) {
+		global := &api.ImagesVerification{
+			Notation: &api.NotationVerification{TrustPolicy: *secretRef("notation", "trustpolicy.json")},
+		}
+		imageLevel := &api.ImagesVerification{
+			CosignKeys: &api.CosignKeys{
+				Keys: []api.CosignNamedKey{
+					{Name: "build", PublicKey: *secretRef("build-key", "cosign.pub")},
+					{Name: "scanner", PublicKey: *secretRef("scanner-key", "cosign.pub")},
+				},
+			},
+		}
+		merged := mergeImagesVerification(global, imageLevel)
+
+		assert.Nil(t, merged.Notation)
+		require.NotNil(t, merged.CosignKeys)
+		assert.Len(t, merged.CosignKeys.Keys, 2)
+	})
+
+	t.Run("attestations are inherited and replaced independently of the method", func(t *testing.T) {
+		global := &api.ImagesVerification{
+			CosignKey: secretRef("org-key", "cosign.pub"),
+			Attestations: &api.AttestationsVerification{
+				SLSAProvenance: &api.SLSAProvenancePolicy{BuilderIDs: []string{"https://ci.example.com/org"}},
+			},
+		}
+		imageLevel := &api.ImagesVerification{
+			CosignKey: secretRef("image-key", "cosign.pub"),
+		}
+		merged := mergeImagesVerification(global, imageLevel)
+		assert.Equal(t, "image-key", merged.CosignKey.SecretName)
+		require.NotNil(t, merged.Attestations)
+		assert.Equal(t, []string{"https://ci.example.com/org"}, merged.Attestations.SLSAProvenance.BuilderIDs)
+
+		imageLevel.Attestations = &api.AttestationsVerification{
+			SLSAProvenance: &api.SLSAProvenancePolicy{BuilderIDs: []string{"https://ci.example.com/image"}},
+		}
+		merged = mergeImagesVerification(global, imageLevel)
+		assert.Equal(t, []string{"https://ci.example.com/image"}, merged.Attestations.SLSAProvenance.BuilderIDs)
+	})
+
+	t.Run("platform verification is inherited and replaced independently of the method", func(t *testing.T) {
+		global := &api.ImagesVerification{
+			CosignKey:            secretRef("org-key", "cosign.pub"),
+			PlatformVerification: new("both"),
+		}
+		imageLevel := &api.ImagesVerification{
+			CosignKey: secretRef("image-key", "cosign.pub"),
+		}
+		merged := mergeImagesVerification(global, imageLevel)
+		assert.Equal(t, "both", *merged.PlatformVerification)
+
+		imageLevel.PlatformVerification = new("per-platform")
+		merged = mergeImagesVerification(global, imageLevel)
+		assert.Equal(t, "per-platform", *merged.PlatformVerification)
+	})
+
 	t.Run("empty non-nil struct does not overwrite previously merged values", func(t *testing.T) {
 		global := &api.ImagesVerification{
 
@@ -2691,7 +2993,7 @@ func Test_newImageFromImagesVerification(t *testing.T) {
 		}
 		_, err := newImageFromImagesVerification(makeKubeClient(), testNamespace, settings, img)
 		require.Error(t, err)
-		assert.Contains(t, err.Error(), "cosignKey is required when verification is enabled")
+		assert.Contains(t, err.Error(), "cosignKey, cosignKeys, cosignKeyless or notation is required when verification is enabled")
 	})
 
 	t.Run("cosign-key with secret not found in kube returns error", func(t *testing.T) {
@@ -2732,6 +3034,226 @@ func Test_newImageFromImagesVerification(t *testing.T) {
 		require.NotNil(t, result.Verify)
 	})
 
+	t.Run("cosign-keys with valid secrets populates Verify correctly", func(t *testing.T) {
+		build := makeSecret(testNamespace, "build-key", secretKey, fakePEM+"build")
+		scanner := makeSecret(testNamespace, "scanner-key", secretKey, fakePEM+"scanner")
+		settings := &api.ImagesVerification{
+			CosignKeys: &api.CosignKeys{
+				Keys: []api.CosignNamedKey{
+					{Name: "build", PublicKey: api.SecretRef{SecretName: "build-key", Key: secretKey}},
+					{Name: "scanner", PublicKey: api.SecretRef{SecretName: "scanner-key", Key: secretKey}},
+				},
+				Threshold: new(int32(1)),
+			},
+		}
+		result, err := newImageFromImagesVerification(makeKubeClient(build, scanner), testNamespace, settings, baseImg())
+		require.NoError(t, err)
+		require.NotNil(t, result.Verify)
+		assert.Equal(t, []image.NamedPublicKey{
+			{Name: "build", Key: fakePEM + "build"},
+			{Name: "scanner", Key: fakePEM + "scanner"},
+		}, result.Verify.CosignKeys)
+		assert.Equal(t, 1, result.Verify.Threshold)
+		assert.Empty(t, result.Verify.CosignKey)
+	})
+
+	t.Run("cosign-keys without threshold requires all signers", func(t *testing.T) {
+		build := makeSecret(testNamespace, "build-key", secretKey, fakePEM)
+		settings := &api.ImagesVerification{
+			CosignKeys: &api.CosignKeys{
+				Keys: []api.CosignNamedKey{{Name: "build", PublicKey: api.SecretRef{SecretName: "build-key", Key: secretKey}}},
+			},
+		}
+		result, err := newImageFromImagesVerification(makeKubeClient(build), testNamespace, settings, baseImg())
+		require.NoError(t, err)
+		assert.Equal(t, 0, result.Verify.Threshold)
+	})
+
+	t.Run("cosign-keys with secret not found in kube returns error naming the signer", func(t *testing.T) {
+		build := makeSecret(testNamespace, "build-key", secretKey, fakePEM)
+		settings := &api.ImagesVerification{
+			CosignKeys: &api.CosignKeys{
+				Keys: []api.CosignNamedKey{
+					{Name: "build", PublicKey: api.SecretRef{SecretName: "build-key", Key: secretKey}},
+					{Name: "scanner", PublicKey: api.SecretRef{SecretName: "scanner-key", Key: secretKey}},
+				},
+			},
+		}
+		_, err := newImageFromImagesVerification(makeKubeClient(build), testNamespace, settings, baseImg())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch public key secret field of signer scanner")
+	})
+
+	keylessSecrets := func(t *testing.T) (roots, rekor runtime.Object) {
+		t.Helper()
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "sigstore"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+		}
+		certDER, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		require.NoError(t, err)
+		pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+		roots = makeSecret(testNamespace, "sigstore-roots", "fulcio.pem", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})))
+		rekor = makeSecret(testNamespace, "sigstore-rekor", "rekor.pub", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})))
+		return roots, rekor
+	}
+
+	keylessSettings := func() *api.CosignKeyless {
+		return &api.CosignKeyless{
+			TrustedRoots:   api.SecretRef{SecretName: "sigstore-roots", Key: "fulcio.pem"},
+			RekorPublicKey: api.SecretRef{SecretName: "sigstore-rekor", Key: "rekor.pub"},
+			IdentityRegexp: new("https://github.com/org/.*"),
+			Issuer:         new("https://token.actions.githubusercontent.com"),
+		}
+	}
+
+	t.Run("cosign-keyless with valid secrets populates Verify correctly", func(t *testing.T) {
+		roots, rekor := keylessSecrets(t)
+		settings := &api.ImagesVerification{CosignKeyless: keylessSettings()}
+		result, err := newImageFromImagesVerification(makeKubeClient(roots, rekor), testNamespace, settings, baseImg())
+		require.NoError(t, err)
+		assert.True(t, result.EnableVerification)
+		require.NotNil(t, result.Verify)
+		assert.NotNil(t, result.Verify.Keyless)
+		assert.Empty(t, result.Verify.CosignKey)
+	})
+
+	t.Run("cosign-keyless with secret not found in kube returns error", func(t *testing.T) {
+		_, rekor := keylessSecrets(t)
+		settings := &api.ImagesVerification{CosignKeyless: keylessSettings()}
+		_, err := newImageFromImagesVerification(makeKubeClient(rekor), testNamespace, settings, baseImg())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch trusted roots secret field")
+	})
+
+	t.Run("cosign-keyless with invalid identity returns error", func(t *testing.T) {
+		roots, rekor := keylessSecrets(t)
+		keyless := keylessSettings()
+		keyless.IdentityRegexp = new("(")
+		settings := &api.ImagesVerification{CosignKeyless: keyless}
+		_, err := newImageFromImagesVerification(makeKubeClient(roots, rekor), testNamespace, settings, baseImg())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid keyless verification settings")
+	})
+
+	const trustPolicy = `{"version":"1.0","trustPolicies":[{"name":"default","registryScopes":["*"],
+		"signatureVerification":{"level":"strict"},"trustStores":["ca:acme"],"trustedIdentities":["*"]}]}`
+
+	notationSettings := func() *api.NotationVerification {
+		return &api.NotationVerification{
+			TrustPolicy: api.SecretRef{SecretName: "notation", Key: "trustpolicy.json"},
+			TrustStores: []api.NotationTrustStore{
+				{Name: "ca:acme", Certificates: api.SecretRef{SecretName: "sigstore-roots", Key: "fulcio.pem"}},
+			},
+		}
+	}
+
+	t.Run("notation with valid secrets populates Verify correctly", func(t *testing.T) {
+		roots, _ := keylessSecrets(t)
+		policy := makeSecret(testNamespace, "notation", "trustpolicy.json", trustPolicy)
+		settings := &api.ImagesVerification{Notation: notationSettings()}
+		result, err := newImageFromImagesVerification(makeKubeClient(roots, policy), testNamespace, settings, baseImg())
+		require.NoError(t, err)
+		assert.True(t, result.EnableVerification)
+		require.NotNil(t, result.Verify)
+		assert.NotNil(t, result.Verify.Notation)
+		assert.Nil(t, result.Verify.Keyless)
+	})
+
+	t.Run("notation with trust store secret not found in kube returns error", func(t *testing.T) {
+		policy := makeSecret(testNamespace, "notation", "trustpolicy.json", trustPolicy)
+		settings := &api.ImagesVerification{Notation: notationSettings()}
+		_, err := newImageFromImagesVerification(makeKubeClient(policy), testNamespace, settings, baseImg())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to fetch trust store ca:acme secret field")
+	})
+
+	t.Run("notation with trust store missing from settings returns error", func(t *testing.T) {
+		policy := makeSecret(testNamespace, "notation", "trustpolicy.json", trustPolicy)
+		notation := notationSettings()
+		notation.TrustStores = nil
+		settings := &api.ImagesVerification{Notation: notation}
+		_, err := newImageFromImagesVerification(makeKubeClient(policy), testNamespace, settings, baseImg())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid notation verification settings")
+	})
+
+	t.Run("slsa provenance populates Verify correctly", func(t *testing.T) {
+		secret := makeSecret(testNamespace, secretName, secretKey, fakePEM)
+		settings := &api.ImagesVerification{
+			CosignKey: &api.SecretRef{SecretName: secretName, Key: secretKey},
+			Attestations: &api.AttestationsVerification{
+				SLSAProvenance: &api.SLSAProvenancePolicy{
+					BuilderIDs:         []string{"regexp:https://github.com/slsa-framework/slsa-github-generator/.*"},
+					SourceRepositories: []string{"https://github.com/org/app"},
+				},
+			},
+		}
+		result, err := newImageFromImagesVerification(makeKubeClient(secret), testNamespace, settings, baseImg())
+		require.NoError(t, err)
+		require.NotNil(t, result.Verify)
+		assert.Equal(t, fakePEM, result.Verify.CosignKey)
+		assert.NotNil(t, result.Verify.Provenance)
+	})
+
+	t.Run("slsa provenance with invalid regexp returns error", func(t *testing.T) {
+		secret := makeSecret(testNamespace, secretName, secretKey, fakePEM)
+		settings := &api.ImagesVerification{
+			CosignKey: &api.SecretRef{SecretName: secretName, Key: secretKey},
+			Attestations: &api.AttestationsVerification{
+				SLSAProvenance: &api.SLSAProvenancePolicy{BuilderIDs: []string{"regexp:(builder"}},
+			},
+		}
+		_, err := newImageFromImagesVerification(makeKubeClient(secret), testNamespace, settings, baseImg())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid attestation settings")
+	})
+
+	t.Run("slsa provenance with notation returns error", func(t *testing.T) {
+		roots, _ := keylessSecrets(t)
+		policy := makeSecret(testNamespace, "notation", "trustpolicy.json", trustPolicy)
+		settings := &api.ImagesVerification{
+			Notation: notationSettings(),
+			Attestations: &api.AttestationsVerification{
+				SLSAProvenance: &api.SLSAProvenancePolicy{BuilderIDs: []string{"https://ci.example.com/builder"}},
+			},
+		}
+		_, err := newImageFromImagesVerification(makeKubeClient(roots, policy), testNamespace, settings, baseImg())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "attestations require a cosign verification method")
+	})
+
+	t.Run("platform verification populates Verify with the image's platforms", func(t *testing.T) {
+		secret := makeSecret(testNamespace, secretName, secretKey, fakePEM)
+		settings := &api.ImagesVerification{
+			CosignKey:            &api.SecretRef{SecretName: secretName, Key: secretKey},
+			PlatformVerification: new("per-platform"),
+		}
+		img := baseImg()
+		img.Platforms = []string{"linux/amd64", "linux/arm64"}
+		result, err := newImageFromImagesVerification(makeKubeClient(secret), testNamespace, settings, img)
+		require.NoError(t, err)
+		require.NotNil(t, result.Verify)
+		assert.Equal(t, image.PlatformVerificationPerPlatform, result.Verify.PlatformVerification)
+		assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, result.Verify.Platforms)
+	})
+
+	t.Run("unknown platform verification returns error", func(t *testing.T) {
+		secret := makeSecret(testNamespace, secretName, secretKey, fakePEM)
+		settings := &api.ImagesVerification{
+			CosignKey:            &api.SecretRef{SecretName: secretName, Key: secretKey},
+			PlatformVerification: new("all"),
+		}
+		_, err := newImageFromImagesVerification(makeKubeClient(secret), testNamespace, settings, baseImg())
+		assert.ErrorContains(t, err, `unknown platform verification "all"`)
+	})
 }
 
 func Test_newImageFromSettings(t *testing.T) {
@@ -2767,6 +3289,133 @@ func Test_newImageFromSettings(t *testing.T) {
 		assert.Equal(t, []string{"v1.0.0"}, img.IgnoreTags)
 	})
 
+	t.Run("should apply calver settings", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			UpdateStrategy: new(image.StrategyCalVer.String()),
+			CalVer: &api.CalVerSettings{
+				Layout:      new("YYYY.0M.0D"),
+				Constraints: []string{"same-year"},
+			},
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.Equal(t, image.StrategyCalVer, img.UpdateStrategy)
+		assert.Equal(t, "YYYY.0M.0D", img.CalVerLayout)
+		assert.Equal(t, []string{"same-year"}, img.CalVerConstraints)
+	})
+
+	t.Run("should apply label settings", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			UpdateStrategy: new(image.StrategyLabel.String()),
+			Label: &api.LabelSettings{
+				Name:       new("org.example.version"),
+				Format:     new("calver"),
+				Constraint: new(""),
+			},
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.Equal(t, image.StrategyLabel, img.UpdateStrategy)
+		assert.Equal(t, "org.example.version", img.LabelName)
+		assert.Equal(t, "calver", img.LabelFormat)
+		assert.Equal(t, "", img.LabelConstraint)
+	})
+
+	t.Run("should apply minAge setting", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			MinAge: &v1.Duration{Duration: 48 * time.Hour},
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.Equal(t, 48*time.Hour, img.MinAge)
+	})
+
+	t.Run("should apply maxBump setting", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			MaxBump: new("patch"),
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.Equal(t, "patch", img.MaxBump)
+	})
+
+	t.Run("should apply pinDigest setting", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			PinDigest: new(true),
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.True(t, img.PinDigest)
+	})
+
+	t.Run("should apply required and forbidden labels", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			RequiredLabels:  []api.LabelMatch{{Key: "com.example.qa", Value: "passed|waived"}},
+			ForbiddenLabels: []api.LabelMatch{{Key: "com.example.deprecated", Value: "true"}},
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.Equal(t, []image.LabelMatch{{Key: "com.example.qa", Value: "passed|waived"}}, img.RequiredLabels)
+		assert.Equal(t, []image.LabelMatch{{Key: "com.example.deprecated", Value: "true"}}, img.ForbiddenLabels)
+	})
+
+	t.Run("should apply requireAllPlatforms setting", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			Platforms:           []string{"linux/amd64", "linux/arm64"},
+			RequireAllPlatforms: new(true),
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, img.Platforms)
+		assert.True(t, img.RequireAllPlatforms)
+	})
+
+	t.Run("should apply prerelease setting", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			Prerelease: []string{"beta"},
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.Equal(t, []string{"beta"}, img.Prerelease)
+	})
+
+	t.Run("should apply vulnerability settings", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			Vulnerabilities: &api.VulnerabilitySettings{MaxCritical: new(int32(0)), MaxMedium: new(int32(20))},
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		require.NotNil(t, img.Vulnerabilities)
+		assert.Equal(t, map[image.Severity]int{image.SeverityCritical: 0, image.SeverityMedium: 20}, img.Vulnerabilities.MaxCount)
+		assert.True(t, img.Vulnerabilities.RequireReport)
+		assert.False(t, img.Vulnerabilities.Fallback)
+
+		settings.Vulnerabilities.RequireReport = new(false)
+		settings.Vulnerabilities.Fallback = new(true)
+		img = newImageFromCommonUpdateSettings(context.Background(), settings)
+		assert.False(t, img.Vulnerabilities.RequireReport)
+		assert.True(t, img.Vulnerabilities.Fallback)
+	})
+
+	t.Run("should apply relaxed semver setting", func(t *testing.T) {
+		settings := &api.CommonUpdateSettings{
+			SemVer: &api.SemVerSettings{Relaxed: new(true)},
+		}
+
+		img := newImageFromCommonUpdateSettings(context.Background(), settings)
+
+		assert.True(t, img.RelaxedSemVer)
+	})
+
 	t.Run("should handle empty but non-nil settings struct", func(t *testing.T) {
 		// Expected: An empty settings struct should result in default values.
 		settings := &api.CommonUpdateSettings{} // Empty struct, all fields are nil
@@ -5154,3 +5803,43 @@ func Test_countPullRequestProviders(t *testing.T) {
 		})
 	}
 }
+
+func Test_validateAllowTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings *api.CommonUpdateSettings
+		wantErr  string
+	}{
+		{
+			name: "no settings",
+		},
+		{
+			name:     "no allowTags",
+			settings: &api.CommonUpdateSettings{},
+		},
+		{
+			name:     "valid expression",
+			settings: &api.CommonUpdateSettings{AllowTags: new(`CEL:"team" in labels`)},
+		},
+		{
+			name:     "invalid regexp is not validated",
+			settings: &api.CommonUpdateSettings{AllowTags: new("regexp:[a-z")},
+		},
+		{
+			name:     "invalid expression",
+			settings: &api.CommonUpdateSettings{AllowTags: new("cel:tag ==")},
+			wantErr:  "invalid allowTags",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAllowTags(tt.settings)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"sync"
 	"text/template"
+	"time"
 
 	argocdapi "github.com/argoproj/argo-cd/v3/pkg/apis/application/v1alpha1"
 	"github.com/argoproj/argo-cd/v3/util/db"
@@ -26,6 +27,8 @@ type ImageUpdaterResult struct {
 	NumErrors                int
 	ApplicationsMatched      int
 	Changes                  []ChangeEntry
+	Held                     []HeldEntry
+	Pinned                   []PinnedEntry
 }
 
 type UpdateConfiguration struct {
@@ -45,6 +48,7 @@ type UpdateConfiguration struct {
 	DisableKubeEvents      bool
 	IgnorePlatforms        bool
 	GitCreds               git.CredsStore
+	AdmissionHook          *AdmissionHook
 }
 
 type GitCredsSource func(app *argocdapi.Application) (git.Creds, error)
@@ -176,6 +180,45 @@ type ChangeEntry struct {
 	NewTag *tag.ImageTag
 }
 
+// HeldReason is a machine-readable identifier for why an update was held back
+type HeldReason string
+
+const (
+	// HeldReasonMinAge means the new version has not yet reached its minimum age
+	HeldReasonMinAge HeldReason = "MinAge"
+	// HeldReasonMissingSignatures means the new version has not been signed
+	// by enough of the required signers
+	HeldReasonMissingSignatures HeldReason = "MissingSignatures"
+	// HeldReasonVulnerabilities means the vulnerability report of the new
+	// version exceeds the vulnerability policy, or is missing
+	HeldReasonVulnerabilities HeldReason = "Vulnerabilities"
+	// HeldReasonMissingSBOM means the new version has no SBOM attached
+	HeldReasonMissingSBOM HeldReason = "MissingSBOM"
+	// HeldReasonDeniedLicenses means the SBOM of the new version contains
+	// denied licenses
+	HeldReasonDeniedLicenses HeldReason = "DeniedLicenses"
+	// HeldReasonAdmissionDenied means the admission hook denied the update
+	HeldReasonAdmissionDenied HeldReason = "AdmissionDenied"
+)
+
+// HeldEntry represents an available image update that has been held back by
+// Image Updater
+type HeldEntry struct {
+	Image   *image.ContainerImage
+	Tag     *tag.ImageTag
+	Reason  HeldReason
+	Message string
+}
+
+// PinnedEntry represents an image that has been pinned to a version instead of
+// being updated according to its update strategy
+type PinnedEntry struct {
+	Image  *image.ContainerImage
+	Tag    *tag.ImageTag
+	Until  time.Time
+	Reason string
+}
+
 // SyncIterationState holds shared state of a running update operation
 type SyncIterationState struct {
 	lock            sync.Mutex
@@ -240,6 +283,47 @@ type Image struct {
 	PullSecret     string
 	Platforms      []string
 
+	// RequireAllPlatforms only considers tags providing each of the Platforms
+	RequireAllPlatforms bool
+
+	// RelaxedSemVer enables relaxed parsing of tags for the semver strategy
+	RelaxedSemVer bool
+
+	// calver strategy settings
+	CalVerLayout      string
+	CalVerConstraints []string
+
+	// label strategy settings
+	LabelName       string
+	LabelFormat     string
+	LabelConstraint string
+
+	// MinAge is the minimum age of a tag before it is considered for update
+	MinAge time.Duration
+
+	// MaxBump is the largest allowed version bump for the semver strategy
+	MaxBump string
+
+	// PinDigest writes the new tag along with the digest it points to
+	PinDigest bool
+
+	// Prerelease is the pre-release policy for the semver strategy
+	Prerelease []string
+
+	// RequiredLabels and ForbiddenLabels are the image labels new versions
+	// must and must not have
+	RequiredLabels  []image.LabelMatch
+	ForbiddenLabels []image.LabelMatch
+
+	// Pin overrides the update strategy with a fixed version until it expires
+	Pin *ImagePin
+
+	// Vulnerabilities is the policy for the vulnerability reports of new versions
+	Vulnerabilities *image.VulnerabilityPolicy
+
+	// SBOM is the policy for the SBOMs of new versions
+	SBOM *image.SBOMPolicy
+
 	// ManifestTarget settings
 	HelmImageName      string
 	HelmImageTag       string
@@ -254,6 +338,18 @@ type Image struct {
 	*image.Verify
 }
 
+// ImagePin pins an image to a fixed version until a point in time
+type ImagePin struct {
+	Version string
+	Until   time.Time
+	Reason  string
+}
+
+// IsActive returns true if the pin is set and has not yet expired at time now
+func (p *ImagePin) IsActive(now time.Time) bool {
+	return p != nil && now.Before(p.Until)
+}
+
 // ImageList is a list of Image objects that can be updated.
 type ImageList []*Image
 
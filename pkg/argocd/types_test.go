@@ -3,6 +3,7 @@ package argocd
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -87,3 +88,17 @@ func Test_IsValidGitCommitMethod(t *testing.T) {
 	assert.False(t, IsValidGitCommitMethod("API"))
 	assert.False(t, IsValidGitCommitMethod("graphql"))
 }
+
+func Test_ImagePinIsActive(t *testing.T) {
+	now := time.Now()
+	t.Run("No pin is never active", func(t *testing.T) {
+		var pin *ImagePin
+		assert.False(t, pin.IsActive(now))
+	})
+	t.Run("Pin is active until it expires", func(t *testing.T) {
+		pin := &ImagePin{Version: "1.2.3", Until: now.Add(time.Hour)}
+		assert.True(t, pin.IsActive(now))
+		assert.False(t, pin.IsActive(now.Add(time.Hour)))
+		assert.False(t, pin.IsActive(now.Add(2*time.Hour)))
+	})
+}
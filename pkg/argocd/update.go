@@ -23,6 +23,7 @@ import (
 	"github.com/argoproj-labs/argocd-image-updater/pkg/kube"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/image"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/options"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 
@@ -104,9 +105,50 @@ func UpdateApplication(ctx context.Context, updateConf *UpdateConfiguration, sta
 		vc.Strategy = applicationImage.UpdateStrategy
 		vc.MatchFunc, vc.MatchArgs = applicationImage.ParseMatch(imageOpCtx, applicationImage.AllowTags)
 		vc.IgnoreList = applicationImage.IgnoreTags
+		vc.MinAge = applicationImage.MinAge
+		vc.RelaxedSemVer = applicationImage.RelaxedSemVer
+		vc.LabelPolicy, err = image.ParseLabelPolicy(applicationImage.RequiredLabels, applicationImage.ForbiddenLabels)
+		if err != nil {
+			imgCtx.Errorf("Invalid label policy: %v", err)
+			result.NumErrors += 1
+			continue
+		}
 		vc.Options = applicationImage.
 			GetPlatformOptions(imageOpCtx, updateConf.IgnorePlatforms, applicationImage.Platforms).
-			WithMetadata(vc.Strategy.NeedsMetadata())
+			WithMetadata(vc.NeedsMetadata()).
+			WithAllPlatforms(applicationImage.RequireAllPlatforms)
+
+		if vc.Strategy == image.StrategyCalVer || vc.Strategy == image.StrategyLabel {
+			vc.CalVer, err = image.ParseCalVerConstraint(applicationImage.CalVerLayout, applicationImage.CalVerConstraints)
+			if err != nil {
+				imgCtx.Errorf("Invalid calver configuration: %v", err)
+				result.NumErrors += 1
+				continue
+			}
+		}
+
+		if vc.Strategy == image.StrategyLabel {
+			vc.Label, err = image.ParseLabelConstraint(applicationImage.LabelName, applicationImage.LabelFormat, applicationImage.LabelConstraint)
+			if err != nil {
+				imgCtx.Errorf("Invalid label configuration: %v", err)
+				result.NumErrors += 1
+				continue
+			}
+		}
+
+		vc.MaxBump, err = image.ParseVersionBump(applicationImage.MaxBump)
+		if err != nil {
+			imgCtx.Errorf("Invalid max bump configuration: %v", err)
+			result.NumErrors += 1
+			continue
+		}
+
+		vc.Prerelease, err = image.ParsePrereleasePolicy(applicationImage.Prerelease)
+		if err != nil {
+			imgCtx.Errorf("Invalid pre-release policy: %v", err)
+			result.NumErrors += 1
+			continue
+		}
 
 		// If a strategy needs meta-data and tagsortmode is set for the
 		// registry, let the user know.
@@ -149,45 +191,107 @@ func UpdateApplication(ctx context.Context, updateConf *UpdateConfiguration, sta
 			continue
 		}
 
-		// Get list of available image tags from the repository
-		// Load creds, create registry client, fetch tags (retry once on 401/403)
-		tags, err := rep.GetTags(imageOpCtx, applicationImage.ContainerImage, regClient, &vc, secretVal == "")
-		if err != nil {
-			// Retry once on 401/403
-			if errors.Is(err, registry.ErrCredentialsInvalid) {
-				imgCtx.Infof("credentials invalid (401/403), refetching and retrying once")
-				// The endpoint can provide default credentials for pulling images
-				creds, err = rep.SetEndpointCredentials(imageOpCtx, updateConf.KubeClient.KubeClient, secretVal)
+		// A pinned image is updated to the pinned version instead of the one
+		// selected by the update strategy, until the pin expires.
+		var latest *tag.ImageTag
+		if pin := applicationImage.Pin; pin.IsActive(time.Now()) {
+			latest = tag.NewImageTag(pin.Version, time.Unix(0, 0), "")
+			imgCtx.Infof("Image is pinned to %s until %s", pin.Version, pin.Until.Format(time.RFC3339))
+			result.Pinned = append(result.Pinned, PinnedEntry{
+				Image:  applicationImage.WithTag(latest),
+				Tag:    latest,
+				Until:  pin.Until,
+				Reason: pin.Reason,
+			})
+			// The digest strategy writes the digest of the tag, so we have
+			// to resolve it for the pinned version as well.
+			if vc.Strategy == image.StrategyDigest {
+				digest, err := registry.GetTagDigest(imageOpCtx, regClient, pin.Version, vc.Options)
 				if err != nil {
-					imgCtx.Errorf("Could not set registry endpoint credentials: %v", err)
+					imgCtx.Errorf("Could not get digest of pinned tag %s: %v", pin.Version, err)
 					result.NumErrors += 1
 					continue
 				}
+				latest = tag.NewImageTag(pin.Version, time.Unix(0, 0), digest)
+			}
+		} else {
+			if pin != nil {
+				imgCtx.Debugf("Pin to %s expired at %s, using update strategy", pin.Version, pin.Until.Format(time.RFC3339))
+			}
+			// Get list of available image tags from the repository
+			// Load creds, create registry client, fetch tags (retry once on 401/403)
+			tags, err := rep.GetTags(imageOpCtx, applicationImage.ContainerImage, regClient, &vc, secretVal == "")
+			if err != nil {
+				// Retry once on 401/403
+				if errors.Is(err, registry.ErrCredentialsInvalid) {
+					imgCtx.Infof("credentials invalid (401/403), refetching and retrying once")
+					// The endpoint can provide default credentials for pulling images
+					creds, err = rep.SetEndpointCredentials(imageOpCtx, updateConf.KubeClient.KubeClient, secretVal)
+					if err != nil {
+						imgCtx.Errorf("Could not set registry endpoint credentials: %v", err)
+						result.NumErrors += 1
+						continue
+					}
 
-				regClient, err = updateConf.NewRegFN(rep, creds.Username, creds.Password)
+					regClient, err = updateConf.NewRegFN(rep, creds.Username, creds.Password)
+					if err != nil {
+						imgCtx.Errorf("Could not create registry client: %v", err)
+						result.NumErrors += 1
+						continue
+					}
+					tags, err = rep.GetTags(imageOpCtx, applicationImage.ContainerImage, regClient, &vc, secretVal == "")
+				}
 				if err != nil {
-					imgCtx.Errorf("Could not create registry client: %v", err)
+					imgCtx.Errorf("Could not get tags from registry: %v", err)
 					result.NumErrors += 1
 					continue
 				}
-				tags, err = rep.GetTags(imageOpCtx, applicationImage.ContainerImage, regClient, &vc, secretVal == "")
 			}
+
+			imgCtx.Tracef("List of available tags found: %v", tags.Tags())
+
+			// Get the latest available tag matching any constraint that might be set
+			// for allowed updates.
+			candidate, err := updateableImage.GetUpdateCandidate(imageOpCtx, &vc, tags)
 			if err != nil {
-				imgCtx.Errorf("Could not get tags from registry: %v", err)
+				imgCtx.Errorf("Unable to find newest version from available tags: %v", err)
 				result.NumErrors += 1
 				continue
 			}
-		}
-
-		imgCtx.Tracef("List of available tags found: %v", tags.Tags())
+			latest = candidate.Tag
+
+			// A newer tag that has not yet reached its minimum age is held back,
+			// and we fall back to the newest tag that is old enough (if any).
+			// Tags without a creation date are held back as well, as their
+			// age is unknown.
+			if soaking := candidate.Soaking; soaking != nil {
+				var message string
+				if soaking.HasDate() {
+					eligibleAt := soaking.TagDate.Add(vc.MinAge)
+					imgCtx.Infof("Update to %s deferred, tag has not yet reached minimum age of %s (eligible at %s)", soaking.String(), vc.MinAge, eligibleAt.Format(time.RFC3339))
+					message = fmt.Sprintf("Update to %s deferred until %s, as it has not yet reached the minimum age of %s.", soaking.String(), eligibleAt.Format(time.RFC3339), vc.MinAge)
+				} else {
+					imgCtx.Infof("Update to %s deferred, tag has no creation date to check the minimum age of %s against", soaking.String(), vc.MinAge)
+					message = fmt.Sprintf("Update to %s deferred, as its image has no creation date to check the minimum age of %s against.", soaking.String(), vc.MinAge)
+				}
+				result.Held = append(result.Held, HeldEntry{
+					Image:   applicationImage.WithTag(soaking),
+					Tag:     soaking,
+					Reason:  HeldReasonMinAge,
+					Message: message,
+				})
+			}
 
-		// Get the latest available tag matching any constraint that might be set
-		// for allowed updates.
-		latest, err := updateableImage.GetNewestVersionFromTags(imageOpCtx, &vc, tags)
-		if err != nil {
-			imgCtx.Errorf("Unable to find newest version from available tags: %v", err)
-			result.NumErrors += 1
-			continue
+			// A tag whose vulnerability report exceeds the vulnerability
+			// policy is held back, and we may fall back to an older one.
+			if applicationImage.Vulnerabilities != nil && latest != nil {
+				latest, err = selectVulnerabilityCompliantTag(imageOpCtx, applicationImage, updateableImage.ImageTag, candidate, regClient, &result)
+				if err != nil {
+					imgCtx.Errorf("Unable to check vulnerability reports: %v", err)
+					result.NumErrors += 1
+					continue
+				}
+			}
 		}
 
 		// If we have no latest tag information, it means there was no tag which
@@ -199,6 +303,22 @@ func UpdateApplication(ctx context.Context, updateConf *UpdateConfiguration, sta
 			continue
 		}
 
+		// Pin the new tag to the digest it currently points to, so that the
+		// image reference we write back is immutable. The tag from the list
+		// may be shared with the registry cache, so we work on a copy.
+		if applicationImage.PinDigest && vc.Strategy != image.StrategyDigest {
+			digest, err := registry.GetTagDigest(imageOpCtx, regClient, latest.TagName, vc.Options)
+			if err != nil {
+				imgCtx.Errorf("Could not pin tag %s to its digest: %v", latest.TagName, err)
+				result.NumErrors += 1
+				continue
+			}
+			pinned := *latest
+			pinned.TagDigest = digest
+			latest = &pinned
+			imgCtx.Debugf("Pinned tag %s to digest %s", latest.TagName, latest.TagDigest)
+		}
+
 		// When several containers in the application reference the same image
 		// name, the live image list (Argo CD's alias-less, de-duplicated
 		// Status.Summary.Images) can cause the earlier ContainsImage lookup to
@@ -238,16 +358,107 @@ func UpdateApplication(ctx context.Context, updateConf *UpdateConfiguration, sta
 						result.NumErrors += 1
 						continue
 					}
+				case applicationImage.Verify != nil && len(applicationImage.Verify.CosignKeys) > 0:
+					signers, err := image.VerifyWithPublicKeys(imageOpCtx, appImageWithTag, applicationImage.Verify, regClient)
+					if err != nil {
+						// Too few signers are a policy decision rather than a
+						// failure, so the update is held instead of failed.
+						var thresholdErr *image.ThresholdError
+						if errors.As(err, &thresholdErr) {
+							imgCtx.Infof("Update to %s rejected, as it is missing signatures from %s", latest.String(), strings.Join(thresholdErr.Missing, ", "))
+							result.Held = append(result.Held, HeldEntry{
+								Image:   appImageWithTag,
+								Tag:     latest,
+								Reason:  HeldReasonMissingSignatures,
+								Message: fmt.Sprintf("Update to %s rejected, as it is missing signatures from %s (%d of %d required signatures found).", latest.String(), strings.Join(thresholdErr.Missing, ", "), len(thresholdErr.Matched), thresholdErr.Threshold),
+							})
+							result.NumSkipped += 1
+							continue
+						}
+						imgCtx.Errorf("Unable to verify image %s with public keys: %v", appImageFullNameWithTag, err)
+						result.NumErrors += 1
+						continue
+					}
+					imgCtx.Debugf("Image %s is signed by %s", appImageFullNameWithTag, strings.Join(signers, ", "))
+				case applicationImage.Verify != nil && applicationImage.Verify.Keyless != nil:
+					err := image.VerifyKeyless(imageOpCtx, appImageWithTag, applicationImage.Verify, regClient)
+					if err != nil {
+						imgCtx.Errorf("Unable to verify image %s with keyless signature: %v", appImageFullNameWithTag, err)
+						result.NumErrors += 1
+						continue
+					}
+				case applicationImage.Verify != nil && applicationImage.Verify.Notation != nil:
+					err := image.VerifyNotation(imageOpCtx, appImageWithTag, applicationImage.Verify, regClient)
+					if err != nil {
+						imgCtx.Errorf("Unable to verify image %s with Notation signature: %v", appImageFullNameWithTag, err)
+						result.NumErrors += 1
+						continue
+					}
 				// additional verification methods will be added here
 				default:
 					imgCtx.Errorf("Image verification enabled but no verification method configured for %s", appImageFullNameWithTag)
 					result.NumErrors += 1
 					continue
 				}
+
+				if applicationImage.Verify.Provenance != nil {
+					err := image.VerifyProvenance(imageOpCtx, appImageWithTag, applicationImage.Verify, regClient)
+					if err != nil {
+						imgCtx.Errorf("Unable to verify provenance of image %s: %v", appImageFullNameWithTag, err)
+						result.NumErrors += 1
+						continue
+					}
+				}
 			} else {
 				imgCtx.Debugf("Image verification not configured for %s, skipping", appImageFullNameWithTag)
 			}
 
+			// An image without an SBOM, or whose SBOM contains denied
+			// licenses, is rejected by its SBOM policy.
+			if applicationImage.SBOM != nil {
+				var reason HeldReason
+				var rejection string
+				sbom, err := image.FetchSBOM(imageOpCtx, appImageWithTag, regClient)
+				switch {
+				case errors.Is(err, image.ErrNoSBOM):
+					reason, rejection = HeldReasonMissingSBOM, "it has no SBOM"
+				case err != nil:
+					imgCtx.Errorf("Unable to fetch SBOM of image %s: %v", appImageFullNameWithTag, err)
+					result.NumErrors += 1
+					continue
+				default:
+					if err := applicationImage.SBOM.Check(sbom); err != nil {
+						reason, rejection = HeldReasonDeniedLicenses, "its SBOM contains "+err.Error()
+					}
+				}
+				if reason != "" {
+					imgCtx.Infof("Update to %s rejected, as %s", latest.String(), rejection)
+					result.Held = append(result.Held, HeldEntry{
+						Image:   appImageWithTag,
+						Tag:     latest,
+						Reason:  reason,
+						Message: fmt.Sprintf("Update to %s rejected, as %s.", latest.String(), rejection),
+					})
+					result.NumSkipped += 1
+					continue
+				}
+			}
+
+			// The admission hook has the final say on whether the update
+			// may be written.
+			if hook := updateConf.AdmissionHook; hook != nil {
+				allowed, err := admitUpdate(imageOpCtx, hook, &updateConf.UpdateApp.Application, applicationImage, updateableImage.ImageTag, latest, regClient, vc.Options, &result)
+				if err != nil {
+					imgCtx.Errorf("Unable to review update to %s with admission hook: %v", appImageFullNameWithTag, err)
+					result.NumErrors += 1
+					continue
+				}
+				if !allowed {
+					result.NumSkipped += 1
+					continue
+				}
+			}
+
 			needUpdate = true
 			imgCtx.Infof("Setting new image to %s", appImageFullNameWithTag)
 
@@ -473,6 +684,126 @@ func leadingDocumentStartPrefix(raw []byte) []byte {
 	return []byte(strings.Join(lines[:n+1], "\n") + "\n")
 }
 
+// admitUpdate asks the admission hook whether the update of applicationImage
+// from running to latest may be written to app. A denial is recorded as held
+// in result. If the hook fails, or the digest of latest cannot be resolved for
+// the review, the update is allowed if the hook fails open, and an error is
+// returned otherwise.
+func admitUpdate(ctx context.Context, hook *AdmissionHook, app *v1alpha1.Application, applicationImage *Image, running *tag.ImageTag, latest *tag.ImageTag, regClient registry.RegistryClient, opts *options.ManifestOptions, result *ImageUpdaterResult) (bool, error) {
+	log := log.LoggerFromContext(ctx)
+
+	digest := latest.TagDigest
+	if digest == "" {
+		digest = latest.ManifestDigest
+	}
+	if digest == "" {
+		var err error
+		digest, err = registry.GetTagDigest(ctx, regClient, latest.TagName, opts)
+		if err != nil {
+			if hook.FailOpen {
+				log.Warnf("Could not resolve digest of %s for admission hook, allowing update as its failure policy is %s: %v", latest.String(), AdmissionFailurePolicyIgnore, err)
+				return true, nil
+			}
+			return false, err
+		}
+	}
+
+	req := &AdmissionRequest{
+		Application: AdmissionApplication{Name: app.GetName(), Namespace: app.GetNamespace()},
+		Alias:       applicationImage.ImageAlias,
+		Image:       applicationImage.GetFullNameWithoutTag(),
+		NewTag:      latest.TagName,
+		Digest:      digest,
+		Labels:      latest.Labels,
+	}
+	if running != nil {
+		req.OldTag = running.TagName
+	}
+
+	review, err := hook.Review(ctx, req)
+	if err != nil {
+		if hook.FailOpen {
+			log.Warnf("Admission hook failed, allowing update to %s as its failure policy is %s: %v", latest.String(), AdmissionFailurePolicyIgnore, err)
+			return true, nil
+		}
+		return false, err
+	}
+	if review.Allowed {
+		log.Debugf("Admission hook allowed update to %s", latest.String())
+		return true, nil
+	}
+
+	reason := review.Reason
+	if reason == "" {
+		reason = "no reason given"
+	}
+	log.Infof("Update to %s rejected by admission hook: %s", latest.String(), reason)
+	result.Held = append(result.Held, HeldEntry{
+		Image:   applicationImage.WithTag(latest),
+		Tag:     latest,
+		Reason:  HeldReasonAdmissionDenied,
+		Message: fmt.Sprintf("Update to %s rejected by admission hook: %s.", latest.String(), strings.TrimSuffix(reason, ".")),
+	})
+	return false, nil
+}
+
+// selectVulnerabilityCompliantTag returns the newest of the eligible tags
+// whose vulnerability report satisfies the vulnerability policy of
+// applicationImage, or nil if there is none. Rejected tags are recorded as
+// held in result. Older tags are only considered if the policy allows to fall
+// back, and never beyond the running tag, even if the running tag itself is
+// not eligible.
+func selectVulnerabilityCompliantTag(ctx context.Context, applicationImage *Image, running *tag.ImageTag, updateCandidate *image.UpdateCandidate, regClient registry.RegistryClient, result *ImageUpdaterResult) (*tag.ImageTag, error) {
+	log := log.LoggerFromContext(ctx)
+	policy := applicationImage.Vulnerabilities
+	eligible := updateCandidate.Eligible
+
+	for i := len(eligible) - 1; i >= 0; i-- {
+		candidate := eligible[i]
+		if candidate.Equals(running) {
+			log.Debugf("Reached running tag %s, not falling back any further", candidate.String())
+			return candidate, nil
+		}
+		if i < len(eligible)-updateCandidate.Newer {
+			log.Debugf("%s is not newer than running tag %s, not falling back any further", candidate.String(), running.String())
+			return nil, nil
+		}
+
+		candidateImage := applicationImage.WithTag(candidate)
+		var reason string
+		report, err := image.FetchVulnerabilityReport(ctx, candidateImage, regClient)
+		switch {
+		case errors.Is(err, image.ErrNoVulnerabilityReport):
+			if !policy.RequireReport {
+				log.Debugf("No vulnerability report found for %s, which is not required", candidate.String())
+				return candidate, nil
+			}
+			reason = "it has no vulnerability report"
+		case err != nil:
+			return nil, err
+		default:
+			err := policy.Check(report)
+			if err == nil {
+				log.Debugf("Vulnerability reports of %s satisfy the vulnerability policy (%s)", candidate.String(), report)
+				return candidate, nil
+			}
+			reason = "it has " + err.Error()
+		}
+
+		log.Infof("Update to %s rejected, as %s", candidate.String(), reason)
+		result.Held = append(result.Held, HeldEntry{
+			Image:   candidateImage,
+			Tag:     candidate,
+			Reason:  HeldReasonVulnerabilities,
+			Message: fmt.Sprintf("Update to %s rejected, as %s.", candidate.String(), reason),
+		})
+		if !policy.Fallback {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
 // marshalParamsOverride marshals the parameter overrides of a given application
 // into YAML bytes
 func marshalParamsOverride(ctx context.Context, applicationImages *ApplicationImages, originalData []byte) ([]byte, error) {
@@ -8,9 +8,13 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -1715,32 +1719,11 @@ registries:
 		assert.Equal(t, 0, res.NumImagesUpdated)
 	})
 
-	t.Run("Test Kubernetes Job with forceUpdate and digest strategy (issue #1344)", func(t *testing.T) {
-		// This test reproduces the scenario from issue #1344:
-		// - Application uses a Kubernetes Job (not in app.Status.Summary.Images)
-		// - forceUpdate: true is set
-		// - updateStrategy: "digest" is used
-		// - A version constraint (tag) is provided
-
+	t.Run("Test successful update with calver strategy", func(t *testing.T) {
 		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
 			regMock := regmock.RegistryClient{}
-			regMock.On("NewRepository", mock.Anything, mock.MatchedBy(func(s string) bool {
-				return s == "org/job-image"
-			})).Return(nil)
-			// Return the tag that matches our version constraint
-			regMock.On("Tags", mock.Anything).Return([]string{"latest"}, nil)
-
-			// For digest strategy, we need to mock ManifestForTag and TagMetadata
-			meta1 := &schema2.DeserializedManifest{}
-			regMock.On("ManifestForTag", mock.Anything, "latest").Return(meta1, nil)
-			// Create a digest as [32]byte array
-			var digest [32]byte
-			copy(digest[:], []byte("abcdef1234567890"))
-			regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
-				CreatedAt: time.Unix(1234567890, 0),
-				Digest:    digest,
-			}, nil)
-
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"24.04-1", "24.04-10", "24.04-2", "24.10-1", "25.01-1", "latest"}, nil)
 			return &regMock, nil
 		}
 
@@ -1752,48 +1735,39 @@ registries:
 				Clientset: fake.NewFakeKubeClient(),
 			},
 		}
-
-		// Image configuration with forceUpdate and digest strategy
-		// The tag "latest" serves as the version constraint for digest strategy
-		containerImg := image.NewFromIdentifier("job-image=gcr.io/org/job-image:latest")
-		iuImg := NewImage(containerImg)
-		iuImg.KustomizeImageName = "org/job-image"
-		iuImg.ForceUpdate = true
-		iuImg.UpdateStrategy = image.StrategyDigest
-
-		imageList := ImageList{iuImg}
-
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.UpdateStrategy = image.StrategyCalVer
+		img.CalVerLayout = "YY.0M-MICRO"
+		img.CalVerConstraints = []string{"same-year"}
 		appImages := &ApplicationImages{
 			Application: v1alpha1.Application{
 				ObjectMeta: v1.ObjectMeta{
-					Name:      "job-app",
-					Namespace: "default",
+					Name:      "guestbook",
+					Namespace: "guestbook",
 				},
 				Spec: v1alpha1.ApplicationSpec{
 					Source: &v1alpha1.ApplicationSource{
 						Kustomize: &v1alpha1.ApplicationSourceKustomize{
-							Images: v1alpha1.KustomizeImages{},
+							Images: v1alpha1.KustomizeImages{
+								"jannfis/foobar:24.04-1",
+							},
 						},
 					},
 				},
 				Status: v1alpha1.ApplicationStatus{
 					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
 					Summary: v1alpha1.ApplicationSummary{
-						// Empty images list - simulating a Kubernetes Job that doesn't
-						// appear in the application status
-						Images: []string{},
+						Images: []string{
+							"jannfis/foobar:24.04-1",
+						},
 					},
 				},
 			},
-			Images: imageList,
 			WriteBackConfig: &WriteBackConfig{
 				Method: WriteBackApplication,
 			},
+			Images: ImageList{img},
 		}
-
-		// Before the fix for issue #1344, this would fail with:
-		// "cannot use update strategy 'digest' for image... without a version constraint"
-		// because the constraint was lost when setting ImageTag to nil
 		res := UpdateApplication(context.Background(), &UpdateConfiguration{
 			NewRegFN:   mockClientFn,
 			ArgoClient: &argoClient,
@@ -1801,53 +1775,17 @@ registries:
 			UpdateApp:  appImages,
 			DryRun:     false,
 		}, NewSyncIterationState())
-
-		// Verify the update succeeded
-		assert.Equal(t, 0, res.NumErrors, "Should not have errors with forceUpdate + digest strategy")
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/foobar:24.10-1"), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
 		assert.Equal(t, 0, res.NumSkipped)
-		assert.Equal(t, 1, res.NumApplicationsProcessed)
-		assert.Equal(t, 1, res.NumImagesConsidered)
-		assert.Equal(t, 1, res.NumImagesUpdated, "Image should be updated with digest")
-
-		// Verify the kustomize image was updated with the digest
-		require.Len(t, appImages.Application.Spec.Source.Kustomize.Images, 1)
-		updatedImage := string(appImages.Application.Spec.Source.Kustomize.Images[0])
-		assert.Contains(t, updatedImage, "gcr.io/org/job-image")
-		assert.Contains(t, updatedImage, "latest")
-		assert.Contains(t, updatedImage, "sha256:", "Image should include digest")
-
-		// The constraint on the original image must still be present.
-		require.NotNil(t, iuImg.ContainerImage.ImageTag)
-		assert.Equal(t, "latest", iuImg.ContainerImage.ImageTag.TagName)
+		assert.Equal(t, 1, res.NumImagesUpdated)
 	})
 
-	// Test for issue #1357: digest update strategy with suffixed tags (e.g., "latest-bookworm")
-	// Previously, when an image like "nginx:latest-bookworm@sha256:..." was parsed, the tag name
-	// "latest-bookworm" was lost, causing alternating commits between the correct tag and "latest".
-	// This test verifies that the tag name is preserved correctly across multiple update cycles.
-	t.Run("Test digest update strategy with suffixed tag preserves tag name (issue #1357)", func(t *testing.T) {
-		// Use a proper 32-byte hash that when hex-encoded becomes a valid SHA256 digest
-		// The bytes 0x12, 0x34, 0x56, 0x78... become "1234567890abcdef..." when hex-encoded
-		digestBytes := [32]byte{
-			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
-			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
-			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
-			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
-		}
-		testDigest := "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
-
+	t.Run("Test error on invalid calver layout", func(t *testing.T) {
 		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
 			regMock := regmock.RegistryClient{}
 			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
-			regMock.On("Tags", mock.Anything).Return([]string{"latest-bookworm"}, nil)
-			// Mock ManifestForTag to return metadata with the digest
-			meta1 := &schema2.DeserializedManifest{}
-			regMock.On("ManifestForTag", mock.Anything, "latest-bookworm").Return(meta1, nil)
-			// Mock TagMetadata to return the tag info with digest
-			regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
-				CreatedAt: time.Now(),
-				Digest:    digestBytes,
-			}, nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"24.04-1", "24.04-10", "24.04-2", "24.10-1", "25.01-1", "latest"}, nil)
 			return &regMock, nil
 		}
 
@@ -1859,14 +1797,10 @@ registries:
 				Clientset: fake.NewFakeKubeClient(),
 			},
 		}
-
-		// Image configuration with digest update strategy and suffixed tag
-		containerImg := image.NewFromIdentifier("nginx=docker.io/library/nginx:latest-bookworm")
-		iuImg := NewImage(containerImg)
-		iuImg.UpdateStrategy = image.StrategyDigest
-
-		imageList := ImageList{iuImg}
-
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.UpdateStrategy = image.StrategyCalVer
+		img.CalVerLayout = "0M-MICRO"
+		img.CalVerConstraints = []string{"same-year"}
 		appImages := &ApplicationImages{
 			Application: v1alpha1.Application{
 				ObjectMeta: v1.ObjectMeta{
@@ -1877,8 +1811,7 @@ registries:
 					Source: &v1alpha1.ApplicationSource{
 						Kustomize: &v1alpha1.ApplicationSourceKustomize{
 							Images: v1alpha1.KustomizeImages{
-								// Initial state: just the tag without digest
-								"docker.io/library/nginx:latest-bookworm",
+								"jannfis/foobar:24.04-1",
 							},
 						},
 					},
@@ -1887,17 +1820,16 @@ registries:
 					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
 					Summary: v1alpha1.ApplicationSummary{
 						Images: []string{
-							"docker.io/library/nginx:latest-bookworm",
+							"jannfis/foobar:24.04-1",
 						},
 					},
 				},
 			},
-			Images: imageList,
 			WriteBackConfig: &WriteBackConfig{
 				Method: WriteBackApplication,
 			},
+			Images: ImageList{img},
 		}
-
 		res := UpdateApplication(context.Background(), &UpdateConfiguration{
 			NewRegFN:   mockClientFn,
 			ArgoClient: &argoClient,
@@ -1905,44 +1837,31 @@ registries:
 			UpdateApp:  appImages,
 			DryRun:     false,
 		}, NewSyncIterationState())
-
-		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumErrors)
 		assert.Equal(t, 0, res.NumSkipped)
-		assert.Equal(t, 1, res.NumApplicationsProcessed)
 		assert.Equal(t, 1, res.NumImagesConsidered)
-		assert.Equal(t, 1, res.NumImagesUpdated)
-
-		// Verify the updated image has both the tag name AND the digest
-		require.Len(t, appImages.Application.Spec.Source.Kustomize.Images, 1)
-		updatedImage := string(appImages.Application.Spec.Source.Kustomize.Images[0])
-
-		// The image should contain the original tag "latest-bookworm"
-		assert.Contains(t, updatedImage, "latest-bookworm", "Updated image should preserve the tag name 'latest-bookworm'")
-
-		// The image should also contain a digest
-		assert.Contains(t, updatedImage, "@sha256:", "Updated image should contain a digest")
-
-		// Now simulate the second update cycle - this is where the bug manifested
-		// The image now has both tag and digest: "nginx:latest-bookworm@sha256:..."
-		// Parse this image and verify it correctly extracts both tag name and digest
-		parsedImage := image.NewFromIdentifier(updatedImage)
-
-		// This is the critical assertion for issue #1357:
-		// The parsed image should have TagName = "latest-bookworm", not empty
-		require.NotNil(t, parsedImage.ImageTag)
-		assert.Equal(t, "latest-bookworm", parsedImage.ImageTag.TagName,
-			"Parsed image should preserve tag name 'latest-bookworm', not lose it to 'latest'")
+		assert.Equal(t, 0, res.NumImagesUpdated)
+	})
 
-		// Simulate a second update with the same digest - should NOT trigger an update
-		// because the digest hasn't changed
-		containerImg2 := image.NewFromIdentifier("nginx=docker.io/library/nginx:latest-bookworm")
-		iuImg2 := NewImage(containerImg2)
-		iuImg2.UpdateStrategy = image.StrategyDigest
+	t.Run("Test successful update with max minor bump", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.2.3", "1.2.4", "1.3.0", "2.0.0", "2.1.0"}, nil)
+			return &regMock, nil
+		}
 
-		imageList2 := ImageList{iuImg2}
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
 
-		// Create a new app state with the previously updated image (tag + digest)
-		appImages2 := &ApplicationImages{
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.MaxBump = "minor"
+		appImages := &ApplicationImages{
 			Application: v1alpha1.Application{
 				ObjectMeta: v1.ObjectMeta{
 					Name:      "guestbook",
@@ -1952,8 +1871,7 @@ registries:
 					Source: &v1alpha1.ApplicationSource{
 						Kustomize: &v1alpha1.ApplicationSourceKustomize{
 							Images: v1alpha1.KustomizeImages{
-								// State after first update: tag + digest
-								v1alpha1.KustomizeImage("docker.io/library/nginx:latest-bookworm@" + testDigest),
+								"jannfis/foobar:1.2.3",
 							},
 						},
 					},
@@ -1962,69 +1880,45 @@ registries:
 					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
 					Summary: v1alpha1.ApplicationSummary{
 						Images: []string{
-							"docker.io/library/nginx:latest-bookworm@" + testDigest,
+							"jannfis/foobar:1.2.3",
 						},
 					},
 				},
 			},
-			Images: imageList2,
 			WriteBackConfig: &WriteBackConfig{
 				Method: WriteBackApplication,
 			},
+			Images: ImageList{img},
 		}
-
-		res2 := UpdateApplication(context.Background(), &UpdateConfiguration{
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
 			NewRegFN:   mockClientFn,
 			ArgoClient: &argoClient,
 			KubeClient: &kubeClient,
-			UpdateApp:  appImages2,
+			UpdateApp:  appImages,
 			DryRun:     false,
 		}, NewSyncIterationState())
-
-		// No update should occur because the digest is the same
-		assert.Equal(t, 0, res2.NumErrors)
-		assert.Equal(t, 0, res2.NumImagesUpdated, "No update should occur when digest hasn't changed")
-
-		// Verify the image still has the correct tag name after being parsed
-		updatedImage2 := string(appImages2.Application.Spec.Source.Kustomize.Images[0])
-		assert.Contains(t, updatedImage2, "latest-bookworm",
-			"Image should still contain 'latest-bookworm' after second update cycle")
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/foobar:1.3.0"), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumImagesUpdated)
 	})
 
-	// Test for issue #1357: Two tags pointing to the same digest should be considered equal
-	// In the reported issue, the user has tags like "latest" and "latest-bookworm" both pointing
-	// to the same digest. When using digest update strategy, switching between these tags
-	// should NOT trigger an update since they resolve to the same content.
-	t.Run("Test two tags with same digest are considered equal (issue #1357)", func(t *testing.T) {
-		// Both tags will return the same digest
-		sharedDigestBytes := [32]byte{
-			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
-			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
-			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
-			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
-		}
-		sharedDigest := "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
-
-		// Mock registry that returns BOTH tags, and both resolve to the same digest
+	t.Run("Test successful update with label strategy", func(t *testing.T) {
+		labels := map[string]string{"build-7": "1.4.0", "build-8": "2.0.0", "build-9": "1.3.1"}
 		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
 			regMock := regmock.RegistryClient{}
 			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
-			// Return both tags - "latest" and "latest-bookworm" both exist
-			regMock.On("Tags", mock.Anything).Return([]string{"latest", "latest-bookworm"}, nil)
-
-			// Both tags return manifests
-			metaLatest := &schema2.DeserializedManifest{}
-			regMock.On("ManifestForTag", mock.Anything, "latest").Return(metaLatest, nil)
-
-			metaBookworm := &schema2.DeserializedManifest{}
-			regMock.On("ManifestForTag", mock.Anything, "latest-bookworm").Return(metaBookworm, nil)
-
-			// CRITICAL: Both tags return the SAME digest
-			regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
-				CreatedAt: time.Now(),
-				Digest:    sharedDigestBytes,
-			}, nil)
-
+			regMock.On("Tags", mock.Anything).Return([]string{"build-7", "build-8", "build-9"}, nil)
+			for tagName, version := range labels {
+				m := &ocischema.DeserializedManifest{Manifest: ocischema.Manifest{
+					Config: distribution.Descriptor{Digest: godigest.FromString(tagName)},
+				}}
+				regMock.On("ManifestForTag", mock.Anything, tagName).Return(m, nil)
+				regMock.On("TagMetadata", mock.Anything, m, mock.Anything).Return(&tag.TagInfo{
+					CreatedAt: time.Unix(1234567890, 0),
+					Labels:    map[string]string{image.DefaultVersionLabel: version},
+				}, nil)
+			}
 			return &regMock, nil
 		}
 
@@ -2036,16 +1930,9 @@ registries:
 				Clientset: fake.NewFakeKubeClient(),
 			},
 		}
-
-		// User wants to track "latest-bookworm" tag with digest strategy
-		containerImg := image.NewFromIdentifier("nginx=docker.io/library/nginx:latest-bookworm")
-		iuImg := NewImage(containerImg)
-		iuImg.UpdateStrategy = image.StrategyDigest
-
-		imageList := ImageList{iuImg}
-
-		// Application currently has "latest" tag with the SAME digest
-		// This simulates the scenario where both tags point to the same image content
+		img := NewImage(image.NewFromIdentifier("jannfis/labelled"))
+		img.UpdateStrategy = image.StrategyLabel
+		img.LabelConstraint = "~1"
 		appImages := &ApplicationImages{
 			Application: v1alpha1.Application{
 				ObjectMeta: v1.ObjectMeta{
@@ -2056,8 +1943,7 @@ registries:
 					Source: &v1alpha1.ApplicationSource{
 						Kustomize: &v1alpha1.ApplicationSourceKustomize{
 							Images: v1alpha1.KustomizeImages{
-								// Current state: "latest" tag with the shared digest
-								v1alpha1.KustomizeImage("docker.io/library/nginx:latest@" + sharedDigest),
+								"jannfis/labelled:build-9",
 							},
 						},
 					},
@@ -2066,17 +1952,16 @@ registries:
 					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
 					Summary: v1alpha1.ApplicationSummary{
 						Images: []string{
-							"docker.io/library/nginx:latest@" + sharedDigest,
+							"jannfis/labelled:build-9",
 						},
 					},
 				},
 			},
-			Images: imageList,
 			WriteBackConfig: &WriteBackConfig{
 				Method: WriteBackApplication,
 			},
+			Images: ImageList{img},
 		}
-
 		res := UpdateApplication(context.Background(), &UpdateConfiguration{
 			NewRegFN:   mockClientFn,
 			ArgoClient: &argoClient,
@@ -2084,27 +1969,913 @@ registries:
 			UpdateApp:  appImages,
 			DryRun:     false,
 		}, NewSyncIterationState())
-
-		// Since both "latest" and "latest-bookworm" resolve to the same digest,
-		// NO update should occur - the content is identical
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/labelled:build-7"), appImages.Application.Spec.Source.Kustomize.Images[0])
 		assert.Equal(t, 0, res.NumErrors)
-		assert.Equal(t, 0, res.NumImagesUpdated,
-			"No update should occur when two different tags point to the same digest")
-
-		// Verify the image was NOT changed (still has "latest" tag, not "latest-bookworm")
-		updatedImage := string(appImages.Application.Spec.Source.Kustomize.Images[0])
-		assert.Contains(t, updatedImage, "latest@"+sharedDigest,
-			"Image should remain unchanged since digest is the same")
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumImagesUpdated)
 	})
 
-	// ---------------------------------------------------------------------------
-	// Image-signature verification branches (update.go lines 201-223)
-	// ---------------------------------------------------------------------------
+	t.Run("Test successful update with required and forbidden labels", func(t *testing.T) {
+		labels := map[string]map[string]string{
+			"1.0.0": {"com.example.qa": "passed"},
+			"1.1.0": {"com.example.qa": "passed"},
+			"1.2.0": {"com.example.qa": "passed", "com.example.deprecated": "true"},
+			"1.3.0": {},
+		}
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.0.0", "1.1.0", "1.2.0", "1.3.0"}, nil)
+			for tagName, tagLabels := range labels {
+				m := &ocischema.DeserializedManifest{Manifest: ocischema.Manifest{
+					Config: distribution.Descriptor{Digest: godigest.FromString(tagName)},
+				}}
+				regMock.On("ManifestForTag", mock.Anything, tagName).Return(m, nil)
+				regMock.On("TagMetadata", mock.Anything, m, mock.Anything).Return(&tag.TagInfo{
+					CreatedAt: time.Unix(1234567890, 0),
+					Labels:    tagLabels,
+				}, nil)
+			}
+			return &regMock, nil
+		}
 
-	// verifyAppImages is a minimal helper that wires up a single-image kustomize
-	// Application for the verification tests.  The returned ApplicationImages uses
-	// the image "gcr.io/jannfis/foobar" with a semver >=1.0.1 constraint so that
-	// the mock registry (returning "1.0.1" and "1.0.2") always picks "1.0.2" as
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/qa-labelled"))
+		img.RequiredLabels = []image.LabelMatch{{Key: "com.example.qa", Value: "passed"}}
+		img.ForbiddenLabels = []image.LabelMatch{{Key: "com.example.deprecated"}}
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								"jannfis/qa-labelled:1.0.0",
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"jannfis/qa-labelled:1.0.0",
+						},
+					},
+				},
+			},
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+			Images: ImageList{img},
+		}
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/qa-labelled:1.1.0"), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+	})
+
+	t.Run("Test label strategy with invalid label format", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			return &regMock, nil
+		}
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/labelled"))
+		img.UpdateStrategy = image.StrategyLabel
+		img.LabelFormat = "pep440"
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								"jannfis/labelled:build-9",
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"jannfis/labelled:build-9",
+						},
+					},
+				},
+			},
+			Images: ImageList{img},
+		}
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+		assert.Equal(t, 1, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+	})
+
+	t.Run("Test update to pinned version", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.2.3", "1.2.4", "1.3.0", "2.0.0", "2.1.0"}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.Pin = &ImagePin{Version: "1.2.4", Until: time.Now().Add(time.Hour), Reason: "incident"}
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								"jannfis/foobar:1.2.3",
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"jannfis/foobar:1.2.3",
+						},
+					},
+				},
+			},
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+			Images: ImageList{img},
+		}
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/foobar:1.2.4"), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+		require.Len(t, res.Pinned, 1)
+		assert.Equal(t, "1.2.4", res.Pinned[0].Tag.TagName)
+		assert.Equal(t, "incident", res.Pinned[0].Reason)
+	})
+
+	t.Run("Test update with expired pin", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.2.3", "1.2.4", "1.3.0", "2.0.0", "2.1.0"}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.Pin = &ImagePin{Version: "1.2.4", Until: time.Now().Add(-time.Hour)}
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								"jannfis/foobar:1.2.3",
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"jannfis/foobar:1.2.3",
+						},
+					},
+				},
+			},
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+			Images: ImageList{img},
+		}
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/foobar:2.1.0"), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+		assert.Empty(t, res.Pinned)
+	})
+
+	t.Run("Test successful update with rc pre-release policy", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.2.3", "1.2.4", "1.3.0-beta.1", "1.3.0-rc.1", "1.4.0-alpha.1"}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.Prerelease = []string{"rc"}
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								"jannfis/foobar:1.2.3",
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"jannfis/foobar:1.2.3",
+						},
+					},
+				},
+			},
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+			Images: ImageList{img},
+		}
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/foobar:1.3.0-rc.1"), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+	})
+
+	t.Run("Test successful update with relaxed semver", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"v1.2.3.1", "v1.2.3.4", "v1.2.3.10-alpine", "v1.2.4", "v1.3.0-rc.1", "latest"}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.RelaxedSemVer = true
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								"jannfis/foobar:v1.2.3.1",
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"jannfis/foobar:v1.2.3.1",
+						},
+					},
+				},
+			},
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+			Images: ImageList{img},
+		}
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/foobar:v1.2.4"), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+	})
+
+	t.Run("Test successful update with pinned digest", func(t *testing.T) {
+		var digest [32]byte
+		copy(digest[:], []byte("abcdef1234567890"))
+		pinned := fmt.Sprintf("sha256:%x", digest)
+		meta1 := &schema2.DeserializedManifest{}
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.2.3", "1.3.0"}, nil)
+			regMock.On("ManifestForTag", mock.Anything, "1.3.0").Return(meta1, nil)
+			regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
+				CreatedAt: time.Unix(1234567890, 0),
+				Digest:    digest,
+			}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.PinDigest = true
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								"jannfis/foobar:1.2.3",
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"jannfis/foobar:1.2.3",
+						},
+					},
+				},
+			},
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+			Images: ImageList{img},
+		}
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/foobar:1.3.0@"+pinned), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+	})
+
+	t.Run("Test no update when running image is already pinned to the newest digest", func(t *testing.T) {
+		var digest [32]byte
+		copy(digest[:], []byte("abcdef1234567890"))
+		pinned := fmt.Sprintf("sha256:%x", digest)
+		meta1 := &schema2.DeserializedManifest{}
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.2.3", "1.3.0"}, nil)
+			regMock.On("ManifestForTag", mock.Anything, "1.3.0").Return(meta1, nil)
+			regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
+				CreatedAt: time.Unix(1234567890, 0),
+				Digest:    digest,
+			}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+		img := NewImage(image.NewFromIdentifier("jannfis/foobar"))
+		img.PinDigest = true
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								v1alpha1.KustomizeImage("jannfis/foobar:1.3.0@" + pinned),
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"jannfis/foobar:1.3.0@" + pinned,
+						},
+					},
+				},
+			},
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+			Images: ImageList{img},
+		}
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+		assert.Equal(t, v1alpha1.KustomizeImage("jannfis/foobar:1.3.0@"+pinned), appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+	})
+
+	t.Run("Test Kubernetes Job with forceUpdate and digest strategy (issue #1344)", func(t *testing.T) {
+		// This test reproduces the scenario from issue #1344:
+		// - Application uses a Kubernetes Job (not in app.Status.Summary.Images)
+		// - forceUpdate: true is set
+		// - updateStrategy: "digest" is used
+		// - A version constraint (tag) is provided
+
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.MatchedBy(func(s string) bool {
+				return s == "org/job-image"
+			})).Return(nil)
+			// Return the tag that matches our version constraint
+			regMock.On("Tags", mock.Anything).Return([]string{"latest"}, nil)
+
+			// For digest strategy, we need to mock ManifestForTag and TagMetadata
+			meta1 := &schema2.DeserializedManifest{}
+			regMock.On("ManifestForTag", mock.Anything, "latest").Return(meta1, nil)
+			// Create a digest as [32]byte array
+			var digest [32]byte
+			copy(digest[:], []byte("abcdef1234567890"))
+			regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
+				CreatedAt: time.Unix(1234567890, 0),
+				Digest:    digest,
+			}, nil)
+
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+
+		// Image configuration with forceUpdate and digest strategy
+		// The tag "latest" serves as the version constraint for digest strategy
+		containerImg := image.NewFromIdentifier("job-image=gcr.io/org/job-image:latest")
+		iuImg := NewImage(containerImg)
+		iuImg.KustomizeImageName = "org/job-image"
+		iuImg.ForceUpdate = true
+		iuImg.UpdateStrategy = image.StrategyDigest
+
+		imageList := ImageList{iuImg}
+
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "job-app",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						// Empty images list - simulating a Kubernetes Job that doesn't
+						// appear in the application status
+						Images: []string{},
+					},
+				},
+			},
+			Images: imageList,
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+		}
+
+		// Before the fix for issue #1344, this would fail with:
+		// "cannot use update strategy 'digest' for image... without a version constraint"
+		// because the constraint was lost when setting ImageTag to nil
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+
+		// Verify the update succeeded
+		assert.Equal(t, 0, res.NumErrors, "Should not have errors with forceUpdate + digest strategy")
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumApplicationsProcessed)
+		assert.Equal(t, 1, res.NumImagesConsidered)
+		assert.Equal(t, 1, res.NumImagesUpdated, "Image should be updated with digest")
+
+		// Verify the kustomize image was updated with the digest
+		require.Len(t, appImages.Application.Spec.Source.Kustomize.Images, 1)
+		updatedImage := string(appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Contains(t, updatedImage, "gcr.io/org/job-image")
+		assert.Contains(t, updatedImage, "latest")
+		assert.Contains(t, updatedImage, "sha256:", "Image should include digest")
+
+		// The constraint on the original image must still be present.
+		require.NotNil(t, iuImg.ContainerImage.ImageTag)
+		assert.Equal(t, "latest", iuImg.ContainerImage.ImageTag.TagName)
+	})
+
+	// Test for issue #1357: digest update strategy with suffixed tags (e.g., "latest-bookworm")
+	// Previously, when an image like "nginx:latest-bookworm@sha256:..." was parsed, the tag name
+	// "latest-bookworm" was lost, causing alternating commits between the correct tag and "latest".
+	// This test verifies that the tag name is preserved correctly across multiple update cycles.
+	t.Run("Test digest update strategy with suffixed tag preserves tag name (issue #1357)", func(t *testing.T) {
+		// Use a proper 32-byte hash that when hex-encoded becomes a valid SHA256 digest
+		// The bytes 0x12, 0x34, 0x56, 0x78... become "1234567890abcdef..." when hex-encoded
+		digestBytes := [32]byte{
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+		}
+		testDigest := "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"latest-bookworm"}, nil)
+			// Mock ManifestForTag to return metadata with the digest
+			meta1 := &schema2.DeserializedManifest{}
+			regMock.On("ManifestForTag", mock.Anything, "latest-bookworm").Return(meta1, nil)
+			// Mock TagMetadata to return the tag info with digest
+			regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
+				CreatedAt: time.Now(),
+				Digest:    digestBytes,
+			}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+
+		// Image configuration with digest update strategy and suffixed tag
+		containerImg := image.NewFromIdentifier("nginx=docker.io/library/nginx:latest-bookworm")
+		iuImg := NewImage(containerImg)
+		iuImg.UpdateStrategy = image.StrategyDigest
+
+		imageList := ImageList{iuImg}
+
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								// Initial state: just the tag without digest
+								"docker.io/library/nginx:latest-bookworm",
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"docker.io/library/nginx:latest-bookworm",
+						},
+					},
+				},
+			},
+			Images: imageList,
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+		}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumSkipped)
+		assert.Equal(t, 1, res.NumApplicationsProcessed)
+		assert.Equal(t, 1, res.NumImagesConsidered)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+
+		// Verify the updated image has both the tag name AND the digest
+		require.Len(t, appImages.Application.Spec.Source.Kustomize.Images, 1)
+		updatedImage := string(appImages.Application.Spec.Source.Kustomize.Images[0])
+
+		// The image should contain the original tag "latest-bookworm"
+		assert.Contains(t, updatedImage, "latest-bookworm", "Updated image should preserve the tag name 'latest-bookworm'")
+
+		// The image should also contain a digest
+		assert.Contains(t, updatedImage, "@sha256:", "Updated image should contain a digest")
+
+		// Now simulate the second update cycle - this is where the bug manifested
+		// The image now has both tag and digest: "nginx:latest-bookworm@sha256:..."
+		// Parse this image and verify it correctly extracts both tag name and digest
+		parsedImage := image.NewFromIdentifier(updatedImage)
+
+		// This is the critical assertion for issue #1357:
+		// The parsed image should have TagName = "latest-bookworm", not empty
+		require.NotNil(t, parsedImage.ImageTag)
+		assert.Equal(t, "latest-bookworm", parsedImage.ImageTag.TagName,
+			"Parsed image should preserve tag name 'latest-bookworm', not lose it to 'latest'")
+
+		// Simulate a second update with the same digest - should NOT trigger an update
+		// because the digest hasn't changed
+		containerImg2 := image.NewFromIdentifier("nginx=docker.io/library/nginx:latest-bookworm")
+		iuImg2 := NewImage(containerImg2)
+		iuImg2.UpdateStrategy = image.StrategyDigest
+
+		imageList2 := ImageList{iuImg2}
+
+		// Create a new app state with the previously updated image (tag + digest)
+		appImages2 := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								// State after first update: tag + digest
+								v1alpha1.KustomizeImage("docker.io/library/nginx:latest-bookworm@" + testDigest),
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"docker.io/library/nginx:latest-bookworm@" + testDigest,
+						},
+					},
+				},
+			},
+			Images: imageList2,
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+		}
+
+		res2 := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages2,
+			DryRun:     false,
+		}, NewSyncIterationState())
+
+		// No update should occur because the digest is the same
+		assert.Equal(t, 0, res2.NumErrors)
+		assert.Equal(t, 0, res2.NumImagesUpdated, "No update should occur when digest hasn't changed")
+
+		// Verify the image still has the correct tag name after being parsed
+		updatedImage2 := string(appImages2.Application.Spec.Source.Kustomize.Images[0])
+		assert.Contains(t, updatedImage2, "latest-bookworm",
+			"Image should still contain 'latest-bookworm' after second update cycle")
+	})
+
+	// Test for issue #1357: Two tags pointing to the same digest should be considered equal
+	// In the reported issue, the user has tags like "latest" and "latest-bookworm" both pointing
+	// to the same digest. When using digest update strategy, switching between these tags
+	// should NOT trigger an update since they resolve to the same content.
+	t.Run("Test two tags with same digest are considered equal (issue #1357)", func(t *testing.T) {
+		// Both tags will return the same digest
+		sharedDigestBytes := [32]byte{
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+			0x12, 0x34, 0x56, 0x78, 0x90, 0xab, 0xcd, 0xef,
+		}
+		sharedDigest := "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+		// Mock registry that returns BOTH tags, and both resolve to the same digest
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			// Return both tags - "latest" and "latest-bookworm" both exist
+			regMock.On("Tags", mock.Anything).Return([]string{"latest", "latest-bookworm"}, nil)
+
+			// Both tags return manifests
+			metaLatest := &schema2.DeserializedManifest{}
+			regMock.On("ManifestForTag", mock.Anything, "latest").Return(metaLatest, nil)
+
+			metaBookworm := &schema2.DeserializedManifest{}
+			regMock.On("ManifestForTag", mock.Anything, "latest-bookworm").Return(metaBookworm, nil)
+
+			// CRITICAL: Both tags return the SAME digest
+			regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
+				CreatedAt: time.Now(),
+				Digest:    sharedDigestBytes,
+			}, nil)
+
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		kubeClient := kube.ImageUpdaterKubernetesClient{
+			KubeClient: &registryKube.KubernetesClient{
+				Clientset: fake.NewFakeKubeClient(),
+			},
+		}
+
+		// User wants to track "latest-bookworm" tag with digest strategy
+		containerImg := image.NewFromIdentifier("nginx=docker.io/library/nginx:latest-bookworm")
+		iuImg := NewImage(containerImg)
+		iuImg.UpdateStrategy = image.StrategyDigest
+
+		imageList := ImageList{iuImg}
+
+		// Application currently has "latest" tag with the SAME digest
+		// This simulates the scenario where both tags point to the same image content
+		appImages := &ApplicationImages{
+			Application: v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "guestbook",
+					Namespace: "guestbook",
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					Source: &v1alpha1.ApplicationSource{
+						Kustomize: &v1alpha1.ApplicationSourceKustomize{
+							Images: v1alpha1.KustomizeImages{
+								// Current state: "latest" tag with the shared digest
+								v1alpha1.KustomizeImage("docker.io/library/nginx:latest@" + sharedDigest),
+							},
+						},
+					},
+				},
+				Status: v1alpha1.ApplicationStatus{
+					SourceType: v1alpha1.ApplicationSourceTypeKustomize,
+					Summary: v1alpha1.ApplicationSummary{
+						Images: []string{
+							"docker.io/library/nginx:latest@" + sharedDigest,
+						},
+					},
+				},
+			},
+			Images: imageList,
+			WriteBackConfig: &WriteBackConfig{
+				Method: WriteBackApplication,
+			},
+		}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &kubeClient,
+			UpdateApp:  appImages,
+			DryRun:     false,
+		}, NewSyncIterationState())
+
+		// Since both "latest" and "latest-bookworm" resolve to the same digest,
+		// NO update should occur - the content is identical
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated,
+			"No update should occur when two different tags point to the same digest")
+
+		// Verify the image was NOT changed (still has "latest" tag, not "latest-bookworm")
+		updatedImage := string(appImages.Application.Spec.Source.Kustomize.Images[0])
+		assert.Contains(t, updatedImage, "latest@"+sharedDigest,
+			"Image should remain unchanged since digest is the same")
+	})
+
+	// ---------------------------------------------------------------------------
+	// Image-signature verification branches (update.go lines 201-223)
+	// ---------------------------------------------------------------------------
+
+	// verifyAppImages is a minimal helper that wires up a single-image kustomize
+	// Application for the verification tests.  The returned ApplicationImages uses
+	// the image "gcr.io/jannfis/foobar" with a semver >=1.0.1 constraint so that
+	// the mock registry (returning "1.0.1" and "1.0.2") always picks "1.0.2" as
 	// the new tag — and the early-return path in GetTags never calls ManifestForTag.
 	verifyAppImages := func(iuImg *Image) *ApplicationImages {
 		return &ApplicationImages{
@@ -2127,51 +2898,231 @@ registries:
 			WriteBackConfig: &WriteBackConfig{Method: WriteBackApplication},
 			Images:          ImageList{iuImg},
 		}
-	}
+	}
+
+	verifyKubeClient := kube.ImageUpdaterKubernetesClient{
+		KubeClient: &registryKube.KubernetesClient{
+			Clientset: fake.NewFakeKubeClient(),
+		},
+	}
+
+	t.Run("image verification disabled per-image proceeds to update", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		// EnableVerification is false by default
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+	})
+
+	t.Run("verification enabled but no method configured counts as error", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+			return &regMock, nil
+		}
+
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.EnableVerification = true
+		// Verify intentionally left nil — simulates a bug where EnableVerification=true
+		// but no verification method was configured; should count as an error.
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 1, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+	})
+
+	t.Run("cosign-key verification fetch failure counts as error", func(t *testing.T) {
+		// ManifestForTag is called by fetchTagSignatures (slow path — ManifestDigest
+		// is not cached for SemVer tags).  Returning an error forces VerifyWithPublicKey
+		// to fail, which increments NumErrors and skips the update.
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+			regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("registry unavailable"))
+			return &regMock, nil
+		}
+
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.EnableVerification = true
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 1, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+	})
+
+	t.Run("cosign-keyless verification fetch failure counts as error", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+			regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("registry unavailable"))
+			return &regMock, nil
+		}
+
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.EnableVerification = true
+		iuImg.Verify = &image.Verify{Keyless: &image.KeylessVerify{}}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 1, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+	})
+
+	t.Run("cosign-keys verification below threshold holds update and names missing signers", func(t *testing.T) {
+		build, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		scanner, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		pemPub := func(key *ecdsa.PrivateKey) string {
+			der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+			require.NoError(t, err)
+			return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		}
 
-	verifyKubeClient := kube.ImageUpdaterKubernetesClient{
-		KubeClient: &registryKube.KubernetesClient{
-			Clientset: fake.NewFakeKubeClient(),
-		},
-	}
+		// The image is only signed by the build system. The mock ManifestForTag
+		// returns a zero-value manifest, so godigest.FromBytes(nil) is the image
+		// manifest digest.
+		payloadType := "application/vnd.dev.cosign.simplesigning.v1+json"
+		payload := fmt.Appendf(nil, `{"critical":{"image":{"docker-manifest-digest":"%s"}}}`, godigest.FromBytes(nil))
+		pae := append(fmt.Appendf(nil, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload)), payload...)
+		paeHash := sha256.Sum256(pae)
+		rawSig, err := ecdsa.SignASN1(rand.Reader, build, paeHash[:])
+		require.NoError(t, err)
+		blobBytes := fmt.Appendf(nil, `{"dsseEnvelope":{"payload":"%s","payloadType":"%s","signatures":[{"sig":"%s"}]}}`,
+			base64.StdEncoding.EncodeToString(payload), payloadType, base64.StdEncoding.EncodeToString(rawSig))
+		sigManifest := &ocischema.DeserializedManifest{
+			Manifest: ocischema.Manifest{
+				Layers: []distribution.Descriptor{{
+					MediaType: "application/vnd.dev.sigstore.bundle.v0.3+json",
+					Digest:    godigest.FromBytes(blobBytes),
+					Size:      int64(len(blobBytes)),
+				}},
+			},
+		}
 
-	t.Run("image verification disabled per-image proceeds to update", func(t *testing.T) {
 		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
 			regMock := regmock.RegistryClient{}
 			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
 			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+			regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(&schema2.DeserializedManifest{}, nil)
+			regMock.On("Referrers", mock.Anything, mock.Anything).Return([]distribution.Descriptor{{
+				MediaType:    "application/vnd.oci.image.manifest.v1+json",
+				ArtifactType: "application/vnd.dev.sigstore.bundle.v0.3+json",
+				Digest:       godigest.FromBytes([]byte("sig-manifest")),
+			}}, nil)
+			regMock.On("ManifestForDigest", mock.Anything, mock.Anything).Return(sigManifest, nil)
+			regMock.On("BlobContent", mock.Anything, mock.Anything).Return(blobBytes, nil)
 			return &regMock, nil
 		}
 
-		argoClient := argomock.ArgoCD{}
-		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
-
 		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
-		// EnableVerification is false by default
+		iuImg.EnableVerification = true
+		iuImg.Verify = &image.Verify{CosignKeys: []image.NamedPublicKey{
+			{Name: "build", Key: pemPub(build)},
+			{Name: "scanner", Key: pemPub(scanner)},
+		}}
 
 		res := UpdateApplication(context.Background(), &UpdateConfiguration{
 			NewRegFN:   mockClientFn,
-			ArgoClient: &argoClient,
+			ArgoClient: &argomock.ArgoCD{},
 			KubeClient: &verifyKubeClient,
 			UpdateApp:  verifyAppImages(iuImg),
 		}, NewSyncIterationState())
 
 		assert.Equal(t, 0, res.NumErrors)
-		assert.Equal(t, 1, res.NumImagesUpdated)
+		assert.Equal(t, 1, res.NumSkipped)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+		require.Len(t, res.Held, 1)
+		assert.Equal(t, HeldReasonMissingSignatures, res.Held[0].Reason)
+		assert.Equal(t, "1.0.2", res.Held[0].Tag.TagName)
+		assert.Contains(t, res.Held[0].Message, "missing signatures from scanner (1 of 2 required signatures found)")
 	})
 
-	t.Run("verification enabled but no method configured counts as error", func(t *testing.T) {
+	t.Run("signed image without provenance attestation blocks update", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+
+		// The image carries a valid signature, but no SLSA provenance
+		payloadType := "application/vnd.dev.cosign.simplesigning.v1+json"
+		payload := fmt.Appendf(nil, `{"critical":{"image":{"docker-manifest-digest":"%s"}}}`, godigest.FromBytes(nil))
+		pae := append(fmt.Appendf(nil, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload)), payload...)
+		paeHash := sha256.Sum256(pae)
+		rawSig, err := ecdsa.SignASN1(rand.Reader, key, paeHash[:])
+		require.NoError(t, err)
+		blobBytes := fmt.Appendf(nil, `{"dsseEnvelope":{"payload":"%s","payloadType":"%s","signatures":[{"sig":"%s"}]}}`,
+			base64.StdEncoding.EncodeToString(payload), payloadType, base64.StdEncoding.EncodeToString(rawSig))
+		sigManifest := &ocischema.DeserializedManifest{
+			Manifest: ocischema.Manifest{
+				Layers: []distribution.Descriptor{{
+					MediaType: "application/vnd.dev.sigstore.bundle.v0.3+json",
+					Digest:    godigest.FromBytes(blobBytes),
+					Size:      int64(len(blobBytes)),
+				}},
+			},
+		}
+
 		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
 			regMock := regmock.RegistryClient{}
 			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
 			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+			regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(&schema2.DeserializedManifest{}, nil)
+			regMock.On("Referrers", mock.Anything, mock.Anything).Return([]distribution.Descriptor{{
+				MediaType:    "application/vnd.oci.image.manifest.v1+json",
+				ArtifactType: "application/vnd.dev.sigstore.bundle.v0.3+json",
+				Digest:       godigest.FromBytes([]byte("sig-manifest")),
+			}}, nil)
+			regMock.On("ManifestForDigest", mock.Anything, mock.Anything).Return(sigManifest, nil)
+			regMock.On("BlobContent", mock.Anything, mock.Anything).Return(blobBytes, nil)
 			return &regMock, nil
 		}
 
+		provenance, err := image.NewProvenancePolicy([]string{"https://ci.example.com/builder"}, nil, nil)
+		require.NoError(t, err)
 		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
 		iuImg.EnableVerification = true
-		// Verify intentionally left nil — simulates a bug where EnableVerification=true
-		// but no verification method was configured; should count as an error.
+		iuImg.Verify = &image.Verify{
+			CosignKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+			Provenance: provenance,
+		}
 
 		res := UpdateApplication(context.Background(), &UpdateConfiguration{
 			NewRegFN:   mockClientFn,
@@ -2184,20 +3135,31 @@ registries:
 		assert.Equal(t, 0, res.NumImagesUpdated)
 	})
 
-	t.Run("cosign-key verification fetch failure counts as error", func(t *testing.T) {
-		// ManifestForTag is called by fetchTagSignatures (slow path — ManifestDigest
-		// is not cached for SemVer tags).  Returning an error forces VerifyWithPublicKey
-		// to fail, which increments NumErrors and skips the update.
+	t.Run("notation verification without signature blocks update", func(t *testing.T) {
 		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
 			regMock := regmock.RegistryClient{}
 			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
 			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
-			regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("registry unavailable"))
+			regMock.On("ManifestForTag", mock.Anything, "1.0.2").Return(&schema2.DeserializedManifest{}, nil)
+			regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("manifest unknown"))
+			regMock.On("Referrers", mock.Anything, mock.Anything).Return([]distribution.Descriptor{}, nil)
 			return &regMock, nil
 		}
 
+		caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		caTmpl := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour), IsCA: true, BasicConstraintsValid: true}
+		caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+		require.NoError(t, err)
+		caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}))
+
+		nv, err := image.NewNotationVerify(`{"version":"1.0","trustPolicies":[{"name":"default","registryScopes":["*"],
+			"signatureVerification":{"level":"audit"},"trustStores":["ca:acme"],"trustedIdentities":["*"]}]}`,
+			map[string]string{"ca:acme": caPEM})
+		require.NoError(t, err)
 		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
 		iuImg.EnableVerification = true
+		iuImg.Verify = &image.Verify{Notation: nv}
 
 		res := UpdateApplication(context.Background(), &UpdateConfiguration{
 			NewRegFN:   mockClientFn,
@@ -2210,6 +3172,35 @@ registries:
 		assert.Equal(t, 0, res.NumImagesUpdated)
 	})
 
+	t.Run("notation verification with skip level proceeds to update", func(t *testing.T) {
+		mockClientFn := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+			return &regMock, nil
+		}
+
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		nv, err := image.NewNotationVerify(`{"version":"1.0","trustPolicies":[{"name":"foobar","registryScopes":["gcr.io/jannfis/foobar"],
+			"signatureVerification":{"level":"skip"}}]}`, nil)
+		require.NoError(t, err)
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.EnableVerification = true
+		iuImg.Verify = &image.Verify{Notation: nv}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   mockClientFn,
+			ArgoClient: &argoClient,
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+	})
+
 	t.Run("cosign-key verification succeeds proceeds to update", func(t *testing.T) {
 		// Generate a real ECDSA key pair for this test.
 		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
@@ -2296,6 +3287,353 @@ registries:
 		assert.Equal(t, 1, res.NumImagesUpdated)
 	})
 
+	// artifactRegistry returns a registry client serving the tags 1.0.1 to
+	// 1.0.3, each with a distinct manifest, and the given artifacts of
+	// artifactType as referrers of the tags' manifests.
+	artifactRegistry := func(t *testing.T, artifactType string, artifacts map[string]string) func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+		return func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2", "1.0.3"}, nil)
+			for _, tagName := range []string{"1.0.1", "1.0.2", "1.0.3"} {
+				m, err := schema2.FromStruct(schema2.Manifest{
+					Config: distribution.Descriptor{MediaType: schema2.MediaTypeImageConfig, Digest: godigest.FromString(tagName)},
+				})
+				require.NoError(t, err)
+				regMock.On("ManifestForTag", mock.Anything, tagName).Return(m, nil)
+
+				_, payload, err := m.Payload()
+				require.NoError(t, err)
+				artifact, ok := artifacts[tagName]
+				if !ok {
+					regMock.On("Referrers", mock.Anything, godigest.FromBytes(payload)).Return([]distribution.Descriptor{}, nil)
+					continue
+				}
+				blob := []byte(artifact)
+				artifactDigest := godigest.FromString("artifact-" + tagName)
+				regMock.On("Referrers", mock.Anything, godigest.FromBytes(payload)).Return([]distribution.Descriptor{{
+					MediaType:    "application/vnd.oci.image.manifest.v1+json",
+					ArtifactType: artifactType,
+					Digest:       artifactDigest,
+				}}, nil)
+				regMock.On("ManifestForDigest", mock.Anything, artifactDigest).Return(&ocischema.DeserializedManifest{
+					Manifest: ocischema.Manifest{
+						Layers: []distribution.Descriptor{{MediaType: artifactType, Digest: godigest.FromBytes(blob), Size: int64(len(blob))}},
+					},
+				}, nil)
+				regMock.On("BlobContent", mock.Anything, godigest.FromBytes(blob)).Return(blob, nil)
+			}
+			regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("manifest unknown"))
+			return &regMock, nil
+		}
+	}
+	const cleanReport = `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"Trivy"}},"results":[]}]}`
+	const criticalReport = `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"Trivy","rules":[` +
+		`{"id":"CVE-2024-0001","properties":{"security-severity":"9.8"}}]}},"results":[{"ruleId":"CVE-2024-0001","ruleIndex":0}]}]}`
+
+	t.Run("vulnerable newest tag is held", func(t *testing.T) {
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.Vulnerabilities = &image.VulnerabilityPolicy{MaxCount: map[image.Severity]int{image.SeverityCritical: 0}, RequireReport: true}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   artifactRegistry(t, "application/sarif+json", map[string]string{"1.0.2": cleanReport, "1.0.3": criticalReport}),
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+		assert.Equal(t, 1, res.NumSkipped)
+		require.Len(t, res.Held, 1)
+		assert.Equal(t, HeldReasonVulnerabilities, res.Held[0].Reason)
+		assert.Equal(t, "1.0.3", res.Held[0].Tag.TagName)
+		assert.Equal(t, "Update to 1.0.3 rejected, as it has too many vulnerabilities: 1 critical (max 0).", res.Held[0].Message)
+	})
+
+	t.Run("vulnerable newest tag falls back to older compliant tag", func(t *testing.T) {
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.Vulnerabilities = &image.VulnerabilityPolicy{MaxCount: map[image.Severity]int{image.SeverityCritical: 0}, RequireReport: true, Fallback: true}
+		appImages := verifyAppImages(iuImg)
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   artifactRegistry(t, "application/sarif+json", map[string]string{"1.0.2": cleanReport, "1.0.3": criticalReport}),
+			ArgoClient: &argoClient,
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  appImages,
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+		require.Len(t, res.Held, 1)
+		assert.Equal(t, "1.0.3", res.Held[0].Tag.TagName)
+		assert.Equal(t, v1alpha1.KustomizeImages{"gcr.io/jannfis/foobar:1.0.2"}, appImages.Application.Spec.Source.Kustomize.Images)
+	})
+
+	t.Run("fallback stops at the running tag", func(t *testing.T) {
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.Vulnerabilities = &image.VulnerabilityPolicy{RequireReport: true, Fallback: true}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   artifactRegistry(t, "application/sarif+json", map[string]string{"1.0.1": cleanReport}),
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+		require.Len(t, res.Held, 2)
+		assert.Equal(t, "1.0.3", res.Held[0].Tag.TagName)
+		assert.Equal(t, "Update to 1.0.3 rejected, as it has no vulnerability report.", res.Held[0].Message)
+		assert.Equal(t, "1.0.2", res.Held[1].Tag.TagName)
+	})
+
+	t.Run("fallback stops at a running tag that is not eligible", func(t *testing.T) {
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.AllowTags = `regexp:^1\.0\.[13]$`
+		iuImg.Vulnerabilities = &image.VulnerabilityPolicy{RequireReport: true, Fallback: true}
+		appImages := verifyAppImages(iuImg)
+		appImages.Application.Spec.Source.Kustomize.Images = v1alpha1.KustomizeImages{"jannfis/foobar:1.0.2"}
+		appImages.Application.Status.Summary.Images = []string{"gcr.io/jannfis/foobar:1.0.2"}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   artifactRegistry(t, "application/sarif+json", map[string]string{"1.0.1": cleanReport}),
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  appImages,
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+		require.Len(t, res.Held, 1)
+		assert.Equal(t, "1.0.3", res.Held[0].Tag.TagName)
+		assert.Equal(t, v1alpha1.KustomizeImages{"jannfis/foobar:1.0.2"}, appImages.Application.Spec.Source.Kustomize.Images)
+	})
+
+	t.Run("missing report is accepted when not required", func(t *testing.T) {
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.Vulnerabilities = &image.VulnerabilityPolicy{MaxCount: map[image.Severity]int{image.SeverityCritical: 0}}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   artifactRegistry(t, "application/sarif+json", nil),
+			ArgoClient: &argoClient,
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+		assert.Empty(t, res.Held)
+	})
+
+	const spdxSBOM = `{"spdxVersion":"SPDX-2.3","packages":[{"name":"musl","licenseConcluded":"MIT"},{"name":"mongodb","licenseDeclared":"SSPL-1.0"}]}`
+
+	t.Run("image with denied license in its SBOM is held", func(t *testing.T) {
+		sbomPolicy, err := image.NewSBOMPolicy([]string{"SSPL-*"})
+		require.NoError(t, err)
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.SBOM = sbomPolicy
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   artifactRegistry(t, "application/spdx+json", map[string]string{"1.0.3": spdxSBOM}),
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+		assert.Equal(t, 1, res.NumSkipped)
+		require.Len(t, res.Held, 1)
+		assert.Equal(t, HeldReasonDeniedLicenses, res.Held[0].Reason)
+		assert.Equal(t, "Update to 1.0.3 rejected, as its SBOM contains denied licenses: SSPL-1.0.", res.Held[0].Message)
+	})
+
+	t.Run("image without SBOM is held", func(t *testing.T) {
+		sbomPolicy, err := image.NewSBOMPolicy(nil)
+		require.NoError(t, err)
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.SBOM = sbomPolicy
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   artifactRegistry(t, "application/spdx+json", map[string]string{"1.0.2": spdxSBOM}),
+			ArgoClient: &argomock.ArgoCD{},
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+		require.Len(t, res.Held, 1)
+		assert.Equal(t, HeldReasonMissingSBOM, res.Held[0].Reason)
+		assert.Equal(t, "1.0.3", res.Held[0].Tag.TagName)
+	})
+
+	t.Run("image with compliant SBOM proceeds to update", func(t *testing.T) {
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		sbomPolicy, err := image.NewSBOMPolicy([]string{"AGPL-*"})
+		require.NoError(t, err)
+		iuImg := NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))
+		iuImg.SBOM = sbomPolicy
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:   artifactRegistry(t, "application/spdx+json", map[string]string{"1.0.3": spdxSBOM}),
+			ArgoClient: &argoClient,
+			KubeClient: &verifyKubeClient,
+			UpdateApp:  verifyAppImages(iuImg),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+		assert.Empty(t, res.Held)
+	})
+
+	// admissionRegistry returns a registry client serving the tags 1.0.1 and
+	// 1.0.2, whose manifests resolve to the digest of their tag name.
+	admissionRegistry := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+		regMock := regmock.RegistryClient{}
+		regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+		regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+		regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(&schema2.DeserializedManifest{}, nil)
+		regMock.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{
+			Digest: sha256.Sum256([]byte("1.0.2")),
+		}, nil)
+		return &regMock, nil
+	}
+	// admissionServer returns an admission hook endpoint responding with
+	// status and body, and recording the request it received in req.
+	admissionServer := func(t *testing.T, status int, body string, req *AdmissionRequest) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	t.Run("update allowed by admission hook", func(t *testing.T) {
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+
+		var review AdmissionRequest
+		srv := admissionServer(t, http.StatusOK, `{"allowed": true}`, &review)
+		appImages := verifyAppImages(NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1")))
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:      admissionRegistry,
+			ArgoClient:    &argoClient,
+			KubeClient:    &verifyKubeClient,
+			UpdateApp:     appImages,
+			AdmissionHook: NewAdmissionHook(&iuapi.AdmissionHook{URL: srv.URL}),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+		assert.Empty(t, res.Held)
+		assert.Equal(t, AdmissionRequest{
+			Application: AdmissionApplication{Name: "guestbook", Namespace: "guestbook"},
+			Alias:       "foobar",
+			Image:       "gcr.io/jannfis/foobar",
+			OldTag:      "1.0.1",
+			NewTag:      "1.0.2",
+			Digest:      fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("1.0.2"))),
+		}, review)
+		assert.Equal(t, v1alpha1.KustomizeImages{"gcr.io/jannfis/foobar:1.0.2"}, appImages.Application.Spec.Source.Kustomize.Images)
+	})
+
+	t.Run("update denied by admission hook is held", func(t *testing.T) {
+		var review AdmissionRequest
+		srv := admissionServer(t, http.StatusOK, `{"allowed": false, "reason": "change freeze in effect"}`, &review)
+		appImages := verifyAppImages(NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1")))
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:      admissionRegistry,
+			ArgoClient:    &argomock.ArgoCD{},
+			KubeClient:    &verifyKubeClient,
+			UpdateApp:     appImages,
+			AdmissionHook: NewAdmissionHook(&iuapi.AdmissionHook{URL: srv.URL}),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+		assert.Equal(t, 1, res.NumSkipped)
+		require.Len(t, res.Held, 1)
+		assert.Equal(t, HeldReasonAdmissionDenied, res.Held[0].Reason)
+		assert.Equal(t, "1.0.2", res.Held[0].Tag.TagName)
+		assert.Equal(t, "Update to 1.0.2 rejected by admission hook: change freeze in effect.", res.Held[0].Message)
+		assert.Equal(t, v1alpha1.KustomizeImages{"jannfis/foobar:1.0.1"}, appImages.Application.Spec.Source.Kustomize.Images)
+	})
+
+	t.Run("failing admission hook fails closed", func(t *testing.T) {
+		srv := admissionServer(t, http.StatusInternalServerError, "", &AdmissionRequest{})
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:      admissionRegistry,
+			ArgoClient:    &argomock.ArgoCD{},
+			KubeClient:    &verifyKubeClient,
+			UpdateApp:     verifyAppImages(NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))),
+			AdmissionHook: NewAdmissionHook(&iuapi.AdmissionHook{URL: srv.URL}),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 1, res.NumErrors)
+		assert.Equal(t, 0, res.NumImagesUpdated)
+		assert.Empty(t, res.Held)
+	})
+
+	t.Run("failing admission hook fails open", func(t *testing.T) {
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+		srv := admissionServer(t, http.StatusInternalServerError, "", &AdmissionRequest{})
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:      admissionRegistry,
+			ArgoClient:    &argoClient,
+			KubeClient:    &verifyKubeClient,
+			UpdateApp:     verifyAppImages(NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))),
+			AdmissionHook: NewAdmissionHook(&iuapi.AdmissionHook{URL: srv.URL, FailurePolicy: new(AdmissionFailurePolicyIgnore)}),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+	})
+
+	t.Run("failing digest lookup for admission hook fails open", func(t *testing.T) {
+		argoClient := argomock.ArgoCD{}
+		argoClient.On("UpdateSpec", mock.Anything, mock.Anything).Return(nil, nil)
+		var review AdmissionRequest
+		srv := admissionServer(t, http.StatusOK, `{"allowed": false}`, &review)
+		failingRegistry := func(endpoint *registry.RegistryEndpoint, username, password string) (registry.RegistryClient, error) {
+			regMock := regmock.RegistryClient{}
+			regMock.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+			regMock.On("Tags", mock.Anything).Return([]string{"1.0.1", "1.0.2"}, nil)
+			regMock.On("ManifestForTag", mock.Anything, mock.Anything).Return(nil, errors.New("registry unavailable"))
+			return &regMock, nil
+		}
+
+		res := UpdateApplication(context.Background(), &UpdateConfiguration{
+			NewRegFN:      failingRegistry,
+			ArgoClient:    &argoClient,
+			KubeClient:    &verifyKubeClient,
+			UpdateApp:     verifyAppImages(NewImage(image.NewFromIdentifier("foobar=gcr.io/jannfis/foobar:>=1.0.1"))),
+			AdmissionHook: NewAdmissionHook(&iuapi.AdmissionHook{URL: srv.URL, FailurePolicy: new(AdmissionFailurePolicyIgnore)}),
+		}, NewSyncIterationState())
+
+		assert.Equal(t, 0, res.NumErrors)
+		assert.Equal(t, 1, res.NumImagesUpdated)
+		assert.Empty(t, review.NewTag)
+	})
+
 	// Regression test for #1547: when two containers share the same image name
 	// and tag but their digests have diverged, the live image list
 	// (Status.Summary.Images) is alias-less and de-duplicated by full reference.
@@ -7,12 +7,19 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/cache"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry"
 )
 
 type Metrics struct {
 	Endpoint       *EndpointMetrics
 	ImageUpdaterCR *ImageUpdaterCRMetrics
 	Clients        *ClientMetrics
+	Cache          *CacheMetrics
+	Mirrors        *MirrorMetrics
+	Retries        *RetryMetrics
+	Quotas         *QuotaMetrics
 }
 
 var (
@@ -40,6 +47,40 @@ type ClientMetrics struct {
 	kubeAPIRequestsErrorsTotal prometheus.Counter
 }
 
+// CacheMetrics collects the statistics of the tag caches of the registry
+// endpoints. They are read from the caches when the metrics are collected.
+type CacheMetrics struct {
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+	stats     func() map[string]cache.Stats
+}
+
+// MirrorMetrics collects the statistics of the endpoints of the registries
+// that have mirrors. They are read from the endpoints when the metrics are
+// collected.
+type MirrorMetrics struct {
+	requests    *prometheus.Desc
+	failures    *prometheus.Desc
+	circuitOpen *prometheus.Desc
+	stats       func() map[string][]registry.EndpointStats
+}
+
+// RetryMetrics collects the retries of the requests to the registries. They
+// are read from the endpoints when the metrics are collected.
+type RetryMetrics struct {
+	retries   *prometheus.Desc
+	throttled *prometheus.Desc
+	stats     func() map[string]registry.RetryStats
+}
+
+// QuotaMetrics collects the quotas the registries report. They are read from
+// the registry transports' quota trackers when the metrics are collected.
+type QuotaMetrics struct {
+	remaining *prometheus.Desc
+	stats     func() []registry.QuotaStats
+}
+
 // NewEndpointMetrics returns a new endpoint metrics object
 func NewEndpointMetrics() *EndpointMetrics {
 	metrics := &EndpointMetrics{}
@@ -100,11 +141,144 @@ func NewClientMetrics() *ClientMetrics {
 	return metrics
 }
 
+// NewCacheMetrics returns a new tag cache metrics object
+func NewCacheMetrics() *CacheMetrics {
+	metrics := &CacheMetrics{
+		hits: prometheus.NewDesc("argocd_image_updater_tag_cache_hits_total",
+			"The number of tag metadata lookups answered by the cache of this registry",
+			[]string{"registry"}, nil),
+		misses: prometheus.NewDesc("argocd_image_updater_tag_cache_misses_total",
+			"The number of tag metadata lookups not answered by the cache of this registry",
+			[]string{"registry"}, nil),
+		evictions: prometheus.NewDesc("argocd_image_updater_tag_cache_evictions_total",
+			"The number of entries evicted from the tag cache of this registry",
+			[]string{"registry", "reason"}, nil),
+		stats: registry.CacheStats,
+	}
+	crmetrics.Registry.MustRegister(metrics)
+	return metrics
+}
+
+// Describe implements prometheus.Collector
+func (cm *CacheMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cm.hits
+	ch <- cm.misses
+	ch <- cm.evictions
+}
+
+// Collect implements prometheus.Collector
+func (cm *CacheMetrics) Collect(ch chan<- prometheus.Metric) {
+	for prefix, stats := range cm.stats() {
+		ch <- prometheus.MustNewConstMetric(cm.hits, prometheus.CounterValue, float64(stats.Hits), prefix)
+		ch <- prometheus.MustNewConstMetric(cm.misses, prometheus.CounterValue, float64(stats.Misses), prefix)
+		ch <- prometheus.MustNewConstMetric(cm.evictions, prometheus.CounterValue, float64(stats.Expired), prefix, "expired")
+		ch <- prometheus.MustNewConstMetric(cm.evictions, prometheus.CounterValue, float64(stats.Evicted), prefix, "size")
+		ch <- prometheus.MustNewConstMetric(cm.evictions, prometheus.CounterValue, float64(stats.Invalidated), prefix, "invalidated")
+	}
+}
+
+// NewMirrorMetrics returns a new registry mirror metrics object
+func NewMirrorMetrics() *MirrorMetrics {
+	metrics := &MirrorMetrics{
+		requests: prometheus.NewDesc("argocd_image_updater_registry_mirror_requests_total",
+			"The number of requests served by this endpoint of a registry with mirrors",
+			[]string{"registry", "endpoint"}, nil),
+		failures: prometheus.NewDesc("argocd_image_updater_registry_mirror_failures_total",
+			"The number of requests failed over from this endpoint of a registry with mirrors",
+			[]string{"registry", "endpoint"}, nil),
+		circuitOpen: prometheus.NewDesc("argocd_image_updater_registry_mirror_circuit_open",
+			"Whether requests are currently not sent to this endpoint of a registry with mirrors",
+			[]string{"registry", "endpoint"}, nil),
+		stats: registry.MirrorStats,
+	}
+	crmetrics.Registry.MustRegister(metrics)
+	return metrics
+}
+
+// Describe implements prometheus.Collector
+func (mm *MirrorMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- mm.requests
+	ch <- mm.failures
+	ch <- mm.circuitOpen
+}
+
+// Collect implements prometheus.Collector
+func (mm *MirrorMetrics) Collect(ch chan<- prometheus.Metric) {
+	for prefix, endpoints := range mm.stats() {
+		for _, stats := range endpoints {
+			circuitOpen := 0.0
+			if stats.CircuitOpen {
+				circuitOpen = 1
+			}
+			ch <- prometheus.MustNewConstMetric(mm.requests, prometheus.CounterValue, float64(stats.Served), prefix, stats.Endpoint)
+			ch <- prometheus.MustNewConstMetric(mm.failures, prometheus.CounterValue, float64(stats.Failed), prefix, stats.Endpoint)
+			ch <- prometheus.MustNewConstMetric(mm.circuitOpen, prometheus.GaugeValue, circuitOpen, prefix, stats.Endpoint)
+		}
+	}
+}
+
+// NewRetryMetrics returns a new registry retry metrics object
+func NewRetryMetrics() *RetryMetrics {
+	metrics := &RetryMetrics{
+		retries: prometheus.NewDesc("argocd_image_updater_registry_retries_total",
+			"The number of requests to this registry that were retried",
+			[]string{"registry"}, nil),
+		throttled: prometheus.NewDesc("argocd_image_updater_registry_throttled_total",
+			"The number of requests to this registry that were throttled (HTTP 429)",
+			[]string{"registry"}, nil),
+		stats: registry.RegistryRetryStats,
+	}
+	crmetrics.Registry.MustRegister(metrics)
+	return metrics
+}
+
+// Describe implements prometheus.Collector
+func (rm *RetryMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- rm.retries
+	ch <- rm.throttled
+}
+
+// Collect implements prometheus.Collector
+func (rm *RetryMetrics) Collect(ch chan<- prometheus.Metric) {
+	for prefix, stats := range rm.stats() {
+		ch <- prometheus.MustNewConstMetric(rm.retries, prometheus.CounterValue, float64(stats.Retries), prefix)
+		ch <- prometheus.MustNewConstMetric(rm.throttled, prometheus.CounterValue, float64(stats.Throttled), prefix)
+	}
+}
+
+// NewQuotaMetrics returns a new registry quota metrics object
+func NewQuotaMetrics() *QuotaMetrics {
+	metrics := &QuotaMetrics{
+		remaining: prometheus.NewDesc("argocd_image_updater_registry_quota_remaining",
+			"The number of requests remaining in the quota this registry reported for these credentials",
+			[]string{"registry", "username"}, nil),
+		stats: registry.RegistryQuotaStats,
+	}
+	crmetrics.Registry.MustRegister(metrics)
+	return metrics
+}
+
+// Describe implements prometheus.Collector
+func (qm *QuotaMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- qm.remaining
+}
+
+// Collect implements prometheus.Collector
+func (qm *QuotaMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range qm.stats() {
+		ch <- prometheus.MustNewConstMetric(qm.remaining, prometheus.GaugeValue, float64(stats.Remaining), stats.Registry, stats.Username)
+	}
+}
+
 func NewMetrics() *Metrics {
 	return &Metrics{
 		Endpoint:       NewEndpointMetrics(),
 		ImageUpdaterCR: NewImageUpdaterCRMetrics(),
 		Clients:        NewClientMetrics(),
+		Cache:          NewCacheMetrics(),
+		Mirrors:        NewMirrorMetrics(),
+		Retries:        NewRetryMetrics(),
+		Quotas:         NewQuotaMetrics(),
 	}
 }
 
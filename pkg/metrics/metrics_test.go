@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"strings"
 	"testing"
 
 	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -8,6 +9,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/cache"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry"
 )
 
 func TestMetricsInitialization(t *testing.T) {
@@ -53,6 +57,34 @@ func TestMetricsInitialization(t *testing.T) {
 		assert.NotNil(t, apm.ImagesUpdatedErrorsTotal)
 	})
 
+	t.Run("NewCacheMetrics", func(t *testing.T) {
+		crmetrics.Registry = prometheus.NewRegistry()
+		cm := NewCacheMetrics()
+		assert.NotNil(t, cm)
+		assert.NotNil(t, cm.stats)
+	})
+
+	t.Run("NewMirrorMetrics", func(t *testing.T) {
+		crmetrics.Registry = prometheus.NewRegistry()
+		mm := NewMirrorMetrics()
+		assert.NotNil(t, mm)
+		assert.NotNil(t, mm.stats)
+	})
+
+	t.Run("NewRetryMetrics", func(t *testing.T) {
+		crmetrics.Registry = prometheus.NewRegistry()
+		rm := NewRetryMetrics()
+		assert.NotNil(t, rm)
+		assert.NotNil(t, rm.stats)
+	})
+
+	t.Run("NewQuotaMetrics", func(t *testing.T) {
+		crmetrics.Registry = prometheus.NewRegistry()
+		qm := NewQuotaMetrics()
+		assert.NotNil(t, qm)
+		assert.NotNil(t, qm.stats)
+	})
+
 	t.Run("InitMetrics is idempotent", func(t *testing.T) {
 		// Replace the default registry with a new one for this test.
 		crmetrics.Registry = prometheus.NewRegistry()
@@ -123,3 +155,96 @@ func TestImageUpdaterCRMetricsRemovals(t *testing.T) {
 		assert.Equal(t, float64(3), testutil.ToFloat64(apm.ImagesUpdatedErrorsTotal.WithLabelValues("cr2", "ns2")))
 	})
 }
+
+func TestCacheMetrics(t *testing.T) {
+	crmetrics.Registry = prometheus.NewRegistry()
+	cm := NewCacheMetrics()
+	cm.stats = func() map[string]cache.Stats {
+		return map[string]cache.Stats{
+			"docker.io": {Hits: 10, Misses: 4, Expired: 3, Evicted: 2, Invalidated: 1},
+		}
+	}
+
+	expected := `
+# HELP argocd_image_updater_tag_cache_evictions_total The number of entries evicted from the tag cache of this registry
+# TYPE argocd_image_updater_tag_cache_evictions_total counter
+argocd_image_updater_tag_cache_evictions_total{reason="expired",registry="docker.io"} 3
+argocd_image_updater_tag_cache_evictions_total{reason="invalidated",registry="docker.io"} 1
+argocd_image_updater_tag_cache_evictions_total{reason="size",registry="docker.io"} 2
+# HELP argocd_image_updater_tag_cache_hits_total The number of tag metadata lookups answered by the cache of this registry
+# TYPE argocd_image_updater_tag_cache_hits_total counter
+argocd_image_updater_tag_cache_hits_total{registry="docker.io"} 10
+# HELP argocd_image_updater_tag_cache_misses_total The number of tag metadata lookups not answered by the cache of this registry
+# TYPE argocd_image_updater_tag_cache_misses_total counter
+argocd_image_updater_tag_cache_misses_total{registry="docker.io"} 4
+`
+	assert.NoError(t, testutil.CollectAndCompare(cm, strings.NewReader(expected)))
+}
+
+func TestMirrorMetrics(t *testing.T) {
+	crmetrics.Registry = prometheus.NewRegistry()
+	mm := NewMirrorMetrics()
+	mm.stats = func() map[string][]registry.EndpointStats {
+		return map[string][]registry.EndpointStats{
+			"quay.io": {
+				{Endpoint: "https://quay.io", Served: 2, Failed: 3, CircuitOpen: true},
+				{Endpoint: "https://mirror.example.com", Served: 5},
+			},
+		}
+	}
+
+	expected := `
+# HELP argocd_image_updater_registry_mirror_circuit_open Whether requests are currently not sent to this endpoint of a registry with mirrors
+# TYPE argocd_image_updater_registry_mirror_circuit_open gauge
+argocd_image_updater_registry_mirror_circuit_open{endpoint="https://mirror.example.com",registry="quay.io"} 0
+argocd_image_updater_registry_mirror_circuit_open{endpoint="https://quay.io",registry="quay.io"} 1
+# HELP argocd_image_updater_registry_mirror_failures_total The number of requests failed over from this endpoint of a registry with mirrors
+# TYPE argocd_image_updater_registry_mirror_failures_total counter
+argocd_image_updater_registry_mirror_failures_total{endpoint="https://mirror.example.com",registry="quay.io"} 0
+argocd_image_updater_registry_mirror_failures_total{endpoint="https://quay.io",registry="quay.io"} 3
+# HELP argocd_image_updater_registry_mirror_requests_total The number of requests served by this endpoint of a registry with mirrors
+# TYPE argocd_image_updater_registry_mirror_requests_total counter
+argocd_image_updater_registry_mirror_requests_total{endpoint="https://mirror.example.com",registry="quay.io"} 5
+argocd_image_updater_registry_mirror_requests_total{endpoint="https://quay.io",registry="quay.io"} 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(mm, strings.NewReader(expected)))
+}
+
+func TestRetryMetrics(t *testing.T) {
+	crmetrics.Registry = prometheus.NewRegistry()
+	rm := NewRetryMetrics()
+	rm.stats = func() map[string]registry.RetryStats {
+		return map[string]registry.RetryStats{
+			"docker.io": {Retries: 7, Throttled: 4},
+		}
+	}
+
+	expected := `
+# HELP argocd_image_updater_registry_retries_total The number of requests to this registry that were retried
+# TYPE argocd_image_updater_registry_retries_total counter
+argocd_image_updater_registry_retries_total{registry="docker.io"} 7
+# HELP argocd_image_updater_registry_throttled_total The number of requests to this registry that were throttled (HTTP 429)
+# TYPE argocd_image_updater_registry_throttled_total counter
+argocd_image_updater_registry_throttled_total{registry="docker.io"} 4
+`
+	assert.NoError(t, testutil.CollectAndCompare(rm, strings.NewReader(expected)))
+}
+
+func TestQuotaMetrics(t *testing.T) {
+	crmetrics.Registry = prometheus.NewRegistry()
+	qm := NewQuotaMetrics()
+	qm.stats = func() []registry.QuotaStats {
+		return []registry.QuotaStats{
+			{Registry: "https://registry-1.docker.io", Limit: 100, Remaining: 76},
+			{Registry: "https://registry-1.docker.io", Username: "jannfis", Limit: 200, Remaining: 150},
+		}
+	}
+
+	expected := `
+# HELP argocd_image_updater_registry_quota_remaining The number of requests remaining in the quota this registry reported for these credentials
+# TYPE argocd_image_updater_registry_quota_remaining gauge
+argocd_image_updater_registry_quota_remaining{registry="https://registry-1.docker.io",username=""} 76
+argocd_image_updater_registry_quota_remaining{registry="https://registry-1.docker.io",username="jannfis"} 150
+`
+	assert.NoError(t, testutil.CollectAndCompare(qm, strings.NewReader(expected)))
+}
@@ -25,7 +25,9 @@ import (
 	api "github.com/argoproj-labs/argocd-image-updater/api/v1alpha1"
 	"github.com/argoproj-labs/argocd-image-updater/internal/controller"
 	"github.com/argoproj-labs/argocd-image-updater/pkg/argocd"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/image"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry"
 )
 
 const (
@@ -481,6 +483,10 @@ func (s *WebhookServer) processWebhookEvent(ctx context.Context, event *argocd.W
 	processingCtx := log.ContextWithLogger(context.Background(), logCtx)
 	logCtx.Infof("Processing webhook event for %s/%s:%s", event.RegistryURL, event.Repository, event.Tag)
 
+	// The pushed tag may have replaced an earlier image, so any metadata
+	// cached for it is stale.
+	registry.InvalidateCachedTags(processingCtx, &image.ContainerImage{RegistryURL: event.RegistryURL, ImageName: event.Repository}, event.Tag)
+
 	imageList := &api.ImageUpdaterList{}
 
 	logCtx.Debugf("Listing all ImageUpdater CRs...")
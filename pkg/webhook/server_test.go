@@ -24,6 +24,8 @@ import (
 	imageupdaterapi "github.com/argoproj-labs/argocd-image-updater/api/v1alpha1"
 	"github.com/argoproj-labs/argocd-image-updater/internal/controller"
 	"github.com/argoproj-labs/argocd-image-updater/pkg/argocd"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 )
 
 type mockRateLimiter struct {
@@ -332,6 +334,28 @@ func TestProcessWebhookEvent(t *testing.T) {
 	wg.Wait()
 }
 
+// TestProcessWebhookEventInvalidatesCachedTag ensures that the cached metadata
+// of a pushed tag is evicted, as the tag may refer to a different image now
+func TestProcessWebhookEventInvalidatesCachedTag(t *testing.T) {
+	ctx := context.Background()
+	registry.RestoreDefaultRegistryConfiguration(ctx)
+	defer registry.RestoreDefaultRegistryConfiguration(ctx)
+	ep := registry.GetDefaultRegistry(ctx)
+	require.NotNil(t, ep)
+	ep.Cache.SetTag("library/nginx", tag.NewImageTag("1.21.0", time.Unix(0, 0), ""))
+	ep.Cache.SetTag("library/nginx", tag.NewImageTag("1.22.0", time.Unix(0, 0), ""))
+
+	server := createMockServer(t, 8080)
+	err := server.processWebhookEvent(ctx, &argocd.WebhookEvent{
+		RegistryURL: "docker.io",
+		Repository:  "nginx",
+		Tag:         "1.21.0",
+	})
+	assert.NoError(t, err)
+	assert.False(t, ep.Cache.HasTag("library/nginx", "1.21.0"))
+	assert.True(t, ep.Cache.HasTag("library/nginx", "1.22.0"))
+}
+
 // TestWebhookServerWebhookEndpoint ensures that the webhook endpoint of the server is working properly
 func TestWebhookServerWebhookEndpoint(t *testing.T) {
 	server := createMockServer(t, 8080)
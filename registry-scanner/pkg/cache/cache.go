@@ -1,15 +1,58 @@
 package cache
 
 import (
+	"time"
+
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 )
 
+// Types of ImageTagCache that can be configured for a registry
+const (
+	// TypeMemory caches tags in memory (the default)
+	TypeMemory = "memory"
+	// TypeDisk persists cached tags to disk, see DiskCache
+	TypeDisk = "disk"
+)
+
 type ImageTagCache interface {
 	HasTag(imageName string, imageTag string) bool
 	GetTag(imageName string, imageTag string) (*tag.ImageTag, error)
 	SetTag(imageName string, imgTag *tag.ImageTag)
+	// DeleteTag evicts the entry for a tag of an image, if there is one
+	DeleteTag(imageName string, imageTag string)
+	// DeleteImage evicts the entries for all tags of an image
+	DeleteImage(imageName string)
 	ClearCache()
 	NumEntries() int
+	// Stats returns the counters of the events of the cache
+	Stats() Stats
+}
+
+// Options bound the entries of an ImageTagCache. The zero value keeps all
+// entries forever.
+type Options struct {
+	// TTL is the duration after which an entry expires. Zero disables
+	// expiry.
+	TTL time.Duration
+	// MaxEntries is the maximum number of entries, above which the least
+	// recently used entries are evicted. Zero disables the limit.
+	MaxEntries int
+}
+
+// Stats holds the counters of the events of an ImageTagCache
+type Stats struct {
+	// Hits is the number of lookups that found an entry
+	Hits uint64
+	// Misses is the number of lookups that found no valid entry
+	Misses uint64
+	// Expired is the number of entries that were evicted after their TTL
+	Expired uint64
+	// Evicted is the number of entries that were evicted to stay within the
+	// maximum number of entries
+	Evicted uint64
+	// Invalidated is the number of entries that were evicted explicitly,
+	// e.g. because the tag was pushed again
+	Invalidated uint64
 }
 
 // KnownImage represents a known image and the applications using it, without
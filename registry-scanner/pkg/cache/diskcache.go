@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+// diskCacheVersion is the version of the format of DiskCache entries. It must
+// be increased with every incompatible change to the format, including changes
+// to tag.ImageTag. Entries of any other version are dropped when the cache is
+// loaded.
+const diskCacheVersion = 1
+
+// diskCacheEntry is the format of a single entry of a DiskCache on disk
+type diskCacheEntry struct {
+	Version  int           `json:"version"`
+	Image    string        `json:"image"`
+	Tag      *tag.ImageTag `json:"tag"`
+	CachedAt time.Time     `json:"cachedAt"`
+}
+
+// DiskCache is an ImageTagCache that persists its entries in a directory, so
+// that they survive restarts. Each entry is stored in a file of its own, in a
+// subdirectory sharded by the hash of the entry's key. All entries are held in
+// memory as well, and are only read from disk when the cache is created.
+type DiskCache struct {
+	path    string
+	lock    sync.Mutex
+	tags    map[string]tag.ImageTag
+	tracker *entryTracker
+}
+
+// NewDiskCache returns a new instance of DiskCache that stores its entries in
+// the directory at path, which is created if it does not exist, and whose
+// entries are bounded by opts. Existing entries are loaded from path, dropping
+// those that cannot be read, are of an incompatible version, or have expired.
+func NewDiskCache(path string, opts Options) (ImageTagCache, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create cache directory %s: %w", path, err)
+	}
+	dc := &DiskCache{
+		path:    path,
+		tags:    make(map[string]tag.ImageTag),
+		tracker: newEntryTracker(opts),
+	}
+	if err := dc.load(); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// load reads all entries from disk
+func (dc *DiskCache) load() error {
+	var entries []*diskCacheEntry
+	dropped := 0
+	err := filepath.WalkDir(dc.path, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if filepath.Ext(path) != ".json" {
+			// Left over from an interrupted write
+			_ = os.Remove(path)
+			return nil
+		}
+		entry, err := readDiskCacheEntry(path)
+		if err == nil && dc.entryPath(entry.key()) != path {
+			err = fmt.Errorf("entry is stored under the wrong name")
+		}
+		if err != nil {
+			log.Debugf("Dropping cache entry %s: %v", path, err)
+			_ = os.Remove(path)
+			dropped++
+			return nil
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not load cache from %s: %w", dc.path, err)
+	}
+
+	// Entries are tracked from the least to the most recently cached one, so
+	// that the least recently cached ones are evicted first.
+	slices.SortFunc(entries, func(a, b *diskCacheEntry) int {
+		return a.CachedAt.Compare(b.CachedAt)
+	})
+	for _, entry := range entries {
+		key := entry.key()
+		if dc.tracker.isExpired(&trackedEntry{key: key, cachedAt: entry.CachedAt}) {
+			_ = os.Remove(dc.entryPath(key))
+			dropped++
+			continue
+		}
+		dc.tags[key] = *entry.Tag
+		for _, k := range dc.tracker.add(key, entry.CachedAt) {
+			dc.deleteEntry(k)
+			dropped++
+		}
+	}
+	log.Infof("Loaded %d entries from cache %s, dropped %d invalid or expired entries", len(dc.tags), dc.path, dropped)
+	return nil
+}
+
+// readDiskCacheEntry reads the entry from the file at path
+func readDiskCacheEntry(path string) (*diskCacheEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry diskCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Version != diskCacheVersion {
+		return nil, fmt.Errorf("incompatible version %d", entry.Version)
+	}
+	if entry.Image == "" || entry.Tag == nil || entry.Tag.TagName == "" {
+		return nil, fmt.Errorf("incomplete entry")
+	}
+	return &entry, nil
+}
+
+// key returns the cache key of the entry
+func (e *diskCacheEntry) key() string {
+	return tagCacheKey(e.Image, e.Tag.TagName)
+}
+
+// entryPath returns the path of the file storing the entry with key
+func (dc *DiskCache) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(dc.path, name[:2], name+".json")
+}
+
+// HasTag returns true if cache has entry for given tag, false if not
+func (dc *DiskCache) HasTag(imageName string, tagName string) bool {
+	tag, err := dc.GetTag(imageName, tagName)
+	return err == nil && tag != nil
+}
+
+// SetTag sets a tag entry into the cache, and writes it to disk. Failing to
+// write the entry is not fatal, as it only is refetched after a restart.
+func (dc *DiskCache) SetTag(imageName string, imgTag *tag.ImageTag) {
+	key := tagCacheKey(imageName, imgTag.TagName)
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	dc.tags[key] = *imgTag
+	cachedAt := dc.tracker.now()
+	if err := dc.writeEntry(key, &diskCacheEntry{Version: diskCacheVersion, Image: imageName, Tag: imgTag, CachedAt: cachedAt}); err != nil {
+		log.Warnf("Could not write cache entry for %s:%s: %v", imageName, imgTag.TagName, err)
+	}
+	for _, k := range dc.tracker.add(key, cachedAt) {
+		dc.deleteEntry(k)
+	}
+}
+
+// deleteEntry removes the entry with key from memory and disk
+func (dc *DiskCache) deleteEntry(key string) {
+	delete(dc.tags, key)
+	if err := os.Remove(dc.entryPath(key)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Could not remove cache entry %s: %v", dc.entryPath(key), err)
+	}
+}
+
+// writeEntry atomically writes entry to the file of key
+func (dc *DiskCache) writeEntry(key string, entry *diskCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := dc.entryPath(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// GetTag gets a tag entry from the cache
+func (dc *DiskCache) GetTag(imageName string, tagName string) (*tag.ImageTag, error) {
+	key := tagCacheKey(imageName, tagName)
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	imgTag, ok := dc.tags[key]
+	if !dc.tracker.lookup(key, ok) {
+		if ok {
+			dc.deleteEntry(key)
+		}
+		return nil, nil
+	}
+	return &imgTag, nil
+}
+
+// DeleteTag evicts the entry for a tag of an image, if there is one
+func (dc *DiskCache) DeleteTag(imageName string, tagName string) {
+	key := tagCacheKey(imageName, tagName)
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	if dc.tracker.invalidate(key) {
+		dc.deleteEntry(key)
+	}
+}
+
+// DeleteImage evicts the entries for all tags of an image
+func (dc *DiskCache) DeleteImage(imageName string) {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	for _, k := range dc.tracker.invalidatePrefix(tagCacheKey(imageName, "")) {
+		dc.deleteEntry(k)
+	}
+}
+
+// ClearCache clears the cache, and removes all of its entries from disk
+func (dc *DiskCache) ClearCache() {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	dc.tags = make(map[string]tag.ImageTag)
+	dc.tracker.clear()
+	shards, err := os.ReadDir(dc.path)
+	if err != nil {
+		log.Warnf("Could not clear cache %s: %v", dc.path, err)
+		return
+	}
+	for _, shard := range shards {
+		if !shard.IsDir() || strings.HasPrefix(shard.Name(), ".") {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dc.path, shard.Name())); err != nil {
+			log.Warnf("Could not clear cache %s: %v", dc.path, err)
+		}
+	}
+}
+
+// NumEntries returns the number of entries in the cache
+func (dc *DiskCache) NumEntries() int {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	return len(dc.tags)
+}
+
+// Stats returns the counters of the events of the cache
+func (dc *DiskCache) Stats() Stats {
+	return dc.tracker.stats()
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+func Test_DiskCache(t *testing.T) {
+	imageName := "foo/bar"
+	imageTag := "v1.0.0"
+
+	t.Run("Cache hit", func(t *testing.T) {
+		dc, err := NewDiskCache(t.TempDir(), Options{})
+		require.NoError(t, err)
+		newTag := tag.NewImageTagWithLabels(imageTag, time.Unix(0, 0), "sha256:abcd", map[string]string{"org.opencontainers.image.version": "1.0.0"})
+		dc.SetTag(imageName, newTag)
+		cachedTag, err := dc.GetTag(imageName, imageTag)
+		require.NoError(t, err)
+		require.NotNil(t, cachedTag)
+		assert.Equal(t, newTag, cachedTag)
+		assert.True(t, dc.HasTag(imageName, imageTag))
+		assert.Equal(t, 1, dc.NumEntries())
+	})
+
+	t.Run("Cache miss", func(t *testing.T) {
+		dc, err := NewDiskCache(t.TempDir(), Options{})
+		require.NoError(t, err)
+		dc.SetTag(imageName, tag.NewImageTag(imageTag, time.Unix(0, 0), ""))
+		cachedTag, err := dc.GetTag(imageName, "v1.0.1")
+		require.NoError(t, err)
+		require.Nil(t, cachedTag)
+		assert.False(t, dc.HasTag(imageName, "v1.0.1"))
+	})
+
+	t.Run("Entries survive a restart", func(t *testing.T) {
+		path := t.TempDir()
+		dc, err := NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+		newTag := tag.NewImageTag(imageTag, createdAt, "sha256:abcd")
+		newTag.ManifestDigest = "sha256:ef01"
+		dc.SetTag(imageName, newTag)
+		dc.SetTag("foo/baz", tag.NewImageTag("v2.0.0", createdAt, ""))
+
+		dc, err = NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, dc.NumEntries())
+		cachedTag, err := dc.GetTag(imageName, imageTag)
+		require.NoError(t, err)
+		require.NotNil(t, cachedTag)
+		assert.Equal(t, "sha256:abcd", cachedTag.TagDigest)
+		assert.Equal(t, "sha256:ef01", cachedTag.ManifestDigest)
+		assert.True(t, createdAt.Equal(*cachedTag.TagDate))
+		assert.True(t, dc.HasTag("foo/baz", "v2.0.0"))
+	})
+
+	t.Run("Incompatible and invalid entries are dropped", func(t *testing.T) {
+		path := t.TempDir()
+		dc, err := NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		dc.SetTag(imageName, tag.NewImageTag(imageTag, time.Unix(0, 0), ""))
+		dc.SetTag(imageName, tag.NewImageTag("v1.0.1", time.Unix(0, 0), ""))
+		dc.SetTag(imageName, tag.NewImageTag("v1.0.2", time.Unix(0, 0), ""))
+
+		diskCache := dc.(*DiskCache)
+		oldVersion := diskCache.entryPath(tagCacheKey(imageName, "v1.0.1"))
+		require.NoError(t, os.WriteFile(oldVersion, []byte(`{"version":0,"image":"foo/bar","tag":{"TagName":"v1.0.1"}}`), 0o600))
+		corrupt := diskCache.entryPath(tagCacheKey(imageName, "v1.0.2"))
+		require.NoError(t, os.WriteFile(corrupt, []byte(`{"version":1,`), 0o600))
+
+		dc, err = NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, dc.NumEntries())
+		assert.True(t, dc.HasTag(imageName, imageTag))
+		assert.False(t, dc.HasTag(imageName, "v1.0.1"))
+		assert.False(t, dc.HasTag(imageName, "v1.0.2"))
+		assert.NoFileExists(t, oldVersion)
+		assert.NoFileExists(t, corrupt)
+	})
+
+	t.Run("Cache clear", func(t *testing.T) {
+		path := t.TempDir()
+		dc, err := NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		dc.SetTag(imageName, tag.NewImageTag(imageTag, time.Unix(0, 0), ""))
+		assert.Equal(t, 1, dc.NumEntries())
+		dc.ClearCache()
+		assert.Equal(t, 0, dc.NumEntries())
+		cachedTag, err := dc.GetTag(imageName, imageTag)
+		require.NoError(t, err)
+		require.Nil(t, cachedTag)
+
+		dc, err = NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, dc.NumEntries())
+	})
+
+	t.Run("Cache directory is created", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "registry", "cache")
+		_, err := NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		assert.DirExists(t, path)
+	})
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 
@@ -9,14 +10,24 @@ import (
 )
 
 type MemCache struct {
-	cache *memcache.Cache
+	cache   *memcache.Cache
+	lock    sync.Mutex
+	tracker *entryTracker
 }
 
-// NewMemCache returns a new instance of MemCache
+// NewMemCache returns a new instance of MemCache, which keeps all entries
+// forever
 func NewMemCache() ImageTagCache {
+	return NewBoundedMemCache(Options{})
+}
+
+// NewBoundedMemCache returns a new instance of MemCache, whose entries are
+// bounded by opts
+func NewBoundedMemCache(opts Options) ImageTagCache {
 	mc := MemCache{}
 	c := memcache.New(0, 0)
 	mc.cache = c
+	mc.tracker = newEntryTracker(opts)
 	return &mc
 }
 
@@ -32,14 +43,25 @@ func (mc *MemCache) HasTag(imageName string, tagName string) bool {
 
 // SetTag sets a tag entry into the cache
 func (mc *MemCache) SetTag(imageName string, imgTag *tag.ImageTag) {
-	mc.cache.Set(tagCacheKey(imageName, imgTag.TagName), *imgTag, -1)
+	key := tagCacheKey(imageName, imgTag.TagName)
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	mc.cache.Set(key, *imgTag, -1)
+	tracker := mc.entryTracker()
+	for _, k := range tracker.add(key, tracker.now()) {
+		mc.cache.Delete(k)
+	}
 }
 
 // GetTag gets a tag entry from the cache
 func (mc *MemCache) GetTag(imageName string, tagName string) (*tag.ImageTag, error) {
+	key := tagCacheKey(imageName, tagName)
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
 	var imgTag tag.ImageTag
-	e, ok := mc.cache.Get(tagCacheKey(imageName, tagName))
-	if !ok {
+	e, ok := mc.cache.Get(key)
+	if !mc.entryTracker().lookup(key, ok) {
+		mc.cache.Delete(key)
 		return nil, nil
 	}
 	imgTag, ok = e.(tag.ImageTag)
@@ -49,15 +71,37 @@ func (mc *MemCache) GetTag(imageName string, tagName string) (*tag.ImageTag, err
 	return &imgTag, nil
 }
 
+// DeleteTag evicts the entry for a tag of an image, if there is one
+func (mc *MemCache) DeleteTag(imageName string, tagName string) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	key := tagCacheKey(imageName, tagName)
+	if mc.entryTracker().invalidate(key) {
+		mc.cache.Delete(key)
+	}
+}
+
+// DeleteImage evicts the entries for all tags of an image
+func (mc *MemCache) DeleteImage(imageName string) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	for _, k := range mc.entryTracker().invalidatePrefix(tagCacheKey(imageName, "")) {
+		mc.cache.Delete(k)
+	}
+}
+
 func (mc *MemCache) SetImage(imageName, application string) {
 	mc.cache.Set(imageCacheKey(imageName), application, -1)
 }
 
 // ClearCache clears the cache
 func (mc *MemCache) ClearCache() {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
 	for k := range mc.cache.Items() {
 		mc.cache.Delete(k)
 	}
+	mc.entryTracker().clear()
 }
 
 // NumEntries returns the number of entries in the cache
@@ -65,6 +109,22 @@ func (mc *MemCache) NumEntries() int {
 	return mc.cache.ItemCount()
 }
 
+// Stats returns the counters of the events of the cache
+func (mc *MemCache) Stats() Stats {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	return mc.entryTracker().stats()
+}
+
+// entryTracker returns the tracker of the entries of the cache, creating an
+// unbounded one if the cache was not created by one of its constructors
+func (mc *MemCache) entryTracker() *entryTracker {
+	if mc.tracker == nil {
+		mc.tracker = newEntryTracker(Options{})
+	}
+	return mc.tracker
+}
+
 func tagCacheKey(imageName, imageTag string) string {
 	return fmt.Sprintf("tags:%s:%s", imageName, imageTag)
 }
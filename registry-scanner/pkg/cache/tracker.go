@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// trackedEntry is an entry of a cache tracked by an entryTracker
+type trackedEntry struct {
+	key      string
+	cachedAt time.Time
+}
+
+// entryTracker tracks the age and the recency of use of the entries of a
+// cache, to expire entries older than the TTL and to evict the least recently
+// used entries above the maximum number of entries. It also counts the events
+// of the cache. An entryTracker is not safe for concurrent use, except for
+// reading its stats.
+type entryTracker struct {
+	opts      Options
+	now       func() time.Time
+	lru       *list.List
+	entries   map[string]*list.Element
+	lastSweep time.Time
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	expired     atomic.Uint64
+	evicted     atomic.Uint64
+	invalidated atomic.Uint64
+}
+
+// newEntryTracker returns a new entryTracker bounding a cache by opts
+func newEntryTracker(opts Options) *entryTracker {
+	t := &entryTracker{
+		opts:    opts,
+		now:     time.Now,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	t.lastSweep = t.now()
+	return t
+}
+
+// lookup records a lookup of key, which was found in the cache if found is
+// true, and returns whether the entry may be used. It returns false for an
+// expired entry, which is no longer tracked and must be removed from the cache
+// by the caller.
+func (t *entryTracker) lookup(key string, found bool) bool {
+	e, ok := t.entries[key]
+	if !found || !ok {
+		t.misses.Add(1)
+		return false
+	}
+	if t.isExpired(e.Value.(*trackedEntry)) {
+		t.remove(key)
+		t.expired.Add(1)
+		t.misses.Add(1)
+		return false
+	}
+	t.lru.MoveToFront(e)
+	t.hits.Add(1)
+	return true
+}
+
+// add tracks the entry key, cached at cachedAt, as the most recently used
+// entry. It returns the keys of the entries that expired or were evicted to
+// stay within the maximum number of entries, which must be removed from the
+// cache by the caller.
+func (t *entryTracker) add(key string, cachedAt time.Time) []string {
+	if e, ok := t.entries[key]; ok {
+		e.Value.(*trackedEntry).cachedAt = cachedAt
+		t.lru.MoveToFront(e)
+	} else {
+		t.entries[key] = t.lru.PushFront(&trackedEntry{key: key, cachedAt: cachedAt})
+	}
+
+	var removed []string
+	if t.opts.TTL > 0 && t.now().Sub(t.lastSweep) >= t.opts.TTL {
+		removed = t.sweep()
+	}
+	for t.opts.MaxEntries > 0 && t.lru.Len() > t.opts.MaxEntries {
+		oldest := t.lru.Back().Value.(*trackedEntry)
+		t.remove(oldest.key)
+		t.evicted.Add(1)
+		removed = append(removed, oldest.key)
+	}
+	return removed
+}
+
+// sweep stops tracking all expired entries, and returns their keys
+func (t *entryTracker) sweep() []string {
+	var removed []string
+	for e := t.lru.Front(); e != nil; {
+		next := e.Next()
+		if entry := e.Value.(*trackedEntry); t.isExpired(entry) {
+			t.remove(entry.key)
+			t.expired.Add(1)
+			removed = append(removed, entry.key)
+		}
+		e = next
+	}
+	t.lastSweep = t.now()
+	return removed
+}
+
+// invalidate stops tracking key, and returns whether it was tracked
+func (t *entryTracker) invalidate(key string) bool {
+	if _, ok := t.entries[key]; !ok {
+		return false
+	}
+	t.remove(key)
+	t.invalidated.Add(1)
+	return true
+}
+
+// invalidatePrefix stops tracking the entries whose key starts with prefix,
+// and returns their keys
+func (t *entryTracker) invalidatePrefix(prefix string) []string {
+	var removed []string
+	for key := range t.entries {
+		if strings.HasPrefix(key, prefix) {
+			t.remove(key)
+			t.invalidated.Add(1)
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
+
+// remove stops tracking key
+func (t *entryTracker) remove(key string) {
+	if e, ok := t.entries[key]; ok {
+		t.lru.Remove(e)
+		delete(t.entries, key)
+	}
+}
+
+// clear stops tracking all entries
+func (t *entryTracker) clear() {
+	t.lru.Init()
+	t.entries = make(map[string]*list.Element)
+}
+
+// isExpired returns true if entry is older than the TTL
+func (t *entryTracker) isExpired(entry *trackedEntry) bool {
+	return t.opts.TTL > 0 && t.now().Sub(entry.cachedAt) >= t.opts.TTL
+}
+
+// stats returns the counters of the events of the cache
+func (t *entryTracker) stats() Stats {
+	return Stats{
+		Hits:        t.hits.Load(),
+		Misses:      t.misses.Load(),
+		Expired:     t.expired.Load(),
+		Evicted:     t.evicted.Load(),
+		Invalidated: t.invalidated.Load(),
+	}
+}
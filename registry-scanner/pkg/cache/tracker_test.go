@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+// fakeClock is a clock for entryTrackers that only advances when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestMemCache returns a bounded MemCache using clock
+func newTestMemCache(opts Options, clock *fakeClock) ImageTagCache {
+	mc := NewBoundedMemCache(opts)
+	mc.(*MemCache).tracker.now = clock.Now
+	mc.(*MemCache).tracker.lastSweep = clock.Now()
+	return mc
+}
+
+func Test_BoundedMemCache(t *testing.T) {
+	imageName := "foo/bar"
+
+	t.Run("Entries expire after TTL", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		mc := newTestMemCache(Options{TTL: time.Minute}, clock)
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		clock.Advance(30 * time.Second)
+		assert.True(t, mc.HasTag(imageName, "v1.0.0"))
+		clock.Advance(30 * time.Second)
+		assert.False(t, mc.HasTag(imageName, "v1.0.0"))
+		assert.Equal(t, 0, mc.NumEntries())
+		assert.Equal(t, Stats{Hits: 1, Misses: 1, Expired: 1}, mc.Stats())
+	})
+
+	t.Run("Setting a tag renews its TTL", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		mc := newTestMemCache(Options{TTL: time.Minute}, clock)
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		clock.Advance(45 * time.Second)
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		clock.Advance(45 * time.Second)
+		assert.True(t, mc.HasTag(imageName, "v1.0.0"))
+	})
+
+	t.Run("Expired entries are swept", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		mc := newTestMemCache(Options{TTL: time.Minute}, clock)
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.1", time.Unix(0, 0), ""))
+		clock.Advance(time.Minute)
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.2", time.Unix(0, 0), ""))
+		assert.Equal(t, 1, mc.NumEntries())
+		assert.Equal(t, uint64(2), mc.Stats().Expired)
+	})
+
+	t.Run("Least recently used entries are evicted", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		mc := newTestMemCache(Options{MaxEntries: 2}, clock)
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.1", time.Unix(0, 0), ""))
+		assert.True(t, mc.HasTag(imageName, "v1.0.0"))
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.2", time.Unix(0, 0), ""))
+		assert.Equal(t, 2, mc.NumEntries())
+		assert.True(t, mc.HasTag(imageName, "v1.0.0"))
+		assert.False(t, mc.HasTag(imageName, "v1.0.1"))
+		assert.True(t, mc.HasTag(imageName, "v1.0.2"))
+		assert.Equal(t, uint64(1), mc.Stats().Evicted)
+	})
+
+	t.Run("Invalidating a tag", func(t *testing.T) {
+		mc := NewBoundedMemCache(Options{})
+		mc.SetTag(imageName, tag.NewImageTag("v1.0", time.Unix(0, 0), ""))
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.1", time.Unix(0, 0), ""))
+		mc.DeleteTag(imageName, "v1.0")
+		mc.DeleteTag(imageName, "v2.0")
+		assert.False(t, mc.HasTag(imageName, "v1.0"))
+		assert.True(t, mc.HasTag(imageName, "v1.0.1"))
+		assert.Equal(t, uint64(1), mc.Stats().Invalidated)
+	})
+
+	t.Run("Invalidating an image", func(t *testing.T) {
+		mc := NewBoundedMemCache(Options{})
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.1", time.Unix(0, 0), ""))
+		mc.SetTag(imageName+"/baz", tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		mc.DeleteImage(imageName)
+		assert.Equal(t, 1, mc.NumEntries())
+		assert.True(t, mc.HasTag(imageName+"/baz", "v1.0.0"))
+		assert.Equal(t, uint64(2), mc.Stats().Invalidated)
+	})
+
+	t.Run("Unbounded cache built without constructor", func(t *testing.T) {
+		mc := &MemCache{cache: NewMemCache().(*MemCache).cache}
+		mc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		assert.True(t, mc.HasTag(imageName, "v1.0.0"))
+		assert.Equal(t, Stats{Hits: 1}, mc.Stats())
+	})
+}
+
+func Test_BoundedDiskCache(t *testing.T) {
+	imageName := "foo/bar"
+
+	t.Run("Expired entries are dropped on load", func(t *testing.T) {
+		path := t.TempDir()
+		dc, err := NewDiskCache(path, Options{TTL: time.Hour})
+		require.NoError(t, err)
+		dc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		dc.(*DiskCache).tracker.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+		dc.SetTag(imageName, tag.NewImageTag("v1.0.1", time.Unix(0, 0), ""))
+
+		dc, err = NewDiskCache(path, Options{TTL: time.Hour})
+		require.NoError(t, err)
+		assert.Equal(t, 1, dc.NumEntries())
+		assert.True(t, dc.HasTag(imageName, "v1.0.0"))
+		assert.False(t, dc.HasTag(imageName, "v1.0.1"))
+	})
+
+	t.Run("Least recently cached entries are dropped on load", func(t *testing.T) {
+		path := t.TempDir()
+		dc, err := NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		dc.(*DiskCache).tracker.now = clock.Now
+		for _, tagName := range []string{"v1.0.0", "v1.0.1", "v1.0.2"} {
+			dc.SetTag(imageName, tag.NewImageTag(tagName, time.Unix(0, 0), ""))
+			clock.Advance(time.Second)
+		}
+
+		dc, err = NewDiskCache(path, Options{MaxEntries: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 2, dc.NumEntries())
+		assert.False(t, dc.HasTag(imageName, "v1.0.0"))
+		assert.True(t, dc.HasTag(imageName, "v1.0.1"))
+		assert.True(t, dc.HasTag(imageName, "v1.0.2"))
+	})
+
+	t.Run("Invalidated entries are removed from disk", func(t *testing.T) {
+		path := t.TempDir()
+		dc, err := NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		dc.SetTag(imageName, tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		dc.SetTag(imageName, tag.NewImageTag("v1.0.1", time.Unix(0, 0), ""))
+		dc.SetTag("foo/baz", tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		dc.DeleteTag("foo/baz", "v1.0.0")
+		dc.DeleteImage(imageName)
+		assert.Equal(t, 0, dc.NumEntries())
+		assert.Equal(t, uint64(3), dc.Stats().Invalidated)
+
+		dc, err = NewDiskCache(path, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, dc.NumEntries())
+	})
+}
@@ -0,0 +1,307 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	godigest "github.com/opencontainers/go-digest"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+// inTotoPayloadType is the DSSE payload type of in-toto attestations
+const inTotoPayloadType = "application/vnd.in-toto+json"
+
+// Predicate types of the supported SLSA provenance versions
+const (
+	slsaProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	slsaProvenanceV1  = "https://slsa.dev/provenance/v1"
+)
+
+// ProvenancePolicy defines the expectations a SLSA provenance attestation of
+// an image must satisfy. Use NewProvenancePolicy to initialize a new object.
+type ProvenancePolicy struct {
+	builderIDs         []func(string) bool
+	sourceRepositories []func(string) bool
+	buildTypes         []func(string) bool
+}
+
+// NewProvenancePolicy creates a SLSA provenance policy. An attestation must
+// have been made by one of builderIDs, which must not be empty. If given, its
+// source repository must be one of sourceRepositories, and its build type one
+// of buildTypes. Values prefixed with "regexp:" are regular expressions that
+// must match the whole value, all others must match exactly.
+//
+// Source repositories are matched without the "git+" scheme prefix, the
+// "@<ref>" suffix and the ".git" extension of the source URI, e.g. as
+// "https://github.com/org/repo".
+func NewProvenancePolicy(builderIDs, sourceRepositories, buildTypes []string) (*ProvenancePolicy, error) {
+	if len(builderIDs) == 0 {
+		return nil, fmt.Errorf("at least one builder ID is required")
+	}
+
+	pp := &ProvenancePolicy{}
+	var err error
+	if pp.builderIDs, err = newValueMatchers("builder ID", builderIDs); err != nil {
+		return nil, err
+	}
+	if pp.sourceRepositories, err = newValueMatchers("source repository", sourceRepositories); err != nil {
+		return nil, err
+	}
+	if pp.buildTypes, err = newValueMatchers("build type", buildTypes); err != nil {
+		return nil, err
+	}
+	return pp, nil
+}
+
+// newValueMatchers returns a matcher for each of values, which are either
+// exact values or, if prefixed with "regexp:", regular expressions.
+func newValueMatchers(name string, values []string) ([]func(string) bool, error) {
+	matchers := make([]func(string) bool, 0, len(values))
+	for _, value := range values {
+		expr, ok := strings.CutPrefix(value, "regexp:")
+		if !ok {
+			matchers = append(matchers, func(s string) bool { return s == value })
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s regexp %q: %w", name, expr, err)
+		}
+		matchers = append(matchers, re.MatchString)
+	}
+	return matchers, nil
+}
+
+// provenanceStatement is an in-toto statement carrying a SLSA provenance
+// predicate
+type provenanceStatement struct {
+	inTotoStatement
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// slsaProvenanceV02Predicate holds the fields of a SLSA v0.2 provenance
+// predicate that can be checked by a ProvenancePolicy
+type slsaProvenanceV02Predicate struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType  string `json:"buildType"`
+	Invocation struct {
+		ConfigSource struct {
+			URI string `json:"uri"`
+		} `json:"configSource"`
+	} `json:"invocation"`
+}
+
+// slsaProvenanceV1Predicate holds the fields of a SLSA v1 provenance
+// predicate that can be checked by a ProvenancePolicy
+type slsaProvenanceV1Predicate struct {
+	BuildDefinition struct {
+		BuildType            string `json:"buildType"`
+		ResolvedDependencies []struct {
+			URI string `json:"uri"`
+		} `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+	} `json:"runDetails"`
+}
+
+// provenance is the version independent content of a SLSA provenance
+// predicate. SLSA v1 has no dedicated source field, so the URIs of all
+// resolved dependencies are considered as sources.
+type provenance struct {
+	builderID string
+	buildType string
+	sources   []string
+}
+
+// VerifyProvenance verifies that img carries a SLSA provenance attestation
+// satisfying verifyConfig.Provenance. The attestation must be stored in a
+// sigstore bundle, and be signed with the cosign public key, one of the
+// public keys of the signers, or a keyless certificate of the identity
+// configured in verifyConfig.
+//
+// As with VerifyWithPublicKey, cached signatures on img.ImageTag are used if
+// present, and verification succeeds as soon as any one attestation
+// satisfies the policy.
+//
+// regClient must already have NewRepository called for the image's repository.
+func VerifyProvenance(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) error {
+	logCtx := log.LoggerFromContext(ctx)
+	imageRef := img.GetFullNameWithTag()
+
+	if verifyConfig.Provenance == nil {
+		return fmt.Errorf("no provenance policy configured for image %s", imageRef)
+	}
+
+	if err := loadTagSignatures(ctx, img, regClient); err != nil {
+		return err
+	}
+	imgDigest, err := resolveManifestDigest(ctx, img.ImageTag, regClient)
+	if err != nil {
+		return err
+	}
+
+	candidates := 0
+	var lastErr error
+	for _, sig := range img.ImageTag.TagSignatures {
+		if sig.PayloadType != inTotoPayloadType {
+			continue
+		}
+		var stmt provenanceStatement
+		if err := json.Unmarshal(sig.Payload, &stmt); err != nil {
+			logCtx.Debugf("Skipping attestation of %s with invalid in-toto statement: %v", imageRef, err)
+			continue
+		}
+		if stmt.PredicateType != slsaProvenanceV02 && stmt.PredicateType != slsaProvenanceV1 {
+			continue
+		}
+		candidates += 1
+
+		err := verifyProvenanceAttestation(imageRef, sig, &stmt, imgDigest, verifyConfig)
+		if err == nil {
+			logCtx.Infof("SLSA provenance attestation verified successfully for %s", imageRef)
+			return nil
+		}
+		logCtx.Debugf("SLSA provenance attestation candidate for %s did not verify: %v", imageRef, err)
+		lastErr = err
+	}
+
+	if candidates == 0 {
+		return fmt.Errorf("no SLSA provenance attestation found for image %s", imageRef)
+	}
+	return fmt.Errorf("provenance verification failed for image %s: no matching attestation found among %d candidate(s): %w",
+		imageRef, candidates, lastErr)
+}
+
+// verifyProvenanceAttestation verifies a single provenance attestation
+// candidate: its signature, its subject, and its predicate.
+func verifyProvenanceAttestation(imageRef string, sig *tag.TagSignature, stmt *provenanceStatement, imgDigest godigest.Digest, verifyConfig *Verify) error {
+	if err := verifyAttestationSignature(imageRef, sig, verifyConfig); err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(stmt.Type, "https://in-toto.io/Statement") {
+		return fmt.Errorf("attestation payload is not an in-toto statement")
+	}
+	bound := false
+	algo := imgDigest.Algorithm().String()
+	for _, subj := range stmt.Subject {
+		if subj.Digest[algo] == imgDigest.Encoded() {
+			bound = true
+			break
+		}
+	}
+	if !bound {
+		return fmt.Errorf("attestation has no subject matching image manifest digest %s", imgDigest)
+	}
+
+	prov, err := parseProvenance(stmt)
+	if err != nil {
+		return err
+	}
+	return verifyConfig.Provenance.check(prov)
+}
+
+// verifyAttestationSignature verifies the signature of an attestation with
+// the cosign verification method configured in verifyConfig.
+func verifyAttestationSignature(imageRef string, sig *tag.TagSignature, verifyConfig *Verify) error {
+	switch {
+	case verifyConfig.CosignKey != "":
+		return verifySignature(imageRef, sig, verifyConfig.CosignKey)
+	case len(verifyConfig.CosignKeys) > 0:
+		for _, key := range verifyConfig.CosignKeys {
+			if verifySignature(imageRef, sig, key.Key) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("attestation is not signed by any of the configured signers")
+	case verifyConfig.Keyless != nil:
+		return verifyKeylessSignature(imageRef, sig, verifyConfig.Keyless)
+	default:
+		return fmt.Errorf("attestations can only be verified with a cosign public key or keyless policy")
+	}
+}
+
+// parseProvenance extracts the version independent content of the SLSA
+// provenance predicate of stmt.
+func parseProvenance(stmt *provenanceStatement) (*provenance, error) {
+	if stmt.PredicateType == slsaProvenanceV02 {
+		var pred slsaProvenanceV02Predicate
+		if err := json.Unmarshal(stmt.Predicate, &pred); err != nil {
+			return nil, fmt.Errorf("failed to parse SLSA v0.2 provenance: %w", err)
+		}
+		prov := &provenance{builderID: pred.Builder.ID, buildType: pred.BuildType}
+		if uri := pred.Invocation.ConfigSource.URI; uri != "" {
+			prov.sources = []string{uri}
+		}
+		return prov, nil
+	}
+
+	var pred slsaProvenanceV1Predicate
+	if err := json.Unmarshal(stmt.Predicate, &pred); err != nil {
+		return nil, fmt.Errorf("failed to parse SLSA v1 provenance: %w", err)
+	}
+	prov := &provenance{builderID: pred.RunDetails.Builder.ID, buildType: pred.BuildDefinition.BuildType}
+	for _, dep := range pred.BuildDefinition.ResolvedDependencies {
+		if dep.URI != "" {
+			prov.sources = append(prov.sources, dep.URI)
+		}
+	}
+	return prov, nil
+}
+
+// check verifies that prov satisfies the policy
+func (pp *ProvenancePolicy) check(prov *provenance) error {
+	if !matchesAny(pp.builderIDs, prov.builderID) {
+		return fmt.Errorf("builder ID %q is not allowed", prov.builderID)
+	}
+	if len(pp.buildTypes) > 0 && !matchesAny(pp.buildTypes, prov.buildType) {
+		return fmt.Errorf("build type %q is not allowed", prov.buildType)
+	}
+	if len(pp.sourceRepositories) > 0 {
+		allowed := slices.ContainsFunc(prov.sources, func(uri string) bool {
+			return matchesAny(pp.sourceRepositories, sourceRepository(uri))
+		})
+		if !allowed {
+			return fmt.Errorf("source repositories %v are not allowed", prov.sources)
+		}
+	}
+	return nil
+}
+
+// matchesAny returns true if any of matchers matches value
+func matchesAny(matchers []func(string) bool, value string) bool {
+	return slices.ContainsFunc(matchers, func(match func(string) bool) bool {
+		return match(value)
+	})
+}
+
+// sourceRepository returns the repository of a source URI such as
+// "git+https://github.com/org/repo.git@refs/heads/main", i.e. the URI
+// without the "git+" prefix, the "@<ref>" suffix and the ".git" extension.
+func sourceRepository(uri string) string {
+	uri = strings.TrimPrefix(uri, "git+")
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		scheme, rest = "", uri
+	}
+	if host, path, ok := strings.Cut(rest, "/"); ok {
+		path, _, _ = strings.Cut(path, "@")
+		rest = host + "/" + strings.TrimSuffix(path, ".git")
+	}
+	if scheme == "" {
+		return rest
+	}
+	return scheme + "://" + rest
+}
@@ -0,0 +1,231 @@
+package image
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBuilderID  = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0"
+	testBuildType  = "https://github.com/slsa-framework/slsa-github-generator/container@v1"
+	testSourceRepo = "https://github.com/org/app"
+)
+
+// slsaV02Statement returns a SLSA v0.2 provenance statement for imgDigest
+func slsaV02Statement(imgDigest, builderID, source string) []byte {
+	return fmt.Appendf(nil, `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2",`+
+		`"subject":[{"name":"quay.io/org/app","digest":{"sha256":"%s"}}],`+
+		`"predicate":{"builder":{"id":"%s"},"buildType":"%s","invocation":{"configSource":{"uri":"%s","digest":{"sha1":"abc"}}}}}`,
+		godigest.Digest(imgDigest).Encoded(), builderID, testBuildType, source)
+}
+
+// slsaV1Statement returns a SLSA v1 provenance statement for imgDigest
+func slsaV1Statement(imgDigest, builderID, source string) []byte {
+	return fmt.Appendf(nil, `{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://slsa.dev/provenance/v1",`+
+		`"subject":[{"name":"quay.io/org/app","digest":{"sha256":"%s"}}],`+
+		`"predicate":{"buildDefinition":{"buildType":"%s","resolvedDependencies":[{"uri":"%s"}]},"runDetails":{"builder":{"id":"%s"}}}}`,
+		godigest.Digest(imgDigest).Encoded(), testBuildType, source, builderID)
+}
+
+// makeAttestationBundle creates a sigstore bundle holding the in-toto
+// statement signed with priv
+func makeAttestationBundle(t *testing.T, kp testKeyPair, statement []byte) []byte {
+	t.Helper()
+	_, sig := signPAE(t, kp.priv, inTotoPayloadType, statement)
+	blob, err := json.Marshal(sigstoreBundle{
+		DSSEEnvelope: &dsseEnvelope{
+			Payload:     base64.StdEncoding.EncodeToString(statement),
+			PayloadType: inTotoPayloadType,
+			Signatures:  []dsseSignature{{Sig: sig}},
+		},
+	})
+	require.NoError(t, err)
+	return blob
+}
+
+// bundleFetcher returns a fetcher serving each of blobs as a bundle
+// referrer of imgDigest
+func bundleFetcher(imgDigest string, blobs ...[]byte) *mockFetcher {
+	fetcher := &mockFetcher{
+		referrers: map[string][]distribution.Descriptor{},
+		manifests: map[string]distribution.Manifest{},
+		blobs:     map[string][]byte{},
+	}
+	for _, blob := range blobs {
+		dgst := godigest.FromBytes(blob)
+		artifact := godigest.FromString("artifact-" + dgst.String())
+		fetcher.referrers[imgDigest] = append(fetcher.referrers[imgDigest], bundleReferrer(artifact.String()))
+		fetcher.manifests[artifact.String()] = &ocischema.DeserializedManifest{
+			Manifest: ocischema.Manifest{
+				Layers: []distribution.Descriptor{{MediaType: sigstoreBundleType, Digest: dgst, Size: int64(len(blob))}},
+			},
+		}
+		fetcher.blobs[dgst.String()] = blob
+	}
+	return fetcher
+}
+
+func Test_NewProvenancePolicy(t *testing.T) {
+	t.Run("Builder ID is required", func(t *testing.T) {
+		_, err := NewProvenancePolicy(nil, []string{testSourceRepo}, nil)
+		assert.ErrorContains(t, err, "at least one builder ID is required")
+	})
+
+	t.Run("Invalid regexp", func(t *testing.T) {
+		_, err := NewProvenancePolicy([]string{testBuilderID}, []string{"regexp:https://github.com/org/(app"}, nil)
+		assert.ErrorContains(t, err, "invalid source repository regexp")
+	})
+
+	t.Run("Exact values and regexps", func(t *testing.T) {
+		pp, err := NewProvenancePolicy(
+			[]string{"regexp:https://github.com/slsa-framework/slsa-github-generator/.*@refs/tags/v2\\..*"},
+			[]string{"https://github.com/org/other", testSourceRepo},
+			[]string{testBuildType})
+		require.NoError(t, err)
+		assert.NoError(t, pp.check(&provenance{builderID: testBuilderID, buildType: testBuildType, sources: []string{testSourceRepo}}))
+	})
+}
+
+func Test_ProvenancePolicy_check(t *testing.T) {
+	pp, err := NewProvenancePolicy([]string{testBuilderID}, []string{testSourceRepo}, []string{testBuildType})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		prov    provenance
+		wantErr string
+	}{
+		{
+			name: "matching provenance",
+			prov: provenance{builderID: testBuilderID, buildType: testBuildType, sources: []string{"git+https://github.com/org/app@refs/heads/main"}},
+		},
+		{
+			name: "source among several dependencies",
+			prov: provenance{builderID: testBuilderID, buildType: testBuildType, sources: []string{"pkg:docker/alpine@3.20", "git+https://github.com/org/app.git@refs/tags/v1.0.0"}},
+		},
+		{
+			name:    "unknown builder",
+			prov:    provenance{builderID: "https://ci.example.com/builder", buildType: testBuildType, sources: []string{testSourceRepo}},
+			wantErr: `builder ID "https://ci.example.com/builder" is not allowed`,
+		},
+		{
+			name:    "unknown build type",
+			prov:    provenance{builderID: testBuilderID, buildType: "https://example.com/make", sources: []string{testSourceRepo}},
+			wantErr: `build type "https://example.com/make" is not allowed`,
+		},
+		{
+			name:    "unknown source repository",
+			prov:    provenance{builderID: testBuilderID, buildType: testBuildType, sources: []string{"git+https://github.com/fork/app@refs/heads/main"}},
+			wantErr: "source repositories [git+https://github.com/fork/app@refs/heads/main] are not allowed",
+		},
+		{
+			name:    "no source repository",
+			prov:    provenance{builderID: testBuilderID, buildType: testBuildType},
+			wantErr: "source repositories [] are not allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pp.check(&tt.prov)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_sourceRepository(t *testing.T) {
+	tests := map[string]string{
+		"git+https://github.com/org/app@refs/heads/main": "https://github.com/org/app",
+		"git+https://github.com/org/app.git@v1.0.0":      "https://github.com/org/app",
+		"https://github.com/org/app":                     "https://github.com/org/app",
+		"git+ssh://git@github.com/org/app.git":           "ssh://git@github.com/org/app",
+		"github.com/org/app@main":                        "github.com/org/app",
+	}
+	for uri, want := range tests {
+		assert.Equal(t, want, sourceRepository(uri), uri)
+	}
+}
+
+func Test_VerifyProvenance(t *testing.T) {
+	ctx := context.Background()
+	const imgManifestDigest = "sha256:ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234"
+	kp := newTestKeyPair(t)
+	pp, err := NewProvenancePolicy([]string{testBuilderID}, []string{testSourceRepo}, nil)
+	require.NoError(t, err)
+	verifyConfig := &Verify{CosignKey: kp.pemPub, Provenance: pp}
+	_, _, signature := makeDSSEBundle(t, kp.priv, imgManifestDigest)
+
+	t.Run("SLSA v0.2 provenance verifies", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		attestation := makeAttestationBundle(t, kp, slsaV02Statement(imgManifestDigest, testBuilderID, "git+https://github.com/org/app@refs/heads/main"))
+		err := VerifyProvenance(ctx, img, verifyConfig, bundleFetcher(imgManifestDigest, signature, attestation))
+		assert.NoError(t, err)
+	})
+
+	t.Run("SLSA v1 provenance verifies", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		attestation := makeAttestationBundle(t, kp, slsaV1Statement(imgManifestDigest, testBuilderID, "git+https://github.com/org/app@refs/tags/v1.0.21"))
+		err := VerifyProvenance(ctx, img, verifyConfig, bundleFetcher(imgManifestDigest, attestation))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Signatures with several signers", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		other := newTestKeyPair(t)
+		cfg := &Verify{CosignKeys: []NamedPublicKey{{Name: "other", Key: other.pemPub}, {Name: "build", Key: kp.pemPub}}, Provenance: pp}
+		attestation := makeAttestationBundle(t, kp, slsaV1Statement(imgManifestDigest, testBuilderID, testSourceRepo))
+		err := VerifyProvenance(ctx, img, cfg, bundleFetcher(imgManifestDigest, attestation))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Signature only is rejected", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		err := VerifyProvenance(ctx, img, verifyConfig, bundleFetcher(imgManifestDigest, signature))
+		assert.ErrorContains(t, err, "no SLSA provenance attestation found for image quay.io/org/app:1.0.21")
+	})
+
+	t.Run("Attestation signed with another key is rejected", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		other := newTestKeyPair(t)
+		attestation := makeAttestationBundle(t, other, slsaV1Statement(imgManifestDigest, testBuilderID, testSourceRepo))
+		err := VerifyProvenance(ctx, img, verifyConfig, bundleFetcher(imgManifestDigest, signature, attestation))
+		assert.ErrorContains(t, err, "among 1 candidate(s)")
+		assert.ErrorContains(t, err, "signature does not match public key")
+	})
+
+	t.Run("Attestation from another builder is rejected", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		attestation := makeAttestationBundle(t, kp, slsaV1Statement(imgManifestDigest, "https://ci.example.com/builder", testSourceRepo))
+		err := VerifyProvenance(ctx, img, verifyConfig, bundleFetcher(imgManifestDigest, attestation))
+		assert.ErrorContains(t, err, `builder ID "https://ci.example.com/builder" is not allowed`)
+	})
+
+	t.Run("Attestation for another image is rejected", func(t *testing.T) {
+		statement := slsaV1Statement(imgManifestDigest, testBuilderID, testSourceRepo)
+		sigs := keylessTagSignatures(t, makeAttestationBundle(t, kp, statement), imgManifestDigest)
+		require.Len(t, sigs, 1)
+		var stmt provenanceStatement
+		require.NoError(t, json.Unmarshal(statement, &stmt))
+		other := godigest.Digest("sha256:aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234")
+		err := verifyProvenanceAttestation("quay.io/org/app:1.0.21", sigs[0], &stmt, other, verifyConfig)
+		assert.EqualError(t, err, "attestation has no subject matching image manifest digest "+other.String())
+	})
+
+	t.Run("No provenance policy", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		err := VerifyProvenance(ctx, img, &Verify{CosignKey: kp.pemPub}, &mockFetcher{})
+		assert.ErrorContains(t, err, "no provenance policy configured")
+	})
+}
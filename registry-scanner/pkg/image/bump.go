@@ -0,0 +1,69 @@
+package image
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// VersionBump defines the largest change to the semantic version of the
+// running image that is allowed for an update
+type VersionBump int
+
+const (
+	// VersionBumpAny does not restrict updates (the default)
+	VersionBumpAny VersionBump = 0
+	// VersionBumpPatch only allows updates within the running major and minor version
+	VersionBumpPatch VersionBump = 1
+	// VersionBumpMinor only allows updates within the running major version
+	VersionBumpMinor VersionBump = 2
+	// VersionBumpMajor allows updates to any higher version
+	VersionBumpMajor VersionBump = 3
+)
+
+// String returns the string representation of the version bump
+func (vb VersionBump) String() string {
+	switch vb {
+	case VersionBumpAny:
+		return ""
+	case VersionBumpPatch:
+		return "patch"
+	case VersionBumpMinor:
+		return "minor"
+	case VersionBumpMajor:
+		return "major"
+	}
+
+	return "unknown"
+}
+
+// ParseVersionBump parses the name of a version bump, one of patch, minor or
+// major. An empty string yields VersionBumpAny.
+func ParseVersionBump(val string) (VersionBump, error) {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "":
+		return VersionBumpAny, nil
+	case "patch":
+		return VersionBumpPatch, nil
+	case "minor":
+		return VersionBumpMinor, nil
+	case "major":
+		return VersionBumpMajor, nil
+	default:
+		return VersionBumpAny, fmt.Errorf("unknown version bump %s, must be one of patch, minor, major", val)
+	}
+}
+
+// Allows returns true if updating from version current to version ver stays
+// within the version bump.
+func (vb VersionBump) Allows(current, ver *semver.Version) bool {
+	switch vb {
+	case VersionBumpPatch:
+		return ver.Major() == current.Major() && ver.Minor() == current.Minor()
+	case VersionBumpMinor:
+		return ver.Major() == current.Major()
+	default:
+		return true
+	}
+}
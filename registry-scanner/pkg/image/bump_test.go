@@ -0,0 +1,64 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseVersionBump(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected VersionBump
+	}{
+		{"", VersionBumpAny},
+		{"patch", VersionBumpPatch},
+		{"Minor", VersionBumpMinor},
+		{" major ", VersionBumpMajor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			vb, err := ParseVersionBump(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, vb)
+		})
+	}
+
+	t.Run("Invalid version bump", func(t *testing.T) {
+		_, err := ParseVersionBump("build")
+		assert.ErrorContains(t, err, "unknown version bump")
+	})
+}
+
+func Test_VersionBump_String(t *testing.T) {
+	assert.Equal(t, "", VersionBumpAny.String())
+	assert.Equal(t, "patch", VersionBumpPatch.String())
+	assert.Equal(t, "minor", VersionBumpMinor.String())
+	assert.Equal(t, "major", VersionBumpMajor.String())
+	assert.Equal(t, "unknown", VersionBump(-1).String())
+}
+
+func Test_VersionBump_Allows(t *testing.T) {
+	current := semver.MustParse("1.2.3")
+	tests := []struct {
+		bump     VersionBump
+		version  string
+		expected bool
+	}{
+		{VersionBumpPatch, "1.2.4", true},
+		{VersionBumpPatch, "1.3.0", false},
+		{VersionBumpPatch, "2.0.0", false},
+		{VersionBumpMinor, "1.2.4", true},
+		{VersionBumpMinor, "1.9.0", true},
+		{VersionBumpMinor, "2.0.0", false},
+		{VersionBumpMajor, "2.0.0", true},
+		{VersionBumpAny, "3.0.0", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.bump.String()+"/"+tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.bump.Allows(current, semver.MustParse(tt.version)))
+		})
+	}
+}
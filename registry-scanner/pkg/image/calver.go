@@ -0,0 +1,136 @@
+package image
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+// CalVerConstraint holds the configuration for the calver update strategy
+type CalVerConstraint struct {
+	// Layout is the calendar versioning layout tags are parsed with
+	Layout *tag.CalVerLayout
+	// SameYear restricts updates to versions of the same year as the running version
+	SameYear bool
+	// SameMonth restricts updates to versions of the same year and month as the running version
+	SameMonth bool
+	// MaxAge restricts updates to versions whose date is not older than the given duration
+	MaxAge *CalVerAge
+
+	now func() time.Time
+}
+
+// CalVerAge is a calendar-aware age, expressed in years, months and days
+type CalVerAge struct {
+	Years  int
+	Months int
+	Days   int
+}
+
+// ParseCalVerConstraint parses the calver layout and the list of constraints
+// into a CalVerConstraint. An empty layout selects tag.DefaultCalVerLayout.
+//
+// Valid constraints are:
+//   - same-year: only consider versions of the year of the running version
+//   - same-month: only consider versions of the month of the running version
+//   - max-age:<n><d|w|m|y>: only consider versions not older than n days,
+//     weeks, months or years
+func ParseCalVerConstraint(layout string, constraints []string) (*CalVerConstraint, error) {
+	if layout == "" {
+		layout = tag.DefaultCalVerLayout
+	}
+	cl, err := tag.ParseCalVerLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	cc := &CalVerConstraint{Layout: cl, now: time.Now}
+	for _, c := range constraints {
+		c = strings.TrimSpace(c)
+		switch {
+		case c == "":
+			continue
+		case strings.EqualFold(c, "same-year"):
+			cc.SameYear = true
+		case strings.EqualFold(c, "same-month"):
+			if !cl.HasMonth() {
+				return nil, fmt.Errorf("calver constraint %s requires a month component in layout %s", c, layout)
+			}
+			cc.SameMonth = true
+		case strings.HasPrefix(strings.ToLower(c), "max-age:"):
+			age, err := parseCalVerAge(c[len("max-age:"):])
+			if err != nil {
+				return nil, fmt.Errorf("invalid calver constraint %s: %w", c, err)
+			}
+			cc.MaxAge = age
+		default:
+			return nil, fmt.Errorf("unknown calver constraint: %s", c)
+		}
+	}
+
+	return cc, nil
+}
+
+// parseCalVerAge parses an age specification such as 30d, 2w, 6m or 1y
+func parseCalVerAge(val string) (*CalVerAge, error) {
+	val = strings.TrimSpace(val)
+	if len(val) < 2 {
+		return nil, fmt.Errorf("age must be in format <n><d|w|m|y>")
+	}
+	n, err := strconv.Atoi(val[:len(val)-1])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("age must be in format <n><d|w|m|y>")
+	}
+	switch strings.ToLower(val[len(val)-1:]) {
+	case "d":
+		return &CalVerAge{Days: n}, nil
+	case "w":
+		return &CalVerAge{Days: n * 7}, nil
+	case "m":
+		return &CalVerAge{Months: n}, nil
+	case "y":
+		return &CalVerAge{Years: n}, nil
+	default:
+		return nil, fmt.Errorf("unknown age unit in %s, must be one of d, w, m, y", val)
+	}
+}
+
+// reference returns the version the same-year and same-month constraints are
+// evaluated against. This is the version of the currently running tag if it
+// matches the layout, or the current date otherwise.
+func (cc *CalVerConstraint) reference(current *tag.ImageTag) *tag.CalVersion {
+	if current != nil {
+		if cv, err := cc.Layout.Parse(current.TagName); err == nil {
+			return cv
+		}
+	}
+	now := cc.now().UTC()
+	return &tag.CalVersion{Year: now.Year(), Month: int(now.Month())}
+}
+
+// Allows returns nil if the version cv is allowed by the constraint, given
+// the currently running tag. Otherwise, an error describing the violated
+// constraint is returned.
+func (cc *CalVerConstraint) Allows(cv *tag.CalVersion, current *tag.ImageTag) error {
+	if cc.SameYear || cc.SameMonth {
+		ref := cc.reference(current)
+		if cv.Year != ref.Year {
+			return fmt.Errorf("year %d differs from %d", cv.Year, ref.Year)
+		}
+		if cc.SameMonth && cv.Month != ref.Month {
+			return fmt.Errorf("month %d differs from %d", cv.Month, ref.Month)
+		}
+	}
+	if cc.MaxAge != nil {
+		oldest := cc.now().UTC().AddDate(-cc.MaxAge.Years, -cc.MaxAge.Months, -cc.MaxAge.Days)
+		// A version denotes a period, e.g. a whole month. It is considered
+		// recent enough if any part of that period is within the max age.
+		if !cv.End().After(oldest) {
+			return fmt.Errorf("version date %s is older than %s", cv.Date().Format(time.DateOnly), oldest.Format(time.DateOnly))
+		}
+	}
+	return nil
+}
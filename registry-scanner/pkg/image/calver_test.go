@@ -0,0 +1,89 @@
+package image
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+func Test_ParseCalVerConstraint(t *testing.T) {
+	t.Run("Default layout without constraints", func(t *testing.T) {
+		cc, err := ParseCalVerConstraint("", nil)
+		require.NoError(t, err)
+		assert.Equal(t, tag.DefaultCalVerLayout, cc.Layout.String())
+		assert.False(t, cc.SameYear)
+		assert.False(t, cc.SameMonth)
+		assert.Nil(t, cc.MaxAge)
+	})
+
+	t.Run("All constraints", func(t *testing.T) {
+		cc, err := ParseCalVerConstraint("YYYY.0M.MICRO", []string{"same-year", " Same-Month ", "max-age:2w"})
+		require.NoError(t, err)
+		assert.True(t, cc.SameYear)
+		assert.True(t, cc.SameMonth)
+		assert.Equal(t, &CalVerAge{Days: 14}, cc.MaxAge)
+	})
+
+	t.Run("Max age units", func(t *testing.T) {
+		for spec, age := range map[string]CalVerAge{
+			"max-age:30d": {Days: 30},
+			"max-age:6m":  {Months: 6},
+			"max-age:1y":  {Years: 1},
+		} {
+			cc, err := ParseCalVerConstraint("", []string{spec})
+			require.NoError(t, err)
+			assert.Equal(t, age, *cc.MaxAge)
+		}
+	})
+
+	t.Run("Invalid layout", func(t *testing.T) {
+		_, err := ParseCalVerConstraint("0M.MICRO", nil)
+		assert.ErrorContains(t, err, "year component is required")
+	})
+
+	t.Run("Invalid constraints", func(t *testing.T) {
+		_, err := ParseCalVerConstraint("", []string{"same-decade"})
+		assert.ErrorContains(t, err, "unknown calver constraint")
+		_, err = ParseCalVerConstraint("", []string{"max-age:6x"})
+		assert.ErrorContains(t, err, "unknown age unit")
+		_, err = ParseCalVerConstraint("", []string{"max-age:m"})
+		assert.ErrorContains(t, err, "format")
+		_, err = ParseCalVerConstraint("YYYY.MICRO", []string{"same-month"})
+		assert.ErrorContains(t, err, "requires a month component")
+	})
+}
+
+func Test_CalVerConstraint_Allows(t *testing.T) {
+	now := func() time.Time { return time.Date(2024, time.November, 17, 12, 0, 0, 0, time.UTC) }
+
+	t.Run("Same year relative to running tag", func(t *testing.T) {
+		cc, err := ParseCalVerConstraint("YYYY.0M.0D", []string{"same-year"})
+		require.NoError(t, err)
+		cc.now = now
+		current := tag.NewImageTag("2023.05.01", time.Unix(0, 0), "")
+		assert.NoError(t, cc.Allows(&tag.CalVersion{Year: 2023, Month: 12, Day: 1}, current))
+		assert.Error(t, cc.Allows(&tag.CalVersion{Year: 2024, Month: 1, Day: 1}, current))
+	})
+
+	t.Run("Same month relative to current date", func(t *testing.T) {
+		cc, err := ParseCalVerConstraint("YYYY.0M.0D", []string{"same-month"})
+		require.NoError(t, err)
+		cc.now = now
+		current := tag.NewImageTag("latest", time.Unix(0, 0), "")
+		assert.NoError(t, cc.Allows(&tag.CalVersion{Year: 2024, Month: 11, Day: 2}, current))
+		assert.Error(t, cc.Allows(&tag.CalVersion{Year: 2024, Month: 10, Day: 31}, current))
+		assert.Error(t, cc.Allows(&tag.CalVersion{Year: 2023, Month: 11, Day: 2}, current))
+	})
+
+	t.Run("Max age considers the whole period of a version", func(t *testing.T) {
+		cc, err := ParseCalVerConstraint("YYYY.0M", []string{"max-age:6m"})
+		require.NoError(t, err)
+		cc.now = now
+		assert.NoError(t, cc.Allows(&tag.CalVersion{Year: 2024, Month: 5}, nil))
+		assert.Error(t, cc.Allows(&tag.CalVersion{Year: 2024, Month: 4}, nil))
+	})
+}
@@ -0,0 +1,112 @@
+package image
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+// Names of the variables that are available in a CEL tag match expression
+const (
+	// CELVarTag holds the name of the tag
+	CELVarTag = "tag"
+	// CELVarLabels holds the labels of the image the tag points to
+	CELVarLabels = "labels"
+	// CELVarCreated holds the creation date of the image the tag points to
+	CELVarCreated = "created"
+	// CELVarNow holds the time of the evaluation
+	CELVarNow = "now"
+)
+
+// celEnv returns the environment that CEL tag match expressions are
+// compiled in. It is created once and shared by all expressions.
+var celEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(CELVarTag, cel.StringType),
+		cel.Variable(CELVarLabels, cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable(CELVarCreated, cel.TimestampType),
+		cel.Variable(CELVarNow, cel.TimestampType),
+	)
+})
+
+// CELMatcher matches tags against a compiled CEL expression. Use
+// NewCELMatcher to initialize a new object.
+type CELMatcher struct {
+	expr          string
+	prg           cel.Program
+	needsMetadata bool
+
+	now func() time.Time
+}
+
+// NewCELMatcher compiles the CEL expression expr into a CELMatcher. The
+// expression must evaluate to a bool. Returns an error if the expression
+// cannot be compiled.
+func NewCELMatcher(expr string) (*CELMatcher, error) {
+	env, err := celEnv()
+	if err != nil {
+		return nil, fmt.Errorf("could not create CEL environment: %w", err)
+	}
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("invalid CEL expression %q: %w", expr, iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("invalid CEL expression %q: must evaluate to bool, not %s", expr, ast.OutputType())
+	}
+	prg, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEL expression %q: %w", expr, err)
+	}
+
+	cm := &CELMatcher{expr: expr, prg: prg, now: time.Now}
+	for _, ref := range ast.NativeRep().ReferenceMap() {
+		if ref.Name == CELVarLabels || ref.Name == CELVarCreated {
+			cm.needsMetadata = true
+		}
+	}
+	return cm, nil
+}
+
+// String returns the expression of the matcher
+func (cm *CELMatcher) String() string {
+	return cm.expr
+}
+
+// NeedsMetadata returns true if the expression refers to the labels or the
+// creation date of an image, which requires its metadata to be fetched
+func (cm *CELMatcher) NeedsMetadata() bool {
+	return cm.needsMetadata
+}
+
+// Matches evaluates the expression for the tag t. Returns an error if the
+// expression could not be evaluated, e.g. because it accesses a label that
+// does not exist.
+func (cm *CELMatcher) Matches(t *tag.ImageTag) (bool, error) {
+	var created time.Time
+	if t.TagDate != nil {
+		created = *t.TagDate
+	}
+	labels := t.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	out, _, err := cm.prg.Eval(map[string]any{
+		CELVarTag:     t.TagName,
+		CELVarLabels:  labels,
+		CELVarCreated: created,
+		CELVarNow:     cm.now(),
+	})
+	if err != nil {
+		return false, err
+	}
+	match, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %v instead of bool", out.Value())
+	}
+	return match, nil
+}
@@ -0,0 +1,95 @@
+package image
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+func Test_NewCELMatcher(t *testing.T) {
+	t.Run("Expression on tag name only", func(t *testing.T) {
+		cm, err := NewCELMatcher(`tag.startsWith("v")`)
+		require.NoError(t, err)
+		assert.Equal(t, `tag.startsWith("v")`, cm.String())
+		assert.False(t, cm.NeedsMetadata())
+	})
+
+	t.Run("Expression on labels", func(t *testing.T) {
+		cm, err := NewCELMatcher(`"org.opencontainers.image.source" in labels`)
+		require.NoError(t, err)
+		assert.True(t, cm.NeedsMetadata())
+	})
+
+	t.Run("Expression on creation date", func(t *testing.T) {
+		cm, err := NewCELMatcher(`created > now - duration("720h")`)
+		require.NoError(t, err)
+		assert.True(t, cm.NeedsMetadata())
+	})
+
+	t.Run("Invalid syntax", func(t *testing.T) {
+		_, err := NewCELMatcher(`tag ==`)
+		assert.ErrorContains(t, err, "invalid CEL expression")
+	})
+
+	t.Run("Unknown variable", func(t *testing.T) {
+		_, err := NewCELMatcher(`version == "1.0"`)
+		assert.ErrorContains(t, err, "undeclared reference")
+	})
+
+	t.Run("Expression not evaluating to bool", func(t *testing.T) {
+		_, err := NewCELMatcher(`tag + "-suffix"`)
+		assert.ErrorContains(t, err, "must evaluate to bool")
+	})
+}
+
+func Test_CELMatcher_Matches(t *testing.T) {
+	now := time.Date(2025, time.June, 15, 12, 0, 0, 0, time.UTC)
+	newTag := func(name string, created time.Time, labels map[string]string) *tag.ImageTag {
+		return tag.NewImageTagWithLabels(name, created, "", labels)
+	}
+
+	t.Run("Match on label and creation date", func(t *testing.T) {
+		cm, err := NewCELMatcher(`labels["org.opencontainers.image.source"].startsWith("https://github.com/myorg/") && created > now - duration("720h")`)
+		require.NoError(t, err)
+		cm.now = func() time.Time { return now }
+
+		match, err := cm.Matches(newTag("1.0.0", now.AddDate(0, 0, -10), map[string]string{"org.opencontainers.image.source": "https://github.com/myorg/app"}))
+		require.NoError(t, err)
+		assert.True(t, match)
+
+		match, err = cm.Matches(newTag("1.0.0", now.AddDate(0, 0, -40), map[string]string{"org.opencontainers.image.source": "https://github.com/myorg/app"}))
+		require.NoError(t, err)
+		assert.False(t, match)
+
+		match, err = cm.Matches(newTag("1.0.0", now.AddDate(0, 0, -10), map[string]string{"org.opencontainers.image.source": "https://github.com/other/app"}))
+		require.NoError(t, err)
+		assert.False(t, match)
+	})
+
+	t.Run("Error on missing label", func(t *testing.T) {
+		cm, err := NewCELMatcher(`labels["team"] == "a"`)
+		require.NoError(t, err)
+		_, err = cm.Matches(newTag("1.0.0", now, nil))
+		assert.ErrorContains(t, err, "no such key")
+	})
+
+	t.Run("Match on missing label with has check", func(t *testing.T) {
+		cm, err := NewCELMatcher(`!("team" in labels) || labels["team"] == "a"`)
+		require.NoError(t, err)
+		match, err := cm.Matches(newTag("1.0.0", now, nil))
+		require.NoError(t, err)
+		assert.True(t, match)
+	})
+
+	t.Run("Match without tag date", func(t *testing.T) {
+		cm, err := NewCELMatcher(`created == timestamp("0001-01-01T00:00:00Z")`)
+		require.NoError(t, err)
+		match, err := cm.Matches(&tag.ImageTag{TagName: "1.0.0"})
+		require.NoError(t, err)
+		assert.True(t, match)
+	})
+}
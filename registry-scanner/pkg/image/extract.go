@@ -0,0 +1,142 @@
+package image
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Names of the capture groups that are recognized in a tag match expression
+const (
+	// ExtractGroupVersion holds the semantic version part of a tag
+	ExtractGroupVersion = "version"
+	// ExtractGroupBuild holds a numeric build counter of a tag
+	ExtractGroupBuild = "build"
+	// ExtractGroupVariant holds the variant of a tag, e.g. "alpine"
+	ExtractGroupVariant = "variant"
+)
+
+// VersionExtractor extracts version information from tag names using the
+// named capture groups of a regular expression. Use NewVersionExtractor to
+// initialize a new object.
+type VersionExtractor struct {
+	re      *regexp.Regexp
+	version int
+	build   int
+	variant int
+}
+
+// ExtractedVersion is the version information extracted from a tag name.
+// Version is nil if the expression has no version group, Build is zero if the
+// expression has no build group or the group did not match, and Variant is
+// empty if the expression has no variant group.
+type ExtractedVersion struct {
+	Version *semver.Version
+	Build   uint64
+	Variant string
+}
+
+// NewVersionExtractor returns a VersionExtractor for the regular expression
+// re. Returns nil if re does not contain any named capture group that can be
+// used to extract a version, i.e. a version or a build group.
+func NewVersionExtractor(re *regexp.Regexp) *VersionExtractor {
+	if re == nil {
+		return nil
+	}
+	ve := &VersionExtractor{
+		re:      re,
+		version: re.SubexpIndex(ExtractGroupVersion),
+		build:   re.SubexpIndex(ExtractGroupBuild),
+		variant: re.SubexpIndex(ExtractGroupVariant),
+	}
+	if ve.version < 0 && ve.build < 0 {
+		return nil
+	}
+	return ve
+}
+
+// Extract extracts the version information from tagName. Returns an error if
+// the tag does not match the expression, or if any of the extracted parts is
+// not valid.
+func (ve *VersionExtractor) Extract(tagName string) (*ExtractedVersion, error) {
+	m := ve.re.FindStringSubmatch(tagName)
+	if m == nil {
+		return nil, fmt.Errorf("tag %s does not match expression %s", tagName, ve.re)
+	}
+
+	ev := &ExtractedVersion{}
+	if ve.version >= 0 {
+		v, err := semver.NewVersion(m[ve.version])
+		if err != nil {
+			return nil, fmt.Errorf("extracted version %q of tag %s is not a semantic version: %w", m[ve.version], tagName, err)
+		}
+		ev.Version = v
+	}
+	// An optional build group that did not participate in the match denotes
+	// the build number zero.
+	if ve.build >= 0 && m[ve.build] != "" {
+		b, err := strconv.ParseUint(m[ve.build], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("extracted build %q of tag %s is not a number", m[ve.build], tagName)
+		}
+		ev.Build = b
+	}
+	if ve.variant >= 0 {
+		ev.Variant = m[ve.variant]
+	}
+	return ev, nil
+}
+
+// HasVersion returns true if the expression has a version group
+func (ve *VersionExtractor) HasVersion() bool {
+	return ve.version >= 0
+}
+
+// HasVariant returns true if the expression has a variant group
+func (ve *VersionExtractor) HasVariant() bool {
+	return ve.variant >= 0
+}
+
+// Compare compares ev to other by their version first and their build number
+// second. Returns -1, 0 or 1 if ev is lower than, equal to or higher than
+// other.
+func (ev *ExtractedVersion) Compare(other *ExtractedVersion) int {
+	if ev.Version != nil && other.Version != nil {
+		if c := ev.Version.Compare(other.Version); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case ev.Build < other.Build:
+		return -1
+	case ev.Build > other.Build:
+		return 1
+	}
+	return 0
+}
+
+// SortTags returns the tags from tagList that the expression could extract a
+// version from, sorted by their extracted version. Ties are broken through a
+// lexical comparison of the tag names. The extracted versions are returned
+// keyed by tag name.
+func (ve *VersionExtractor) SortTags(tagList *tag.ImageTagList) (tag.SortableImageTagList, map[string]*ExtractedVersion) {
+	sil := tag.SortableImageTagList{}
+	versions := make(map[string]*ExtractedVersion)
+	for _, t := range tagList.SortAlphabetically() {
+		ev, err := ve.Extract(t.TagName)
+		if err != nil {
+			continue
+		}
+		sil = append(sil, t)
+		versions[t.TagName] = ev
+	}
+	sort.SliceStable(sil, func(i, j int) bool {
+		return versions[sil[i].TagName].Compare(versions[sil[j].TagName]) < 0
+	})
+	return sil, versions
+}
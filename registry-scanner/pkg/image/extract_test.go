@@ -0,0 +1,70 @@
+package image
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewVersionExtractor(t *testing.T) {
+	assert.Nil(t, NewVersionExtractor(nil))
+	assert.Nil(t, NewVersionExtractor(regexp.MustCompile(`^v\d+$`)))
+	assert.Nil(t, NewVersionExtractor(regexp.MustCompile(`^(?P<variant>\w+)$`)))
+
+	ve := NewVersionExtractor(regexp.MustCompile(`^release-(?P<version>.+)-(?P<variant>\w+)$`))
+	require.NotNil(t, ve)
+	assert.True(t, ve.HasVersion())
+	assert.True(t, ve.HasVariant())
+
+	ve = NewVersionExtractor(regexp.MustCompile(`^build-(?P<build>\d+)-g[0-9a-f]+$`))
+	require.NotNil(t, ve)
+	assert.False(t, ve.HasVersion())
+	assert.False(t, ve.HasVariant())
+}
+
+func Test_VersionExtractor_Extract(t *testing.T) {
+	t.Run("Extract version and variant", func(t *testing.T) {
+		ve := NewVersionExtractor(regexp.MustCompile(`^release-(?P<version>\d+\.\d+\.\d+)-(?P<variant>\w+)$`))
+		ev, err := ve.Extract("release-1.8.2-alpine")
+		require.NoError(t, err)
+		assert.Equal(t, "1.8.2", ev.Version.Original())
+		assert.Equal(t, "alpine", ev.Variant)
+		assert.Equal(t, uint64(0), ev.Build)
+	})
+
+	t.Run("Extract build", func(t *testing.T) {
+		ve := NewVersionExtractor(regexp.MustCompile(`^build-(?P<build>\d+)-g[0-9a-f]+$`))
+		ev, err := ve.Extract("build-4821-g1a2b3c4")
+		require.NoError(t, err)
+		assert.Nil(t, ev.Version)
+		assert.Equal(t, uint64(4821), ev.Build)
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		ve := NewVersionExtractor(regexp.MustCompile(`^build-(?P<build>\d+)-g[0-9a-f]+$`))
+		_, err := ve.Extract("latest")
+		assert.ErrorContains(t, err, "does not match")
+	})
+
+	t.Run("Invalid parts", func(t *testing.T) {
+		ve := NewVersionExtractor(regexp.MustCompile(`^(?P<version>[a-z.]+)$`))
+		_, err := ve.Extract("foo.bar")
+		assert.ErrorContains(t, err, "not a semantic version")
+
+		ve = NewVersionExtractor(regexp.MustCompile(`^(?P<build>\w+)$`))
+		_, err = ve.Extract("abc")
+		assert.ErrorContains(t, err, "not a number")
+	})
+}
+
+func Test_VersionExtractor_SortTags(t *testing.T) {
+	ve := NewVersionExtractor(regexp.MustCompile(`^v?(?P<version>\d+\.\d+\.\d+)(-(?P<build>\d+))?$`))
+	require.NotNil(t, ve)
+	tagList := newImageTagList([]string{"1.10.0", "v1.2.0-10", "1.2.0-9", "latest", "1.9.1"})
+	sil, versions := ve.SortTags(tagList)
+	assert.Equal(t, []string{"1.2.0-9", "v1.2.0-10", "1.9.1", "1.10.0"}, sil.Tags())
+	assert.Len(t, versions, 4)
+	assert.Equal(t, uint64(10), versions["v1.2.0-10"].Build)
+}
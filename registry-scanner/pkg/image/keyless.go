@@ -0,0 +1,572 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/bits"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+// Object identifiers of the Fulcio certificate extensions that hold the
+// issuer of the OIDC token the certificate was requested with. The first
+// one holds the raw issuer and is deprecated in favour of the second one,
+// which holds a DER-encoded UTF8String.
+var (
+	oidFulcioIssuer   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidFulcioIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// rekorKindDSSE is the kind of the Rekor entries that record a DSSE envelope
+const rekorKindDSSE = "dsse"
+
+// CertificateIdentity defines the signer a keyless signature must have been
+// made by. Exactly one of Subject and SubjectRegexp, and exactly one of
+// Issuer and IssuerRegexp must be set.
+type CertificateIdentity struct {
+	// Subject is the exact identity of the signer, i.e. an email address or
+	// URI in the subject alternative names of the signing certificate
+	Subject string
+	// SubjectRegexp is a regular expression matching the identity of the signer
+	SubjectRegexp string
+	// Issuer is the exact issuer of the OIDC token the signer authenticated with
+	Issuer string
+	// IssuerRegexp is a regular expression matching the issuer of the OIDC token
+	IssuerRegexp string
+}
+
+// KeylessVerify is the policy for verifying cosign keyless signatures, which
+// are made with short-lived certificates issued by Fulcio and recorded in
+// the Rekor transparency log. Use NewKeylessVerify to initialize a new object.
+type KeylessVerify struct {
+	roots         *x509.CertPool
+	intermediates *x509.CertPool
+	rekorKey      *ecdsa.PublicKey
+	rekorLogID    []byte
+	subject       func(string) bool
+	issuer        func(string) bool
+}
+
+// NewKeylessVerify creates a keyless verification policy. trustedRoots holds
+// the PEM-encoded Fulcio root and intermediate certificates, of which the
+// self-signed ones are used as trust anchors. rekorKey is the PEM-encoded
+// ECDSA public key of the Rekor transparency log, which is used to verify the
+// transparency log entries offline.
+func NewKeylessVerify(trustedRoots string, rekorKey string, identity CertificateIdentity) (*KeylessVerify, error) {
+	kv := &KeylessVerify{
+		roots:         x509.NewCertPool(),
+		intermediates: x509.NewCertPool(),
+	}
+
+	numRoots := 0
+	rest := []byte(trustedRoots)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trusted certificate: %w", err)
+		}
+		if bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil {
+			kv.roots.AddCert(cert)
+			numRoots += 1
+		} else {
+			kv.intermediates.AddCert(cert)
+		}
+	}
+	if numRoots == 0 {
+		return nil, fmt.Errorf("trusted roots contain no self-signed root certificate")
+	}
+
+	block, _ := pem.Decode([]byte(rekorKey))
+	if block == nil {
+		return nil, fmt.Errorf("unable to PEM decode Rekor public key")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Rekor public key: %w", err)
+	}
+	ecKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key of the Rekor transparency log is not an ECDSA key")
+	}
+	logID := sha256.Sum256(block.Bytes)
+	kv.rekorKey = ecKey
+	kv.rekorLogID = logID[:]
+
+	kv.subject, err = newIdentityMatcher("subject", identity.Subject, identity.SubjectRegexp)
+	if err != nil {
+		return nil, err
+	}
+	kv.issuer, err = newIdentityMatcher("issuer", identity.Issuer, identity.IssuerRegexp)
+	if err != nil {
+		return nil, err
+	}
+
+	return kv, nil
+}
+
+// newIdentityMatcher returns a function matching either exactly value or the
+// regular expression expr, of which exactly one must be given. The regular
+// expression must match the whole string.
+func newIdentityMatcher(name, value, expr string) (func(string) bool, error) {
+	switch {
+	case value != "" && expr != "":
+		return nil, fmt.Errorf("certificate %s and %s regexp are mutually exclusive", name, name)
+	case value != "":
+		return func(s string) bool { return s == value }, nil
+	case expr != "":
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate %s regexp %q: %w", name, expr, err)
+		}
+		return re.MatchString, nil
+	}
+	return nil, fmt.Errorf("certificate %s or %s regexp is required", name, name)
+}
+
+// ---------------------------------------------------------------------------
+// Sigstore bundle verification material
+// ---------------------------------------------------------------------------
+
+// bundleVerificationMaterial is the verification material of a sigstore
+// bundle. Bundles v0.3 hold the signing certificate only, while older
+// bundles hold the certificate chain.
+type bundleVerificationMaterial struct {
+	Certificate          *bundleCertificate `json:"certificate,omitempty"`
+	X509CertificateChain *struct {
+		Certificates []bundleCertificate `json:"certificates"`
+	} `json:"x509CertificateChain,omitempty"`
+	TlogEntries []bundleTlogEntry `json:"tlogEntries"`
+}
+
+// bundleCertificate holds a DER-encoded certificate
+type bundleCertificate struct {
+	RawBytes []byte `json:"rawBytes"`
+}
+
+// bundleTlogEntry is an entry of the Rekor transparency log. Integers are
+// encoded as strings in the protobuf JSON wire format.
+type bundleTlogEntry struct {
+	LogIndex int64 `json:"logIndex,string"`
+	LogID    struct {
+		KeyID []byte `json:"keyId"`
+	} `json:"logId"`
+	KindVersion struct {
+		Kind    string `json:"kind"`
+		Version string `json:"version"`
+	} `json:"kindVersion"`
+	IntegratedTime   int64 `json:"integratedTime,string"`
+	InclusionPromise *struct {
+		SignedEntryTimestamp []byte `json:"signedEntryTimestamp"`
+	} `json:"inclusionPromise,omitempty"`
+	InclusionProof    *bundleInclusionProof `json:"inclusionProof,omitempty"`
+	CanonicalizedBody []byte                `json:"canonicalizedBody"`
+}
+
+// bundleInclusionProof proves the inclusion of an entry in the Merkle tree
+// of the transparency log, whose root hash is signed in the checkpoint
+type bundleInclusionProof struct {
+	LogIndex   int64    `json:"logIndex,string"`
+	RootHash   []byte   `json:"rootHash"`
+	TreeSize   int64    `json:"treeSize,string"`
+	Hashes     [][]byte `json:"hashes"`
+	Checkpoint struct {
+		Envelope string `json:"envelope"`
+	} `json:"checkpoint"`
+}
+
+// rekorDSSEBody is the minimal representation of the body of a Rekor entry
+// of kind dsse, which binds the entry to a DSSE envelope and its signers
+type rekorDSSEBody struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		PayloadHash struct {
+			Algorithm string `json:"algorithm"`
+			Value     string `json:"value"`
+		} `json:"payloadHash"`
+		Signatures []struct {
+			Signature string `json:"signature"`
+			Verifier  string `json:"verifier"`
+		} `json:"signatures"`
+	} `json:"spec"`
+}
+
+// rekorSETPayload is the payload of a signed entry timestamp. Its fields are
+// in the order of the canonical JSON encoding Rekor signs.
+type rekorSETPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// ---------------------------------------------------------------------------
+// VerifyKeyless
+// ---------------------------------------------------------------------------
+
+// VerifyKeyless verifies that img carries a cosign keyless signature made by
+// the identity configured in verifyConfig.Keyless. All checks are performed
+// offline against the material in the sigstore bundle of the signature:
+//
+//   - the signing certificate chains up to one of the trusted roots at the
+//     time the signature was recorded in the transparency log
+//   - the certificate was issued to the configured subject and issuer
+//   - the signature over the DSSE envelope was made with the certificate's key
+//   - the transparency log entry records this signature and certificate, its
+//     signed entry timestamp is valid, and its inclusion proof leads to the
+//     root hash of a checkpoint signed by the transparency log
+//
+// As with VerifyWithPublicKey, cached signatures on img.ImageTag are used if
+// present, and verification succeeds as soon as any one candidate matches.
+//
+// regClient must already have NewRepository called for the image's repository.
+func VerifyKeyless(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) error {
+	return verifyPlatforms(ctx, img, verifyConfig, regClient, func(img *ContainerImage) error {
+		return verifyKeyless(ctx, img, verifyConfig, regClient)
+	})
+}
+
+// verifyKeyless verifies the cosign keyless signature of a single manifest.
+func verifyKeyless(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) error {
+	logCtx := log.LoggerFromContext(ctx)
+	imageRef := img.GetFullNameWithTag()
+
+	if verifyConfig.Keyless == nil {
+		return fmt.Errorf("no keyless verification policy configured for image %s", imageRef)
+	}
+
+	if err := loadTagSignatures(ctx, img, regClient); err != nil {
+		return err
+	}
+
+	logCtx.Debugf("Verifying cosign keyless signature for %s (%d candidate(s))", imageRef, len(img.ImageTag.TagSignatures))
+	var lastErr error
+	for _, sig := range img.ImageTag.TagSignatures {
+		err := verifyKeylessSignature(imageRef, sig, verifyConfig.Keyless)
+		if err == nil {
+			logCtx.Infof("Cosign keyless signature verified successfully for %s", imageRef)
+			return nil
+		}
+		logCtx.Debugf("Keyless signature candidate for %s did not verify: %v", imageRef, err)
+		lastErr = err
+	}
+	return fmt.Errorf("keyless signature verification failed for image %s: no matching signature found among %d candidate(s): %w",
+		imageRef, len(img.ImageTag.TagSignatures), lastErr)
+}
+
+// verifyKeylessSignature verifies a single keyless signature candidate
+// against the policy kv. No network calls are made here.
+func verifyKeylessSignature(imageRef string, sig *tag.TagSignature, kv *KeylessVerify) error {
+	if len(sig.VerificationMaterial) == 0 {
+		return fmt.Errorf("signature for image %s has no sigstore bundle verification material", imageRef)
+	}
+	var vm bundleVerificationMaterial
+	if err := json.Unmarshal(sig.VerificationMaterial, &vm); err != nil {
+		return fmt.Errorf("failed to parse verification material for image %s: %w", imageRef, err)
+	}
+
+	var chain [][]byte
+	if vm.Certificate != nil {
+		chain = append(chain, vm.Certificate.RawBytes)
+	} else if vm.X509CertificateChain != nil {
+		for _, c := range vm.X509CertificateChain.Certificates {
+			chain = append(chain, c.RawBytes)
+		}
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf("verification material for image %s has no signing certificate", imageRef)
+	}
+	cert, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse signing certificate for image %s: %w", imageRef, err)
+	}
+
+	// The signature must be recorded in the transparency log, which also
+	// attests the time of signing that the certificate must be valid at.
+	var integratedTime time.Time
+	var tlogErr error
+	for _, entry := range vm.TlogEntries {
+		if tlogErr = verifyTlogEntry(&entry, sig, cert, kv); tlogErr == nil {
+			integratedTime = time.Unix(entry.IntegratedTime, 0)
+			break
+		}
+	}
+	if integratedTime.IsZero() {
+		if tlogErr == nil {
+			tlogErr = fmt.Errorf("no transparency log entry")
+		}
+		return fmt.Errorf("transparency log verification failed for image %s: %w", imageRef, tlogErr)
+	}
+
+	intermediates := kv.intermediates.Clone()
+	for _, raw := range chain[1:] {
+		c, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate chain for image %s: %w", imageRef, err)
+		}
+		intermediates.AddCert(c)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         kv.roots,
+		Intermediates: intermediates,
+		CurrentTime:   integratedTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return fmt.Errorf("signing certificate for image %s is not trusted: %w", imageRef, err)
+	}
+
+	if err := verifyCertificateIdentity(cert, kv); err != nil {
+		return fmt.Errorf("signing certificate for image %s: %w", imageRef, err)
+	}
+
+	ecKey, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("signing certificate for image %s does not hold an ECDSA key", imageRef)
+	}
+	return verifySignatureWithKey(imageRef, sig, ecKey)
+}
+
+// verifyCertificateIdentity checks that cert was issued to the subject and
+// issuer of the policy kv
+func verifyCertificateIdentity(cert *x509.Certificate, kv *KeylessVerify) error {
+	subjects := slices.Clone(cert.EmailAddresses)
+	for _, uri := range cert.URIs {
+		subjects = append(subjects, uri.String())
+	}
+	if !slices.ContainsFunc(subjects, kv.subject) {
+		return fmt.Errorf("subject %v does not match the expected identity", subjects)
+	}
+
+	issuer, err := certificateIssuer(cert)
+	if err != nil {
+		return err
+	}
+	if !kv.issuer(issuer) {
+		return fmt.Errorf("issuer %q does not match the expected issuer", issuer)
+	}
+	return nil
+}
+
+// certificateIssuer returns the OIDC issuer recorded in a Fulcio certificate
+func certificateIssuer(cert *x509.Certificate) (string, error) {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidFulcioIssuerV2) {
+			var issuer string
+			if _, err := asn1.UnmarshalWithParams(ext.Value, &issuer, "utf8"); err != nil {
+				return "", fmt.Errorf("failed to parse issuer extension: %w", err)
+			}
+			return issuer, nil
+		}
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidFulcioIssuer) {
+			return string(ext.Value), nil
+		}
+	}
+	return "", fmt.Errorf("certificate has no OIDC issuer extension")
+}
+
+// ---------------------------------------------------------------------------
+// Transparency log verification
+// ---------------------------------------------------------------------------
+
+// verifyTlogEntry verifies that entry is a valid entry of the transparency
+// log of the policy kv that records sig, made with the key of cert
+func verifyTlogEntry(entry *bundleTlogEntry, sig *tag.TagSignature, cert *x509.Certificate, kv *KeylessVerify) error {
+	if !bytes.Equal(entry.LogID.KeyID, kv.rekorLogID) {
+		return fmt.Errorf("entry %d was not recorded by the trusted transparency log", entry.LogIndex)
+	}
+	if err := verifyTlogBody(entry, sig, cert); err != nil {
+		return fmt.Errorf("entry %d: %w", entry.LogIndex, err)
+	}
+
+	if entry.InclusionPromise == nil || len(entry.InclusionPromise.SignedEntryTimestamp) == 0 {
+		return fmt.Errorf("entry %d has no signed entry timestamp", entry.LogIndex)
+	}
+	set, err := json.Marshal(rekorSETPayload{
+		Body:           base64.StdEncoding.EncodeToString(entry.CanonicalizedBody),
+		IntegratedTime: entry.IntegratedTime,
+		LogID:          hex.EncodeToString(entry.LogID.KeyID),
+		LogIndex:       entry.LogIndex,
+	})
+	if err != nil {
+		return err
+	}
+	setHash := sha256.Sum256(set)
+	if !ecdsa.VerifyASN1(kv.rekorKey, setHash[:], entry.InclusionPromise.SignedEntryTimestamp) {
+		return fmt.Errorf("entry %d has an invalid signed entry timestamp", entry.LogIndex)
+	}
+
+	if entry.InclusionProof == nil {
+		return fmt.Errorf("entry %d has no inclusion proof", entry.LogIndex)
+	}
+	if err := verifyInclusionProof(entry.InclusionProof, entry.CanonicalizedBody); err != nil {
+		return fmt.Errorf("entry %d: %w", entry.LogIndex, err)
+	}
+	if err := verifyCheckpoint(entry.InclusionProof, kv.rekorKey); err != nil {
+		return fmt.Errorf("entry %d: %w", entry.LogIndex, err)
+	}
+	return nil
+}
+
+// verifyTlogBody checks that the body of a transparency log entry records
+// the DSSE envelope payload and signature of sig, and the certificate cert
+func verifyTlogBody(entry *bundleTlogEntry, sig *tag.TagSignature, cert *x509.Certificate) error {
+	if entry.KindVersion.Kind != rekorKindDSSE {
+		return fmt.Errorf("unsupported entry kind %q", entry.KindVersion.Kind)
+	}
+	var body rekorDSSEBody
+	if err := json.Unmarshal(entry.CanonicalizedBody, &body); err != nil {
+		return fmt.Errorf("failed to parse entry body: %w", err)
+	}
+	if body.Spec.PayloadHash.Algorithm != "sha256" || body.Spec.PayloadHash.Value != sig.EnvelopePayloadDigest {
+		return fmt.Errorf("entry does not record the signed payload")
+	}
+	for _, s := range body.Spec.Signatures {
+		if s.Signature != sig.Sig {
+			continue
+		}
+		verifier, err := base64.StdEncoding.DecodeString(s.Verifier)
+		if err != nil {
+			return fmt.Errorf("failed to decode entry verifier: %w", err)
+		}
+		block, _ := pem.Decode(verifier)
+		if block == nil || !bytes.Equal(block.Bytes, cert.Raw) {
+			return fmt.Errorf("entry records a different signing certificate")
+		}
+		return nil
+	}
+	return fmt.Errorf("entry does not record the signature")
+}
+
+// verifyInclusionProof verifies the RFC 6962 Merkle inclusion proof of the
+// leaf body against the root hash of the proof
+func verifyInclusionProof(proof *bundleInclusionProof, body []byte) error {
+	if proof.LogIndex < 0 || proof.LogIndex >= proof.TreeSize {
+		return fmt.Errorf("inclusion proof index %d is out of range for tree size %d", proof.LogIndex, proof.TreeSize)
+	}
+	index, size := uint64(proof.LogIndex), uint64(proof.TreeSize)
+
+	// The proof consists of the hashes on the path from the leaf up to the
+	// point where it joins the right border of the tree, followed by the
+	// hashes along that border.
+	inner := bits.Len64(index ^ (size - 1))
+	border := bits.OnesCount64(index >> inner)
+	if len(proof.Hashes) != inner+border {
+		return fmt.Errorf("inclusion proof has %d hashes, expected %d", len(proof.Hashes), inner+border)
+	}
+
+	hash := merkleLeafHash(body)
+	for i, h := range proof.Hashes[:inner] {
+		if (index>>i)&1 == 0 {
+			hash = merkleNodeHash(hash, h)
+		} else {
+			hash = merkleNodeHash(h, hash)
+		}
+	}
+	for _, h := range proof.Hashes[inner:] {
+		hash = merkleNodeHash(h, hash)
+	}
+
+	if !bytes.Equal(hash, proof.RootHash) {
+		return fmt.Errorf("inclusion proof does not lead to root hash %x", proof.RootHash)
+	}
+	return nil
+}
+
+// merkleLeafHash returns the RFC 6962 hash of a leaf
+func merkleLeafHash(leaf []byte) []byte {
+	h := sha256.Sum256(append([]byte{0x00}, leaf...))
+	return h[:]
+}
+
+// merkleNodeHash returns the RFC 6962 hash of an inner node
+func merkleNodeHash(left, right []byte) []byte {
+	buf := make([]byte, 0, 1+len(left)+len(right))
+	buf = append(buf, 0x01)
+	buf = append(buf, left...)
+	buf = append(buf, right...)
+	h := sha256.Sum256(buf)
+	return h[:]
+}
+
+// verifyCheckpoint verifies that the checkpoint of the proof is a signed note
+// of the transparency log, and that it commits to the tree size and root hash
+// of the proof. A checkpoint has the form
+//
+//	<origin>
+//	<tree size>
+//	<base64 root hash>
+//	[other content]
+//
+//	— <name> <base64 key hint and signature>
+func verifyCheckpoint(proof *bundleInclusionProof, rekorKey *ecdsa.PublicKey) error {
+	text, sigs, ok := strings.Cut(proof.Checkpoint.Envelope, "\n\n")
+	if !ok {
+		return fmt.Errorf("checkpoint is not a signed note")
+	}
+	text += "\n"
+
+	textHash := sha256.Sum256([]byte(text))
+	verified := false
+	for _, line := range strings.Split(sigs, "\n") {
+		if !strings.HasPrefix(line, "— ") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "— "))
+		if len(fields) != 2 {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil || len(sig) <= 4 {
+			continue
+		}
+		if ecdsa.VerifyASN1(rekorKey, textHash[:], sig[4:]) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return fmt.Errorf("checkpoint is not signed by the trusted transparency log")
+	}
+
+	lines := strings.Split(text, "\n")
+	if len(lines) < 3 {
+		return fmt.Errorf("checkpoint is malformed")
+	}
+	size, err := strconv.ParseInt(lines[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("checkpoint has an invalid tree size: %w", err)
+	}
+	rootHash, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return fmt.Errorf("checkpoint has an invalid root hash: %w", err)
+	}
+	if size != proof.TreeSize || !bytes.Equal(rootHash, proof.RootHash) {
+		return fmt.Errorf("checkpoint does not match the inclusion proof")
+	}
+	return nil
+}
@@ -0,0 +1,493 @@
+package image
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+const (
+	testIdentity = "https://github.com/org/app/.github/workflows/release.yml@refs/heads/main"
+	testIssuer   = "https://token.actions.githubusercontent.com"
+)
+
+// testSigstore is a minimal Fulcio CA and Rekor transparency log for
+// creating keyless signatures
+type testSigstore struct {
+	rootPEM         string
+	intermediatePEM string
+	intermediate    *x509.Certificate
+	intermediateKey *ecdsa.PrivateKey
+	rekor           testKeyPair
+}
+
+func newTestSigstore(t *testing.T) *testSigstore {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rootTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sigstore"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTmpl, rootTmpl, &rootKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	root, err := x509.ParseCertificate(rootDER)
+	require.NoError(t, err)
+
+	intermediateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	intermediateTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(2),
+		Subject:               pkix.Name{CommonName: "sigstore-intermediate"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	intermediateDER, err := x509.CreateCertificate(rand.Reader, intermediateTmpl, root, &intermediateKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	intermediate, err := x509.ParseCertificate(intermediateDER)
+	require.NoError(t, err)
+
+	return &testSigstore{
+		rootPEM:         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootDER})),
+		intermediatePEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: intermediateDER})),
+		intermediate:    intermediate,
+		intermediateKey: intermediateKey,
+		rekor:           newTestKeyPair(t),
+	}
+}
+
+// trustedRoots returns the PEM bundle of the root and intermediate certificate
+func (s *testSigstore) trustedRoots() string {
+	return s.rootPEM + s.intermediatePEM
+}
+
+// issueCertificate issues a short-lived signing certificate for identity and
+// issuer, valid around signedAt
+func (s *testSigstore) issueCertificate(t *testing.T, identity, issuer string, signedAt time.Time) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	uri, err := url.Parse(identity)
+	require.NoError(t, err)
+	issuerV2, err := asn1.MarshalWithParams(issuer, "utf8")
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		NotBefore:    signedAt.Add(-time.Minute),
+		NotAfter:     signedAt.Add(10 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:         []*url.URL{uri},
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidFulcioIssuer, Value: []byte(issuer)},
+			{Id: oidFulcioIssuerV2, Value: issuerV2},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, s.intermediate, &key.PublicKey, s.intermediateKey)
+	require.NoError(t, err)
+	return key, der
+}
+
+// signKeyless creates a keyless sigstore bundle for imgDigest, signed with a
+// certificate for identity and issuer and recorded at index 2 of a
+// transparency log with three entries
+func (s *testSigstore) signKeyless(t *testing.T, identity, issuer, imgDigest string) []byte {
+	t.Helper()
+	signedAt := time.Now().Add(-time.Hour)
+	key, certDER := s.issueCertificate(t, identity, issuer, signedAt)
+
+	payload := fmt.Appendf(nil, `{"_type":"https://in-toto.io/Statement/v1","subject":[{"digest":{"sha256":"%s"}}]}`,
+		godigest.Digest(imgDigest).Encoded())
+	payloadType := "application/vnd.in-toto+json"
+	_, sig := signPAE(t, key, payloadType, payload)
+	payloadHash := sha256.Sum256(payload)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	body := fmt.Appendf(nil, `{"apiVersion":"0.0.1","kind":"dsse","spec":{"payloadHash":{"algorithm":"sha256","value":"%s"},"signatures":[{"signature":"%s","verifier":"%s"}]}}`,
+		hex.EncodeToString(payloadHash[:]), sig, base64.StdEncoding.EncodeToString(certPEM))
+
+	// Tree of three leaves, with the entry as the last one
+	left := merkleNodeHash(merkleLeafHash([]byte("entry-0")), merkleLeafHash([]byte("entry-1")))
+	rootHash := merkleNodeHash(left, merkleLeafHash(body))
+
+	logID := sha256.Sum256(s.rekorPublicKeyDER(t))
+	const logIndex = 1234
+	set, err := json.Marshal(rekorSETPayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: signedAt.Unix(),
+		LogID:          hex.EncodeToString(logID[:]),
+		LogIndex:       logIndex,
+	})
+	require.NoError(t, err)
+	setHash := sha256.Sum256(set)
+	setSig, err := ecdsa.SignASN1(rand.Reader, s.rekor.priv, setHash[:])
+	require.NoError(t, err)
+
+	checkpoint := fmt.Sprintf("rekor.example.com - 42\n3\n%s\n", base64.StdEncoding.EncodeToString(rootHash))
+	checkpointHash := sha256.Sum256([]byte(checkpoint))
+	checkpointSig, err := ecdsa.SignASN1(rand.Reader, s.rekor.priv, checkpointHash[:])
+	require.NoError(t, err)
+	checkpoint += "\n— rekor.example.com " + base64.StdEncoding.EncodeToString(append(logID[:4], checkpointSig...)) + "\n"
+
+	bundle := map[string]any{
+		"mediaType": sigstoreBundleType,
+		"verificationMaterial": map[string]any{
+			"certificate": map[string]any{"rawBytes": certDER},
+			"tlogEntries": []any{map[string]any{
+				"logIndex":          fmt.Sprint(logIndex),
+				"logId":             map[string]any{"keyId": logID[:]},
+				"kindVersion":       map[string]any{"kind": "dsse", "version": "0.0.1"},
+				"integratedTime":    fmt.Sprint(signedAt.Unix()),
+				"inclusionPromise":  map[string]any{"signedEntryTimestamp": setSig},
+				"canonicalizedBody": body,
+				"inclusionProof": map[string]any{
+					"logIndex":   "2",
+					"rootHash":   rootHash,
+					"treeSize":   "3",
+					"hashes":     [][]byte{left},
+					"checkpoint": map[string]any{"envelope": checkpoint},
+				},
+			}},
+		},
+		"dsseEnvelope": map[string]any{
+			"payload":     base64.StdEncoding.EncodeToString(payload),
+			"payloadType": payloadType,
+			"signatures":  []any{map[string]any{"sig": sig}},
+		},
+	}
+	blob, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	return blob
+}
+
+func (s *testSigstore) rekorPublicKeyDER(t *testing.T) []byte {
+	t.Helper()
+	block, _ := pem.Decode([]byte(s.rekor.pemPub))
+	require.NotNil(t, block)
+	return block.Bytes
+}
+
+// keylessTagSignatures extracts the signatures from a keyless bundle blob
+func keylessTagSignatures(t *testing.T, blob []byte, imgDigest string) []*tag.TagSignature {
+	t.Helper()
+	dgst := godigest.FromBytes(blob)
+	fetcher := &mockFetcher{blobs: map[string][]byte{dgst.String(): blob}}
+	sigs, err := extractDSSEBundle(context.Background(), distribution.Descriptor{MediaType: sigstoreBundleType, Digest: dgst},
+		"1.0.0", godigest.Digest(imgDigest), fetcher)
+	require.NoError(t, err)
+	return sigs
+}
+
+// mutateBundle unmarshals blob, applies fn to it and marshals it again
+func mutateBundle(t *testing.T, blob []byte, fn func(b map[string]any)) []byte {
+	t.Helper()
+	var b map[string]any
+	require.NoError(t, json.Unmarshal(blob, &b))
+	fn(b)
+	out, err := json.Marshal(b)
+	require.NoError(t, err)
+	return out
+}
+
+func tlogEntryOf(b map[string]any) map[string]any {
+	return b["verificationMaterial"].(map[string]any)["tlogEntries"].([]any)[0].(map[string]any)
+}
+
+func Test_NewKeylessVerify(t *testing.T) {
+	ss := newTestSigstore(t)
+	identity := CertificateIdentity{Subject: testIdentity, Issuer: testIssuer}
+
+	t.Run("Valid policy", func(t *testing.T) {
+		kv, err := NewKeylessVerify(ss.trustedRoots(), ss.rekor.pemPub, identity)
+		require.NoError(t, err)
+		assert.True(t, kv.subject(testIdentity))
+		assert.False(t, kv.subject(testIdentity+"x"))
+		assert.True(t, kv.issuer(testIssuer))
+	})
+
+	t.Run("Regexp matches whole identity", func(t *testing.T) {
+		kv, err := NewKeylessVerify(ss.trustedRoots(), ss.rekor.pemPub, CertificateIdentity{
+			SubjectRegexp: `https://github\.com/org/.*`,
+			IssuerRegexp:  `https://token\.actions\.githubusercontent\.com`,
+		})
+		require.NoError(t, err)
+		assert.True(t, kv.subject(testIdentity))
+		assert.False(t, kv.subject("https://evil.example.com/https://github.com/org/app"))
+	})
+
+	t.Run("No root certificate", func(t *testing.T) {
+		_, err := NewKeylessVerify(ss.intermediatePEM, ss.rekor.pemPub, identity)
+		assert.ErrorContains(t, err, "no self-signed root certificate")
+	})
+
+	t.Run("Invalid Rekor key", func(t *testing.T) {
+		_, err := NewKeylessVerify(ss.trustedRoots(), "invalid", identity)
+		assert.ErrorContains(t, err, "unable to PEM decode Rekor public key")
+	})
+
+	t.Run("Missing identity", func(t *testing.T) {
+		_, err := NewKeylessVerify(ss.trustedRoots(), ss.rekor.pemPub, CertificateIdentity{Issuer: testIssuer})
+		assert.ErrorContains(t, err, "certificate subject or subject regexp is required")
+	})
+
+	t.Run("Exact and regexp issuer", func(t *testing.T) {
+		_, err := NewKeylessVerify(ss.trustedRoots(), ss.rekor.pemPub, CertificateIdentity{Subject: testIdentity, Issuer: testIssuer, IssuerRegexp: ".*"})
+		assert.ErrorContains(t, err, "mutually exclusive")
+	})
+
+	t.Run("Invalid regexp", func(t *testing.T) {
+		_, err := NewKeylessVerify(ss.trustedRoots(), ss.rekor.pemPub, CertificateIdentity{SubjectRegexp: "(", Issuer: testIssuer})
+		assert.ErrorContains(t, err, "invalid certificate subject regexp")
+	})
+}
+
+func Test_verifyInclusionProof(t *testing.T) {
+	leaves := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	h := func(i int) []byte { return merkleLeafHash(leaves[i]) }
+	root := merkleNodeHash(merkleNodeHash(h(0), h(1)), h(2))
+
+	tests := []struct {
+		index  int64
+		hashes [][]byte
+	}{
+		{0, [][]byte{h(1), h(2)}},
+		{1, [][]byte{h(0), h(2)}},
+		{2, [][]byte{merkleNodeHash(h(0), h(1))}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("index %d", tt.index), func(t *testing.T) {
+			proof := &bundleInclusionProof{LogIndex: tt.index, TreeSize: 3, RootHash: root, Hashes: tt.hashes}
+			assert.NoError(t, verifyInclusionProof(proof, leaves[tt.index]))
+			assert.ErrorContains(t, verifyInclusionProof(proof, []byte("x")), "does not lead to root hash")
+		})
+	}
+
+	t.Run("Wrong number of hashes", func(t *testing.T) {
+		proof := &bundleInclusionProof{LogIndex: 2, TreeSize: 3, RootHash: root, Hashes: [][]byte{h(0), h(1)}}
+		assert.ErrorContains(t, verifyInclusionProof(proof, leaves[2]), "expected 1")
+	})
+
+	t.Run("Index out of range", func(t *testing.T) {
+		proof := &bundleInclusionProof{LogIndex: 3, TreeSize: 3, RootHash: root}
+		assert.ErrorContains(t, verifyInclusionProof(proof, leaves[2]), "out of range")
+	})
+}
+
+func Test_certificateIssuer(t *testing.T) {
+	ss := newTestSigstore(t)
+	_, certDER := ss.issueCertificate(t, testIdentity, testIssuer, time.Now())
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+
+	t.Run("Issuer from extension", func(t *testing.T) {
+		issuer, err := certificateIssuer(cert)
+		require.NoError(t, err)
+		assert.Equal(t, testIssuer, issuer)
+	})
+
+	t.Run("Issuer from deprecated extension", func(t *testing.T) {
+		legacy := *cert
+		legacy.Extensions = nil
+		for _, ext := range cert.Extensions {
+			if !ext.Id.Equal(oidFulcioIssuerV2) {
+				legacy.Extensions = append(legacy.Extensions, ext)
+			}
+		}
+		issuer, err := certificateIssuer(&legacy)
+		require.NoError(t, err)
+		assert.Equal(t, testIssuer, issuer)
+	})
+
+	t.Run("No issuer", func(t *testing.T) {
+		_, err := certificateIssuer(ss.intermediate)
+		assert.ErrorContains(t, err, "no OIDC issuer extension")
+	})
+}
+
+func Test_verifyKeylessSignature(t *testing.T) {
+	const imgDigest = "sha256:ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234"
+	ss := newTestSigstore(t)
+	kv, err := NewKeylessVerify(ss.trustedRoots(), ss.rekor.pemPub, CertificateIdentity{Subject: testIdentity, Issuer: testIssuer})
+	require.NoError(t, err)
+	blob := ss.signKeyless(t, testIdentity, testIssuer, imgDigest)
+
+	t.Run("Valid signature", func(t *testing.T) {
+		sigs := keylessTagSignatures(t, blob, imgDigest)
+		require.Len(t, sigs, 1)
+		assert.NoError(t, verifyKeylessSignature("org/app:1.0.0", sigs[0], kv))
+	})
+
+	t.Run("Key-based signature has no verification material", func(t *testing.T) {
+		kp := newTestKeyPair(t)
+		_, sig := signPAE(t, kp.priv, "application/vnd.in-toto+json", []byte("{}"))
+		err := verifyKeylessSignature("org/app:1.0.0", &tag.TagSignature{Sig: sig}, kv)
+		assert.ErrorContains(t, err, "no sigstore bundle verification material")
+	})
+
+	t.Run("Wrong identity", func(t *testing.T) {
+		other := ss.signKeyless(t, "https://github.com/evil/app/.github/workflows/release.yml@refs/heads/main", testIssuer, imgDigest)
+		err := verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, other, imgDigest)[0], kv)
+		assert.ErrorContains(t, err, "does not match the expected identity")
+	})
+
+	t.Run("Wrong issuer", func(t *testing.T) {
+		other := ss.signKeyless(t, testIdentity, "https://accounts.example.com", imgDigest)
+		err := verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, other, imgDigest)[0], kv)
+		assert.ErrorContains(t, err, "does not match the expected issuer")
+	})
+
+	t.Run("Untrusted certificate authority", func(t *testing.T) {
+		otherCA := newTestSigstore(t)
+		otherKV, err := NewKeylessVerify(otherCA.trustedRoots(), ss.rekor.pemPub, CertificateIdentity{Subject: testIdentity, Issuer: testIssuer})
+		require.NoError(t, err)
+		err = verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, blob, imgDigest)[0], otherKV)
+		assert.ErrorContains(t, err, "is not trusted")
+	})
+
+	t.Run("Untrusted transparency log", func(t *testing.T) {
+		otherLog := newTestKeyPair(t)
+		otherKV, err := NewKeylessVerify(ss.trustedRoots(), otherLog.pemPub, CertificateIdentity{Subject: testIdentity, Issuer: testIssuer})
+		require.NoError(t, err)
+		err = verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, blob, imgDigest)[0], otherKV)
+		assert.ErrorContains(t, err, "not recorded by the trusted transparency log")
+	})
+
+	t.Run("Missing transparency log entry", func(t *testing.T) {
+		tampered := mutateBundle(t, blob, func(b map[string]any) {
+			b["verificationMaterial"].(map[string]any)["tlogEntries"] = []any{}
+		})
+		err := verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, tampered, imgDigest)[0], kv)
+		assert.ErrorContains(t, err, "no transparency log entry")
+	})
+
+	t.Run("Tampered signed entry timestamp", func(t *testing.T) {
+		tampered := mutateBundle(t, blob, func(b map[string]any) {
+			tlogEntryOf(b)["integratedTime"] = "1"
+		})
+		err := verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, tampered, imgDigest)[0], kv)
+		assert.ErrorContains(t, err, "invalid signed entry timestamp")
+	})
+
+	t.Run("Missing inclusion proof", func(t *testing.T) {
+		tampered := mutateBundle(t, blob, func(b map[string]any) {
+			delete(tlogEntryOf(b), "inclusionProof")
+		})
+		err := verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, tampered, imgDigest)[0], kv)
+		assert.ErrorContains(t, err, "no inclusion proof")
+	})
+
+	t.Run("Tampered inclusion proof", func(t *testing.T) {
+		tampered := mutateBundle(t, blob, func(b map[string]any) {
+			tlogEntryOf(b)["inclusionProof"].(map[string]any)["hashes"] = [][]byte{make([]byte, 32)}
+		})
+		err := verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, tampered, imgDigest)[0], kv)
+		assert.ErrorContains(t, err, "does not lead to root hash")
+	})
+
+	t.Run("Unsigned checkpoint", func(t *testing.T) {
+		tampered := mutateBundle(t, blob, func(b map[string]any) {
+			proof := tlogEntryOf(b)["inclusionProof"].(map[string]any)
+			cp := proof["checkpoint"].(map[string]any)
+			env := cp["envelope"].(string)
+			cp["envelope"] = env[:len(env)-10] + "AAAAAAAAA\n"
+		})
+		err := verifyKeylessSignature("org/app:1.0.0", keylessTagSignatures(t, tampered, imgDigest)[0], kv)
+		assert.ErrorContains(t, err, "checkpoint is not signed")
+	})
+
+	t.Run("Entry records another signature", func(t *testing.T) {
+		sig := *keylessTagSignatures(t, blob, imgDigest)[0]
+		other := *keylessTagSignatures(t, ss.signKeyless(t, testIdentity, testIssuer, imgDigest), imgDigest)[0]
+		sig.VerificationMaterial = other.VerificationMaterial
+		err := verifyKeylessSignature("org/app:1.0.0", &sig, kv)
+		assert.ErrorContains(t, err, "does not record the signature")
+	})
+}
+
+func Test_VerifyKeyless(t *testing.T) {
+	ctx := context.Background()
+	const (
+		imgManifestDigest = "sha256:ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234"
+		sigArtifactDigest = "sha256:eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678"
+	)
+	ss := newTestSigstore(t)
+	kv, err := NewKeylessVerify(ss.trustedRoots(), ss.rekor.pemPub, CertificateIdentity{Subject: testIdentity, Issuer: testIssuer})
+	require.NoError(t, err)
+	verifyConfig := &Verify{Keyless: kv}
+
+	newFetcher := func(blob []byte) *mockFetcher {
+		dgst := godigest.FromBytes(blob)
+		return &mockFetcher{
+			referrers: map[string][]distribution.Descriptor{
+				imgManifestDigest: {bundleReferrer(sigArtifactDigest)},
+			},
+			manifests: map[string]distribution.Manifest{
+				sigArtifactDigest: &ocischema.DeserializedManifest{
+					Manifest: ocischema.Manifest{
+						Layers: []distribution.Descriptor{{MediaType: sigstoreBundleType, Digest: dgst, Size: int64(len(blob))}},
+					},
+				},
+			},
+			blobs: map[string][]byte{dgst.String(): blob},
+		}
+	}
+
+	t.Run("Keyless bundle verifies successfully end-to-end", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		err := VerifyKeyless(ctx, img, verifyConfig, newFetcher(ss.signKeyless(t, testIdentity, testIssuer, imgManifestDigest)))
+		assert.NoError(t, err)
+		require.NotEmpty(t, img.ImageTag.TagSignatures)
+	})
+
+	t.Run("Bundle for another image is rejected", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		other := "sha256:aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234"
+		err := VerifyKeyless(ctx, img, verifyConfig, newFetcher(ss.signKeyless(t, testIdentity, testIssuer, other)))
+		assert.ErrorContains(t, err, "failed to fetch cosign signature")
+	})
+
+	t.Run("Key-based bundle is rejected", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		kp := newTestKeyPair(t)
+		_, _, blob := makeDSSEBundle(t, kp.priv, imgManifestDigest)
+		err := VerifyKeyless(ctx, img, verifyConfig, newFetcher(blob))
+		assert.ErrorContains(t, err, "no sigstore bundle verification material")
+	})
+
+	t.Run("No keyless policy", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		err := VerifyKeyless(ctx, img, &Verify{}, &mockFetcher{})
+		assert.ErrorContains(t, err, "no keyless verification policy")
+	})
+}
@@ -0,0 +1,159 @@
+package image
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// DefaultVersionLabel is the image label the label strategy reads the version
+// from, unless configured otherwise
+const DefaultVersionLabel = "org.opencontainers.image.version"
+
+// LabelFormat defines how the value of a version label is parsed
+type LabelFormat int
+
+const (
+	// LabelFormatSemVer parses label values as semantic versions (the default)
+	LabelFormatSemVer LabelFormat = 0
+	// LabelFormatCalVer parses label values as calendar versions
+	LabelFormatCalVer LabelFormat = 1
+)
+
+// String returns the string representation of the label format
+func (lf LabelFormat) String() string {
+	switch lf {
+	case LabelFormatSemVer:
+		return "semver"
+	case LabelFormatCalVer:
+		return "calver"
+	}
+
+	return "unknown"
+}
+
+// LabelConstraint holds the configuration for the label update strategy. Use
+// ParseLabelConstraint to initialize a new object.
+type LabelConstraint struct {
+	// Name is the name of the label holding the version of an image
+	Name string
+	// Format is the versioning scheme of the label values
+	Format LabelFormat
+	// Constraint is the semantic version constraint the label value must
+	// satisfy. It is nil for LabelFormatCalVer.
+	Constraint *semver.Constraints
+
+	constraint string
+}
+
+// LabelVersion is the version read from the label of an image. Exactly one
+// of SemVer and CalVer is set, depending on the format of the label.
+type LabelVersion struct {
+	// Value is the verbatim value of the label
+	Value  string
+	SemVer *semver.Version
+	CalVer *tag.CalVersion
+}
+
+// ParseLabelConstraint parses the configuration of the label strategy. An
+// empty name selects DefaultVersionLabel, and an empty format selects semver.
+// The constraint is a semantic version constraint that is applied to the
+// label value, and must be empty for the calver format. Like with the semver
+// strategy, an empty constraint allows all versions except pre-releases.
+func ParseLabelConstraint(name string, format string, constraint string) (*LabelConstraint, error) {
+	lc := &LabelConstraint{Name: strings.TrimSpace(name), constraint: constraint}
+	if lc.Name == "" {
+		lc.Name = DefaultVersionLabel
+	}
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "semver":
+		lc.Format = LabelFormatSemVer
+	case "calver":
+		lc.Format = LabelFormatCalVer
+	default:
+		return nil, fmt.Errorf("unknown label format %s, must be one of semver, calver", format)
+	}
+
+	if lc.Format != LabelFormatSemVer {
+		if constraint != "" {
+			return nil, fmt.Errorf("label constraint %s requires the semver label format", constraint)
+		}
+		return lc, nil
+	}
+	if constraint == "" {
+		constraint = "*"
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label constraint %s: %w", constraint, err)
+	}
+	lc.Constraint = c
+
+	return lc, nil
+}
+
+// String returns the string representation of the label constraint
+func (lc *LabelConstraint) String() string {
+	if lc.constraint == "" {
+		return fmt.Sprintf("%s (%s)", lc.Name, lc.Format)
+	}
+	return fmt.Sprintf("%s (%s %s)", lc.Name, lc.Format, lc.constraint)
+}
+
+// Version returns the version held by the label of t. The calver layout is
+// required for the calver format. Returns an error if t has no such label,
+// or if its value is not a version of the label format.
+func (lc *LabelConstraint) Version(t *tag.ImageTag, layout *tag.CalVerLayout) (*LabelVersion, error) {
+	val, ok := t.Labels[lc.Name]
+	if !ok {
+		return nil, fmt.Errorf("tag %s has no label %s", t.TagName, lc.Name)
+	}
+	switch lc.Format {
+	case LabelFormatCalVer:
+		cv, err := layout.Parse(val)
+		if err != nil {
+			return nil, fmt.Errorf("label %s=%s of tag %s is not a calendar version: %w", lc.Name, val, t.TagName, err)
+		}
+		return &LabelVersion{Value: val, CalVer: cv}, nil
+	default:
+		sv, err := semver.NewVersion(val)
+		if err != nil {
+			return nil, fmt.Errorf("label %s=%s of tag %s is not a semantic version: %w", lc.Name, val, t.TagName, err)
+		}
+		return &LabelVersion{Value: val, SemVer: sv}, nil
+	}
+}
+
+// Compare compares lv to other, returning -1, 0 or 1 if lv is lower than,
+// equal to or higher than other. Both versions must be of the same format.
+func (lv *LabelVersion) Compare(other *LabelVersion) int {
+	if lv.CalVer != nil && other.CalVer != nil {
+		return lv.CalVer.Compare(other.CalVer)
+	}
+	return lv.SemVer.Compare(other.SemVer)
+}
+
+// SortTags returns the tags from tagList whose label holds a version, sorted
+// by that version. Ties are broken through a lexical comparison of the tag
+// names. The versions are returned keyed by tag name.
+func (lc *LabelConstraint) SortTags(tagList *tag.ImageTagList, layout *tag.CalVerLayout) (tag.SortableImageTagList, map[string]*LabelVersion) {
+	sil := tag.SortableImageTagList{}
+	versions := make(map[string]*LabelVersion)
+	for _, t := range tagList.SortAlphabetically() {
+		lv, err := lc.Version(t, layout)
+		if err != nil {
+			continue
+		}
+		sil = append(sil, t)
+		versions[t.TagName] = lv
+	}
+	sort.SliceStable(sil, func(i, j int) bool {
+		return versions[sil[i].TagName].Compare(versions[sil[j].TagName]) < 0
+	})
+	return sil, versions
+}
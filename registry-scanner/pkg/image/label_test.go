@@ -0,0 +1,97 @@
+package image
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+// newLabelledImageTagList returns a tag list with each tag carrying the
+// version label given for it. A label value of "" omits the label.
+func newLabelledImageTagList(labelName string, tags map[string]string) *tag.ImageTagList {
+	tagList := tag.NewImageTagList()
+	for tagName, val := range tags {
+		labels := map[string]string{}
+		if val != "" {
+			labels[labelName] = val
+		}
+		tagList.Add(tag.NewImageTagWithLabels(tagName, time.Unix(0, 0), "", labels))
+	}
+	return tagList
+}
+
+func Test_ParseLabelConstraint(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		lc, err := ParseLabelConstraint("", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultVersionLabel, lc.Name)
+		assert.Equal(t, LabelFormatSemVer, lc.Format)
+		assert.NotNil(t, lc.Constraint)
+		assert.Equal(t, "org.opencontainers.image.version (semver)", lc.String())
+	})
+
+	t.Run("Custom label with semver constraint", func(t *testing.T) {
+		lc, err := ParseLabelConstraint("com.example.version", "SemVer", "~1.2")
+		require.NoError(t, err)
+		assert.Equal(t, "com.example.version", lc.Name)
+		assert.NotNil(t, lc.Constraint)
+		assert.Equal(t, "com.example.version (semver ~1.2)", lc.String())
+	})
+
+	t.Run("Calver format", func(t *testing.T) {
+		lc, err := ParseLabelConstraint("", "calver", "")
+		require.NoError(t, err)
+		assert.Equal(t, LabelFormatCalVer, lc.Format)
+		assert.Nil(t, lc.Constraint)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		_, err := ParseLabelConstraint("", "name", "")
+		assert.ErrorContains(t, err, "unknown label format")
+	})
+
+	t.Run("Constraint with calver format", func(t *testing.T) {
+		_, err := ParseLabelConstraint("", "calver", "~1.2")
+		assert.ErrorContains(t, err, "requires the semver label format")
+	})
+
+	t.Run("Invalid constraint", func(t *testing.T) {
+		_, err := ParseLabelConstraint("", "", "not a constraint")
+		assert.ErrorContains(t, err, "invalid label constraint")
+	})
+}
+
+func Test_LabelConstraint_SortTags(t *testing.T) {
+	t.Run("Sort by semver label", func(t *testing.T) {
+		tagList := newLabelledImageTagList(DefaultVersionLabel, map[string]string{
+			"build-300": "1.10.0",
+			"build-100": "1.2.0",
+			"build-200": "v1.9.1",
+			"build-400": "",
+			"build-500": "nightly",
+		})
+		lc, err := ParseLabelConstraint("", "", "")
+		require.NoError(t, err)
+		sorted, versions := lc.SortTags(tagList, nil)
+		assert.Equal(t, []string{"build-100", "build-200", "build-300"}, sorted.Tags())
+		assert.Equal(t, "v1.9.1", versions["build-200"].Value)
+	})
+
+	t.Run("Sort by calver label", func(t *testing.T) {
+		tagList := newLabelledImageTagList("version", map[string]string{
+			"a1b2c3": "2024.11.03",
+			"d4e5f6": "2024.01.15",
+			"g7h8i9": "1.2.3",
+		})
+		lc, err := ParseLabelConstraint("version", "calver", "")
+		require.NoError(t, err)
+		layout, err := tag.ParseCalVerLayout(tag.DefaultCalVerLayout)
+		require.NoError(t, err)
+		sorted, _ := lc.SortTags(tagList, layout)
+		assert.Equal(t, []string{"d4e5f6", "a1b2c3"}, sorted.Tags())
+	})
+}
@@ -0,0 +1,101 @@
+package image
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// LabelMatch matches an image label by its key, and optionally by a regular
+// expression its value must match
+type LabelMatch struct {
+	// Key is the key of the label
+	Key string
+	// Value is a regular expression that must match the whole value of the
+	// label. An empty value matches any value.
+	Value string
+}
+
+// String returns the string representation of the label match
+func (lm LabelMatch) String() string {
+	if lm.Value == "" {
+		return lm.Key
+	}
+	return lm.Key + "=~" + lm.Value
+}
+
+// labelMatcher is a LabelMatch with its value expression compiled
+type labelMatcher struct {
+	LabelMatch
+	value *regexp.Regexp
+}
+
+// matches returns true if labels has a label matching lm
+func (lm *labelMatcher) matches(labels map[string]string) bool {
+	val, ok := labels[lm.Key]
+	if !ok {
+		return false
+	}
+	return lm.value == nil || lm.value.MatchString(val)
+}
+
+// LabelPolicy defines the labels an image must or must not have to be
+// considered for an update. Use ParseLabelPolicy to initialize a new object.
+type LabelPolicy struct {
+	required  []labelMatcher
+	forbidden []labelMatcher
+}
+
+// ParseLabelPolicy creates a label policy that requires each of the required
+// labels, and forbids all of the forbidden labels. Returns nil if neither are
+// given.
+func ParseLabelPolicy(required, forbidden []LabelMatch) (*LabelPolicy, error) {
+	if len(required) == 0 && len(forbidden) == 0 {
+		return nil, nil
+	}
+	lp := &LabelPolicy{}
+	var err error
+	if lp.required, err = compileLabelMatches(required); err != nil {
+		return nil, fmt.Errorf("invalid required label: %w", err)
+	}
+	if lp.forbidden, err = compileLabelMatches(forbidden); err != nil {
+		return nil, fmt.Errorf("invalid forbidden label: %w", err)
+	}
+	return lp, nil
+}
+
+// compileLabelMatches compiles the value expressions of matches
+func compileLabelMatches(matches []LabelMatch) ([]labelMatcher, error) {
+	matchers := make([]labelMatcher, 0, len(matches))
+	for _, m := range matches {
+		if strings.TrimSpace(m.Key) == "" {
+			return nil, fmt.Errorf("label key must not be empty")
+		}
+		lm := labelMatcher{LabelMatch: m}
+		if m.Value != "" {
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("label %s: %w", m.Key, err)
+			}
+			lm.value = re
+		}
+		matchers = append(matchers, lm)
+	}
+	return matchers, nil
+}
+
+// Check returns an error describing the first label of labels that violates
+// the policy, or nil if labels satisfy the policy.
+func (lp *LabelPolicy) Check(labels map[string]string) error {
+	for _, lm := range lp.required {
+		if !lm.matches(labels) {
+			return fmt.Errorf("required label %s is missing", lm)
+		}
+	}
+	for _, lm := range lp.forbidden {
+		if lm.matches(labels) {
+			return fmt.Errorf("forbidden label %s is present", lm)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseLabelPolicy(t *testing.T) {
+	t.Run("No labels", func(t *testing.T) {
+		lp, err := ParseLabelPolicy(nil, []LabelMatch{})
+		require.NoError(t, err)
+		assert.Nil(t, lp)
+	})
+	t.Run("Invalid value expression", func(t *testing.T) {
+		_, err := ParseLabelPolicy([]LabelMatch{{Key: "com.example.qa", Value: "pass(ed"}}, nil)
+		assert.ErrorContains(t, err, "invalid required label: label com.example.qa")
+	})
+	t.Run("Empty key", func(t *testing.T) {
+		_, err := ParseLabelPolicy(nil, []LabelMatch{{Key: " ", Value: "true"}})
+		assert.EqualError(t, err, "invalid forbidden label: label key must not be empty")
+	})
+}
+
+func Test_LabelPolicy_Check(t *testing.T) {
+	lp, err := ParseLabelPolicy(
+		[]LabelMatch{{Key: "com.example.qa", Value: "passed|waived"}, {Key: "org.opencontainers.image.source"}},
+		[]LabelMatch{{Key: "com.example.deprecated", Value: "true"}},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr string
+	}{
+		{
+			name:   "All required labels",
+			labels: map[string]string{"com.example.qa": "waived", "org.opencontainers.image.source": "https://github.com/myorg/app"},
+		},
+		{
+			name:   "Forbidden label with other value",
+			labels: map[string]string{"com.example.qa": "passed", "org.opencontainers.image.source": "", "com.example.deprecated": "false"},
+		},
+		{
+			name:    "Required label value must match as a whole",
+			labels:  map[string]string{"com.example.qa": "not-passed", "org.opencontainers.image.source": "https://github.com/myorg/app"},
+			wantErr: "required label com.example.qa=~passed|waived is missing",
+		},
+		{
+			name:    "Required label missing",
+			labels:  map[string]string{"com.example.qa": "passed"},
+			wantErr: "required label org.opencontainers.image.source is missing",
+		},
+		{
+			name:    "No labels",
+			wantErr: "required label com.example.qa=~passed|waived is missing",
+		},
+		{
+			name:    "Forbidden label",
+			labels:  map[string]string{"com.example.qa": "passed", "org.opencontainers.image.source": "", "com.example.deprecated": "true"},
+			wantErr: "forbidden label com.example.deprecated=~true is present",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := lp.Check(tt.labels)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
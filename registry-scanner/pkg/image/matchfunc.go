@@ -4,6 +4,7 @@ import (
 	"regexp"
 
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 )
 
 // MatchFuncAny matches any pattern, i.e. always returns true
@@ -25,3 +26,24 @@ func MatchFuncRegexp(tagName string, args any) bool {
 	}
 	return pattern.Match([]byte(tagName))
 }
+
+// MatchFuncCEL matches the tagName against a CEL expression and returns the
+// result. Expressions that refer to image metadata cannot be evaluated from
+// the tag name alone, so they always match here and are evaluated once the
+// metadata is available.
+func MatchFuncCEL(tagName string, args any) bool {
+	cm, ok := args.(*CELMatcher)
+	if !ok {
+		log.Errorf("args is not a CELMatcher")
+		return false
+	}
+	if cm.NeedsMetadata() {
+		return true
+	}
+	match, err := cm.Matches(&tag.ImageTag{TagName: tagName})
+	if err != nil {
+		log.Debugf("could not evaluate CEL expression %q for tag %s: %v", cm, tagName, err)
+		return false
+	}
+	return match
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_MatchFuncAny(t *testing.T) {
@@ -25,3 +26,20 @@ func Test_MatchFuncRegexp(t *testing.T) {
 		assert.False(t, MatchFuncRegexp("lemon", "[a-z]+"))
 	})
 }
+
+func Test_MatchFuncCEL(t *testing.T) {
+	t.Run("Test with expression on tag name", func(t *testing.T) {
+		cm, err := NewCELMatcher(`tag.matches("^[a-z]+$")`)
+		require.NoError(t, err)
+		assert.True(t, MatchFuncCEL("lemon", cm))
+		assert.False(t, MatchFuncCEL("31337", cm))
+	})
+	t.Run("Test with expression on metadata", func(t *testing.T) {
+		cm, err := NewCELMatcher(`labels["team"] == "a"`)
+		require.NoError(t, err)
+		assert.True(t, MatchFuncCEL("lemon", cm))
+	})
+	t.Run("Test with invalid type", func(t *testing.T) {
+		assert.False(t, MatchFuncCEL("lemon", "tag == 'lemon'"))
+	})
+}
@@ -0,0 +1,1060 @@
+package image
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	"github.com/distribution/reference"
+	"github.com/fxamacker/cbor/v2"
+	godigest "github.com/opencontainers/go-digest"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+)
+
+// notationSignatureType is the OCI artifactType of Notation signatures
+const notationSignatureType = "application/vnd.cncf.notary.signature"
+
+// Media types of the two envelope formats a Notation signature layer can have
+const (
+	notationJWSMediaType  = "application/jose+json"
+	notationCOSEMediaType = "application/cose"
+)
+
+// notationPayloadType is the content type of the signed Notation payload
+const notationPayloadType = "application/vnd.cncf.notary.payload.v1+json"
+
+// Signing schemes defined by the Notation signature specification. With
+// notary.x509, the signing time is not authenticated unless the signature
+// is timestamped, while with notary.x509.signingAuthority the signing
+// authority vouches for it.
+const (
+	notationSchemeX509             = "notary.x509"
+	notationSchemeSigningAuthority = "notary.x509.signingAuthority"
+)
+
+// Names of the Notation extended attributes used in the envelope headers
+const (
+	notationHeaderSigningScheme        = "io.cncf.notary.signingScheme"
+	notationHeaderSigningTime          = "io.cncf.notary.signingTime"
+	notationHeaderExpiry               = "io.cncf.notary.expiry"
+	notationHeaderAuthenticSigningTime = "io.cncf.notary.authenticSigningTime"
+	notationHeaderVerificationPlugin   = "io.cncf.notary.verificationPlugin"
+)
+
+// Trust store types a trust policy can reference
+const (
+	notationStoreCA               = "ca"
+	notationStoreSigningAuthority = "signingAuthority"
+)
+
+// Validations performed by a Notation trust policy, and the actions that can
+// be taken when one of them fails.
+const (
+	notationIntegrity          = "integrity"
+	notationAuthenticity       = "authenticity"
+	notationAuthenticTimestamp = "authenticTimestamp"
+	notationExpiry             = "expiry"
+	notationRevocation         = "revocation"
+
+	notationEnforce = "enforce"
+	notationLog     = "log"
+	notationSkip    = "skip"
+)
+
+// notationLevels maps each verification level to the action taken for each
+// validation, as defined by the Notation trust policy specification.
+var notationLevels = map[string]map[string]string{
+	"strict": {
+		notationIntegrity:          notationEnforce,
+		notationAuthenticity:       notationEnforce,
+		notationAuthenticTimestamp: notationEnforce,
+		notationExpiry:             notationEnforce,
+		notationRevocation:         notationEnforce,
+	},
+	"permissive": {
+		notationIntegrity:          notationEnforce,
+		notationAuthenticity:       notationEnforce,
+		notationAuthenticTimestamp: notationLog,
+		notationExpiry:             notationLog,
+		notationRevocation:         notationLog,
+	},
+	"audit": {
+		notationIntegrity:          notationEnforce,
+		notationAuthenticity:       notationLog,
+		notationAuthenticTimestamp: notationLog,
+		notationExpiry:             notationLog,
+		notationRevocation:         notationLog,
+	},
+	"skip": {
+		notationIntegrity:          notationSkip,
+		notationAuthenticity:       notationSkip,
+		notationAuthenticTimestamp: notationSkip,
+		notationExpiry:             notationSkip,
+		notationRevocation:         notationSkip,
+	},
+}
+
+// notationSubjectAttributes maps the object identifiers of the distinguished
+// name attributes that can be used in trusted identities to their names.
+var notationSubjectAttributes = map[string]string{
+	"2.5.4.3":  "CN",
+	"2.5.4.6":  "C",
+	"2.5.4.7":  "L",
+	"2.5.4.8":  "ST",
+	"2.5.4.10": "O",
+	"2.5.4.11": "OU",
+}
+
+// coseAlgorithms maps the COSE algorithm identifiers allowed by Notation to
+// their JWS names, which are used internally.
+var coseAlgorithms = map[int64]string{
+	-7:  "ES256",
+	-35: "ES384",
+	-36: "ES512",
+	-37: "PS256",
+	-38: "PS384",
+	-39: "PS512",
+}
+
+// Labels of the COSE header parameters used by Notation
+const (
+	coseHeaderAlgorithm   int64 = 1
+	coseHeaderCritical    int64 = 2
+	coseHeaderContentType int64 = 3
+	coseHeaderX5Chain     int64 = 33
+)
+
+// coseSign1Tag is the CBOR tag of a COSE_Sign1 message
+const coseSign1Tag = 18
+
+// NotationVerify is the policy for verifying Notation signatures, which are
+// made with X.509 certificates and stored as OCI referrers of the image. Use
+// NewNotationVerify to initialize a new object.
+type NotationVerify struct {
+	policies []*notationTrustPolicy
+}
+
+// notationTrustPolicy is a single, validated policy of a trust policy document
+type notationTrustPolicy struct {
+	name       string
+	scopes     []string
+	level      string
+	actions    map[string]string
+	roots      map[string]*x509.CertPool
+	anyone     bool
+	identities []map[string]string
+}
+
+// notationPolicyDocument is the JSON trust policy document used by Notation
+type notationPolicyDocument struct {
+	Version       string                 `json:"version"`
+	TrustPolicies []notationPolicyConfig `json:"trustPolicies"`
+}
+
+// notationPolicyConfig is a single trust policy of the trust policy document
+type notationPolicyConfig struct {
+	Name                  string   `json:"name"`
+	RegistryScopes        []string `json:"registryScopes"`
+	SignatureVerification struct {
+		Level    string            `json:"level"`
+		Override map[string]string `json:"override"`
+	} `json:"signatureVerification"`
+	TrustStores       []string `json:"trustStores"`
+	TrustedIdentities []string `json:"trustedIdentities"`
+}
+
+// NewNotationVerify creates a Notation verification policy. trustPolicy is a
+// Notation trust policy document in JSON format, and trustStores maps the
+// trust store names referenced by the policy, in the form "<type>:<name>",
+// to the PEM-encoded certificates in the respective trust store. Supported
+// trust store types are "ca" and "signingAuthority".
+func NewNotationVerify(trustPolicy string, trustStores map[string]string) (*NotationVerify, error) {
+	var doc notationPolicyDocument
+	if err := json.Unmarshal([]byte(trustPolicy), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse trust policy: %w", err)
+	}
+	if doc.Version != "1.0" {
+		return nil, fmt.Errorf("unsupported trust policy version %q", doc.Version)
+	}
+	if len(doc.TrustPolicies) == 0 {
+		return nil, fmt.Errorf("trust policy document contains no trust policies")
+	}
+
+	nv := &NotationVerify{}
+	names := map[string]bool{}
+	scopes := map[string]string{}
+	for _, cfg := range doc.TrustPolicies {
+		if cfg.Name == "" {
+			return nil, fmt.Errorf("trust policy without a name")
+		}
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("duplicate trust policy %q", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		policy, err := newNotationTrustPolicy(cfg, trustStores)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust policy %q: %w", cfg.Name, err)
+		}
+		for _, scope := range policy.scopes {
+			if other, ok := scopes[scope]; ok {
+				return nil, fmt.Errorf("registry scope %q is used by trust policies %q and %q", scope, other, cfg.Name)
+			}
+			scopes[scope] = cfg.Name
+		}
+		nv.policies = append(nv.policies, policy)
+	}
+
+	return nv, nil
+}
+
+// newNotationTrustPolicy validates a single trust policy and loads the trust
+// stores it references.
+func newNotationTrustPolicy(cfg notationPolicyConfig, trustStores map[string]string) (*notationTrustPolicy, error) {
+	policy := &notationTrustPolicy{
+		name:  cfg.Name,
+		level: cfg.SignatureVerification.Level,
+		roots: map[string]*x509.CertPool{},
+	}
+
+	if len(cfg.RegistryScopes) == 0 {
+		return nil, fmt.Errorf("no registry scopes")
+	}
+	for _, scope := range cfg.RegistryScopes {
+		if scope == "*" {
+			if len(cfg.RegistryScopes) > 1 {
+				return nil, fmt.Errorf("wildcard registry scope must be the only registry scope")
+			}
+		} else {
+			named, err := reference.ParseNamed(scope)
+			if err != nil || !reference.IsNameOnly(named) {
+				return nil, fmt.Errorf("registry scope %q is not a fully qualified repository", scope)
+			}
+		}
+		policy.scopes = append(policy.scopes, scope)
+	}
+
+	levelActions, ok := notationLevels[policy.level]
+	if !ok {
+		return nil, fmt.Errorf("unknown signature verification level %q", policy.level)
+	}
+	policy.actions = make(map[string]string, len(levelActions))
+	for validation, action := range levelActions {
+		policy.actions[validation] = action
+	}
+	for validation, action := range cfg.SignatureVerification.Override {
+		if policy.level == "skip" {
+			return nil, fmt.Errorf("validations cannot be overridden with verification level skip")
+		}
+		if _, ok := levelActions[validation]; !ok {
+			return nil, fmt.Errorf("unknown validation %q", validation)
+		}
+		if validation == notationIntegrity {
+			return nil, fmt.Errorf("integrity validation cannot be overridden")
+		}
+		if action != notationEnforce && action != notationLog && action != notationSkip {
+			return nil, fmt.Errorf("unknown action %q for validation %q", action, validation)
+		}
+		policy.actions[validation] = action
+	}
+
+	if policy.level == "skip" {
+		if len(cfg.TrustStores) > 0 || len(cfg.TrustedIdentities) > 0 {
+			return nil, fmt.Errorf("trust stores and trusted identities must not be set with verification level skip")
+		}
+		return policy, nil
+	}
+
+	if len(cfg.TrustStores) == 0 {
+		return nil, fmt.Errorf("no trust stores")
+	}
+	for _, name := range cfg.TrustStores {
+		storeType, _, _ := strings.Cut(name, ":")
+		if storeType != notationStoreCA && storeType != notationStoreSigningAuthority {
+			return nil, fmt.Errorf("unsupported trust store %q", name)
+		}
+		certs, ok := trustStores[name]
+		if !ok {
+			return nil, fmt.Errorf("trust store %q is not configured", name)
+		}
+		if policy.roots[storeType] == nil {
+			policy.roots[storeType] = x509.NewCertPool()
+		}
+		if err := addPEMCertificates(policy.roots[storeType], certs); err != nil {
+			return nil, fmt.Errorf("trust store %q: %w", name, err)
+		}
+	}
+
+	if len(cfg.TrustedIdentities) == 0 {
+		return nil, fmt.Errorf("no trusted identities")
+	}
+	for _, identity := range cfg.TrustedIdentities {
+		if identity == "*" {
+			if len(cfg.TrustedIdentities) > 1 {
+				return nil, fmt.Errorf("wildcard trusted identity must be the only trusted identity")
+			}
+			policy.anyone = true
+			continue
+		}
+		dn, ok := strings.CutPrefix(identity, "x509.subject:")
+		if !ok {
+			return nil, fmt.Errorf("unsupported trusted identity %q", identity)
+		}
+		attrs, err := parseDistinguishedName(dn)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted identity %q: %w", identity, err)
+		}
+		for _, required := range []string{"C", "ST", "O"} {
+			if _, ok := attrs[required]; !ok {
+				return nil, fmt.Errorf("trusted identity %q is missing required attribute %s", identity, required)
+			}
+		}
+		policy.identities = append(policy.identities, attrs)
+	}
+
+	return policy, nil
+}
+
+// addPEMCertificates adds all PEM-encoded certificates in certs to pool. At
+// least one certificate is required.
+func addPEMCertificates(pool *x509.CertPool, certs string) error {
+	numCerts := 0
+	rest := []byte(certs)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		pool.AddCert(cert)
+		numCerts += 1
+	}
+	if numCerts == 0 {
+		return fmt.Errorf("no certificates found")
+	}
+	return nil
+}
+
+// parseDistinguishedName parses a distinguished name such as
+// "C=US, ST=WA, O=acme-rockets.io" into a map of attribute names to values.
+// Commas and other special characters in values can be escaped with a
+// backslash.
+func parseDistinguishedName(dn string) (map[string]string, error) {
+	var parts []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range dn {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	parts = append(parts, current.String())
+
+	attrs := map[string]string{}
+	for _, part := range parts {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("malformed attribute %q", strings.TrimSpace(part))
+		}
+		if _, ok := attrs[name]; ok {
+			return nil, fmt.Errorf("duplicate attribute %s", name)
+		}
+		attrs[name] = value
+	}
+	return attrs, nil
+}
+
+// certificateSubject returns the subject attributes of cert that can be used
+// in trusted identities.
+func certificateSubject(cert *x509.Certificate) (map[string]string, error) {
+	attrs := map[string]string{}
+	for _, atv := range cert.Subject.Names {
+		name, ok := notationSubjectAttributes[atv.Type.String()]
+		if !ok {
+			continue
+		}
+		if _, ok := attrs[name]; ok {
+			return nil, fmt.Errorf("certificate subject has duplicate attribute %s", name)
+		}
+		attrs[name] = fmt.Sprint(atv.Value)
+	}
+	return attrs, nil
+}
+
+// policyFor returns the trust policy applying to repository, preferring a
+// policy that names the repository explicitly over the wildcard policy.
+func (nv *NotationVerify) policyFor(repository string) (*notationTrustPolicy, error) {
+	var wildcard *notationTrustPolicy
+	for _, policy := range nv.policies {
+		if slices.Contains(policy.scopes, repository) {
+			return policy, nil
+		}
+		if slices.Contains(policy.scopes, "*") {
+			wildcard = policy
+		}
+	}
+	if wildcard == nil {
+		return nil, fmt.Errorf("no trust policy applies to repository %s", repository)
+	}
+	return wildcard, nil
+}
+
+// notationEnvelope holds the parts of a Notation signature envelope required
+// for verification, independent of the envelope format.
+type notationEnvelope struct {
+	alg                  string
+	signingInput         []byte
+	signature            []byte
+	payload              []byte
+	contentType          string
+	signingScheme        string
+	signingTime          time.Time
+	authenticSigningTime time.Time
+	expiry               time.Time
+	certChain            []*x509.Certificate
+}
+
+// notationPayload is the minimal representation of the signed Notation
+// payload, which binds the signature to the image manifest digest.
+type notationPayload struct {
+	TargetArtifact struct {
+		Digest string `json:"digest"`
+	} `json:"targetArtifact"`
+}
+
+// VerifyNotation verifies that img carries a Notation signature satisfying
+// the trust policy in verifyConfig that applies to the image's repository.
+// Verification succeeds as soon as any one signature satisfies the policy,
+// or immediately if the applicable policy has verification level skip.
+//
+// Revocation of the signing certificates cannot be checked. If a certificate
+// in the chain names an OCSP responder or CRL distribution point, its
+// revocation status is unknown, which is logged but does not fail the
+// revocation validation. RFC 3161 timestamp countersignatures are not evaluated, so the
+// certificates of the notary.x509 signing scheme must be valid at the time
+// of verification for the authenticTimestamp validation to pass.
+//
+// regClient must already have NewRepository called for the image's repository.
+func VerifyNotation(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) error {
+	return verifyPlatforms(ctx, img, verifyConfig, regClient, func(img *ContainerImage) error {
+		return verifyNotation(ctx, img, verifyConfig, regClient)
+	})
+}
+
+// verifyNotation verifies the Notation signature of a single manifest.
+func verifyNotation(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) error {
+	logCtx := log.LoggerFromContext(ctx)
+	imageRef := img.GetFullNameWithTag()
+
+	if verifyConfig.Notation == nil {
+		return fmt.Errorf("no Notation verification policy configured for image %s", imageRef)
+	}
+	if img.ImageTag == nil {
+		return fmt.Errorf("image %s has no tag information", imageRef)
+	}
+
+	named, err := reference.ParseNormalizedNamed(img.GetFullNameWithoutTag())
+	if err != nil {
+		return fmt.Errorf("unable to determine repository of image %s: %w", imageRef, err)
+	}
+	policy, err := verifyConfig.Notation.policyFor(named.Name())
+	if err != nil {
+		return err
+	}
+	if policy.level == "skip" {
+		logCtx.Infof("Skipping Notation signature verification for %s as configured by trust policy %q", imageRef, policy.name)
+		return nil
+	}
+
+	imgDigest, err := resolveManifestDigest(ctx, img.ImageTag, regClient)
+	if err != nil {
+		return err
+	}
+
+	envelopes, err := fetchNotationSignatures(ctx, img.ImageTag.TagName, imgDigest, regClient)
+	if err != nil {
+		return fmt.Errorf("failed to fetch Notation signature for %s: %w", imageRef, err)
+	}
+
+	logCtx.Debugf("Verifying Notation signature for %s with trust policy %q (%d candidate(s))", imageRef, policy.name, len(envelopes))
+	var lastErr error
+	for _, env := range envelopes {
+		err := policy.verify(ctx, imageRef, env, imgDigest, time.Now())
+		if err == nil {
+			logCtx.Infof("Notation signature verified successfully for %s", imageRef)
+			return nil
+		}
+		logCtx.Debugf("Notation signature candidate for %s did not verify: %v", imageRef, err)
+		lastErr = err
+	}
+	return fmt.Errorf("notation signature verification failed for image %s: no matching signature found among %d candidate(s): %w",
+		imageRef, len(envelopes), lastErr)
+}
+
+// fetchNotationSignatures collects the Notation signature envelopes referring
+// to the image manifest imgDigest. The OCI Referrers API is tried first, then
+// the OCI Referrers Tag Schema fallback. Signatures that cannot be fetched or
+// parsed are logged and skipped.
+func fetchNotationSignatures(ctx context.Context, imgTagName string, imgDigest godigest.Digest, regClient RegistryFetcher) ([]*notationEnvelope, error) {
+	logCtx := log.LoggerFromContext(ctx)
+
+	logCtx.Debugf("Fetching OCI referrers for digest %s (tag %q)", imgDigest, imgTagName)
+	referrers, referrersErr := regClient.Referrers(ctx, imgDigest)
+	if referrersErr != nil {
+		logCtx.Debugf("OCI Referrers API unavailable for tag %q (digest %s): %v — will try tag-based fallback",
+			imgTagName, imgDigest, referrersErr)
+	}
+
+	if len(notationReferrers(referrers)) == 0 {
+		fallbackTag := strings.ReplaceAll(imgDigest.String(), ":", "-")
+		logCtx.Debugf("No Notation referrers found for tag %q; trying tag-based fallback %q", imgTagName, fallbackTag)
+		index, err := regClient.ManifestForTag(ctx, fallbackTag)
+		if err != nil {
+			logCtx.Debugf("Tag-based fallback %q not found for tag %q: %v", fallbackTag, imgTagName, err)
+		} else if mediaType, _, err := index.Payload(); err == nil && mediaType == ociImageIndexMediaType {
+			referrers = index.References()
+		}
+	}
+
+	var envelopes []*notationEnvelope
+	for _, ref := range notationReferrers(referrers) {
+		logCtx.Debugf("Found Notation signature artifact at %s for tag %q", ref.Digest, imgTagName)
+		env, err := fetchNotationEnvelope(ctx, ref.Digest, regClient)
+		if err != nil {
+			logCtx.Warnf("error reading Notation signature %s for tag %q: %v — skipping", ref.Digest, imgTagName, err)
+			continue
+		}
+		envelopes = append(envelopes, env)
+	}
+
+	if len(envelopes) == 0 {
+		if referrersErr != nil {
+			return nil, fmt.Errorf("failed to fetch OCI referrers for digest %s: %w", imgDigest, referrersErr)
+		}
+		return nil, fmt.Errorf("no Notation signature found in OCI referrers or tag-based fallback for image tag %q (digest %s)", imgTagName, imgDigest)
+	}
+	return envelopes, nil
+}
+
+// notationReferrers returns the descriptors of Notation signatures in refs
+func notationReferrers(refs []distribution.Descriptor) []distribution.Descriptor {
+	var sigs []distribution.Descriptor
+	for _, ref := range refs {
+		if ref.ArtifactType == notationSignatureType {
+			sigs = append(sigs, ref)
+		}
+	}
+	return sigs
+}
+
+// fetchNotationEnvelope fetches and parses the signature envelope stored in
+// the Notation signature manifest dgst.
+func fetchNotationEnvelope(ctx context.Context, dgst godigest.Digest, regClient RegistryFetcher) (*notationEnvelope, error) {
+	m, err := regClient.ManifestForDigest(ctx, dgst)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching signature manifest: %w", err)
+	}
+	sigManifest, ok := m.(*ocischema.DeserializedManifest)
+	if !ok {
+		return nil, fmt.Errorf("signature manifest is not an OCI image manifest (got %T)", m)
+	}
+	if len(sigManifest.Layers) != 1 {
+		return nil, fmt.Errorf("signature manifest has %d layers, expected exactly one", len(sigManifest.Layers))
+	}
+
+	layer := sigManifest.Layers[0]
+	if layer.MediaType != notationJWSMediaType && layer.MediaType != notationCOSEMediaType {
+		return nil, fmt.Errorf("unsupported signature envelope type %q", layer.MediaType)
+	}
+	blob, err := regClient.BlobContent(ctx, layer.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signature envelope: %w", err)
+	}
+	if layer.MediaType == notationJWSMediaType {
+		return parseJWSEnvelope(blob)
+	}
+	return parseCOSEEnvelope(blob)
+}
+
+// jwsEnvelope is a JWS in flattened JSON serialization, as used by Notation
+type jwsEnvelope struct {
+	Protected string `json:"protected"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+	Header    struct {
+		X5C []string `json:"x5c"`
+	} `json:"header"`
+}
+
+// jwsProtectedHeader is the protected header of a Notation JWS envelope
+type jwsProtectedHeader struct {
+	Alg                  string     `json:"alg"`
+	ContentType          string     `json:"cty"`
+	Critical             []string   `json:"crit"`
+	SigningScheme        string     `json:"io.cncf.notary.signingScheme"`
+	SigningTime          *time.Time `json:"io.cncf.notary.signingTime"`
+	Expiry               *time.Time `json:"io.cncf.notary.expiry"`
+	AuthenticSigningTime *time.Time `json:"io.cncf.notary.authenticSigningTime"`
+}
+
+// parseJWSEnvelope parses a Notation JWS signature envelope
+func parseJWSEnvelope(data []byte) (*notationEnvelope, error) {
+	var jws jwsEnvelope
+	if err := json.Unmarshal(data, &jws); err != nil {
+		return nil, fmt.Errorf("failed to parse JWS envelope: %w", err)
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWS protected header: %w", err)
+	}
+	var rawHeader map[string]json.RawMessage
+	if err := json.Unmarshal(protected, &rawHeader); err != nil {
+		return nil, fmt.Errorf("failed to parse JWS protected header: %w", err)
+	}
+	if _, ok := rawHeader[notationHeaderVerificationPlugin]; ok {
+		return nil, fmt.Errorf("signatures requiring a verification plugin are not supported")
+	}
+	var header jwsProtectedHeader
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse JWS protected header: %w", err)
+	}
+	for _, name := range header.Critical {
+		if err := checkNotationCritical(name); err != nil {
+			return nil, err
+		}
+	}
+
+	env := &notationEnvelope{
+		alg:           header.Alg,
+		signingInput:  []byte(jws.Protected + "." + jws.Payload),
+		contentType:   header.ContentType,
+		signingScheme: header.SigningScheme,
+	}
+	if header.SigningTime != nil {
+		env.signingTime = *header.SigningTime
+	}
+	if header.Expiry != nil {
+		env.expiry = *header.Expiry
+	}
+	if header.AuthenticSigningTime != nil {
+		env.authenticSigningTime = *header.AuthenticSigningTime
+	}
+
+	env.payload, err = base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWS payload: %w", err)
+	}
+	env.signature, err = base64.RawURLEncoding.DecodeString(jws.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWS signature: %w", err)
+	}
+	for _, c := range jws.Header.X5C {
+		der, err := base64.StdEncoding.DecodeString(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode certificate in JWS header: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate in JWS header: %w", err)
+		}
+		env.certChain = append(env.certChain, cert)
+	}
+
+	return env, nil
+}
+
+// coseSign1 is the content of a COSE_Sign1 message
+type coseSign1 struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected map[any]cbor.RawMessage
+	Payload     []byte
+	Signature   []byte
+}
+
+// coseDecMode decodes all CBOR integers as int64, so that header labels can
+// be looked up regardless of their sign.
+var coseDecMode, _ = cbor.DecOptions{IntDec: cbor.IntDecConvertSignedOrFail}.DecMode()
+
+// parseCOSEEnvelope parses a Notation COSE_Sign1 signature envelope
+func parseCOSEEnvelope(data []byte) (*notationEnvelope, error) {
+	var tagged cbor.RawTag
+	if err := coseDecMode.Unmarshal(data, &tagged); err != nil {
+		return nil, fmt.Errorf("failed to parse COSE envelope: %w", err)
+	}
+	if tagged.Number != coseSign1Tag {
+		return nil, fmt.Errorf("COSE envelope is not a COSE_Sign1 message (tag %d)", tagged.Number)
+	}
+	var msg coseSign1
+	if err := coseDecMode.Unmarshal(tagged.Content, &msg); err != nil {
+		return nil, fmt.Errorf("failed to parse COSE_Sign1 message: %w", err)
+	}
+
+	var header map[any]cbor.RawMessage
+	if err := coseDecMode.Unmarshal(msg.Protected, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse COSE protected header: %w", err)
+	}
+	if _, ok := header[notationHeaderVerificationPlugin]; ok {
+		return nil, fmt.Errorf("signatures requiring a verification plugin are not supported")
+	}
+
+	sigStructure, err := cbor.Marshal([]any{"Signature1", msg.Protected, []byte{}, msg.Payload})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode COSE Sig_structure: %w", err)
+	}
+	env := &notationEnvelope{
+		signingInput: sigStructure,
+		signature:    msg.Signature,
+		payload:      msg.Payload,
+	}
+
+	var alg int64
+	if err := decodeCOSEHeader(header, coseHeaderAlgorithm, &alg); err != nil {
+		return nil, err
+	}
+	env.alg = coseAlgorithms[alg]
+	if env.alg == "" {
+		return nil, fmt.Errorf("unsupported COSE algorithm %d", alg)
+	}
+
+	var critical []any
+	if err := decodeCOSEHeader(header, coseHeaderCritical, &critical); err != nil {
+		return nil, err
+	}
+	for _, label := range critical {
+		name, ok := label.(string)
+		if !ok && label != coseHeaderAlgorithm && label != coseHeaderContentType {
+			return nil, fmt.Errorf("unsupported critical header %v", label)
+		}
+		if ok {
+			if err := checkNotationCritical(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	headers := []struct {
+		label any
+		value any
+	}{
+		{coseHeaderContentType, &env.contentType},
+		{notationHeaderSigningScheme, &env.signingScheme},
+		{notationHeaderSigningTime, &env.signingTime},
+		{notationHeaderExpiry, &env.expiry},
+		{notationHeaderAuthenticSigningTime, &env.authenticSigningTime},
+	}
+	for _, h := range headers {
+		if _, ok := header[h.label]; !ok {
+			continue
+		}
+		if err := decodeCOSEHeader(header, h.label, h.value); err != nil {
+			return nil, err
+		}
+	}
+
+	rawChain, ok := msg.Unprotected[coseHeaderX5Chain]
+	if ok {
+		var ders [][]byte
+		if err := coseDecMode.Unmarshal(rawChain, &ders); err != nil {
+			var der []byte
+			if err := coseDecMode.Unmarshal(rawChain, &der); err != nil {
+				return nil, fmt.Errorf("failed to parse COSE certificate chain: %w", err)
+			}
+			ders = [][]byte{der}
+		}
+		for _, der := range ders {
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse certificate in COSE header: %w", err)
+			}
+			env.certChain = append(env.certChain, cert)
+		}
+	}
+
+	return env, nil
+}
+
+// decodeCOSEHeader decodes the header parameter label into v. A missing
+// parameter is left at its zero value.
+func decodeCOSEHeader(header map[any]cbor.RawMessage, label any, v any) error {
+	raw, ok := header[label]
+	if !ok {
+		return nil
+	}
+	if err := coseDecMode.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to parse COSE header %v: %w", label, err)
+	}
+	return nil
+}
+
+// checkNotationCritical returns an error if the critical header name is not
+// understood by the verifier.
+func checkNotationCritical(name string) error {
+	switch name {
+	case notationHeaderSigningScheme, notationHeaderExpiry, notationHeaderAuthenticSigningTime:
+		return nil
+	}
+	return fmt.Errorf("unsupported critical header %q", name)
+}
+
+// verify verifies a single signature envelope against the trust policy at
+// time now. Failed validations are either enforced or logged, depending on
+// the policy.
+func (p *notationTrustPolicy) verify(ctx context.Context, imageRef string, env *notationEnvelope, imgDigest godigest.Digest, now time.Time) error {
+	logCtx := log.LoggerFromContext(ctx)
+
+	// Integrity is enforced at every level except skip, which is handled
+	// by the caller.
+	if err := verifyNotationIntegrity(env, imgDigest); err != nil {
+		return fmt.Errorf("%s validation failed: %w", notationIntegrity, err)
+	}
+
+	check := func(validation string, fn func() error) error {
+		action := p.actions[validation]
+		if action == notationSkip {
+			return nil
+		}
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if action == notationEnforce {
+			return fmt.Errorf("%s validation failed: %w", validation, err)
+		}
+		logCtx.Warnf("Notation %s validation failed for %s, ignoring as configured by trust policy %q: %v", validation, imageRef, p.name, err)
+		return nil
+	}
+
+	chain := env.certChain
+	err := check(notationAuthenticity, func() error {
+		verified, err := p.verifyAuthenticity(env)
+		if err == nil {
+			chain = verified
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = check(notationAuthenticTimestamp, func() error {
+		at := now
+		if env.signingScheme == notationSchemeSigningAuthority {
+			at = env.authenticSigningTime
+		}
+		for _, cert := range chain {
+			if at.Before(cert.NotBefore) || at.After(cert.NotAfter) {
+				return fmt.Errorf("certificate %q is not valid at %s", cert.Subject, at.Format(time.RFC3339))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = check(notationExpiry, func() error {
+		if !env.expiry.IsZero() && now.After(env.expiry) {
+			return fmt.Errorf("signature expired at %s", env.expiry.Format(time.RFC3339))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The OCSP responders and CRL distribution points of the certificates
+	// are not queried, so the revocation status of a certificate naming one
+	// is unknown. As in Notation, an unknown revocation status does not fail
+	// the validation, but is logged if the validation is enforced.
+	logf := logCtx.Debugf
+	switch p.actions[notationRevocation] {
+	case notationSkip:
+		return nil
+	case notationEnforce:
+		logf = logCtx.Warnf
+	}
+	for _, cert := range chain {
+		if len(cert.OCSPServer) > 0 || len(cert.CRLDistributionPoints) > 0 {
+			logf("Revocation status of certificate %q of the Notation signature of %s is unknown, as it cannot be checked", cert.Subject, imageRef)
+		}
+	}
+	return nil
+}
+
+// verifyNotationIntegrity verifies the signature over the envelope with the
+// signing certificate, and that the signed payload refers to imgDigest.
+func verifyNotationIntegrity(env *notationEnvelope, imgDigest godigest.Digest) error {
+	if len(env.certChain) == 0 {
+		return fmt.Errorf("signature envelope has no certificate chain")
+	}
+	switch env.signingScheme {
+	case notationSchemeX509:
+		if env.signingTime.IsZero() {
+			return fmt.Errorf("signature envelope has no signing time")
+		}
+	case notationSchemeSigningAuthority:
+		if env.authenticSigningTime.IsZero() {
+			return fmt.Errorf("signature envelope has no authentic signing time")
+		}
+	default:
+		return fmt.Errorf("unsupported signing scheme %q", env.signingScheme)
+	}
+
+	if err := verifyNotationSignature(env.alg, env.certChain[0].PublicKey, env.signingInput, env.signature); err != nil {
+		return err
+	}
+
+	if env.contentType != notationPayloadType {
+		return fmt.Errorf("unsupported payload content type %q", env.contentType)
+	}
+	var payload notationPayload
+	if err := json.Unmarshal(env.payload, &payload); err != nil {
+		return fmt.Errorf("failed to parse signed payload: %w", err)
+	}
+	if payload.TargetArtifact.Digest != imgDigest.String() {
+		return fmt.Errorf("signed payload refers to %q, expected %q", payload.TargetArtifact.Digest, imgDigest)
+	}
+	return nil
+}
+
+// verifyNotationSignature verifies sig over signingInput with pub using the
+// JWS algorithm alg. ECDSA signatures are expected in the fixed-size r||s
+// encoding shared by JWS and COSE.
+func verifyNotationSignature(alg string, pub crypto.PublicKey, signingInput, sig []byte) error {
+	var hash crypto.Hash
+	var curve elliptic.Curve
+	switch alg {
+	case "ES256":
+		hash, curve = crypto.SHA256, elliptic.P256()
+	case "ES384":
+		hash, curve = crypto.SHA384, elliptic.P384()
+	case "ES512":
+		hash, curve = crypto.SHA512, elliptic.P521()
+	case "PS256":
+		hash = crypto.SHA256
+	case "PS384":
+		hash = crypto.SHA384
+	case "PS512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signingInput)
+	digest := h.Sum(nil)
+
+	if curve != nil {
+		key, ok := pub.(*ecdsa.PublicKey)
+		if !ok || key.Curve != curve {
+			return fmt.Errorf("signing certificate key does not match algorithm %s", alg)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return fmt.Errorf("invalid %s signature length %d", alg, len(sig))
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("signature does not match signing certificate")
+		}
+		return nil
+	}
+
+	key, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("signing certificate key does not match algorithm %s", alg)
+	}
+	if err := rsa.VerifyPSS(key, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+		return fmt.Errorf("signature does not match signing certificate: %w", err)
+	}
+	return nil
+}
+
+// verifyAuthenticity verifies that the certificate chain of env leads to a
+// certificate in one of the policy's trust stores of the type matching the
+// signing scheme, and that the signing certificate has a trusted identity.
+// The chain is verified at the time the signature claims to have been made,
+// its validity at other times is checked by the authenticTimestamp
+// validation. The verified chain is returned.
+func (p *notationTrustPolicy) verifyAuthenticity(env *notationEnvelope) ([]*x509.Certificate, error) {
+	storeType, at := notationStoreCA, env.signingTime
+	if env.signingScheme == notationSchemeSigningAuthority {
+		storeType, at = notationStoreSigningAuthority, env.authenticSigningTime
+	}
+	roots := p.roots[storeType]
+	if roots == nil {
+		return nil, fmt.Errorf("trust policy %q has no trust store of type %s for signing scheme %s", p.name, storeType, env.signingScheme)
+	}
+
+	leaf := env.certChain[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range env.certChain[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   at,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("certificate chain is not trusted: %w", err)
+	}
+
+	if p.anyone {
+		return chains[0], nil
+	}
+	subject, err := certificateSubject(leaf)
+	if err != nil {
+		return nil, err
+	}
+	for _, identity := range p.identities {
+		if matchesIdentity(identity, subject) {
+			return chains[0], nil
+		}
+	}
+	return nil, fmt.Errorf("signing certificate subject %q is not a trusted identity", leaf.Subject)
+}
+
+// matchesIdentity returns true if every attribute of identity has the same
+// value in subject.
+func matchesIdentity(identity, subject map[string]string) bool {
+	for name, value := range identity {
+		if subject[name] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,556 @@
+package image
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	"github.com/fxamacker/cbor/v2"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTrustedIdentity = "x509.subject: C=US, ST=WA, O=acme-rockets.io"
+
+// testNotary is a minimal certificate authority for creating Notation
+// signatures
+type testNotary struct {
+	rootPEM string
+	root    *x509.Certificate
+	rootKey *ecdsa.PrivateKey
+}
+
+func newTestNotary(t *testing.T) *testNotary {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "acme-rockets CA", Organization: []string{"acme-rockets.io"}},
+		NotBefore:             time.Now().Add(-48 * time.Hour),
+		NotAfter:              time.Now().Add(48 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &rootKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	root, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testNotary{
+		rootPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		root:    root,
+		rootKey: rootKey,
+	}
+}
+
+// issueCertificate issues a code signing certificate for pub, valid between
+// notBefore and notAfter, and returns the DER-encoded chain. The certificate
+// can be changed with configure before it is issued.
+func (n *testNotary) issueCertificate(t *testing.T, pub any, notBefore, notAfter time.Time, configure ...func(*x509.Certificate)) [][]byte {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject: pkix.Name{
+			Country:      []string{"US"},
+			Province:     []string{"WA"},
+			Locality:     []string{"Seattle"},
+			Organization: []string{"acme-rockets.io"},
+			CommonName:   "SecureBuilder",
+		},
+		NotBefore:   notBefore,
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	for _, fn := range configure {
+		fn(tmpl)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, n.root, pub, n.rootKey)
+	require.NoError(t, err)
+	return [][]byte{der, n.root.Raw}
+}
+
+// notationHeaders are the signed attributes of a test signature
+type notationHeaders struct {
+	scheme      string
+	signingTime time.Time
+	expiry      time.Time
+	crit        []string
+}
+
+func defaultNotationHeaders() notationHeaders {
+	return notationHeaders{
+		scheme:      notationSchemeX509,
+		signingTime: time.Now().Add(-time.Minute),
+		crit:        []string{notationHeaderSigningScheme},
+	}
+}
+
+func notationTestPayload(t *testing.T, imgDigest string) []byte {
+	t.Helper()
+	payload, err := json.Marshal(map[string]any{
+		"targetArtifact": map[string]any{
+			"mediaType": "application/vnd.oci.image.manifest.v1+json",
+			"digest":    imgDigest,
+			"size":      1234,
+		},
+	})
+	require.NoError(t, err)
+	return payload
+}
+
+// signJWS creates a Notation JWS envelope signed with an ES256 key
+func signJWS(t *testing.T, key *ecdsa.PrivateKey, chain [][]byte, imgDigest string, h notationHeaders) []byte {
+	t.Helper()
+	header := map[string]any{
+		"alg":                       "ES256",
+		"cty":                       notationPayloadType,
+		"crit":                      h.crit,
+		notationHeaderSigningScheme: h.scheme,
+		notationHeaderSigningTime:   h.signingTime.Format(time.RFC3339),
+	}
+	if h.scheme == notationSchemeSigningAuthority {
+		header[notationHeaderAuthenticSigningTime] = h.signingTime.Format(time.RFC3339)
+	}
+	if !h.expiry.IsZero() {
+		header[notationHeaderExpiry] = h.expiry.Format(time.RFC3339)
+	}
+	protected, err := json.Marshal(header)
+	require.NoError(t, err)
+
+	protectedB64 := base64.RawURLEncoding.EncodeToString(protected)
+	payloadB64 := base64.RawURLEncoding.EncodeToString(notationTestPayload(t, imgDigest))
+	digest := sha256.Sum256([]byte(protectedB64 + "." + payloadB64))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	x5c := make([]string, 0, len(chain))
+	for _, der := range chain {
+		x5c = append(x5c, base64.StdEncoding.EncodeToString(der))
+	}
+	envelope, err := json.Marshal(map[string]any{
+		"protected": protectedB64,
+		"payload":   payloadB64,
+		"signature": base64.RawURLEncoding.EncodeToString(sig),
+		"header":    map[string]any{"x5c": x5c},
+	})
+	require.NoError(t, err)
+	return envelope
+}
+
+// signCOSE creates a Notation COSE_Sign1 envelope signed with a PS256 key
+func signCOSE(t *testing.T, key *rsa.PrivateKey, chain [][]byte, imgDigest string, h notationHeaders) []byte {
+	t.Helper()
+	crit := make([]any, 0, len(h.crit))
+	for _, c := range h.crit {
+		crit = append(crit, c)
+	}
+	header := map[any]any{
+		coseHeaderAlgorithm:         int64(-37),
+		coseHeaderCritical:          crit,
+		coseHeaderContentType:       notationPayloadType,
+		notationHeaderSigningScheme: h.scheme,
+		notationHeaderSigningTime:   cbor.Tag{Number: 1, Content: h.signingTime.Unix()},
+	}
+	if h.scheme == notationSchemeSigningAuthority {
+		header[notationHeaderAuthenticSigningTime] = cbor.Tag{Number: 1, Content: h.signingTime.Unix()}
+	}
+	if !h.expiry.IsZero() {
+		header[notationHeaderExpiry] = cbor.Tag{Number: 1, Content: h.expiry.Unix()}
+	}
+	protected, err := cbor.Marshal(header)
+	require.NoError(t, err)
+
+	payload := notationTestPayload(t, imgDigest)
+	sigStructure, err := cbor.Marshal([]any{"Signature1", protected, []byte{}, payload})
+	require.NoError(t, err)
+	digest := sha256.Sum256(sigStructure)
+	sig, err := rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	require.NoError(t, err)
+
+	envelope, err := cbor.Marshal(cbor.Tag{
+		Number:  coseSign1Tag,
+		Content: []any{protected, map[any]any{coseHeaderX5Chain: chain}, payload, sig},
+	})
+	require.NoError(t, err)
+	return envelope
+}
+
+// notationTestPolicy returns a trust policy document with a single policy
+// for quay.io/org/app
+func notationTestPolicy(level string, override map[string]string, trustStore, identity string) string {
+	policy := map[string]any{
+		"name":                  "app",
+		"registryScopes":        []string{"quay.io/org/app"},
+		"signatureVerification": map[string]any{"level": level, "override": override},
+		"trustStores":           []string{trustStore},
+		"trustedIdentities":     []string{identity},
+	}
+	doc, _ := json.Marshal(map[string]any{"version": "1.0", "trustPolicies": []any{policy}})
+	return string(doc)
+}
+
+func Test_NewNotationVerify(t *testing.T) {
+	n := newTestNotary(t)
+	stores := map[string]string{"ca:acme": n.rootPEM, "signingAuthority:acme": n.rootPEM}
+
+	t.Run("Valid trust policy", func(t *testing.T) {
+		nv, err := NewNotationVerify(notationTestPolicy("strict", nil, "ca:acme", testTrustedIdentity), stores)
+		require.NoError(t, err)
+		require.Len(t, nv.policies, 1)
+		assert.Equal(t, notationEnforce, nv.policies[0].actions[notationExpiry])
+		assert.Equal(t, map[string]string{"C": "US", "ST": "WA", "O": "acme-rockets.io"}, nv.policies[0].identities[0])
+	})
+
+	t.Run("Override changes the action of a validation", func(t *testing.T) {
+		nv, err := NewNotationVerify(notationTestPolicy("strict", map[string]string{"revocation": "skip"}, "ca:acme", "*"), stores)
+		require.NoError(t, err)
+		assert.Equal(t, notationSkip, nv.policies[0].actions[notationRevocation])
+		assert.True(t, nv.policies[0].anyone)
+	})
+
+	t.Run("Skip level without trust stores", func(t *testing.T) {
+		_, err := NewNotationVerify(`{"version":"1.0","trustPolicies":[{"name":"skip","registryScopes":["*"],"signatureVerification":{"level":"skip"}}]}`, nil)
+		assert.NoError(t, err)
+	})
+
+	tests := []struct {
+		name        string
+		policy      string
+		expectedErr string
+	}{
+		{"invalid JSON", `{`, "failed to parse trust policy"},
+		{"unsupported version", `{"version":"2.0","trustPolicies":[]}`, "unsupported trust policy version"},
+		{"no policies", `{"version":"1.0","trustPolicies":[]}`, "contains no trust policies"},
+		{"unknown level", notationTestPolicy("lenient", nil, "ca:acme", "*"), "unknown signature verification level"},
+		{"integrity override", notationTestPolicy("strict", map[string]string{"integrity": "log"}, "ca:acme", "*"), "integrity validation cannot be overridden"},
+		{"unknown override action", notationTestPolicy("strict", map[string]string{"expiry": "warn"}, "ca:acme", "*"), "unknown action"},
+		{"unknown trust store", notationTestPolicy("strict", nil, "ca:other", "*"), `trust store "ca:other" is not configured`},
+		{"unsupported trust store type", notationTestPolicy("strict", nil, "tsa:acme", "*"), "unsupported trust store"},
+		{"identity missing required attribute", notationTestPolicy("strict", nil, "ca:acme", "x509.subject: C=US, O=acme-rockets.io"), "missing required attribute ST"},
+		{"unsupported identity", notationTestPolicy("strict", nil, "ca:acme", "builder@acme-rockets.io"), "unsupported trusted identity"},
+		{"skip with trust stores", notationTestPolicy("skip", nil, "ca:acme", "*"), "must not be set with verification level skip"},
+		{"unqualified scope", `{"version":"1.0","trustPolicies":[{"name":"p","registryScopes":["nginx"],"signatureVerification":{"level":"skip"}}]}`, "not a fully qualified repository"},
+		{"scope with tag", `{"version":"1.0","trustPolicies":[{"name":"p","registryScopes":["quay.io/org/app:1.0"],"signatureVerification":{"level":"skip"}}]}`, "not a fully qualified repository"},
+		{"wildcard with other scopes", `{"version":"1.0","trustPolicies":[{"name":"p","registryScopes":["*","quay.io/org/app"],"signatureVerification":{"level":"skip"}}]}`, "wildcard registry scope must be the only"},
+		{"duplicate scope", `{"version":"1.0","trustPolicies":[{"name":"a","registryScopes":["*"],"signatureVerification":{"level":"skip"}},{"name":"b","registryScopes":["*"],"signatureVerification":{"level":"skip"}}]}`, `used by trust policies "a" and "b"`},
+		{"duplicate name", `{"version":"1.0","trustPolicies":[{"name":"a","registryScopes":["*"],"signatureVerification":{"level":"skip"}},{"name":"a","registryScopes":["quay.io/org/app"],"signatureVerification":{"level":"skip"}}]}`, `duplicate trust policy "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNotationVerify(tt.policy, stores)
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func Test_parseDistinguishedName(t *testing.T) {
+	t.Run("Attributes with escaped comma", func(t *testing.T) {
+		attrs, err := parseDistinguishedName(`C=US, ST=WA, O=acme\, Inc.`)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"C": "US", "ST": "WA", "O": "acme, Inc."}, attrs)
+	})
+	t.Run("Malformed attribute", func(t *testing.T) {
+		_, err := parseDistinguishedName("C=US, WA")
+		assert.ErrorContains(t, err, "malformed attribute")
+	})
+	t.Run("Duplicate attribute", func(t *testing.T) {
+		_, err := parseDistinguishedName("C=US, C=DE")
+		assert.ErrorContains(t, err, "duplicate attribute C")
+	})
+}
+
+func Test_NotationVerify_policyFor(t *testing.T) {
+	nv, err := NewNotationVerify(`{"version":"1.0","trustPolicies":[
+		{"name":"default","registryScopes":["*"],"signatureVerification":{"level":"skip"}},
+		{"name":"nginx","registryScopes":["docker.io/library/nginx"],"signatureVerification":{"level":"skip"}}]}`, nil)
+	require.NoError(t, err)
+
+	policy, err := nv.policyFor("docker.io/library/nginx")
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", policy.name)
+
+	policy, err = nv.policyFor("quay.io/org/app")
+	require.NoError(t, err)
+	assert.Equal(t, "default", policy.name)
+
+	nv.policies = nv.policies[1:]
+	_, err = nv.policyFor("quay.io/org/app")
+	assert.ErrorContains(t, err, "no trust policy applies to repository quay.io/org/app")
+}
+
+func Test_VerifyNotation(t *testing.T) {
+	ctx := context.Background()
+	const (
+		imgManifestDigest = "sha256:ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234"
+		sigArtifactDigest = "sha256:eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678"
+	)
+	n := newTestNotary(t)
+	stores := map[string]string{"ca:acme": n.rootPEM, "signingAuthority:acme": n.rootPEM}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecChain := n.issueCertificate(t, &ecKey.PublicKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaChain := n.issueCertificate(t, &rsaKey.PublicKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+
+	newVerify := func(t *testing.T, level string, override map[string]string, trustStore, identity string) *Verify {
+		t.Helper()
+		nv, err := NewNotationVerify(notationTestPolicy(level, override, trustStore, identity), stores)
+		require.NoError(t, err)
+		return &Verify{Notation: nv}
+	}
+	sigManifest := func(mediaType string, blob []byte) distribution.Manifest {
+		return &ocischema.DeserializedManifest{
+			Manifest: ocischema.Manifest{
+				Layers: []distribution.Descriptor{{MediaType: mediaType, Digest: godigest.FromBytes(blob), Size: int64(len(blob))}},
+			},
+		}
+	}
+	newFetcher := func(mediaType string, blob []byte) *mockFetcher {
+		return &mockFetcher{
+			referrers: map[string][]distribution.Descriptor{
+				imgManifestDigest: {{ArtifactType: notationSignatureType, Digest: godigest.Digest(sigArtifactDigest)}},
+			},
+			manifests: map[string]distribution.Manifest{sigArtifactDigest: sigManifest(mediaType, blob)},
+			blobs:     map[string][]byte{godigest.FromBytes(blob).String(): blob},
+		}
+	}
+
+	t.Run("JWS signature verifies successfully", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		blob := signJWS(t, ecKey, ecChain, imgManifestDigest, defaultNotationHeaders())
+		err := VerifyNotation(ctx, img, newVerify(t, "strict", nil, "ca:acme", testTrustedIdentity), newFetcher(notationJWSMediaType, blob))
+		assert.NoError(t, err)
+	})
+
+	t.Run("COSE signature verifies successfully", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		blob := signCOSE(t, rsaKey, rsaChain, imgManifestDigest, defaultNotationHeaders())
+		err := VerifyNotation(ctx, img, newVerify(t, "strict", nil, "ca:acme", testTrustedIdentity), newFetcher(notationCOSEMediaType, blob))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Signing authority scheme requires signingAuthority trust store", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		h := defaultNotationHeaders()
+		h.scheme = notationSchemeSigningAuthority
+		h.crit = append(h.crit, notationHeaderAuthenticSigningTime)
+		blob := signCOSE(t, rsaKey, rsaChain, imgManifestDigest, h)
+		err := VerifyNotation(ctx, img, newVerify(t, "strict", nil, "signingAuthority:acme", "*"), newFetcher(notationCOSEMediaType, blob))
+		assert.NoError(t, err)
+		err = VerifyNotation(ctx, img, newVerify(t, "strict", nil, "ca:acme", "*"), newFetcher(notationCOSEMediaType, blob))
+		assert.ErrorContains(t, err, "no trust store of type signingAuthority")
+	})
+
+	t.Run("Signature found via tag-based fallback", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		blob := signJWS(t, ecKey, ecChain, imgManifestDigest, defaultNotationHeaders())
+		fetcher := newFetcher(notationJWSMediaType, blob)
+		fetcher.referrers = nil
+		fetcher.manifests["sha256-ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234"] = &mockIndex{
+			refs: []distribution.Descriptor{
+				bundleReferrer("sha256:aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234aabb1234"),
+				{ArtifactType: notationSignatureType, Digest: godigest.Digest(sigArtifactDigest)},
+			},
+		}
+		err := VerifyNotation(ctx, img, newVerify(t, "strict", nil, "ca:acme", testTrustedIdentity), fetcher)
+		assert.NoError(t, err)
+	})
+
+	t.Run("No signature blocks the update", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		err := VerifyNotation(ctx, img, newVerify(t, "strict", nil, "ca:acme", "*"), &mockFetcher{})
+		assert.ErrorContains(t, err, "no Notation signature found")
+	})
+
+	t.Run("Skip level does not fetch signatures", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", "")
+		nv, err := NewNotationVerify(`{"version":"1.0","trustPolicies":[{"name":"p","registryScopes":["quay.io/org/app"],"signatureVerification":{"level":"skip"}}]}`, nil)
+		require.NoError(t, err)
+		err = VerifyNotation(ctx, img, &Verify{Notation: nv}, &mockFetcher{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("No applicable trust policy", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		img.ImageName = "org/other"
+		blob := signJWS(t, ecKey, ecChain, imgManifestDigest, defaultNotationHeaders())
+		err := VerifyNotation(ctx, img, newVerify(t, "strict", nil, "ca:acme", "*"), newFetcher(notationJWSMediaType, blob))
+		assert.ErrorContains(t, err, "no trust policy applies to repository quay.io/org/other")
+	})
+
+	t.Run("No Notation policy", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		err := VerifyNotation(ctx, img, &Verify{}, &mockFetcher{})
+		assert.ErrorContains(t, err, "no Notation verification policy")
+	})
+
+	otherNotary := newTestNotary(t)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherChain := otherNotary.issueCertificate(t, &otherKey.PublicKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	expiredChain := n.issueCertificate(t, &ecKey.PublicKey, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	revocableChain := n.issueCertificate(t, &ecKey.PublicKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), func(cert *x509.Certificate) {
+		cert.OCSPServer = []string{"http://ocsp.acme-rockets.io"}
+		cert.CRLDistributionPoints = []string{"http://crl.acme-rockets.io/ca.crl"}
+	})
+
+	expired := defaultNotationHeaders()
+	expired.expiry = time.Now().Add(-time.Minute)
+	expired.crit = append(expired.crit, notationHeaderExpiry)
+	signedWithExpiredCert := defaultNotationHeaders()
+	signedWithExpiredCert.signingTime = time.Now().Add(-90 * time.Minute)
+	unknownCritical := defaultNotationHeaders()
+	unknownCritical.crit = append(unknownCritical.crit, "io.cncf.acme.custom")
+
+	tests := []struct {
+		name        string
+		blob        func(t *testing.T) []byte
+		level       string
+		override    map[string]string
+		identity    string
+		expectedErr string
+	}{
+		{
+			name: "Signature for another image",
+			blob: func(t *testing.T) []byte {
+				return signJWS(t, ecKey, ecChain, sigArtifactDigest, defaultNotationHeaders())
+			},
+			level:       "audit",
+			expectedErr: "integrity validation failed: signed payload refers to",
+		},
+		{
+			name: "Tampered signature",
+			blob: func(t *testing.T) []byte {
+				var env map[string]any
+				require.NoError(t, json.Unmarshal(signJWS(t, ecKey, ecChain, imgManifestDigest, defaultNotationHeaders()), &env))
+				env["payload"] = base64.RawURLEncoding.EncodeToString(notationTestPayload(t, imgManifestDigest+" "))
+				b, err := json.Marshal(env)
+				require.NoError(t, err)
+				return b
+			},
+			level:       "audit",
+			expectedErr: "integrity validation failed: signature does not match",
+		},
+		{
+			name: "Untrusted certificate authority",
+			blob: func(t *testing.T) []byte {
+				return signJWS(t, otherKey, otherChain, imgManifestDigest, defaultNotationHeaders())
+			},
+			level:       "strict",
+			expectedErr: "authenticity validation failed: certificate chain is not trusted",
+		},
+		{
+			name: "Untrusted certificate authority passes in audit level",
+			blob: func(t *testing.T) []byte {
+				return signJWS(t, otherKey, otherChain, imgManifestDigest, defaultNotationHeaders())
+			},
+			level: "audit",
+		},
+		{
+			name: "Untrusted identity",
+			blob: func(t *testing.T) []byte {
+				return signJWS(t, ecKey, ecChain, imgManifestDigest, defaultNotationHeaders())
+			},
+			level:       "strict",
+			identity:    "x509.subject: C=US, ST=WA, O=wabbit-networks.io",
+			expectedErr: "is not a trusted identity",
+		},
+		{
+			name:        "Expired signature",
+			blob:        func(t *testing.T) []byte { return signJWS(t, ecKey, ecChain, imgManifestDigest, expired) },
+			level:       "strict",
+			expectedErr: "expiry validation failed: signature expired",
+		},
+		{
+			name:  "Expired signature passes in permissive level",
+			blob:  func(t *testing.T) []byte { return signJWS(t, ecKey, ecChain, imgManifestDigest, expired) },
+			level: "permissive",
+		},
+		{
+			name: "Expired signing certificate",
+			blob: func(t *testing.T) []byte {
+				return signJWS(t, ecKey, expiredChain, imgManifestDigest, signedWithExpiredCert)
+			},
+			level:       "strict",
+			expectedErr: "authenticTimestamp validation failed",
+		},
+		{
+			name: "Expired signing certificate passes with override",
+			blob: func(t *testing.T) []byte {
+				return signJWS(t, ecKey, expiredChain, imgManifestDigest, signedWithExpiredCert)
+			},
+			level:    "strict",
+			override: map[string]string{"authenticTimestamp": "log"},
+		},
+		{
+			name: "Unknown revocation status passes in strict level",
+			blob: func(t *testing.T) []byte {
+				return signJWS(t, ecKey, revocableChain, imgManifestDigest, defaultNotationHeaders())
+			},
+			level: "strict",
+		},
+		{
+			name: "Unknown revocation status passes in permissive level",
+			blob: func(t *testing.T) []byte {
+				return signJWS(t, ecKey, revocableChain, imgManifestDigest, defaultNotationHeaders())
+			},
+			level: "permissive",
+		},
+		{
+			name:        "Unknown critical header",
+			blob:        func(t *testing.T) []byte { return signJWS(t, ecKey, ecChain, imgManifestDigest, unknownCritical) },
+			level:       "strict",
+			expectedErr: "no Notation signature found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity := tt.identity
+			if identity == "" {
+				identity = testTrustedIdentity
+			}
+			img := newTestImageTag("1.0.21", imgManifestDigest)
+			err := VerifyNotation(ctx, img, newVerify(t, tt.level, tt.override, "ca:acme", identity), newFetcher(notationJWSMediaType, tt.blob(t)))
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func Test_verifyNotationSignature(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, verifyNotationSignature("ES256", &ecKey.PublicKey, []byte("input"), make([]byte, 64)),
+		"does not match algorithm ES256")
+	assert.ErrorContains(t, verifyNotationSignature("ES384", &ecKey.PublicKey, []byte("input"), make([]byte, 64)),
+		"invalid ES384 signature length 64")
+	assert.ErrorContains(t, verifyNotationSignature("RS256", &ecKey.PublicKey, []byte("input"), nil),
+		`unsupported signature algorithm "RS256"`)
+	assert.ErrorContains(t, verifyNotationSignature("PS256", &ecKey.PublicKey, []byte("input"), nil),
+		"does not match algorithm PS256")
+}
@@ -28,6 +28,10 @@ func (img *ContainerImage) ParseUpdateStrategy(ctx context.Context, val string)
 		return StrategyAlphabetical
 	case "digest":
 		return StrategyDigest
+	case "calver":
+		return StrategyCalVer
+	case "label":
+		return StrategyLabel
 	default:
 		logCtx.Warnf("Unknown sort option %s -- using semver", val)
 		return StrategySemVer
@@ -61,6 +65,13 @@ func (img *ContainerImage) ParseMatch(ctx context.Context, val string) (MatchFun
 			return MatchFuncNone, nil
 		}
 		return MatchFuncRegexp, re
+	case "cel":
+		cm, err := NewCELMatcher(opt[1])
+		if err != nil {
+			log.Warnf("Could not compile CEL expression '%s': %v", opt[1], err)
+			return MatchFuncNone, nil
+		}
+		return MatchFuncCEL, cm
 	default:
 		log.Warnf("Unknown match function: %s", opt[0])
 		return MatchFuncNone, nil
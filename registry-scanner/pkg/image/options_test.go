@@ -130,4 +130,13 @@ func Test_ContainerImage_ParseMatch(t *testing.T) {
 	matchFunc, pattern = img.ParseMatch(ctx, "regexp:[aA-zZ") //invalid regexp: missing end ]
 	assert.False(t, matchFunc("MatchFuncNone-tag-name", pattern))
 	assert.Nil(t, pattern)
+
+	matchFunc, pattern = img.ParseMatch(ctx, "cel:tag.startsWith('v1.')")
+	assert.True(t, matchFunc("v1.2.0", pattern))
+	assert.False(t, matchFunc("v2.0.0", pattern))
+	assert.IsType(t, &CELMatcher{}, pattern)
+
+	matchFunc, pattern = img.ParseMatch(ctx, "cel:tag.startsWith(") //invalid CEL: missing end )
+	assert.False(t, matchFunc("MatchFuncNone-tag-name", pattern))
+	assert.Nil(t, pattern)
 }
@@ -0,0 +1,161 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	godigest "github.com/opencontainers/go-digest"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/options"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+// PlatformVerification selects which manifests of a multi-platform image
+// must carry a valid signature.
+type PlatformVerification string
+
+const (
+	// PlatformVerificationIndex verifies the signature of the image index,
+	// which binds the digests of the platform manifests it lists. The index
+	// must list a manifest for each of the verified platforms. This is the
+	// default.
+	PlatformVerificationIndex PlatformVerification = "index"
+	// PlatformVerificationPerPlatform verifies the signature of the manifest
+	// of each of the verified platforms.
+	PlatformVerificationPerPlatform PlatformVerification = "per-platform"
+	// PlatformVerificationBoth verifies the signatures of the image index and
+	// of the manifest of each of the verified platforms.
+	PlatformVerificationBoth PlatformVerification = "both"
+)
+
+// ParsePlatformVerification returns the PlatformVerification named by s. An
+// empty string selects PlatformVerificationIndex.
+func ParsePlatformVerification(s string) (PlatformVerification, error) {
+	switch pv := PlatformVerification(s); pv {
+	case "":
+		return PlatformVerificationIndex, nil
+	case PlatformVerificationIndex, PlatformVerificationPerPlatform, PlatformVerificationBoth:
+		return pv, nil
+	}
+	return "", fmt.Errorf("unknown platform verification %q", s)
+}
+
+// platformManifest is the manifest of a single platform of an image index
+type platformManifest struct {
+	platform string
+	digest   godigest.Digest
+}
+
+// verifyPlatforms verifies the signatures of img that the platform
+// verification policy of verifyConfig requires, using verify to verify the
+// signature of a single manifest. Images that are not multi-platform only
+// have their own manifest verified.
+func verifyPlatforms(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher, verify func(img *ContainerImage) error) error {
+	logCtx := log.LoggerFromContext(ctx)
+	imageRef := img.GetFullNameWithTag()
+
+	mode := verifyConfig.PlatformVerification
+	if (mode == "" || mode == PlatformVerificationIndex) && len(verifyConfig.Platforms) == 0 {
+		return verify(img)
+	}
+	if img.ImageTag == nil {
+		return fmt.Errorf("image %s has no tag information", imageRef)
+	}
+
+	imgDigest, err := resolveManifestDigest(ctx, img.ImageTag, regClient)
+	if err != nil {
+		return err
+	}
+	manifests, isIndex, err := platformManifests(ctx, imgDigest, verifyConfig.Platforms, regClient)
+	if err != nil {
+		return fmt.Errorf("unable to determine platform manifests of image %s: %w", imageRef, err)
+	}
+	if !isIndex {
+		logCtx.Debugf("Image %s is not a multi-platform image, verifying its manifest only", imageRef)
+		return verify(img)
+	}
+
+	if mode != PlatformVerificationPerPlatform {
+		if err := verify(img); err != nil {
+			return err
+		}
+	}
+	if mode == PlatformVerificationPerPlatform || mode == PlatformVerificationBoth {
+		for _, m := range manifests {
+			logCtx.Debugf("Verifying signature of platform %s manifest %s of %s", m.platform, m.digest, imageRef)
+			platformImg := img.WithTag(&tag.ImageTag{TagName: img.ImageTag.TagName, ManifestDigest: m.digest.String()})
+			if err := verify(platformImg); err != nil {
+				return fmt.Errorf("platform %s: %w", m.platform, err)
+			}
+		}
+	}
+	return nil
+}
+
+// platformManifests returns the manifests of the given platforms listed by
+// the image index imgDigest, or of all of its platforms if none are given.
+// A platform without variant also selects the manifests of its variants.
+// isIndex is false if imgDigest is not an image index. An error is returned
+// if the index lists no manifest for one of the given platforms.
+func platformManifests(ctx context.Context, imgDigest godigest.Digest, platforms []string, regClient RegistryFetcher) (manifests []platformManifest, isIndex bool, err error) {
+	m, err := regClient.ManifestForDigest(ctx, imgDigest)
+	if err != nil {
+		return nil, false, fmt.Errorf("error fetching manifest %s: %w", imgDigest, err)
+	}
+	var refs []distribution.Descriptor
+	switch index := m.(type) {
+	case *ocischema.DeserializedImageIndex:
+		refs = index.References()
+	case *manifestlist.DeserializedManifestList:
+		refs = index.References()
+	default:
+		return nil, false, nil
+	}
+
+	// Manifests of unknown platform hold attestations of the image, rather
+	// than the image itself.
+	refs = slices.DeleteFunc(refs, func(ref distribution.Descriptor) bool {
+		return ref.Platform == nil || ref.Platform.OS == "unknown"
+	})
+
+	if len(platforms) == 0 {
+		for _, ref := range refs {
+			manifests = append(manifests, platformManifest{
+				platform: options.PlatformKey(ref.Platform.OS, ref.Platform.Architecture, ref.Platform.Variant),
+				digest:   ref.Digest,
+			})
+		}
+		return manifests, true, nil
+	}
+
+	for _, platform := range platforms {
+		os, arch, variant, err := ParsePlatform(platform)
+		if err != nil {
+			return nil, true, err
+		}
+		wants := options.NewManifestOptions().WithPlatform(os, arch, variant)
+		found := false
+		for _, ref := range refs {
+			if !wants.WantsPlatform(ref.Platform.OS, ref.Platform.Architecture, ref.Platform.Variant) {
+				continue
+			}
+			found = true
+			if slices.ContainsFunc(manifests, func(m platformManifest) bool { return m.digest == ref.Digest }) {
+				continue
+			}
+			manifests = append(manifests, platformManifest{
+				platform: options.PlatformKey(ref.Platform.OS, ref.Platform.Architecture, ref.Platform.Variant),
+				digest:   ref.Digest,
+			})
+		}
+		if !found {
+			return nil, true, fmt.Errorf("image index %s lists no manifest for platform %s", imgDigest, platform)
+		}
+	}
+	return manifests, true, nil
+}
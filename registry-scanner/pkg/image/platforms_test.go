@@ -0,0 +1,212 @@
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	godigest "github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	amd64ManifestDigest = "sha256:aaaa1111aaaa1111aaaa1111aaaa1111aaaa1111aaaa1111aaaa1111aaaa1111"
+	arm64ManifestDigest = "sha256:bbbb2222bbbb2222bbbb2222bbbb2222bbbb2222bbbb2222bbbb2222bbbb2222"
+	attManifestDigest   = "sha256:cccc3333cccc3333cccc3333cccc3333cccc3333cccc3333cccc3333cccc3333"
+)
+
+// newTestIndex returns a multi-platform image index for linux/amd64 and
+// linux/arm64/v8, with an attestation manifest, and its digest
+func newTestIndex(t *testing.T) (*ocischema.DeserializedImageIndex, string) {
+	t.Helper()
+	index, err := ocischema.FromDescriptors([]v1.Descriptor{
+		{MediaType: v1.MediaTypeImageManifest, Digest: amd64ManifestDigest, Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}},
+		{MediaType: v1.MediaTypeImageManifest, Digest: arm64ManifestDigest, Platform: &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+		{MediaType: v1.MediaTypeImageManifest, Digest: attManifestDigest, Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"}},
+	}, nil)
+	require.NoError(t, err)
+	_, payload, err := index.Payload()
+	require.NoError(t, err)
+	return index, godigest.FromBytes(payload).String()
+}
+
+// signedIndexFetcher returns a fetcher serving index, and a signature made
+// with kp for each of the signed manifest digests
+func signedIndexFetcher(t *testing.T, kp testKeyPair, index *ocischema.DeserializedImageIndex, indexDigest string, signed ...string) *mockFetcher {
+	t.Helper()
+	fetcher := &mockFetcher{
+		referrers: map[string][]distribution.Descriptor{},
+		manifests: map[string]distribution.Manifest{indexDigest: index},
+		blobs:     map[string][]byte{},
+	}
+	for _, dgst := range signed {
+		sigManifest, blobDigest, blob := makeDSSEBundle(t, kp.priv, dgst)
+		artifact := godigest.FromString("sig-" + dgst).String()
+		fetcher.referrers[dgst] = []distribution.Descriptor{bundleReferrer(artifact)}
+		fetcher.manifests[artifact] = sigManifest
+		fetcher.blobs[blobDigest] = blob
+	}
+	return fetcher
+}
+
+func Test_ParsePlatformVerification(t *testing.T) {
+	pv, err := ParsePlatformVerification("")
+	require.NoError(t, err)
+	assert.Equal(t, PlatformVerificationIndex, pv)
+	pv, err = ParsePlatformVerification("per-platform")
+	require.NoError(t, err)
+	assert.Equal(t, PlatformVerificationPerPlatform, pv)
+	_, err = ParsePlatformVerification("all")
+	assert.EqualError(t, err, `unknown platform verification "all"`)
+}
+
+func Test_platformManifests(t *testing.T) {
+	ctx := context.Background()
+	index, indexDigest := newTestIndex(t)
+	fetcher := &mockFetcher{manifests: map[string]distribution.Manifest{indexDigest: index}}
+
+	t.Run("All platforms", func(t *testing.T) {
+		manifests, isIndex, err := platformManifests(ctx, godigest.Digest(indexDigest), nil, fetcher)
+		require.NoError(t, err)
+		assert.True(t, isIndex)
+		assert.Equal(t, []platformManifest{
+			{platform: "linux/amd64", digest: amd64ManifestDigest},
+			{platform: "linux/arm64/v8", digest: arm64ManifestDigest},
+		}, manifests)
+	})
+
+	t.Run("Selected platforms", func(t *testing.T) {
+		manifests, _, err := platformManifests(ctx, godigest.Digest(indexDigest), []string{"linux/arm64", "linux/arm64/v8"}, fetcher)
+		require.NoError(t, err)
+		assert.Equal(t, []platformManifest{{platform: "linux/arm64/v8", digest: arm64ManifestDigest}}, manifests)
+	})
+
+	t.Run("Missing platform", func(t *testing.T) {
+		_, _, err := platformManifests(ctx, godigest.Digest(indexDigest), []string{"linux/amd64", "linux/s390x"}, fetcher)
+		assert.ErrorContains(t, err, "lists no manifest for platform linux/s390x")
+	})
+
+	t.Run("Single manifest", func(t *testing.T) {
+		fetcher := &mockFetcher{manifests: map[string]distribution.Manifest{amd64ManifestDigest: &ocischema.DeserializedManifest{}}}
+		manifests, isIndex, err := platformManifests(ctx, amd64ManifestDigest, []string{"linux/amd64"}, fetcher)
+		require.NoError(t, err)
+		assert.False(t, isIndex)
+		assert.Empty(t, manifests)
+	})
+}
+
+func Test_VerifyWithPublicKey_Platforms(t *testing.T) {
+	ctx := context.Background()
+	kp := newTestKeyPair(t)
+	index, indexDigest := newTestIndex(t)
+
+	tests := []struct {
+		name    string
+		mode    PlatformVerification
+		signed  []string
+		wantErr string
+	}{
+		{
+			name:   "index signature",
+			mode:   PlatformVerificationIndex,
+			signed: []string{indexDigest},
+		},
+		{
+			name:    "index signature missing",
+			mode:    PlatformVerificationIndex,
+			signed:  []string{amd64ManifestDigest, arm64ManifestDigest},
+			wantErr: "no cosign signature found",
+		},
+		{
+			name:   "per-platform signatures",
+			mode:   PlatformVerificationPerPlatform,
+			signed: []string{amd64ManifestDigest, arm64ManifestDigest},
+		},
+		{
+			name:    "per-platform signature missing",
+			mode:    PlatformVerificationPerPlatform,
+			signed:  []string{indexDigest, amd64ManifestDigest},
+			wantErr: "platform linux/arm64/v8: failed to fetch cosign signature",
+		},
+		{
+			name:   "index and per-platform signatures",
+			mode:   PlatformVerificationBoth,
+			signed: []string{indexDigest, amd64ManifestDigest, arm64ManifestDigest},
+		},
+		{
+			name:    "index signature missing with both",
+			mode:    PlatformVerificationBoth,
+			signed:  []string{amd64ManifestDigest, arm64ManifestDigest},
+			wantErr: "no cosign signature found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := newTestImageTag("1.0.21", indexDigest)
+			verifyConfig := &Verify{CosignKey: kp.pemPub, PlatformVerification: tt.mode}
+			err := VerifyWithPublicKey(ctx, img, verifyConfig, signedIndexFetcher(t, kp, index, indexDigest, tt.signed...))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Selected platforms only", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", indexDigest)
+		verifyConfig := &Verify{CosignKey: kp.pemPub, PlatformVerification: PlatformVerificationPerPlatform, Platforms: []string{"linux/amd64"}}
+		err := VerifyWithPublicKey(ctx, img, verifyConfig, signedIndexFetcher(t, kp, index, indexDigest, amd64ManifestDigest))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Index must list selected platforms", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", indexDigest)
+		verifyConfig := &Verify{CosignKey: kp.pemPub, Platforms: []string{"linux/ppc64le"}}
+		err := VerifyWithPublicKey(ctx, img, verifyConfig, signedIndexFetcher(t, kp, index, indexDigest, indexDigest))
+		assert.ErrorContains(t, err, "lists no manifest for platform linux/ppc64le")
+	})
+
+	t.Run("Single manifest image", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", amd64ManifestDigest)
+		verifyConfig := &Verify{CosignKey: kp.pemPub, PlatformVerification: PlatformVerificationPerPlatform}
+		fetcher := signedIndexFetcher(t, kp, index, indexDigest, amd64ManifestDigest)
+		fetcher.manifests[amd64ManifestDigest] = &ocischema.DeserializedManifest{}
+		assert.NoError(t, VerifyWithPublicKey(ctx, img, verifyConfig, fetcher))
+	})
+}
+
+func Test_VerifyWithPublicKeys_Platforms(t *testing.T) {
+	ctx := context.Background()
+	build := newTestKeyPair(t)
+	scanner := newTestKeyPair(t)
+	index, indexDigest := newTestIndex(t)
+
+	// build signed all manifests, scanner the amd64 manifest only
+	fetcher := signedIndexFetcher(t, build, index, indexDigest, amd64ManifestDigest, arm64ManifestDigest)
+	sigManifest, blobDigest, blob := makeDSSEBundle(t, scanner.priv, amd64ManifestDigest)
+	artifact := godigest.FromString("scanner-sig").String()
+	fetcher.referrers[amd64ManifestDigest] = append(fetcher.referrers[amd64ManifestDigest], bundleReferrer(artifact))
+	fetcher.manifests[artifact] = sigManifest
+	fetcher.blobs[blobDigest] = blob
+
+	img := newTestImageTag("1.0.21", indexDigest)
+	verifyConfig := &Verify{
+		CosignKeys:           []NamedPublicKey{{Name: "build", Key: build.pemPub}, {Name: "scanner", Key: scanner.pemPub}},
+		Threshold:            1,
+		PlatformVerification: PlatformVerificationPerPlatform,
+	}
+	signers, err := VerifyWithPublicKeys(ctx, img, verifyConfig, fetcher)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build"}, signers)
+
+	verifyConfig.Threshold = 2
+	_, err = VerifyWithPublicKeys(ctx, newTestImageTag("1.0.21", indexDigest), verifyConfig, fetcher)
+	var thresholdErr *ThresholdError
+	require.ErrorAs(t, err, &thresholdErr)
+	assert.Equal(t, []string{"scanner"}, thresholdErr.Missing)
+	assert.ErrorContains(t, err, "platform linux/arm64/v8")
+}
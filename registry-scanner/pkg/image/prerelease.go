@@ -0,0 +1,102 @@
+package image
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Names of the pre-release channels that can be used as a pre-release policy
+const (
+	// PrereleaseNone allows no pre-release versions
+	PrereleaseNone = "none"
+	// PrereleaseRC allows release candidates
+	PrereleaseRC = "rc"
+	// PrereleaseBeta allows beta versions and release candidates
+	PrereleaseBeta = "beta"
+	// PrereleaseAlpha allows alpha and beta versions and release candidates
+	PrereleaseAlpha = "alpha"
+	// PrereleaseAny allows all pre-release versions
+	PrereleaseAny = "any"
+)
+
+// prereleaseChannels maps the name of a channel to the identifiers it allows,
+// from the most to the least stable
+var prereleaseChannels = map[string][]string{
+	PrereleaseNone:  {},
+	PrereleaseRC:    {"rc"},
+	PrereleaseBeta:  {"rc", "beta"},
+	PrereleaseAlpha: {"rc", "beta", "alpha"},
+}
+
+// prereleaseIdentifierRegexp matches the leading identifier of a pre-release,
+// e.g. "rc" in "rc.1", "rc1" or "rc-1"
+var prereleaseIdentifierRegexp = regexp.MustCompile(`^[A-Za-z]+`)
+
+// PrereleasePolicy defines which pre-release versions are allowed for an
+// update, independently of the version constraint. Use ParsePrereleasePolicy
+// to initialize a new object.
+type PrereleasePolicy struct {
+	// Identifiers holds the allowed pre-release identifiers, e.g. "rc". An
+	// empty list allows no pre-release versions.
+	Identifiers []string
+	// Any allows all pre-release versions
+	Any bool
+}
+
+// ParsePrereleasePolicy parses a pre-release policy. The policy is either a
+// single channel, one of none, rc, beta, alpha or any, or a list of allowed
+// pre-release identifiers. Returns nil if vals is empty, which leaves the
+// decision about pre-release versions to the version constraint.
+func ParsePrereleasePolicy(vals []string) (*PrereleasePolicy, error) {
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	if len(vals) == 1 {
+		channel := strings.ToLower(strings.TrimSpace(vals[0]))
+		if channel == PrereleaseAny {
+			return &PrereleasePolicy{Any: true}, nil
+		}
+		if ids, ok := prereleaseChannels[channel]; ok {
+			return &PrereleasePolicy{Identifiers: ids}, nil
+		}
+	}
+
+	pp := &PrereleasePolicy{Identifiers: make([]string, 0, len(vals))}
+	for _, val := range vals {
+		id := strings.ToLower(strings.TrimSpace(val))
+		if id == PrereleaseNone || id == PrereleaseAny {
+			return nil, fmt.Errorf("pre-release channel %s cannot be combined with other identifiers", id)
+		}
+		if id == "" || prereleaseIdentifierRegexp.FindString(id) != id {
+			return nil, fmt.Errorf("invalid pre-release identifier %q, must only consist of letters", val)
+		}
+		pp.Identifiers = append(pp.Identifiers, id)
+	}
+	return pp, nil
+}
+
+// String returns the string representation of the pre-release policy
+func (pp *PrereleasePolicy) String() string {
+	if pp.Any {
+		return PrereleaseAny
+	}
+	if len(pp.Identifiers) == 0 {
+		return PrereleaseNone
+	}
+	return strings.Join(pp.Identifiers, ",")
+}
+
+// Allows returns true if the policy allows version ver. Versions that are not
+// a pre-release are always allowed. A pre-release is allowed if its leading
+// identifier, e.g. "rc" in "1.2.3-rc.1", is one of the allowed identifiers.
+func (pp *PrereleasePolicy) Allows(ver *semver.Version) bool {
+	if ver.Prerelease() == "" || pp.Any {
+		return true
+	}
+	id := strings.ToLower(prereleaseIdentifierRegexp.FindString(ver.Prerelease()))
+	return id != "" && slices.Contains(pp.Identifiers, id)
+}
@@ -0,0 +1,91 @@
+package image
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParsePrereleasePolicy(t *testing.T) {
+	t.Run("No policy", func(t *testing.T) {
+		pp, err := ParsePrereleasePolicy(nil)
+		require.NoError(t, err)
+		assert.Nil(t, pp)
+	})
+
+	tests := []struct {
+		input    []string
+		expected *PrereleasePolicy
+	}{
+		{[]string{"none"}, &PrereleasePolicy{Identifiers: []string{}}},
+		{[]string{"rc"}, &PrereleasePolicy{Identifiers: []string{"rc"}}},
+		{[]string{"Beta"}, &PrereleasePolicy{Identifiers: []string{"rc", "beta"}}},
+		{[]string{"alpha"}, &PrereleasePolicy{Identifiers: []string{"rc", "beta", "alpha"}}},
+		{[]string{"any"}, &PrereleasePolicy{Any: true}},
+		{[]string{"preview"}, &PrereleasePolicy{Identifiers: []string{"preview"}}},
+		{[]string{"rc", "preview"}, &PrereleasePolicy{Identifiers: []string{"rc", "preview"}}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.input, ","), func(t *testing.T) {
+			pp, err := ParsePrereleasePolicy(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pp)
+		})
+	}
+
+	t.Run("Channel combined with identifiers", func(t *testing.T) {
+		_, err := ParsePrereleasePolicy([]string{"rc", "any"})
+		assert.ErrorContains(t, err, "cannot be combined")
+	})
+
+	t.Run("Invalid identifier", func(t *testing.T) {
+		_, err := ParsePrereleasePolicy([]string{"rc", "rc.1"})
+		assert.ErrorContains(t, err, "invalid pre-release identifier")
+		_, err = ParsePrereleasePolicy([]string{"rc", ""})
+		assert.ErrorContains(t, err, "invalid pre-release identifier")
+	})
+}
+
+func Test_PrereleasePolicy_String(t *testing.T) {
+	for _, input := range []string{"none", "rc", "any"} {
+		pp, err := ParsePrereleasePolicy([]string{input})
+		require.NoError(t, err)
+		assert.Equal(t, input, pp.String())
+	}
+	pp, err := ParsePrereleasePolicy([]string{"beta"})
+	require.NoError(t, err)
+	assert.Equal(t, "rc,beta", pp.String())
+}
+
+func Test_PrereleasePolicy_Allows(t *testing.T) {
+	tests := []struct {
+		policy   string
+		version  string
+		expected bool
+	}{
+		{"none", "1.2.3", true},
+		{"none", "1.2.3-rc.1", false},
+		{"rc", "1.2.3-rc.1", true},
+		{"rc", "1.2.3-RC1", true},
+		{"rc", "1.2.3-beta.1", false},
+		{"beta", "1.2.3-rc.1", true},
+		{"beta", "1.2.3-beta.2", true},
+		{"beta", "1.2.3-alpha.1", false},
+		{"alpha", "1.2.3-alpha.1", true},
+		{"alpha", "1.2.3-preview.1", false},
+		{"preview", "1.2.3-preview.1", true},
+		{"any", "1.2.3-snapshot", true},
+		{"any", "1.2.3-0", true},
+		{"rc", "1.2.3-0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy+"/"+tt.version, func(t *testing.T) {
+			pp, err := ParsePrereleasePolicy([]string{tt.policy})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pp.Allows(semver.MustParse(tt.version)))
+		})
+	}
+}
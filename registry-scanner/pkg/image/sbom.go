@@ -0,0 +1,332 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/distribution/distribution/v3"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+)
+
+// Artifact and media types of SPDX SBOMs. CycloneDX SBOMs share their type
+// with CycloneDX vulnerability reports.
+const (
+	spdxArtifactType = "application/spdx+json"
+	spdxMediaType    = "text/spdx+json"
+)
+
+// spdxPredicateType is the predicate type of in-toto attestations holding an
+// SPDX SBOM
+const spdxPredicateType = "https://spdx.dev/Document"
+
+// sbomTagSuffix is the suffix of the tag "cosign attach sbom" stores the SBOM
+// of an image at, e.g. "sha256-<hex>.sbom"
+const sbomTagSuffix = ".sbom"
+
+// ErrNoSBOM is returned by FetchSBOM when no SBOM is attached to an image
+var ErrNoSBOM = errors.New("no SBOM found")
+
+// SBOMPolicy defines the software bill of materials an image must have to be
+// considered for an update. Use NewSBOMPolicy to initialize a new object.
+type SBOMPolicy struct {
+	deniedLicenses []string
+}
+
+// NewSBOMPolicy creates an SBOM policy that requires an SBOM, and denies the
+// licenses matching any of deniedLicenses. These are SPDX license
+// identifiers, or glob patterns of them such as "AGPL-*", and are matched
+// case-insensitively.
+func NewSBOMPolicy(deniedLicenses []string) (*SBOMPolicy, error) {
+	sp := &SBOMPolicy{}
+	for _, pattern := range deniedLicenses {
+		pattern = strings.ToLower(pattern)
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid denied license pattern %q: %w", pattern, err)
+		}
+		sp.deniedLicenses = append(sp.deniedLicenses, pattern)
+	}
+	return sp, nil
+}
+
+// SBOM is the summary of the SBOMs attached to an image
+type SBOM struct {
+	// Licenses are the distinct licenses of the packages in the SBOMs,
+	// sorted. A license expression such as "MIT OR Apache-2.0" contributes
+	// each of the licenses it refers to.
+	Licenses []string
+	// Documents is the number of SBOMs the licenses were collected from
+	Documents int
+}
+
+// LicenseError is returned by SBOMPolicy.Check when an SBOM contains
+// denied licenses
+type LicenseError struct {
+	// Denied are the denied licenses found in the SBOM, sorted
+	Denied []string
+}
+
+func (e *LicenseError) Error() string {
+	return "denied licenses: " + strings.Join(e.Denied, ", ")
+}
+
+// Check returns a *LicenseError if sbom contains denied licenses
+func (sp *SBOMPolicy) Check(sbom *SBOM) error {
+	var denied []string
+	for _, license := range sbom.Licenses {
+		if sp.denies(license) {
+			denied = append(denied, license)
+		}
+	}
+	if len(denied) > 0 {
+		return &LicenseError{Denied: denied}
+	}
+	return nil
+}
+
+// denies returns true if license matches any of the denied licenses
+func (sp *SBOMPolicy) denies(license string) bool {
+	license = strings.ToLower(license)
+	return slices.ContainsFunc(sp.deniedLicenses, func(pattern string) bool {
+		match, err := filepath.Match(pattern, license)
+		return err == nil && match
+	})
+}
+
+// FetchSBOM fetches the SBOMs attached to img and collects the licenses of
+// the packages they list. SPDX and CycloneDX SBOMs in JSON format are
+// supported, attached as OCI referrers, as attestations in a sigstore bundle,
+// or with "cosign attach sbom". The SBOMs are read as they are, their
+// signatures are not verified.
+//
+// Returns an error wrapping ErrNoSBOM if img has no SBOM.
+//
+// regClient must already have NewRepository called for the image's repository.
+func FetchSBOM(ctx context.Context, img *ContainerImage, regClient RegistryFetcher) (*SBOM, error) {
+	logCtx := log.LoggerFromContext(ctx)
+	imgTagName := img.ImageTag.TagName
+
+	imgDigest, err := resolveManifestDigest(ctx, img.ImageTag, regClient)
+	if err != nil {
+		return nil, err
+	}
+	digestTag := strings.ReplaceAll(imgDigest.String(), ":", "-")
+
+	logCtx.Debugf("Fetching OCI referrers for digest %s (tag %q)", imgDigest, imgTagName)
+	referrers, referrersErr := regClient.Referrers(ctx, imgDigest)
+	if referrersErr != nil {
+		logCtx.Debugf("OCI Referrers API unavailable for tag %q (digest %s): %v — will try tag-based fallback",
+			imgTagName, imgDigest, referrersErr)
+	}
+
+	if len(sbomReferrers(referrers)) == 0 {
+		logCtx.Debugf("No SBOM referrers found for tag %q; trying tag-based fallback %q", imgTagName, digestTag)
+		index, err := regClient.ManifestForTag(ctx, digestTag)
+		if err != nil {
+			logCtx.Debugf("Tag-based fallback %q not found for tag %q: %v", digestTag, imgTagName, err)
+		} else if mediaType, _, err := index.Payload(); err == nil && mediaType == ociImageIndexMediaType {
+			referrers = index.References()
+		}
+	}
+
+	sbom := &SBOM{}
+	licenses := map[string]bool{}
+	addLicenses := func(found []string) {
+		sbom.Documents += 1
+		for _, license := range found {
+			licenses[license] = true
+		}
+	}
+
+	for _, ref := range sbomReferrers(referrers) {
+		blob, err := fetchArtifactBlob(ctx, ref, regClient)
+		if err == nil && ref.ArtifactType == sigstoreBundleType {
+			blob, err = attestedSBOM(blob)
+		}
+		if err != nil {
+			logCtx.Warnf("error reading SBOM %s for tag %q: %v — skipping", ref.Digest, imgTagName, err)
+			continue
+		}
+		if blob == nil {
+			continue
+		}
+		found, ok, err := parseSBOM(blob)
+		if err != nil {
+			logCtx.Warnf("error reading SBOM %s for tag %q: %v — skipping", ref.Digest, imgTagName, err)
+			continue
+		}
+		if ok {
+			logCtx.Debugf("Found SBOM at %s with %d licenses for tag %q", ref.Digest, len(found), imgTagName)
+			addLicenses(found)
+		}
+	}
+
+	// cosign attach sbom stores the SBOM as a layer of an image manifest
+	// tagged after the image's digest
+	if sbom.Documents == 0 {
+		attachTag := digestTag + sbomTagSuffix
+		logCtx.Debugf("No SBOM referrers found for tag %q; trying cosign attachment tag %q", imgTagName, attachTag)
+		m, err := regClient.ManifestForTag(ctx, attachTag)
+		if err != nil {
+			logCtx.Debugf("Cosign attachment tag %q not found for tag %q: %v", attachTag, imgTagName, err)
+		} else {
+			for _, layer := range m.References() {
+				if layer.MediaType != spdxMediaType && layer.MediaType != spdxArtifactType && layer.MediaType != cycloneDXArtifactType {
+					continue
+				}
+				blob, err := regClient.BlobContent(ctx, layer.Digest)
+				if err != nil {
+					logCtx.Warnf("error fetching SBOM %s for tag %q: %v — skipping", layer.Digest, imgTagName, err)
+					continue
+				}
+				found, ok, err := parseSBOM(blob)
+				if err != nil {
+					logCtx.Warnf("error reading SBOM %s for tag %q: %v — skipping", layer.Digest, imgTagName, err)
+					continue
+				}
+				if ok {
+					logCtx.Debugf("Found attached SBOM %s with %d licenses for tag %q", layer.Digest, len(found), imgTagName)
+					addLicenses(found)
+				}
+			}
+		}
+	}
+
+	if sbom.Documents == 0 {
+		if referrersErr != nil {
+			return nil, fmt.Errorf("failed to fetch OCI referrers for digest %s: %w", imgDigest, referrersErr)
+		}
+		return nil, fmt.Errorf("%w in OCI referrers or tag-based fallbacks for image tag %q (digest %s)", ErrNoSBOM, imgTagName, imgDigest)
+	}
+	for license := range licenses {
+		sbom.Licenses = append(sbom.Licenses, license)
+	}
+	slices.Sort(sbom.Licenses)
+	return sbom, nil
+}
+
+// sbomReferrers returns the descriptors of refs that may hold an SBOM
+func sbomReferrers(refs []distribution.Descriptor) []distribution.Descriptor {
+	var sboms []distribution.Descriptor
+	for _, ref := range refs {
+		switch ref.ArtifactType {
+		case spdxArtifactType, spdxMediaType, cycloneDXArtifactType, sigstoreBundleType:
+			sboms = append(sboms, ref)
+		}
+	}
+	return sboms
+}
+
+// attestedSBOM returns the SBOM held by the in-toto attestation in a
+// sigstore bundle, or nil if the bundle does not hold an SBOM.
+func attestedSBOM(blob []byte) ([]byte, error) {
+	predicateType, predicate, err := attestationPredicate(blob)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(predicateType, spdxPredicateType) || predicateType == cycloneDXPredicateType {
+		return predicate, nil
+	}
+	return nil, nil
+}
+
+// sbomDocument holds the fields of SPDX and CycloneDX documents that carry
+// the licenses of their packages
+type sbomDocument struct {
+	// SPDX
+	SPDXVersion string `json:"spdxVersion"`
+	Packages    []struct {
+		LicenseConcluded string `json:"licenseConcluded"`
+		LicenseDeclared  string `json:"licenseDeclared"`
+	} `json:"packages"`
+	// CycloneDX
+	BOMFormat  string                `json:"bomFormat"`
+	Components *[]cycloneDXComponent `json:"components"`
+	Metadata   struct {
+		Component *cycloneDXComponent `json:"component"`
+	} `json:"metadata"`
+}
+
+// cycloneDXComponent is a component of a CycloneDX document
+type cycloneDXComponent struct {
+	Licenses []struct {
+		License struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// parseSBOM returns the licenses of the packages in an SPDX or CycloneDX
+// document. ok is false if data is a CycloneDX document without components,
+// such as a VEX document.
+func parseSBOM(data []byte) (licenses []string, ok bool, err error) {
+	var doc sbomDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false, fmt.Errorf("failed to parse SBOM: %w", err)
+	}
+
+	switch {
+	case doc.SPDXVersion != "":
+		for _, pkg := range doc.Packages {
+			licenses = append(licenses, licenseExpressionIDs(pkg.LicenseConcluded)...)
+			licenses = append(licenses, licenseExpressionIDs(pkg.LicenseDeclared)...)
+		}
+		return licenses, true, nil
+	case doc.BOMFormat == "CycloneDX":
+		if doc.Components == nil && doc.Metadata.Component == nil {
+			return nil, false, nil
+		}
+		var components []cycloneDXComponent
+		if doc.Metadata.Component != nil {
+			components = append(components, *doc.Metadata.Component)
+		}
+		if doc.Components != nil {
+			components = append(components, *doc.Components...)
+		}
+		for len(components) > 0 {
+			c := components[0]
+			components = append(components[1:], c.Components...)
+			for _, l := range c.Licenses {
+				switch {
+				case l.Expression != "":
+					licenses = append(licenses, licenseExpressionIDs(l.Expression)...)
+				case l.License.ID != "":
+					licenses = append(licenses, l.License.ID)
+				case l.License.Name != "":
+					licenses = append(licenses, l.License.Name)
+				}
+			}
+		}
+		return licenses, true, nil
+	}
+	return nil, false, fmt.Errorf("unsupported SBOM format")
+}
+
+// licenseExpressionIDs returns the licenses an SPDX license expression such
+// as "(MIT OR GPL-2.0-only WITH Classpath-exception-2.0)" refers to. License
+// exceptions, NONE and NOASSERTION are left out.
+func licenseExpressionIDs(expr string) []string {
+	var ids []string
+	fields := strings.FieldsFunc(expr, func(r rune) bool {
+		return r == '(' || r == ')' || unicode.IsSpace(r)
+	})
+	for i := 0; i < len(fields); i++ {
+		switch strings.ToUpper(fields[i]) {
+		case "AND", "OR", "NONE", "NOASSERTION":
+		case "WITH":
+			i++
+		default:
+			ids = append(ids, fields[i])
+		}
+	}
+	return ids
+}
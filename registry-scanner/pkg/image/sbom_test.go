@@ -0,0 +1,204 @@
+package image
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spdxSBOM = `{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {"name": "musl", "licenseConcluded": "MIT", "licenseDeclared": "MIT"},
+    {"name": "readline", "licenseConcluded": "NOASSERTION", "licenseDeclared": "GPL-3.0-or-later"},
+    {"name": "openjdk", "licenseDeclared": "(GPL-2.0-only WITH Classpath-exception-2.0 OR Apache-2.0)"},
+    {"name": "unknown", "licenseConcluded": "NONE"}
+  ]
+}`
+
+const cycloneDXSBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"name": "app", "licenses": [{"license": {"id": "Apache-2.0"}}]}},
+  "components": [
+    {"name": "busybox", "licenses": [{"license": {"id": "GPL-2.0-only"}}], "components": [
+      {"name": "nested", "licenses": [{"expression": "BSD-3-Clause AND MIT"}]}
+    ]},
+    {"name": "vendored", "licenses": [{"license": {"name": "Custom License"}}]}
+  ]
+}`
+
+func Test_parseSBOM(t *testing.T) {
+	tests := []struct {
+		name    string
+		sbom    string
+		want    []string
+		wantOK  bool
+		wantErr string
+	}{
+		{
+			name:   "SPDX document",
+			sbom:   spdxSBOM,
+			want:   []string{"MIT", "MIT", "GPL-3.0-or-later", "GPL-2.0-only", "Apache-2.0"},
+			wantOK: true,
+		},
+		{
+			name:   "CycloneDX document with nested components",
+			sbom:   cycloneDXSBOM,
+			want:   []string{"Apache-2.0", "GPL-2.0-only", "Custom License", "BSD-3-Clause", "MIT"},
+			wantOK: true,
+		},
+		{
+			name:   "CycloneDX document without licenses",
+			sbom:   `{"bomFormat": "CycloneDX", "components": []}`,
+			wantOK: true,
+		},
+		{
+			name: "CycloneDX VEX document",
+			sbom: cycloneDXVEXReport,
+		},
+		{
+			name:    "Unsupported format",
+			sbom:    trivyJSONReport,
+			wantErr: "unsupported SBOM format",
+		},
+		{
+			name:    "Invalid JSON",
+			sbom:    `not json`,
+			wantErr: "failed to parse SBOM",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			licenses, ok, err := parseSBOM([]byte(tt.sbom))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, licenses)
+		})
+	}
+}
+
+func Test_SBOMPolicy_Check(t *testing.T) {
+	sbom := &SBOM{Licenses: []string{"AGPL-3.0-only", "Apache-2.0", "GPL-3.0-or-later", "MIT"}, Documents: 1}
+
+	t.Run("Denied licenses", func(t *testing.T) {
+		sp, err := NewSBOMPolicy([]string{"agpl-*", "GPL-3.0-or-later", "SSPL-1.0"})
+		require.NoError(t, err)
+		err = sp.Check(sbom)
+		var licenseErr *LicenseError
+		require.ErrorAs(t, err, &licenseErr)
+		assert.Equal(t, []string{"AGPL-3.0-only", "GPL-3.0-or-later"}, licenseErr.Denied)
+		assert.EqualError(t, err, "denied licenses: AGPL-3.0-only, GPL-3.0-or-later")
+	})
+
+	t.Run("No denied licenses", func(t *testing.T) {
+		sp, err := NewSBOMPolicy([]string{"SSPL-1.0"})
+		require.NoError(t, err)
+		assert.NoError(t, sp.Check(sbom))
+	})
+
+	t.Run("SBOM required only", func(t *testing.T) {
+		sp, err := NewSBOMPolicy(nil)
+		require.NoError(t, err)
+		assert.NoError(t, sp.Check(sbom))
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := NewSBOMPolicy([]string{"GPL-[2"})
+		assert.ErrorContains(t, err, "invalid denied license pattern")
+	})
+}
+
+func Test_FetchSBOM(t *testing.T) {
+	ctx := context.Background()
+	const imgManifestDigest = "sha256:ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234"
+
+	t.Run("SBOMs are read from referrers", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		fetcher := reportFetcher(imgManifestDigest, map[string]string{
+			spdxSBOM:           spdxArtifactType,
+			cycloneDXVEXReport: cycloneDXArtifactType,
+			trivySARIFReport:   sarifArtifactType,
+		})
+		sbom, err := FetchSBOM(ctx, img, fetcher)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sbom.Documents)
+		assert.Equal(t, []string{"Apache-2.0", "GPL-2.0-only", "GPL-3.0-or-later", "MIT"}, sbom.Licenses)
+	})
+
+	t.Run("SBOM attestation", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://cyclonedx.org/bom",`+
+			`"subject":[{"digest":{"sha256":"%s"}}],"predicate":%s}`, godigest.Digest(imgManifestDigest).Encoded(), cycloneDXSBOM)
+		bundle, err := json.Marshal(sigstoreBundle{
+			DSSEEnvelope: &dsseEnvelope{
+				Payload:     base64.StdEncoding.EncodeToString([]byte(statement)),
+				PayloadType: inTotoPayloadType,
+			},
+		})
+		require.NoError(t, err)
+		kp := newTestKeyPair(t)
+		_, _, signature := makeDSSEBundle(t, kp.priv, imgManifestDigest)
+		sbom, err := FetchSBOM(ctx, img, bundleFetcher(imgManifestDigest, signature, bundle))
+		require.NoError(t, err)
+		assert.Equal(t, 1, sbom.Documents)
+		assert.Equal(t, []string{"Apache-2.0", "BSD-3-Clause", "Custom License", "GPL-2.0-only", "MIT"}, sbom.Licenses)
+	})
+
+	t.Run("SBOM attached with cosign", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		blob := []byte(spdxSBOM)
+		fetcher := &mockFetcher{
+			manifests: map[string]distribution.Manifest{
+				strings.ReplaceAll(imgManifestDigest, ":", "-") + ".sbom": &ocischema.DeserializedManifest{
+					Manifest: ocischema.Manifest{
+						Layers: []distribution.Descriptor{{MediaType: spdxMediaType, Digest: godigest.FromBytes(blob), Size: int64(len(blob))}},
+					},
+				},
+			},
+			blobs: map[string][]byte{godigest.FromBytes(blob).String(): blob},
+		}
+		sbom, err := FetchSBOM(ctx, img, fetcher)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sbom.Documents)
+		assert.Contains(t, sbom.Licenses, "GPL-3.0-or-later")
+	})
+
+	t.Run("No SBOM", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		fetcher := reportFetcher(imgManifestDigest, map[string]string{cycloneDXVEXReport: cycloneDXArtifactType})
+		_, err := FetchSBOM(ctx, img, fetcher)
+		assert.ErrorIs(t, err, ErrNoSBOM)
+	})
+
+	t.Run("Referrers error is propagated", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		fetcher := &mockFetcher{
+			referrerErrors: map[string]error{imgManifestDigest: fmt.Errorf("registry timeout")},
+		}
+		_, err := FetchSBOM(ctx, img, fetcher)
+		assert.ErrorContains(t, err, "registry timeout")
+		assert.NotErrorIs(t, err, ErrNoSBOM)
+	})
+}
+
+func Test_licenseExpressionIDs(t *testing.T) {
+	assert.Equal(t, []string{"MIT"}, licenseExpressionIDs("MIT"))
+	assert.Equal(t, []string{"GPL-2.0-only", "Apache-2.0"}, licenseExpressionIDs("(GPL-2.0-only WITH Classpath-exception-2.0 OR Apache-2.0)"))
+	assert.Equal(t, []string{"LGPL-2.1+", "BSD-3-Clause"}, licenseExpressionIDs("LGPL-2.1+ and BSD-3-Clause"))
+	assert.Empty(t, licenseExpressionIDs("NOASSERTION"))
+	assert.Empty(t, licenseExpressionIDs(""))
+}
@@ -9,7 +9,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/distribution/distribution/v3"
@@ -46,8 +48,58 @@ const simpleSigningSigAnnotation = "dev.cosignproject.cosign/signature"
 type Verify struct {
 	// CosignKey is the PEM-encoded ECDSA public key.
 	CosignKey string
+	// CosignKeys are the public keys of several signers, of which at least
+	// Threshold must have signed the image.
+	CosignKeys []NamedPublicKey
+	// Threshold is the number of CosignKeys that must have signed the image.
+	// Zero means all of them.
+	Threshold int
+	// Keyless is the policy for cosign keyless verification. Use
+	// NewKeylessVerify to initialize it.
+	Keyless *KeylessVerify
+	// Notation is the policy for Notation signature verification. Use
+	// NewNotationVerify to initialize it.
+	Notation *NotationVerify
+	// Provenance is the policy for SLSA provenance attestations, which are
+	// verified in addition to the signature. Use NewProvenancePolicy to
+	// initialize it.
+	Provenance *ProvenancePolicy
+	// PlatformVerification selects which manifests of a multi-platform image
+	// must be signed. Empty means PlatformVerificationIndex.
+	PlatformVerification PlatformVerification
+	// Platforms are the platforms whose manifests are verified, e.g.
+	// "linux/amd64". All platforms of the image are verified if empty.
+	Platforms []string
 }
 
+// NamedPublicKey is the PEM-encoded ECDSA public key of a named signer.
+type NamedPublicKey struct {
+	// Name identifies the signer in logs and errors.
+	Name string
+	// Key is the PEM-encoded ECDSA public key.
+	Key string
+}
+
+// ThresholdError is returned by VerifyWithPublicKeys when fewer signers than
+// required have signed an image.
+type ThresholdError struct {
+	// Matched are the names of the signers that have signed the image.
+	Matched []string
+	// Missing are the names of the signers that have not signed the image.
+	Missing []string
+	// Threshold is the number of signers required.
+	Threshold int
+}
+
+func (e *ThresholdError) Error() string {
+	return fmt.Sprintf("%d of %d required signatures found, missing signatures from %s",
+		len(e.Matched), e.Threshold, strings.Join(e.Missing, ", "))
+}
+
+// errNoSignature is returned by fetchTagSignatures when the registry has no
+// cosign signature for an image.
+var errNoSignature = errors.New("no cosign signature found")
+
 // RegistryFetcher is the subset of registry.RegistryClient required for
 // signature verification. The concrete *registry.registryClient satisfies
 // this interface automatically — no import of the registry package needed here.
@@ -74,9 +126,12 @@ type RegistryFetcher interface {
 // ---------------------------------------------------------------------------
 
 // sigstoreBundle is a minimal representation of the sigstore bundle v0.3 JSON.
-// We only need the DSSE envelope for key-based verification.
+// Key-based verification only needs the DSSE envelope, while keyless
+// verification also needs the verification material, which is kept verbatim
+// and parsed by verifyKeylessSignature.
 type sigstoreBundle struct {
-	DSSEEnvelope *dsseEnvelope `json:"dsseEnvelope,omitempty"`
+	VerificationMaterial json.RawMessage `json:"verificationMaterial,omitempty"`
+	DSSEEnvelope         *dsseEnvelope   `json:"dsseEnvelope,omitempty"`
 }
 
 // dsseEnvelope is the Dead Simple Signing Envelope stored inside the bundle.
@@ -127,24 +182,26 @@ type inTotoStatement struct {
 // the network fetch is skipped and the cached candidates are used directly.
 // Verification succeeds as soon as any one candidate matches the configured key.
 //
+// For a multi-platform image, the image index, the manifests of the platforms
+// in verifyConfig, or both are verified, as selected by
+// verifyConfig.PlatformVerification. This applies to all verification
+// methods.
+//
 // regClient must already have NewRepository called for the image's repository.
 func VerifyWithPublicKey(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) error {
+	return verifyPlatforms(ctx, img, verifyConfig, regClient, func(img *ContainerImage) error {
+		return verifyWithPublicKey(ctx, img, verifyConfig, regClient)
+	})
+}
+
+// verifyWithPublicKey verifies the cosign signature of a single manifest
+// with the public key in verifyConfig.
+func verifyWithPublicKey(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) error {
 	logCtx := log.LoggerFromContext(ctx)
 	imageRef := img.GetFullNameWithTag()
 
-	if img.ImageTag == nil {
-		return fmt.Errorf("image %s has no tag information", imageRef)
-	}
-
-	if len(img.ImageTag.TagSignatures) == 0 {
-		logCtx.Debugf("Fetching cosign signature for %s", imageRef)
-		sigs, err := fetchTagSignatures(ctx, img.ImageTag, regClient)
-		if err != nil {
-			return fmt.Errorf("failed to fetch cosign signature for %s: %w", imageRef, err)
-		}
-		img.ImageTag.TagSignatures = sigs
-	} else {
-		logCtx.Debugf("Using %d cached cosign signature candidate(s) for %s", len(img.ImageTag.TagSignatures), imageRef)
+	if err := loadTagSignatures(ctx, img, regClient); err != nil {
+		return err
 	}
 
 	logCtx.Debugf("Verifying cosign signature for %s (%d candidate(s))", imageRef, len(img.ImageTag.TagSignatures))
@@ -158,6 +215,105 @@ func VerifyWithPublicKey(ctx context.Context, img *ContainerImage, verifyConfig
 		imageRef, len(img.ImageTag.TagSignatures))
 }
 
+// VerifyWithPublicKeys verifies that img was signed with at least
+// verifyConfig.Threshold of the public keys in verifyConfig.CosignKeys, or
+// with all of them if no threshold is set. The names of the signers whose
+// signature was found are returned. If too few signers have signed the image,
+// a *ThresholdError naming the missing signers is returned.
+//
+// As with VerifyWithPublicKey, cached signatures on img.ImageTag are used if
+// present. If several manifests of a multi-platform image are verified, the
+// signers that have signed all of them are returned.
+//
+// regClient must already have NewRepository called for the image's repository.
+func VerifyWithPublicKeys(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) ([]string, error) {
+	var signers []string
+	verified := 0
+	err := verifyPlatforms(ctx, img, verifyConfig, regClient, func(img *ContainerImage) error {
+		matched, err := verifyWithPublicKeys(ctx, img, verifyConfig, regClient)
+		if verified == 0 {
+			signers = matched
+		} else {
+			signers = slices.DeleteFunc(signers, func(name string) bool { return !slices.Contains(matched, name) })
+		}
+		verified += 1
+		return err
+	})
+	return signers, err
+}
+
+// verifyWithPublicKeys verifies the cosign signatures of a single manifest
+// with the public keys of the signers in verifyConfig.
+func verifyWithPublicKeys(ctx context.Context, img *ContainerImage, verifyConfig *Verify, regClient RegistryFetcher) ([]string, error) {
+	logCtx := log.LoggerFromContext(ctx)
+	imageRef := img.GetFullNameWithTag()
+
+	keys := verifyConfig.CosignKeys
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no cosign public keys configured for image %s", imageRef)
+	}
+	threshold := verifyConfig.Threshold
+	if threshold == 0 {
+		threshold = len(keys)
+	}
+	if threshold < 0 || threshold > len(keys) {
+		return nil, fmt.Errorf("invalid signature threshold %d for %d public keys", threshold, len(keys))
+	}
+
+	// An image without any signature is rejected like one that lacks some,
+	// so that the missing signers are reported.
+	if err := loadTagSignatures(ctx, img, regClient); err != nil && !errors.Is(err, errNoSignature) {
+		return nil, err
+	}
+
+	var matched, missing []string
+	for _, key := range keys {
+		ecKey, err := parsePublicKey(imageRef, key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key of signer %s: %w", key.Name, err)
+		}
+		signed := slices.ContainsFunc(img.ImageTag.TagSignatures, func(sig *tag.TagSignature) bool {
+			return verifySignatureWithKey(imageRef, sig, ecKey) == nil
+		})
+		if signed {
+			matched = append(matched, key.Name)
+		} else {
+			missing = append(missing, key.Name)
+		}
+	}
+
+	if len(matched) < threshold {
+		return matched, &ThresholdError{Matched: matched, Missing: missing, Threshold: threshold}
+	}
+	logCtx.Infof("Cosign signatures verified successfully for %s, signed by %s (%d of %d required)",
+		imageRef, strings.Join(matched, ", "), threshold, len(keys))
+	return matched, nil
+}
+
+// loadTagSignatures populates img.ImageTag.TagSignatures from the registry,
+// unless they were already fetched by a previous call.
+func loadTagSignatures(ctx context.Context, img *ContainerImage, regClient RegistryFetcher) error {
+	logCtx := log.LoggerFromContext(ctx)
+	imageRef := img.GetFullNameWithTag()
+
+	if img.ImageTag == nil {
+		return fmt.Errorf("image %s has no tag information", imageRef)
+	}
+
+	if len(img.ImageTag.TagSignatures) > 0 {
+		logCtx.Debugf("Using %d cached cosign signature candidate(s) for %s", len(img.ImageTag.TagSignatures), imageRef)
+		return nil
+	}
+
+	logCtx.Debugf("Fetching cosign signature for %s", imageRef)
+	sigs, err := fetchTagSignatures(ctx, img.ImageTag, regClient)
+	if err != nil {
+		return fmt.Errorf("failed to fetch cosign signature for %s: %w", imageRef, err)
+	}
+	img.ImageTag.TagSignatures = sigs
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // fetchTagSignature
 // ---------------------------------------------------------------------------
@@ -188,28 +344,9 @@ func fetchTagSignatures(ctx context.Context, imgTag *tag.ImageTag, regClient Reg
 	logCtx := log.LoggerFromContext(ctx)
 
 	// --- Step 1: resolve the image manifest digest ---
-	var imgDigest godigest.Digest
-
-	if imgTag.ManifestDigest != "" {
-		d, err := godigest.Parse(imgTag.ManifestDigest)
-		if err != nil {
-			return nil, fmt.Errorf("invalid manifest digest %q: %w", imgTag.ManifestDigest, err)
-		}
-		imgDigest = d
-		logCtx.Debugf("Using cached manifest digest %s for referrers lookup of tag %q", imgDigest, imgTag.TagName)
-	} else {
-		// Slow path: ManifestDigest not yet cached (e.g. cache miss).
-		logCtx.Debugf("Manifest digest not cached for tag %q, fetching image manifest", imgTag.TagName)
-		imgManifest, err := regClient.ManifestForTag(ctx, imgTag.TagName)
-		if err != nil {
-			return nil, fmt.Errorf("error fetching image manifest for tag %q: %w", imgTag.TagName, err)
-		}
-		_, imgPayload, err := imgManifest.Payload()
-		if err != nil {
-			return nil, fmt.Errorf("error getting image manifest payload for tag %q: %w", imgTag.TagName, err)
-		}
-		imgDigest = godigest.FromBytes(imgPayload)
-		logCtx.Debugf("Computed manifest digest %s for tag %q", imgDigest, imgTag.TagName)
+	imgDigest, err := resolveManifestDigest(ctx, imgTag, regClient)
+	if err != nil {
+		return nil, err
 	}
 
 	// --- Step 2: try OCI Referrers API (OCI Distribution Spec v1.1) ---
@@ -269,11 +406,40 @@ func fetchTagSignatures(ctx context.Context, imgTag *tag.ImageTag, regClient Reg
 			// Surface the original Referrers error when both paths found nothing.
 			return nil, fmt.Errorf("failed to fetch OCI referrers for digest %s: %w", imgDigest, referrersErr)
 		}
-		return nil, fmt.Errorf("no cosign signature found in OCI referrers or tag-based fallback for image tag %q (digest %s)", imgTag.TagName, imgDigest)
+		return nil, fmt.Errorf("%w in OCI referrers or tag-based fallback for image tag %q (digest %s)", errNoSignature, imgTag.TagName, imgDigest)
 	}
 	return allSigs, nil
 }
 
+// resolveManifestDigest returns the digest of the image manifest imgTag points
+// to, which signatures refer to. The cached ManifestDigest is used if present.
+func resolveManifestDigest(ctx context.Context, imgTag *tag.ImageTag, regClient RegistryFetcher) (godigest.Digest, error) {
+	logCtx := log.LoggerFromContext(ctx)
+
+	if imgTag.ManifestDigest != "" {
+		d, err := godigest.Parse(imgTag.ManifestDigest)
+		if err != nil {
+			return "", fmt.Errorf("invalid manifest digest %q: %w", imgTag.ManifestDigest, err)
+		}
+		logCtx.Debugf("Using cached manifest digest %s for referrers lookup of tag %q", d, imgTag.TagName)
+		return d, nil
+	}
+
+	// Slow path: ManifestDigest not yet cached (e.g. cache miss).
+	logCtx.Debugf("Manifest digest not cached for tag %q, fetching image manifest", imgTag.TagName)
+	imgManifest, err := regClient.ManifestForTag(ctx, imgTag.TagName)
+	if err != nil {
+		return "", fmt.Errorf("error fetching image manifest for tag %q: %w", imgTag.TagName, err)
+	}
+	_, imgPayload, err := imgManifest.Payload()
+	if err != nil {
+		return "", fmt.Errorf("error getting image manifest payload for tag %q: %w", imgTag.TagName, err)
+	}
+	d := godigest.FromBytes(imgPayload)
+	logCtx.Debugf("Computed manifest digest %s for tag %q", d, imgTag.TagName)
+	return d, nil
+}
+
 // fetchSigsFromFallbackTag fetches the manifest at fallbackTag, if it exists,
 // and extracts sigstore bundle signatures from it. It transparently handles
 // both container formats cosign may use for the fallback artifact:
@@ -396,13 +562,18 @@ func extractDSSEBundle(ctx context.Context, layer distribution.Descriptor, imgTa
 	// PAE digest is the same for all signers in this envelope.
 	paeHash := sha256.Sum256(computePAE(env.PayloadType, decodedPayload))
 	payloadDigest := hex.EncodeToString(paeHash[:])
+	payloadHash := sha256.Sum256(decodedPayload)
 
 	// Return one TagSignature per signer so the caller can try each one.
 	sigs := make([]*tag.TagSignature, 0, len(env.Signatures))
 	for _, s := range env.Signatures {
 		sigs = append(sigs, &tag.TagSignature{
-			Sig:           s.Sig,
-			PayloadDigest: payloadDigest,
+			Sig:                   s.Sig,
+			PayloadDigest:         payloadDigest,
+			EnvelopePayloadDigest: hex.EncodeToString(payloadHash[:]),
+			VerificationMaterial:  bundle.VerificationMaterial,
+			PayloadType:           env.PayloadType,
+			Payload:               decodedPayload,
 		})
 	}
 	return sigs, nil
@@ -509,21 +680,36 @@ func computePAE(payloadType string, payload []byte) []byte {
 //
 // sig.PayloadDigest must be hex(sha256(PAE(payloadType, payload))).
 func verifySignature(imageRef string, sig *tag.TagSignature, pemPublicKey string) error {
+	ecKey, err := parsePublicKey(imageRef, pemPublicKey)
+	if err != nil {
+		return err
+	}
+	return verifySignatureWithKey(imageRef, sig, ecKey)
+}
+
+// parsePublicKey parses the PEM-encoded ECDSA public key used to verify the
+// signatures of imageRef.
+func parsePublicKey(imageRef string, pemPublicKey string) (*ecdsa.PublicKey, error) {
 	block, _ := pem.Decode([]byte(pemPublicKey))
 	if block == nil {
-		return fmt.Errorf("unable to PEM decode public key for image %s", imageRef)
+		return nil, fmt.Errorf("unable to PEM decode public key for image %s", imageRef)
 	}
 
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
 	if err != nil {
-		return fmt.Errorf("failed to parse public key: %w", err)
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
 	}
 
 	ecKey, ok := pub.(*ecdsa.PublicKey)
 	if !ok {
-		return fmt.Errorf("public key for image %s is not an ECDSA key", imageRef)
+		return nil, fmt.Errorf("public key for image %s is not an ECDSA key", imageRef)
 	}
+	return ecKey, nil
+}
 
+// verifySignatureWithKey performs ECDSA verification of the pre-fetched
+// TagSignature against ecKey.
+func verifySignatureWithKey(imageRef string, sig *tag.TagSignature, ecKey *ecdsa.PublicKey) error {
 	sigBytes, err := base64.StdEncoding.DecodeString(sig.Sig)
 	if err != nil {
 		return fmt.Errorf("failed to decode signature for image %s: %w", imageRef, err)
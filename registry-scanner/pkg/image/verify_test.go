@@ -12,6 +12,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -1226,3 +1227,109 @@ func Test_VerifyWithPublicKey(t *testing.T) {
 		require.NotEmpty(t, img.ImageTag.TagSignatures)
 	})
 }
+
+func Test_VerifyWithPublicKeys(t *testing.T) {
+	ctx := context.Background()
+	const (
+		imgManifestDigest = "sha256:ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234"
+		sigArtifactDigest = "sha256:eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678eeff5678"
+	)
+	build := newTestKeyPair(t)
+	scanner := newTestKeyPair(t)
+	release := newTestKeyPair(t)
+	keys := []NamedPublicKey{
+		{Name: "build", Key: build.pemPub},
+		{Name: "scanner", Key: scanner.pemPub},
+		{Name: "release", Key: release.pemPub},
+	}
+
+	// newFetcher returns a fetcher serving one bundle signed by signers
+	newFetcher := func(signers ...testKeyPair) *mockFetcher {
+		var privKeys []*ecdsa.PrivateKey
+		for _, kp := range signers {
+			privKeys = append(privKeys, kp.priv)
+		}
+		bundleManifest, blobDigest, blobBytes := makeDSSEBundleWithSigners(t, privKeys, imgManifestDigest)
+		return &mockFetcher{
+			referrers: map[string][]distribution.Descriptor{
+				imgManifestDigest: {bundleReferrer(sigArtifactDigest)},
+			},
+			manifests: map[string]distribution.Manifest{sigArtifactDigest: bundleManifest},
+			blobs:     map[string][]byte{blobDigest: blobBytes},
+		}
+	}
+
+	t.Run("all signers required and present", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		signers, err := VerifyWithPublicKeys(ctx, img, &Verify{CosignKeys: keys}, newFetcher(release, build, scanner))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"build", "scanner", "release"}, signers)
+	})
+
+	t.Run("all signers required and one missing", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		signers, err := VerifyWithPublicKeys(ctx, img, &Verify{CosignKeys: keys}, newFetcher(build, scanner))
+		var thresholdErr *ThresholdError
+		require.ErrorAs(t, err, &thresholdErr)
+		assert.Equal(t, []string{"build", "scanner"}, signers)
+		assert.Equal(t, []string{"release"}, thresholdErr.Missing)
+		assert.EqualError(t, err, "2 of 3 required signatures found, missing signatures from release")
+	})
+
+	t.Run("threshold met by a subset of signers", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		signers, err := VerifyWithPublicKeys(ctx, img, &Verify{CosignKeys: keys, Threshold: 2}, newFetcher(scanner, release))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"scanner", "release"}, signers)
+	})
+
+	t.Run("threshold not met", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		other := newTestKeyPair(t)
+		_, err := VerifyWithPublicKeys(ctx, img, &Verify{CosignKeys: keys, Threshold: 2}, newFetcher(build, other))
+		var thresholdErr *ThresholdError
+		require.ErrorAs(t, err, &thresholdErr)
+		assert.Equal(t, []string{"build"}, thresholdErr.Matched)
+		assert.Equal(t, []string{"scanner", "release"}, thresholdErr.Missing)
+		assert.Equal(t, 2, thresholdErr.Threshold)
+	})
+
+	t.Run("no signature at all reports all signers as missing", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		_, err := VerifyWithPublicKeys(ctx, img, &Verify{CosignKeys: keys, Threshold: 1}, &mockFetcher{})
+		var thresholdErr *ThresholdError
+		require.ErrorAs(t, err, &thresholdErr)
+		assert.Empty(t, thresholdErr.Matched)
+		assert.Equal(t, []string{"build", "scanner", "release"}, thresholdErr.Missing)
+	})
+
+	t.Run("referrers error is propagated", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		fetcher := &mockFetcher{
+			referrerErrors: map[string]error{imgManifestDigest: fmt.Errorf("registry timeout")},
+		}
+		_, err := VerifyWithPublicKeys(ctx, img, &Verify{CosignKeys: keys}, fetcher)
+		assert.ErrorContains(t, err, "registry timeout")
+		var thresholdErr *ThresholdError
+		assert.False(t, errors.As(err, &thresholdErr))
+	})
+
+	t.Run("invalid public key names the signer", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		invalid := []NamedPublicKey{{Name: "build", Key: build.pemPub}, {Name: "scanner", Key: "not a key"}}
+		_, err := VerifyWithPublicKeys(ctx, img, &Verify{CosignKeys: invalid}, newFetcher(build))
+		assert.ErrorContains(t, err, "invalid public key of signer scanner")
+	})
+
+	t.Run("threshold exceeding the number of keys", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		_, err := VerifyWithPublicKeys(ctx, img, &Verify{CosignKeys: keys, Threshold: 4}, &mockFetcher{})
+		assert.ErrorContains(t, err, "invalid signature threshold 4 for 3 public keys")
+	})
+
+	t.Run("no keys configured", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		_, err := VerifyWithPublicKeys(ctx, img, &Verify{}, &mockFetcher{})
+		assert.ErrorContains(t, err, "no cosign public keys configured")
+	})
+}
@@ -3,6 +3,9 @@ package image
 import (
 	"context"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/options"
@@ -23,6 +26,10 @@ const (
 	StrategyAlphabetical UpdateStrategy = 2
 	// StrategyDigest defines the digest strategy.
 	StrategyDigest UpdateStrategy = 3
+	// StrategyCalVer defines the calendar versioning strategy.
+	StrategyCalVer UpdateStrategy = 4
+	// StrategyLabel defines the strategy that orders tags by a version label.
+	StrategyLabel UpdateStrategy = 5
 )
 
 // String returns the string representation of the update strategy.
@@ -36,6 +43,10 @@ func (us UpdateStrategy) String() string {
 		return "alphabetical"
 	case StrategyDigest:
 		return "digest"
+	case StrategyCalVer:
+		return "calver"
+	case StrategyLabel:
+		return "label"
 	}
 
 	return "unknown"
@@ -61,10 +72,34 @@ type VersionConstraint struct {
 	IgnoreList []string
 	Strategy   UpdateStrategy
 	Options    *options.ManifestOptions
+	CalVer     *CalVerConstraint
+	// MinAge is the minimum age a tag must have reached, based on its
+	// creation date, before it is considered for an update
+	MinAge time.Duration
+	// MaxBump is the largest change to the semantic version of the running
+	// tag that is allowed by the semver strategy
+	MaxBump VersionBump
+	// RelaxedSemVer makes the semver strategy accept tags with a prefix, a
+	// fourth version component or a suffix, see tag.ParseRelaxedVersion
+	RelaxedSemVer bool
+	// Prerelease restricts the pre-release versions that are allowed by the
+	// semver strategy. If nil, only the constraint decides whether
+	// pre-release versions are allowed.
+	Prerelease *PrereleasePolicy
+	// Label configures the label strategy. If nil, the version is read from
+	// DefaultVersionLabel as a semantic version.
+	Label *LabelConstraint
+	// LabelPolicy restricts the tags considered for an update to those whose
+	// image labels satisfy it. If nil, labels are not checked.
+	LabelPolicy *LabelPolicy
 }
 
 type MatchFuncFn func(tagName string, pattern any) bool
 
+// relaxedConstraintPrefixRegexp matches a prefix such as "release-" of the
+// version in a constraint, which is not understood by semver constraints
+var relaxedConstraintPrefixRegexp = regexp.MustCompile(`^(?:[A-Za-z]+[-_])+`)
+
 // String returns the string representation of VersionConstraint
 func (vc *VersionConstraint) String() string {
 	return vc.Constraint
@@ -78,22 +113,104 @@ func NewVersionConstraint() *VersionConstraint {
 	}
 }
 
+// UpdateCandidate is the result of looking up the newest version of an image
+type UpdateCandidate struct {
+	// Tag is the newest tag eligible for an update, or nil if there is none
+	Tag *tag.ImageTag
+	// Eligible holds all tags eligible for an update, ordered by the update
+	// strategy from the oldest to the newest, i.e. Tag is the last one
+	Eligible tag.SortableImageTagList
+	// Soaking is the newest tag that satisfies the version constraint and is
+	// newer than Tag, but has not yet reached the minimum age, or whose age is
+	// unknown. Nil if there is no such tag.
+	Soaking *tag.ImageTag
+	// Excluded holds the tags that satisfy the version constraint, but were
+	// excluded by the pre-release policy
+	Excluded []*tag.ImageTag
+	// Newer is the number of tags at the end of Eligible that are newer than
+	// the running tag. If it cannot be told whether a tag is newer than the
+	// running tag, only Tag is taken to be.
+	Newer int
+}
+
 // GetNewestVersionFromTags returns the latest available version from a list of
 // tags while optionally taking a semver constraint into account. Returns nil
 // if no suitable version could be found or the registry returned no tags.
 func (img *ContainerImage) GetNewestVersionFromTags(ctx context.Context, vc *VersionConstraint, tagList *tag.ImageTagList) (*tag.ImageTag, error) {
+	candidate, err := img.GetUpdateCandidate(ctx, vc, tagList)
+	if err != nil {
+		return nil, err
+	}
+	return candidate.Tag, nil
+}
+
+// GetUpdateCandidate returns the latest available version from a list of tags
+// like GetNewestVersionFromTags, and additionally the newest version that was
+// held back because it has not yet reached the minimum age of vc.
+func (img *ContainerImage) GetUpdateCandidate(ctx context.Context, vc *VersionConstraint, tagList *tag.ImageTagList) (*UpdateCandidate, error) {
 	logCtx := log.LoggerFromContext(ctx)
+	candidate := &UpdateCandidate{}
+
+	// The label strategy reads semantic versions from the default label when
+	// it has not been configured explicitly.
+	label := vc.Label
+	if vc.Strategy == StrategyLabel && label == nil {
+		var err error
+		label, err = ParseLabelConstraint("", "", "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The calver strategy, and the label strategy for calendar versioned
+	// labels, fall back to the default layout when it has not been configured
+	// explicitly.
+	calver := vc.CalVer
+	var calverLayout *tag.CalVerLayout
+	if vc.Strategy == StrategyCalVer || (vc.Strategy == StrategyLabel && label.Format == LabelFormatCalVer) {
+		if calver == nil {
+			var err error
+			calver, err = ParseCalVerConstraint("", nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		calverLayout = calver.Layout
+	}
+
+	// A tag match expression with named capture groups defines which part of
+	// the tag holds the version for the semver strategy. It takes precedence
+	// over relaxed parsing of the tag names.
+	var extractor *VersionExtractor
+	var extracted map[string]*ExtractedVersion
+	if vc.Strategy == StrategySemVer {
+		if re, ok := vc.MatchArgs.(*regexp.Regexp); ok {
+			extractor = NewVersionExtractor(re)
+		}
+	}
+	relaxed := vc.Strategy == StrategySemVer && vc.RelaxedSemVer && extractor == nil
 
 	var availableTags tag.SortableImageTagList
+	var labelVersions map[string]*LabelVersion
 	switch vc.Strategy {
 	case StrategySemVer:
-		availableTags = tagList.SortBySemVer(ctx)
+		if extractor != nil {
+			availableTags, extracted = extractor.SortTags(tagList)
+		} else if relaxed {
+			availableTags = tagList.SortByRelaxedSemVer(ctx)
+		} else {
+			availableTags = tagList.SortBySemVer(ctx)
+		}
 	case StrategyAlphabetical:
 		availableTags = tagList.SortAlphabetically()
 	case StrategyNewestBuild:
 		availableTags = tagList.SortByDate()
 	case StrategyDigest:
 		availableTags = tagList.SortAlphabetically()
+	case StrategyCalVer:
+		availableTags = tagList.SortByCalVer(ctx, calver.Layout)
+	case StrategyLabel:
+		availableTags, labelVersions = label.SortTags(tagList, calverLayout)
 	}
 
 	considerTags := tag.SortableImageTagList{}
@@ -105,7 +222,7 @@ func (img *ContainerImage) GetNewestVersionFromTags(ctx context.Context, vc *Ver
 		} else {
 			logCtx.Warnf("no tags found for image %s in registry", img.GetFullNameWithoutTag())
 		}
-		return nil, nil
+		return candidate, nil
 	}
 
 	// The given constraint MUST match a semver constraint. When no constraint
@@ -114,8 +231,15 @@ func (img *ContainerImage) GetNewestVersionFromTags(ctx context.Context, vc *Ver
 	// constraint carrying a "-0" suffix), as documented.
 	var semverConstraint *semver.Constraints
 	var err error
-	if vc.Strategy == StrategySemVer {
+	if extractor != nil && !extractor.HasVersion() {
+		if vc.Constraint != "" {
+			logCtx.Debugf("match expression has no version group, ignoring constraint %s", vc.Constraint)
+		}
+	} else if vc.Strategy == StrategySemVer {
 		constraint := vc.Constraint
+		if relaxed {
+			constraint = relaxedConstraintPrefixRegexp.ReplaceAllString(constraint, "")
+		}
 		if constraint == "" {
 			constraint = "*"
 		}
@@ -124,6 +248,80 @@ func (img *ContainerImage) GetNewestVersionFromTags(ctx context.Context, vc *Ver
 			logCtx.Errorf("invalid constraint '%s' given: '%v'", vc, err)
 			return nil, err
 		}
+		// A pre-release policy takes over the decision about pre-release
+		// versions from the constraint.
+		if vc.Prerelease != nil {
+			semverConstraint.IncludePrerelease = true
+		}
+	}
+
+	// If the match expression has a variant group, only tags of the same
+	// variant as the running tag are considered.
+	var variant *string
+	if extractor != nil && extractor.HasVariant() && img.ImageTag != nil {
+		if ev, err := extractor.Extract(img.ImageTag.TagName); err == nil {
+			variant = &ev.Variant
+		}
+	}
+
+	// Relaxed versions of tags are only considered if they have the same suffix
+	// as the running tag, e.g. the same platform.
+	var relaxedVersions map[string]*tag.RelaxedVersion
+	var suffix string
+	if relaxed {
+		relaxedVersions = make(map[string]*tag.RelaxedVersion, len(availableTags))
+		for _, t := range availableTags {
+			if rv, err := tag.ParseRelaxedVersion(t.TagName); err == nil {
+				relaxedVersions[t.TagName] = rv
+			}
+		}
+		if img.ImageTag != nil {
+			if rv, err := tag.ParseRelaxedVersion(img.ImageTag.TagName); err == nil {
+				suffix = rv.Suffix
+			}
+		}
+	}
+
+	// Calendar version constraints of the label strategy are relative to the
+	// label of the running tag, if it is still available in the registry.
+	var labelReference *tag.ImageTag
+	if vc.Strategy == StrategyLabel && img.ImageTag != nil {
+		if lv, ok := labelVersions[img.ImageTag.TagName]; ok {
+			labelReference = tag.NewImageTag(lv.Value, time.Unix(0, 0), "")
+		}
+	}
+
+	// A maximum version bump is relative to the version of the running tag,
+	// so we refuse to update if we cannot tell which version that is.
+	var currentVersion *semver.Version
+	if vc.Strategy == StrategySemVer && vc.MaxBump != VersionBumpAny {
+		switch {
+		case extractor != nil && !extractor.HasVersion():
+			logCtx.Debugf("match expression has no version group, ignoring max bump %s", vc.MaxBump)
+		case img.ImageTag == nil:
+			logCtx.Warnf("cannot enforce max bump %s for image %s without a running tag", vc.MaxBump, img.GetFullNameWithoutTag())
+			return candidate, nil
+		case extractor != nil:
+			ev, err := extractor.Extract(img.ImageTag.TagName)
+			if err != nil {
+				logCtx.Warnf("cannot enforce max bump %s for image %s: %v", vc.MaxBump, img.GetFullNameWithoutTag(), err)
+				return candidate, nil
+			}
+			currentVersion = ev.Version
+		case relaxed:
+			rv, err := tag.ParseRelaxedVersion(img.ImageTag.TagName)
+			if err != nil {
+				logCtx.Warnf("cannot enforce max bump %s for image %s: %v", vc.MaxBump, img.GetFullNameWithoutTag(), err)
+				return candidate, nil
+			}
+			currentVersion = rv.SemVer()
+		default:
+			currentVersion, err = semver.NewVersion(img.ImageTag.TagName)
+			if err != nil {
+				logCtx.Warnf("cannot enforce max bump %s for image %s: running tag %s is not a semantic version", vc.MaxBump, img.GetFullNameWithoutTag(), img.ImageTag.TagName)
+				return candidate, nil
+			}
+		}
 	}
 
 	// Loop through all tags to check whether it's an update candidate.
@@ -131,21 +329,75 @@ func (img *ContainerImage) GetNewestVersionFromTags(ctx context.Context, vc *Ver
 		logCtx.Tracef("Finding out whether to consider %s for being updateable", tag.TagName)
 
 		if vc.Strategy == StrategySemVer {
-			// Non-parseable tag does not mean error - just skip it
-			ver, err := semver.NewVersion(tag.TagName)
-			if err != nil {
-				logCtx.Tracef("Not a valid version: %s", tag.TagName)
-				continue
+			var ver *semver.Version
+			if extractor != nil {
+				ev := extracted[tag.TagName]
+				if variant != nil && ev.Variant != *variant {
+					logCtx.Tracef("%s has variant %q, but running variant is %q", tag.TagName, ev.Variant, *variant)
+					continue
+				}
+				ver = ev.Version
+			} else if relaxed {
+				rv := relaxedVersions[tag.TagName]
+				if rv == nil {
+					logCtx.Tracef("Not a valid relaxed version: %s", tag.TagName)
+					continue
+				}
+				if rv.Suffix != suffix {
+					logCtx.Tracef("%s has suffix %q, but running suffix is %q", tag.TagName, rv.Suffix, suffix)
+					continue
+				}
+				ver = rv.SemVer()
+			} else {
+				// Non-parseable tag does not mean error - just skip it
+				ver, err = semver.NewVersion(tag.TagName)
+				if err != nil {
+					logCtx.Tracef("Not a valid version: %s", tag.TagName)
+					continue
+				}
 			}
 
 			// If we have a version constraint, check image tag against it. If the
 			// constraint is not satisfied, skip tag.
-			if semverConstraint != nil {
+			if semverConstraint != nil && ver != nil {
 				if !semverConstraint.Check(ver) {
 					logCtx.Tracef("%s did not match constraint %s", ver.Original(), vc.Constraint)
 					continue
 				}
 			}
+
+			if currentVersion != nil && ver != nil && !vc.MaxBump.Allows(currentVersion, ver) {
+				logCtx.Tracef("%s exceeds max %s bump from %s", ver.Original(), vc.MaxBump, currentVersion.Original())
+				continue
+			}
+
+			if vc.Prerelease != nil && ver != nil && !vc.Prerelease.Allows(ver) {
+				logCtx.Tracef("%s is not allowed by pre-release policy %s", tag.TagName, vc.Prerelease)
+				candidate.Excluded = append(candidate.Excluded, tag)
+				continue
+			}
+		} else if vc.Strategy == StrategyCalVer {
+			ver, err := calver.Layout.Parse(tag.TagName)
+			if err != nil {
+				logCtx.Tracef("Not a valid calendar version: %s", tag.TagName)
+				continue
+			}
+			if err := calver.Allows(ver, img.ImageTag); err != nil {
+				logCtx.Tracef("%s did not match calver constraint: %v", tag.TagName, err)
+				continue
+			}
+		} else if vc.Strategy == StrategyLabel {
+			lv := labelVersions[tag.TagName]
+			if label.Constraint != nil && !label.Constraint.Check(lv.SemVer) {
+				logCtx.Tracef("%s with label %s=%s did not match constraint", tag.TagName, label.Name, lv.Value)
+				continue
+			}
+			if lv.CalVer != nil {
+				if err := calver.Allows(lv.CalVer, labelReference); err != nil {
+					logCtx.Tracef("%s with label %s=%s did not match calver constraint: %v", tag.TagName, label.Name, lv.Value, err)
+					continue
+				}
+			}
 		} else if vc.Strategy == StrategyDigest {
 			if tag.TagName != vc.Constraint {
 				logCtx.Tracef("%s did not match contraint %s", tag.TagName, vc.Constraint)
@@ -153,20 +405,140 @@ func (img *ContainerImage) GetNewestVersionFromTags(ctx context.Context, vc *Ver
 			}
 		}
 
+		// A match expression that refers to the metadata of a tag can only be
+		// evaluated now that the metadata is available.
+		if cm, ok := vc.MatchArgs.(*CELMatcher); ok && cm.NeedsMetadata() {
+			match, err := cm.Matches(tag)
+			if err != nil {
+				logCtx.Tracef("could not evaluate CEL expression for %s: %v", tag.TagName, err)
+				continue
+			}
+			if !match {
+				logCtx.Tracef("%s did not match CEL expression %s", tag.TagName, cm)
+				continue
+			}
+		}
+
+		// Tags whose labels do not satisfy the label policy are not considered
+		if vc.LabelPolicy != nil {
+			if err := vc.LabelPolicy.Check(tag.Labels); err != nil {
+				logCtx.Tracef("%s did not satisfy label policy: %v", tag.TagName, err)
+				continue
+			}
+		}
+
+		// Tags younger than the minimum age are held back, unless they are
+		// already running. So are tags whose age is unknown, because their
+		// image has no creation date.
+		if vc.MinAge > 0 && (img.ImageTag == nil || !tag.Equals(img.ImageTag)) {
+			if !tag.HasDate() {
+				logCtx.Debugf("%s has no creation date, cannot tell whether it has reached minimum age of %s", tag.TagName, vc.MinAge)
+				candidate.Soaking = tag
+				continue
+			}
+			if time.Since(*tag.TagDate) < vc.MinAge {
+				logCtx.Debugf("%s was created at %s and has not yet reached minimum age of %s", tag.TagName, tag.TagDate.Format(time.RFC3339), vc.MinAge)
+				candidate.Soaking = tag
+				continue
+			}
+		}
+
 		// Append tag as update candidate
 		considerTags = append(considerTags, tag)
+		candidate.Soaking = nil
 	}
 
 	logCtx.Debugf("found %d from %d tags eligible for consideration", len(considerTags), len(availableTags))
 
+	if candidate.Soaking != nil {
+		logCtx.Infof("newest tag %s for image %s has not yet reached minimum age of %s", candidate.Soaking.TagName, img.GetFullNameWithoutTag(), vc.MinAge)
+	}
+
 	// If we found tags to consider, return the most recent tag found according
 	// to the update strategy.
 	if len(considerTags) > 0 {
-		return considerTags[len(considerTags)-1], nil
+		candidate.Tag = considerTags[len(considerTags)-1]
+		candidate.Eligible = considerTags
+		candidate.Newer = countNewer(img.ImageTag, availableTags, considerTags, func(tagName, runningName string) (int, bool) {
+			switch vc.Strategy {
+			case StrategySemVer:
+				switch {
+				case extractor != nil:
+					a, errA := extractor.Extract(tagName)
+					b, errB := extractor.Extract(runningName)
+					if errA != nil || errB != nil {
+						return 0, false
+					}
+					return a.Compare(b), true
+				case relaxed:
+					a, errA := tag.ParseRelaxedVersion(tagName)
+					b, errB := tag.ParseRelaxedVersion(runningName)
+					if errA != nil || errB != nil {
+						return 0, false
+					}
+					return a.Compare(b), true
+				default:
+					a, errA := semver.NewVersion(tagName)
+					b, errB := semver.NewVersion(runningName)
+					if errA != nil || errB != nil {
+						return 0, false
+					}
+					return a.Compare(b), true
+				}
+			case StrategyAlphabetical:
+				return strings.Compare(tagName, runningName), true
+			case StrategyCalVer:
+				a, errA := calver.Layout.Parse(tagName)
+				b, errB := calver.Layout.Parse(runningName)
+				if errA != nil || errB != nil {
+					return 0, false
+				}
+				return a.Compare(b), true
+			}
+			return 0, false
+		})
+		return candidate, nil
+	}
+	if candidate.Soaking != nil {
+		return candidate, nil
 	}
 
 	logCtx.Warnf("no tags for image %s matched constraint %q", img.GetFullNameWithoutTag(), vc.Constraint)
-	return nil, nil
+	return candidate, nil
+}
+
+// countNewer returns the number of tags at the end of eligible that are newer
+// than running. Tags ordered after the running tag in available are newer. If
+// the running tag is not available, e.g. because it was removed from the
+// registry, tags are compared with it by compare, which reports whether the
+// strategy can order the tags by their names. Otherwise, only the newest
+// eligible tag is taken to be newer.
+func countNewer(running *tag.ImageTag, available, eligible tag.SortableImageTagList, compare func(tagName, runningName string) (int, bool)) int {
+	if running == nil {
+		return len(eligible)
+	}
+	position := make(map[string]int, len(available))
+	for i, t := range available {
+		position[t.TagName] = i
+	}
+	runningPosition, runningAvailable := position[running.TagName]
+
+	newer := 0
+	for i := len(eligible) - 1; i >= 0; i-- {
+		var isNewer bool
+		if runningAvailable {
+			isNewer = position[eligible[i].TagName] > runningPosition
+		} else if c, ok := compare(eligible[i].TagName, running.TagName); ok {
+			isNewer = c > 0
+		} else {
+			isNewer = i == len(eligible)-1
+		}
+		if !isNewer {
+			break
+		}
+		newer++
+	}
+	return newer
 }
 
 // IsTagIgnored matches tag against the patterns in IgnoreList and returns true if one of them matches
@@ -191,10 +563,27 @@ func (us UpdateStrategy) IsCacheable() bool {
 	}
 }
 
+// NeedsMetadata returns true if the constraint requires image metadata to be
+// evaluated correctly, either because of its strategy or because it filters
+// on metadata
+func (vc *VersionConstraint) NeedsMetadata() bool {
+	return vc.Strategy.NeedsMetadata() || vc.FiltersOnMetadata()
+}
+
+// FiltersOnMetadata returns true if the constraint filters tags by their
+// metadata, i.e. it has a minimum age, a label policy or a match expression
+// that refers to labels or creation dates
+func (vc *VersionConstraint) FiltersOnMetadata() bool {
+	if cm, ok := vc.MatchArgs.(*CELMatcher); ok && cm.NeedsMetadata() {
+		return true
+	}
+	return vc.MinAge > 0 || vc.LabelPolicy != nil
+}
+
 // NeedsMetadata returns true if strategy us requires image metadata to work correctly
 func (us UpdateStrategy) NeedsMetadata() bool {
 	switch us {
-	case StrategyNewestBuild:
+	case StrategyNewestBuild, StrategyLabel:
 		return true
 	default:
 		return false
@@ -157,6 +157,447 @@ func Test_LatestVersion(t *testing.T) {
 		assert.Equal(t, newDigest, newTag.TagDigest)
 	})
 
+	t.Run("Find the latest version using a version extracted by a named capture group", func(t *testing.T) {
+		tagList := newImageTagList([]string{"release-1.8.2-alpine", "release-1.10.0-alpine", "release-1.9.0-debian", "release-2.0.0-alpine", "1.11.0"})
+		img := NewFromIdentifier("jannfis/test:release-1.8.2-alpine")
+		vc := VersionConstraint{Constraint: "1.x"}
+		vc.MatchFunc, vc.MatchArgs = img.ParseMatch(context.Background(), `regexp:^release-(?P<version>\d+\.\d+\.\d+)-\w+$`)
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "release-1.10.0-alpine", newTag.TagName)
+	})
+
+	t.Run("Find the latest version of the running variant using named capture groups", func(t *testing.T) {
+		tagList := newImageTagList([]string{"release-1.8.2-alpine", "release-1.9.0-alpine", "release-1.10.0-debian"})
+		img := NewFromIdentifier("jannfis/test:release-1.8.2-alpine")
+		vc := VersionConstraint{}
+		vc.MatchFunc, vc.MatchArgs = img.ParseMatch(context.Background(), `regexp:^release-(?P<version>\d+\.\d+\.\d+)-(?P<variant>\w+)$`)
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "release-1.9.0-alpine", newTag.TagName)
+	})
+
+	t.Run("Find the latest version using a numeric build extracted by a named capture group", func(t *testing.T) {
+		tagList := newImageTagList([]string{"build-4821-g1a2b3c4", "build-999-gffffff0", "build-10000-g0000000", "latest"})
+		img := NewFromIdentifier("jannfis/test:build-999-gffffff0")
+		vc := VersionConstraint{}
+		vc.MatchFunc, vc.MatchArgs = img.ParseMatch(context.Background(), `regexp:^build-(?P<build>\d+)-g[0-9a-f]{7}$`)
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "build-10000-g0000000", newTag.TagName)
+	})
+
+	t.Run("Find the latest version matching a CEL expression on labels", func(t *testing.T) {
+		tagList := tag.NewImageTagList()
+		tagList.Add(tag.NewImageTagWithLabels("1.0.0", time.Now(), "", map[string]string{"org.opencontainers.image.source": "https://github.com/myorg/app"}))
+		tagList.Add(tag.NewImageTagWithLabels("1.1.0", time.Now(), "", map[string]string{"org.opencontainers.image.source": "https://github.com/myorg/app"}))
+		tagList.Add(tag.NewImageTagWithLabels("1.2.0", time.Now(), "", map[string]string{"org.opencontainers.image.source": "https://github.com/evil/app"}))
+		tagList.Add(tag.NewImageTagWithLabels("1.3.0", time.Now(), "", nil))
+		img := NewFromIdentifier("jannfis/test:1.0.0")
+		vc := VersionConstraint{}
+		vc.MatchFunc, vc.MatchArgs = img.ParseMatch(context.Background(), `cel:labels["org.opencontainers.image.source"].startsWith("https://github.com/myorg/")`)
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.1.0", newTag.TagName)
+	})
+
+	t.Run("Find the latest version satisfying a label policy", func(t *testing.T) {
+		tagList := tag.NewImageTagList()
+		tagList.Add(tag.NewImageTagWithLabels("1.0.0", time.Now(), "", map[string]string{"com.example.qa": "passed"}))
+		tagList.Add(tag.NewImageTagWithLabels("1.1.0", time.Now(), "", map[string]string{"com.example.qa": "passed", "com.example.deprecated": "true"}))
+		tagList.Add(tag.NewImageTagWithLabels("1.2.0", time.Now(), "", map[string]string{"com.example.qa": "failed"}))
+		tagList.Add(tag.NewImageTagWithLabels("1.3.0", time.Now(), "", nil))
+		img := NewFromIdentifier("jannfis/test:1.0.0")
+		lp, err := ParseLabelPolicy([]LabelMatch{{Key: "com.example.qa", Value: "passed"}}, []LabelMatch{{Key: "com.example.deprecated"}})
+		require.NoError(t, err)
+		vc := VersionConstraint{LabelPolicy: lp}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.0.0", newTag.TagName)
+	})
+
+	t.Run("Find the latest four-part version with relaxed semver", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3.4", "1.2.3.10", "1.2.4.1", "1.3.0.0", "latest"})
+		img := NewFromIdentifier("jannfis/test:1.2.3.4")
+		vc := VersionConstraint{Constraint: "~1.2", RelaxedSemVer: true}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.2.4.1", newTag.TagName)
+	})
+
+	t.Run("Find the latest version of the running suffix with relaxed semver", func(t *testing.T) {
+		tagList := newImageTagList([]string{"v1.2.3-windowsservercore-ltsc2022", "v1.2.4-windowsservercore-ltsc2022", "v1.2.5-nanoserver-ltsc2022", "v1.2.6", "v1.3.0-rc.1-windowsservercore-ltsc2022"})
+		img := NewFromIdentifier("jannfis/test:v1.2.3-windowsservercore-ltsc2022")
+		vc := VersionConstraint{RelaxedSemVer: true}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "v1.2.4-windowsservercore-ltsc2022", newTag.TagName)
+	})
+
+	t.Run("Find the latest pre-release version with relaxed semver", func(t *testing.T) {
+		tagList := newImageTagList([]string{"v1.2.3-windowsservercore-ltsc2022", "v1.3.0-rc.1-windowsservercore-ltsc2022", "v1.3.0-rc.2-nanoserver-ltsc2022"})
+		img := NewFromIdentifier("jannfis/test:v1.2.3-windowsservercore-ltsc2022")
+		vc := VersionConstraint{Constraint: ">=1.2.3-0", RelaxedSemVer: true}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "v1.3.0-rc.1-windowsservercore-ltsc2022", newTag.TagName)
+	})
+
+	t.Run("Find the latest version with relaxed semver and a prefixed constraint", func(t *testing.T) {
+		tagList := newImageTagList([]string{"release-1.2.3", "release-1.2.9", "release-1.3.0"})
+		img := NewFromIdentifier("jannfis/test:release-1.2.3")
+		vc := VersionConstraint{Constraint: "release-1.2.x", RelaxedSemVer: true}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "release-1.2.9", newTag.TagName)
+	})
+
+	t.Run("Find the latest version with relaxed semver and a max bump", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3.4", "1.2.9.1", "1.3.0.0"})
+		img := NewFromIdentifier("jannfis/test:1.2.3.4")
+		vc := VersionConstraint{RelaxedSemVer: true, MaxBump: VersionBumpPatch}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.2.9.1", newTag.TagName)
+	})
+
+	t.Run("Find no four-part version without relaxed semver", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3.4", "1.2.3.10"})
+		img := NewFromIdentifier("jannfis/test:1.2.3.4")
+		vc := VersionConstraint{}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		assert.Nil(t, newTag)
+	})
+
+	t.Run("Find the latest release candidate with rc pre-release policy", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.3.0-alpha.1", "1.3.0-beta.1", "1.3.0-rc.1", "1.3.0-rc.2", "1.4.0-beta.1"})
+		img := NewFromIdentifier("jannfis/test:1.2.3")
+		prerelease, err := ParsePrereleasePolicy([]string{"rc"})
+		require.NoError(t, err)
+		vc := VersionConstraint{Prerelease: prerelease}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, candidate.Tag)
+		assert.Equal(t, "1.3.0-rc.2", candidate.Tag.TagName)
+		excluded := []string{}
+		for _, et := range candidate.Excluded {
+			excluded = append(excluded, et.TagName)
+		}
+		assert.ElementsMatch(t, []string{"1.3.0-alpha.1", "1.3.0-beta.1", "1.4.0-beta.1"}, excluded)
+	})
+
+	t.Run("Find the latest beta version with pre-release policy and constraint", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.2.4-beta.1", "1.3.0-rc.1", "2.0.0-beta.1"})
+		img := NewFromIdentifier("jannfis/test:1.2.3")
+		prerelease, err := ParsePrereleasePolicy([]string{"beta"})
+		require.NoError(t, err)
+		vc := VersionConstraint{Constraint: "1.x", Prerelease: prerelease}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.3.0-rc.1", newTag.TagName)
+	})
+
+	t.Run("Find no pre-release version with none pre-release policy", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.2.4", "1.3.0-rc.1"})
+		img := NewFromIdentifier("jannfis/test:1.2.3")
+		prerelease, err := ParsePrereleasePolicy([]string{"none"})
+		require.NoError(t, err)
+		vc := VersionConstraint{Constraint: ">=1.2.3-0", Prerelease: prerelease}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, candidate.Tag)
+		assert.Equal(t, "1.2.4", candidate.Tag.TagName)
+		require.Len(t, candidate.Excluded, 1)
+		assert.Equal(t, "1.3.0-rc.1", candidate.Excluded[0].TagName)
+	})
+
+	t.Run("Return all eligible versions ordered by version", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.5", "1.2.3", "2.0.0", "1.3.0", "0.9.0"})
+		img := NewFromIdentifier("jannfis/test:1.2.3")
+		vc := VersionConstraint{Constraint: "^1.2"}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, candidate.Tag)
+		assert.Equal(t, "1.3.0", candidate.Tag.TagName)
+		assert.Equal(t, []string{"1.2.3", "1.2.5", "1.3.0"}, candidate.Eligible.Tags())
+		assert.Equal(t, 2, candidate.Newer)
+	})
+
+	t.Run("Count eligible versions newer than a running tag that is not eligible", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.5", "1.2.3", "1.3.0", "0.9.0"})
+		img := NewFromIdentifier("jannfis/test:1.2.4")
+		vc := VersionConstraint{Constraint: "^1.2"}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.2.3", "1.2.5", "1.3.0"}, candidate.Eligible.Tags())
+		assert.Equal(t, 2, candidate.Newer)
+	})
+
+	t.Run("Count only the newest version as newer if the running tag cannot be compared", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.5", "1.2.3", "1.3.0"})
+		img := NewFromIdentifier("jannfis/test:latest")
+		vc := VersionConstraint{Constraint: "^1.2"}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		assert.Equal(t, 1, candidate.Newer)
+	})
+
+	t.Run("Find the latest version with a max patch bump", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.2.5", "1.3.0", "2.0.0", "0.9.0"})
+		img := NewFromIdentifier("jannfis/test:1.2.3")
+		vc := VersionConstraint{MaxBump: VersionBumpPatch}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.2.5", newTag.TagName)
+	})
+
+	t.Run("Find the latest version with a max minor bump", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.2.5", "1.3.0", "2.0.0", "3.1.0"})
+		img := NewFromIdentifier("jannfis/test:v1.2.3")
+		vc := VersionConstraint{MaxBump: VersionBumpMinor}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.3.0", newTag.TagName)
+	})
+
+	t.Run("Find the latest version with a max major bump", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.3.0", "2.0.0", "3.1.0"})
+		img := NewFromIdentifier("jannfis/test:1.2.3")
+		vc := VersionConstraint{MaxBump: VersionBumpMajor}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "3.1.0", newTag.TagName)
+	})
+
+	t.Run("Find the latest version with a max bump and a semver constraint", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.2.5", "1.2.7", "1.3.0"})
+		img := NewFromIdentifier("jannfis/test:1.2.3")
+		vc := VersionConstraint{Constraint: "<1.2.7", MaxBump: VersionBumpPatch}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.2.5", newTag.TagName)
+	})
+
+	t.Run("Find the latest version with a max bump relative to an extracted version", func(t *testing.T) {
+		tagList := newImageTagList([]string{"release-1.8.2-alpine", "release-1.8.4-alpine", "release-1.9.0-alpine", "release-2.0.0-alpine"})
+		img := NewFromIdentifier("jannfis/test:release-1.8.2-alpine")
+		vc := VersionConstraint{MaxBump: VersionBumpPatch}
+		vc.MatchFunc, vc.MatchArgs = img.ParseMatch(context.Background(), `regexp:^release-(?P<version>\d+\.\d+\.\d+)-\w+$`)
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "release-1.8.4-alpine", newTag.TagName)
+	})
+
+	t.Run("Find no version with a max bump and a non-semver running tag", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.2.5", "1.3.0"})
+		img := NewFromIdentifier("jannfis/test:latest")
+		vc := VersionConstraint{MaxBump: VersionBumpPatch}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		assert.Nil(t, newTag)
+	})
+
+	t.Run("Find no version with a max bump and no running tag", func(t *testing.T) {
+		tagList := newImageTagList([]string{"1.2.3", "1.2.5", "1.3.0"})
+		img := NewFromIdentifier("jannfis/test")
+		vc := VersionConstraint{MaxBump: VersionBumpMinor}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		assert.Nil(t, newTag)
+	})
+
+	t.Run("Find the latest version that has reached the minimum age", func(t *testing.T) {
+		tagList := tag.NewImageTagList()
+		tagList.Add(tag.NewImageTag("1.0.0", time.Now().Add(-72*time.Hour), ""))
+		tagList.Add(tag.NewImageTag("1.1.0", time.Now().Add(-49*time.Hour), ""))
+		tagList.Add(tag.NewImageTag("1.2.0", time.Now().Add(-47*time.Hour), ""))
+		tagList.Add(tag.NewImageTag("1.3.0", time.Now().Add(-1*time.Hour), ""))
+		img := NewFromIdentifier("jannfis/test:1.0.0")
+		vc := VersionConstraint{MinAge: 48 * time.Hour}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, candidate.Tag)
+		assert.Equal(t, "1.1.0", candidate.Tag.TagName)
+		require.NotNil(t, candidate.Soaking)
+		assert.Equal(t, "1.3.0", candidate.Soaking.TagName)
+
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "1.1.0", newTag.TagName)
+	})
+
+	t.Run("Find the latest version when all tags have reached the minimum age", func(t *testing.T) {
+		tagList := tag.NewImageTagList()
+		tagList.Add(tag.NewImageTag("1.0.0", time.Now().Add(-72*time.Hour), ""))
+		tagList.Add(tag.NewImageTag("1.1.0", time.Now().Add(-49*time.Hour), ""))
+		img := NewFromIdentifier("jannfis/test:1.0.0")
+		vc := VersionConstraint{MinAge: 48 * time.Hour}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, candidate.Tag)
+		assert.Equal(t, "1.1.0", candidate.Tag.TagName)
+		assert.Nil(t, candidate.Soaking)
+	})
+
+	t.Run("Find the latest version with a running tag younger than the minimum age", func(t *testing.T) {
+		tagList := tag.NewImageTagList()
+		tagList.Add(tag.NewImageTag("1.0.0", time.Now().Add(-72*time.Hour), ""))
+		tagList.Add(tag.NewImageTag("1.1.0", time.Now().Add(-1*time.Hour), ""))
+		img := NewFromIdentifier("jannfis/test:1.1.0")
+		vc := VersionConstraint{MinAge: 48 * time.Hour}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, candidate.Tag)
+		assert.Equal(t, "1.1.0", candidate.Tag.TagName)
+		assert.Nil(t, candidate.Soaking)
+	})
+
+	t.Run("Hold back versions without creation date when a minimum age is set", func(t *testing.T) {
+		tagList := tag.NewImageTagList()
+		tagList.Add(tag.NewImageTag("1.0.0", time.Now().Add(-72*time.Hour), ""))
+		tagList.Add(tag.NewImageTag("1.1.0", time.Time{}, ""))
+		img := NewFromIdentifier("jannfis/test:1.0.0")
+		vc := VersionConstraint{MinAge: 48 * time.Hour}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, candidate.Tag)
+		assert.Equal(t, "1.0.0", candidate.Tag.TagName)
+		require.NotNil(t, candidate.Soaking)
+		assert.Equal(t, "1.1.0", candidate.Soaking.TagName)
+	})
+
+	t.Run("Find no version when the only digest is younger than the minimum age", func(t *testing.T) {
+		tagList := tag.NewImageTagList()
+		tagList.Add(tag.NewImageTag("latest", time.Now().Add(-1*time.Hour), "sha256:abcdef"))
+		img := NewFromIdentifier("jannfis/test:latest@sha256:123456")
+		vc := VersionConstraint{Strategy: StrategyDigest, Constraint: "latest", MinAge: 48 * time.Hour}
+		candidate, err := img.GetUpdateCandidate(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		assert.Nil(t, candidate.Tag)
+		require.NotNil(t, candidate.Soaking)
+		assert.Equal(t, "sha256:abcdef", candidate.Soaking.TagDigest)
+	})
+
+	t.Run("Find the latest version using VersionConstraint StrategyCalVer", func(t *testing.T) {
+		tagList := newImageTagList([]string{"2024.11.3", "2024.9.30", "2023.12.31", "latest", "1.2.3", "2024.11.10"})
+		img := NewFromIdentifier("jannfis/test:2024.9.30")
+		cc, err := ParseCalVerConstraint("YYYY.MM.DD", nil)
+		require.NoError(t, err)
+		vc := VersionConstraint{Strategy: StrategyCalVer, CalVer: cc}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "2024.11.10", newTag.TagName)
+	})
+
+	t.Run("Find the latest version using StrategyCalVer with micro and same-year constraint", func(t *testing.T) {
+		tagList := newImageTagList([]string{"24.04-1", "24.04-10", "24.04-2", "24.10-1", "25.01-1"})
+		img := NewFromIdentifier("jannfis/test:24.04-1")
+		cc, err := ParseCalVerConstraint("YY.0M-MICRO", []string{"same-year"})
+		require.NoError(t, err)
+		vc := VersionConstraint{Strategy: StrategyCalVer, CalVer: cc}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "24.10-1", newTag.TagName)
+	})
+
+	t.Run("Find the latest version using StrategyCalVer with default layout", func(t *testing.T) {
+		tagList := newImageTagList([]string{"2024.01.15", "2024.11.03", "2024.1.5"})
+		img := NewFromIdentifier("jannfis/test:2024.01.15")
+		vc := VersionConstraint{Strategy: StrategyCalVer}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "2024.11.03", newTag.TagName)
+	})
+
+	t.Run("Find the latest version using StrategyCalVer with max-age constraint", func(t *testing.T) {
+		tagList := newImageTagList([]string{"2024.03", "2024.05", "2024.06"})
+		img := NewFromIdentifier("jannfis/test:2024.03")
+		cc, err := ParseCalVerConstraint("YYYY.0M", []string{"max-age:6m"})
+		require.NoError(t, err)
+		cc.now = func() time.Time { return time.Date(2024, time.December, 15, 0, 0, 0, 0, time.UTC) }
+		vc := VersionConstraint{Strategy: StrategyCalVer, CalVer: cc}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "2024.06", newTag.TagName)
+
+		cc.now = func() time.Time { return time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC) }
+		newTag, err = img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		assert.Nil(t, newTag)
+	})
+
+	t.Run("Find the latest version using StrategyLabel", func(t *testing.T) {
+		tagList := newLabelledImageTagList(DefaultVersionLabel, map[string]string{
+			"build-103": "1.2.0",
+			"build-101": "1.10.0",
+			"build-102": "2.0.0-rc.1",
+			"build-104": "",
+		})
+		img := NewFromIdentifier("jannfis/test:build-103")
+		vc := VersionConstraint{Strategy: StrategyLabel}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "build-101", newTag.TagName)
+	})
+
+	t.Run("Find the latest version using StrategyLabel with label constraint", func(t *testing.T) {
+		tagList := newLabelledImageTagList("com.example.version", map[string]string{
+			"a1": "1.2.0",
+			"a2": "1.2.5",
+			"a3": "1.3.0",
+		})
+		img := NewFromIdentifier("jannfis/test:a1")
+		lc, err := ParseLabelConstraint("com.example.version", "semver", "~1.2")
+		require.NoError(t, err)
+		vc := VersionConstraint{Strategy: StrategyLabel, Label: lc}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "a2", newTag.TagName)
+	})
+
+	t.Run("Find the latest version using StrategyLabel with calver label", func(t *testing.T) {
+		tagList := newLabelledImageTagList(DefaultVersionLabel, map[string]string{
+			"f00": "24.04-1",
+			"f01": "24.10-2",
+			"f02": "24.10-10",
+			"f03": "25.01-1",
+		})
+		img := NewFromIdentifier("jannfis/test:f00")
+		lc, err := ParseLabelConstraint("", "calver", "")
+		require.NoError(t, err)
+		cc, err := ParseCalVerConstraint("YY.0M-MICRO", []string{"same-year"})
+		require.NoError(t, err)
+		vc := VersionConstraint{Strategy: StrategyLabel, Label: lc, CalVer: cc}
+		newTag, err := img.GetNewestVersionFromTags(context.Background(), &vc, tagList)
+		require.NoError(t, err)
+		require.NotNil(t, newTag)
+		assert.Equal(t, "f02", newTag.TagName)
+	})
+
 }
 
 func Test_UpdateStrategy_String(t *testing.T) {
@@ -169,6 +610,8 @@ func Test_UpdateStrategy_String(t *testing.T) {
 		{"StrategyNewestBuild", StrategyNewestBuild, "newest-build"},
 		{"StrategyAlphabetical", StrategyAlphabetical, "alphabetical"},
 		{"StrategyDigest", StrategyDigest, "digest"},
+		{"StrategyCalVer", StrategyCalVer, "calver"},
+		{"StrategyLabel", StrategyLabel, "label"},
 		{"unknown", UpdateStrategy(-1), "unknown"},
 	}
 	for _, tt := range tests {
@@ -202,6 +645,29 @@ func Test_UpdateStrategy_IsCacheable(t *testing.T) {
 	assert.True(t, StrategyNewestBuild.IsCacheable())
 	assert.True(t, StrategyAlphabetical.IsCacheable())
 	assert.False(t, StrategyDigest.IsCacheable())
+	assert.True(t, StrategyCalVer.IsCacheable())
+	assert.True(t, StrategyLabel.IsCacheable())
+}
+
+func Test_VersionConstraint_NeedsMetadata(t *testing.T) {
+	assert.False(t, (&VersionConstraint{Strategy: StrategySemVer}).NeedsMetadata())
+	assert.True(t, (&VersionConstraint{Strategy: StrategyNewestBuild}).NeedsMetadata())
+	assert.True(t, (&VersionConstraint{Strategy: StrategySemVer, MinAge: time.Hour}).NeedsMetadata())
+	nameOnly, err := NewCELMatcher(`tag != "latest"`)
+	require.NoError(t, err)
+	assert.False(t, (&VersionConstraint{Strategy: StrategySemVer, MatchArgs: nameOnly}).NeedsMetadata())
+	withLabels, err := NewCELMatcher(`"team" in labels`)
+	require.NoError(t, err)
+	assert.True(t, (&VersionConstraint{Strategy: StrategySemVer, MatchArgs: withLabels}).NeedsMetadata())
+}
+
+func Test_VersionConstraint_FiltersOnMetadata(t *testing.T) {
+	assert.False(t, (&VersionConstraint{Strategy: StrategyNewestBuild}).FiltersOnMetadata())
+	assert.True(t, (&VersionConstraint{Strategy: StrategySemVer, MinAge: time.Hour}).FiltersOnMetadata())
+	withDate, err := NewCELMatcher(`created > now - duration("24h")`)
+	require.NoError(t, err)
+	assert.True(t, (&VersionConstraint{Strategy: StrategySemVer, MatchArgs: withDate}).FiltersOnMetadata())
+	assert.True(t, (&VersionConstraint{Strategy: StrategySemVer, LabelPolicy: &LabelPolicy{}}).FiltersOnMetadata())
 }
 
 func Test_UpdateStrategy_NeedsMetadata(t *testing.T) {
@@ -209,6 +675,8 @@ func Test_UpdateStrategy_NeedsMetadata(t *testing.T) {
 	assert.True(t, StrategyNewestBuild.NeedsMetadata())
 	assert.False(t, StrategyAlphabetical.NeedsMetadata())
 	assert.False(t, StrategyDigest.NeedsMetadata())
+	assert.False(t, StrategyCalVer.NeedsMetadata())
+	assert.True(t, StrategyLabel.NeedsMetadata())
 }
 
 func Test_UpdateStrategy_NeedsVersionConstraint(t *testing.T) {
@@ -216,6 +684,7 @@ func Test_UpdateStrategy_NeedsVersionConstraint(t *testing.T) {
 	assert.False(t, StrategyNewestBuild.NeedsVersionConstraint())
 	assert.False(t, StrategyAlphabetical.NeedsVersionConstraint())
 	assert.True(t, StrategyDigest.NeedsVersionConstraint())
+	assert.False(t, StrategyCalVer.NeedsVersionConstraint())
 }
 
 func Test_UpdateStrategy_WantsOnlyConstraintTag(t *testing.T) {
@@ -223,4 +692,5 @@ func Test_UpdateStrategy_WantsOnlyConstraintTag(t *testing.T) {
 	assert.False(t, StrategyNewestBuild.WantsOnlyConstraintTag())
 	assert.False(t, StrategyAlphabetical.WantsOnlyConstraintTag())
 	assert.True(t, StrategyDigest.WantsOnlyConstraintTag())
+	assert.False(t, StrategyCalVer.WantsOnlyConstraintTag())
 }
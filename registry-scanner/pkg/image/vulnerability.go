@@ -0,0 +1,553 @@
+package image
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+)
+
+// Artifact types of vulnerability reports attached to images as OCI referrers
+const (
+	sarifArtifactType     = "application/sarif+json"
+	cycloneDXArtifactType = "application/vnd.cyclonedx+json"
+)
+
+// Predicate types of in-toto attestations holding vulnerability reports
+const (
+	cosignVulnPredicateType = "https://cosign.sigstore.dev/attestation/vuln/v1"
+	cycloneDXPredicateType  = "https://cyclonedx.org/bom"
+	cycloneDXVEXPredicate   = "https://cyclonedx.org/vex"
+)
+
+// Severity is the severity of a vulnerability
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+	SeverityUnknown  Severity = "unknown"
+)
+
+// severityRank orders severities from the least to the most severe
+var severityRank = map[Severity]int{
+	SeverityUnknown:  0,
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// ErrNoVulnerabilityReport is returned by FetchVulnerabilityReport when no
+// vulnerability report is attached to an image
+var ErrNoVulnerabilityReport = errors.New("no vulnerability report found")
+
+// VulnerabilityPolicy defines the vulnerabilities an image may have to be
+// considered for an update.
+type VulnerabilityPolicy struct {
+	// MaxCount is the maximum number of vulnerabilities allowed per
+	// severity. Severities without an entry are not limited.
+	MaxCount map[Severity]int
+	// RequireReport rejects images that have no vulnerability report
+	RequireReport bool
+	// Fallback selects the newest older version that satisfies the policy
+	// when the newest version does not
+	Fallback bool
+}
+
+// VulnerabilityReport is the summary of the vulnerability reports attached
+// to an image
+type VulnerabilityReport struct {
+	// Counts is the number of distinct vulnerabilities per severity
+	Counts map[Severity]int
+	// Reports is the number of reports the counts were aggregated from
+	Reports int
+}
+
+// String returns the counts of the report, e.g. "2 critical, 5 high"
+func (r *VulnerabilityReport) String() string {
+	var parts []string
+	for _, s := range []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityUnknown} {
+		if n := r.Counts[s]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, s))
+		}
+	}
+	if len(parts) == 0 {
+		return "no vulnerabilities"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// VulnerabilityError is returned by VulnerabilityPolicy.Check when a report
+// exceeds the maximum number of vulnerabilities of one or more severities.
+type VulnerabilityError struct {
+	Report *VulnerabilityReport
+	// Exceeded are the severities whose maximum was exceeded, from the most
+	// to the least severe
+	Exceeded []Severity
+	MaxCount map[Severity]int
+}
+
+func (e *VulnerabilityError) Error() string {
+	parts := make([]string, 0, len(e.Exceeded))
+	for _, s := range e.Exceeded {
+		parts = append(parts, fmt.Sprintf("%d %s (max %d)", e.Report.Counts[s], s, e.MaxCount[s]))
+	}
+	return "too many vulnerabilities: " + strings.Join(parts, ", ")
+}
+
+// Check returns a *VulnerabilityError if report exceeds the policy
+func (vp *VulnerabilityPolicy) Check(report *VulnerabilityReport) error {
+	var exceeded []Severity
+	for _, s := range []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow} {
+		if limit, ok := vp.MaxCount[s]; ok && report.Counts[s] > limit {
+			exceeded = append(exceeded, s)
+		}
+	}
+	if len(exceeded) > 0 {
+		return &VulnerabilityError{Report: report, Exceeded: exceeded, MaxCount: vp.MaxCount}
+	}
+	return nil
+}
+
+// FetchVulnerabilityReport fetches the vulnerability reports attached to img
+// as OCI referrers, and counts the distinct vulnerabilities they report by
+// severity. SARIF and CycloneDX reports, as well as sigstore bundles holding
+// cosign vulnerability or CycloneDX attestations, are supported. The reports
+// are read as they are, their signatures are not verified.
+//
+// A vulnerability reported by several reports is counted once, with the
+// highest severity reported. Vulnerabilities that a CycloneDX VEX analysis
+// marks as not affecting the image are not counted.
+//
+// Returns an error wrapping ErrNoVulnerabilityReport if img has no report.
+//
+// regClient must already have NewRepository called for the image's repository.
+func FetchVulnerabilityReport(ctx context.Context, img *ContainerImage, regClient RegistryFetcher) (*VulnerabilityReport, error) {
+	logCtx := log.LoggerFromContext(ctx)
+	imgTagName := img.ImageTag.TagName
+
+	imgDigest, err := resolveManifestDigest(ctx, img.ImageTag, regClient)
+	if err != nil {
+		return nil, err
+	}
+
+	logCtx.Debugf("Fetching OCI referrers for digest %s (tag %q)", imgDigest, imgTagName)
+	referrers, referrersErr := regClient.Referrers(ctx, imgDigest)
+	if referrersErr != nil {
+		logCtx.Debugf("OCI Referrers API unavailable for tag %q (digest %s): %v — will try tag-based fallback",
+			imgTagName, imgDigest, referrersErr)
+	}
+
+	if len(reportReferrers(referrers)) == 0 {
+		fallbackTag := strings.ReplaceAll(imgDigest.String(), ":", "-")
+		logCtx.Debugf("No vulnerability report referrers found for tag %q; trying tag-based fallback %q", imgTagName, fallbackTag)
+		index, err := regClient.ManifestForTag(ctx, fallbackTag)
+		if err != nil {
+			logCtx.Debugf("Tag-based fallback %q not found for tag %q: %v", fallbackTag, imgTagName, err)
+		} else if mediaType, _, err := index.Payload(); err == nil && mediaType == ociImageIndexMediaType {
+			referrers = index.References()
+		}
+	}
+
+	report := &VulnerabilityReport{Counts: map[Severity]int{}}
+	vulns := map[string]Severity{}
+	for _, ref := range reportReferrers(referrers) {
+		found, ok, err := fetchVulnerabilities(ctx, ref, regClient)
+		if err != nil {
+			logCtx.Warnf("error reading vulnerability report %s for tag %q: %v — skipping", ref.Digest, imgTagName, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		logCtx.Debugf("Found vulnerability report at %s with %d vulnerabilities for tag %q", ref.Digest, len(found), imgTagName)
+		report.Reports += 1
+		for id, severity := range found {
+			addVulnerability(vulns, id, severity)
+		}
+	}
+
+	if report.Reports == 0 {
+		if referrersErr != nil {
+			return nil, fmt.Errorf("failed to fetch OCI referrers for digest %s: %w", imgDigest, referrersErr)
+		}
+		return nil, fmt.Errorf("%w in OCI referrers or tag-based fallback for image tag %q (digest %s)", ErrNoVulnerabilityReport, imgTagName, imgDigest)
+	}
+	for _, severity := range vulns {
+		report.Counts[severity] += 1
+	}
+	return report, nil
+}
+
+// reportReferrers returns the descriptors of refs that may hold a
+// vulnerability report
+func reportReferrers(refs []distribution.Descriptor) []distribution.Descriptor {
+	var reports []distribution.Descriptor
+	for _, ref := range refs {
+		switch ref.ArtifactType {
+		case sarifArtifactType, cycloneDXArtifactType, sigstoreBundleType:
+			reports = append(reports, ref)
+		}
+	}
+	return reports
+}
+
+// fetchVulnerabilities fetches the report stored in the manifest ref, and
+// returns the severity of each vulnerability it reports. ok is false if ref
+// does not hold a vulnerability report, e.g. a sigstore bundle holding a
+// signature.
+func fetchVulnerabilities(ctx context.Context, ref distribution.Descriptor, regClient RegistryFetcher) (vulns map[string]Severity, ok bool, err error) {
+	blob, err := fetchArtifactBlob(ctx, ref, regClient)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if ref.ArtifactType == sigstoreBundleType {
+		blob, ok, err = attestedVulnerabilityReport(blob)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+	}
+	vulns, err = parseVulnerabilityReport(blob)
+	if err != nil {
+		return nil, false, err
+	}
+	return vulns, true, nil
+}
+
+// fetchArtifactBlob fetches the first layer of the OCI image manifest ref,
+// which holds the content of artifacts such as reports and SBOMs.
+func fetchArtifactBlob(ctx context.Context, ref distribution.Descriptor, regClient RegistryFetcher) ([]byte, error) {
+	m, err := regClient.ManifestForDigest(ctx, ref.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching artifact manifest: %w", err)
+	}
+	artifactManifest, isOCI := m.(*ocischema.DeserializedManifest)
+	if !isOCI {
+		return nil, fmt.Errorf("artifact manifest is not an OCI image manifest (got %T)", m)
+	}
+	if len(artifactManifest.Layers) == 0 {
+		return nil, fmt.Errorf("no layers in artifact manifest")
+	}
+	blob, err := regClient.BlobContent(ctx, artifactManifest.Layers[0].Digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch artifact: %w", err)
+	}
+	return blob, nil
+}
+
+// attestationPredicate returns the predicate of the in-toto attestation in a
+// sigstore bundle. predicateType is empty if the bundle does not hold an
+// attestation, e.g. if it holds a signature.
+func attestationPredicate(blob []byte) (predicateType string, predicate json.RawMessage, err error) {
+	var bundle sigstoreBundle
+	if err := json.Unmarshal(blob, &bundle); err != nil {
+		return "", nil, fmt.Errorf("failed to parse sigstore bundle: %w", err)
+	}
+	if bundle.DSSEEnvelope == nil || bundle.DSSEEnvelope.PayloadType != inTotoPayloadType {
+		return "", nil, nil
+	}
+	payload, err := base64.StdEncoding.DecodeString(bundle.DSSEEnvelope.Payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to decode DSSE payload: %w", err)
+	}
+	var stmt struct {
+		PredicateType string          `json:"predicateType"`
+		Predicate     json.RawMessage `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &stmt); err != nil {
+		return "", nil, fmt.Errorf("failed to parse in-toto statement: %w", err)
+	}
+	return stmt.PredicateType, stmt.Predicate, nil
+}
+
+// attestedVulnerabilityReport returns the vulnerability report held by the
+// in-toto attestation in a sigstore bundle. ok is false if the bundle does
+// not hold a vulnerability report.
+func attestedVulnerabilityReport(blob []byte) (report []byte, ok bool, err error) {
+	predicateType, predicate, err := attestationPredicate(blob)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch predicateType {
+	case cosignVulnPredicateType:
+		var pred struct {
+			Scanner struct {
+				Result json.RawMessage `json:"result"`
+			} `json:"scanner"`
+		}
+		if err := json.Unmarshal(predicate, &pred); err != nil {
+			return nil, false, fmt.Errorf("failed to parse vulnerability predicate: %w", err)
+		}
+		return pred.Scanner.Result, true, nil
+	case cycloneDXPredicateType, cycloneDXVEXPredicate:
+		return predicate, true, nil
+	}
+	return nil, false, nil
+}
+
+// vulnerabilityReportDocument holds the top-level fields of the supported
+// report formats that identify the format
+type vulnerabilityReportDocument struct {
+	// SARIF
+	Runs json.RawMessage `json:"runs"`
+	// CycloneDX
+	BOMFormat string `json:"bomFormat"`
+	// Trivy JSON
+	Results json.RawMessage `json:"Results"`
+	// Grype JSON
+	Matches json.RawMessage `json:"matches"`
+}
+
+// parseVulnerabilityReport returns the severity of each vulnerability in a
+// SARIF, CycloneDX, Trivy JSON or Grype JSON report
+func parseVulnerabilityReport(data []byte) (map[string]Severity, error) {
+	var doc vulnerabilityReportDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse vulnerability report: %w", err)
+	}
+	switch {
+	case doc.Runs != nil:
+		return parseSARIFReport(data)
+	case doc.BOMFormat == "CycloneDX":
+		return parseCycloneDXReport(data)
+	case doc.Results != nil:
+		return parseTrivyReport(data)
+	case doc.Matches != nil:
+		return parseGrypeReport(data)
+	}
+	return nil, fmt.Errorf("unsupported vulnerability report format")
+}
+
+// parseSARIFReport returns the vulnerabilities of a SARIF report. The
+// severity of a result is read from the security-severity score of its rule,
+// or else from a severity tag of the rule, as set by Trivy.
+func parseSARIFReport(data []byte) (map[string]Severity, error) {
+	type sarifRule struct {
+		ID         string `json:"id"`
+		Properties struct {
+			SecuritySeverity json.RawMessage `json:"security-severity"`
+			Tags             []string        `json:"tags"`
+		} `json:"properties"`
+	}
+	var report struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []sarifRule `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex *int   `json:"ruleIndex"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse SARIF report: %w", err)
+	}
+
+	vulns := map[string]Severity{}
+	for _, run := range report.Runs {
+		rules := run.Tool.Driver.Rules
+		for _, result := range run.Results {
+			var rule *sarifRule
+			if result.RuleIndex != nil && *result.RuleIndex >= 0 && *result.RuleIndex < len(rules) {
+				rule = &rules[*result.RuleIndex]
+			} else {
+				for i := range rules {
+					if rules[i].ID == result.RuleID {
+						rule = &rules[i]
+						break
+					}
+				}
+			}
+
+			id := result.RuleID
+			severity := SeverityUnknown
+			if rule != nil {
+				if id == "" {
+					id = rule.ID
+				}
+				severity = sarifRuleSeverity(rule.Properties.SecuritySeverity, rule.Properties.Tags)
+			}
+			if id == "" || severity == "" {
+				continue
+			}
+			addVulnerability(vulns, id, severity)
+		}
+	}
+	return vulns, nil
+}
+
+// sarifRuleSeverity returns the severity of a SARIF rule from its
+// security-severity score, which is a string or a number, or else from its
+// tags
+func sarifRuleSeverity(score json.RawMessage, tags []string) Severity {
+	if len(score) > 0 {
+		s := strings.Trim(string(score), `"`)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return cvssSeverity(f)
+		}
+	}
+	for _, t := range tags {
+		switch severity := parseSeverity(t); severity {
+		case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow:
+			return severity
+		}
+	}
+	return SeverityUnknown
+}
+
+// cvssSeverity returns the qualitative severity of a CVSS score
+func cvssSeverity(score float64) Severity {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	}
+	return ""
+}
+
+// cycloneDXRating is the rating of a vulnerability in a CycloneDX document
+type cycloneDXRating struct {
+	Severity string `json:"severity"`
+}
+
+// parseCycloneDXReport returns the vulnerabilities of a CycloneDX BOM or VEX
+// document, with the highest severity of their ratings. Vulnerabilities
+// whose analysis states that they do not affect the image are skipped.
+func parseCycloneDXReport(data []byte) (map[string]Severity, error) {
+	var bom struct {
+		Vulnerabilities []struct {
+			ID       string            `json:"id"`
+			Ratings  []cycloneDXRating `json:"ratings"`
+			Analysis *struct {
+				State string `json:"state"`
+			} `json:"analysis"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("failed to parse CycloneDX report: %w", err)
+	}
+
+	vulns := map[string]Severity{}
+	for _, v := range bom.Vulnerabilities {
+		if v.Analysis != nil {
+			switch v.Analysis.State {
+			case "not_affected", "false_positive", "resolved", "resolved_with_pedigree":
+				continue
+			}
+		}
+		// Vulnerabilities without ratings are of unknown severity, while
+		// those only rated "none" or "info" are not counted.
+		severity := SeverityUnknown
+		for _, r := range v.Ratings {
+			if s := parseSeverity(r.Severity); s != "" && severityRank[s] > severityRank[severity] {
+				severity = s
+			}
+		}
+		if severity == SeverityUnknown && len(v.Ratings) > 0 && !slices.ContainsFunc(v.Ratings, func(r cycloneDXRating) bool {
+			return parseSeverity(r.Severity) == SeverityUnknown
+		}) {
+			continue
+		}
+		addVulnerability(vulns, v.ID, severity)
+	}
+	return vulns, nil
+}
+
+// parseTrivyReport returns the vulnerabilities of a Trivy JSON report
+func parseTrivyReport(data []byte) (map[string]Severity, error) {
+	var report struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID string `json:"VulnerabilityID"`
+				Severity        string `json:"Severity"`
+			} `json:"Vulnerabilities"`
+		} `json:"Results"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse Trivy report: %w", err)
+	}
+
+	vulns := map[string]Severity{}
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			if severity := parseSeverity(v.Severity); severity != "" {
+				addVulnerability(vulns, v.VulnerabilityID, severity)
+			}
+		}
+	}
+	return vulns, nil
+}
+
+// parseGrypeReport returns the vulnerabilities of a Grype JSON report
+func parseGrypeReport(data []byte) (map[string]Severity, error) {
+	var report struct {
+		Matches []struct {
+			Vulnerability struct {
+				ID       string `json:"id"`
+				Severity string `json:"severity"`
+			} `json:"vulnerability"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse Grype report: %w", err)
+	}
+
+	vulns := map[string]Severity{}
+	for _, m := range report.Matches {
+		if severity := parseSeverity(m.Vulnerability.Severity); severity != "" {
+			addVulnerability(vulns, m.Vulnerability.ID, severity)
+		}
+	}
+	return vulns, nil
+}
+
+// parseSeverity returns the severity named s by a scanner. Returns "" for
+// severities that do not denote a vulnerability, such as "none" or "info".
+func parseSeverity(s string) Severity {
+	switch strings.ToLower(s) {
+	case "critical":
+		return SeverityCritical
+	case "high":
+		return SeverityHigh
+	case "medium", "moderate":
+		return SeverityMedium
+	case "low", "negligible":
+		return SeverityLow
+	case "none", "info", "informational":
+		return ""
+	}
+	return SeverityUnknown
+}
+
+// addVulnerability records vulnerability id with severity in vulns, unless
+// it is already recorded with a higher severity
+func addVulnerability(vulns map[string]Severity, id string, severity Severity) {
+	if id == "" {
+		return
+	}
+	if current, ok := vulns[id]; !ok || severityRank[severity] > severityRank[current] {
+		vulns[id] = severity
+	}
+}
@@ -0,0 +1,261 @@
+package image
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	godigest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trivySARIFReport = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "Trivy", "rules": [
+      {"id": "CVE-2024-0001", "properties": {"security-severity": "9.8", "tags": ["vulnerability", "security", "CRITICAL"]}},
+      {"id": "CVE-2024-0002", "properties": {"tags": ["vulnerability", "security", "HIGH"]}},
+      {"id": "CVE-2024-0003", "properties": {"security-severity": 5.3}}
+    ]}},
+    "results": [
+      {"ruleId": "CVE-2024-0001", "ruleIndex": 0, "level": "error"},
+      {"ruleId": "CVE-2024-0001", "ruleIndex": 0, "level": "error"},
+      {"ruleId": "CVE-2024-0002", "level": "error"},
+      {"ruleId": "CVE-2024-0003", "ruleIndex": 2, "level": "warning"},
+      {"ruleId": "CVE-2024-0004", "level": "note"}
+    ]
+  }]
+}`
+
+const cycloneDXVEXReport = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "vulnerabilities": [
+    {"id": "CVE-2024-0001", "ratings": [{"severity": "high"}, {"severity": "critical"}]},
+    {"id": "CVE-2024-0002", "ratings": [{"severity": "medium"}], "analysis": {"state": "not_affected"}},
+    {"id": "CVE-2024-0003", "ratings": [{"severity": "info"}]},
+    {"id": "CVE-2024-0004"}
+  ]
+}`
+
+const trivyJSONReport = `{
+  "SchemaVersion": 2,
+  "Results": [
+    {"Target": "alpine", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-0001", "Severity": "HIGH"},
+      {"VulnerabilityID": "CVE-2024-0005", "Severity": "LOW"}
+    ]},
+    {"Target": "app", "Vulnerabilities": [{"VulnerabilityID": "CVE-2024-0001", "Severity": "CRITICAL"}]}
+  ]
+}`
+
+const grypeJSONReport = `{
+  "matches": [
+    {"vulnerability": {"id": "GHSA-aaaa-bbbb-cccc", "severity": "Medium"}},
+    {"vulnerability": {"id": "CVE-2024-0006", "severity": "Negligible"}}
+  ]
+}`
+
+func Test_parseVulnerabilityReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		report  string
+		want    map[string]Severity
+		wantErr string
+	}{
+		{
+			name:   "SARIF report with security-severity scores and severity tags",
+			report: trivySARIFReport,
+			want: map[string]Severity{
+				"CVE-2024-0001": SeverityCritical,
+				"CVE-2024-0002": SeverityHigh,
+				"CVE-2024-0003": SeverityMedium,
+				"CVE-2024-0004": SeverityUnknown,
+			},
+		},
+		{
+			name:   "CycloneDX VEX document",
+			report: cycloneDXVEXReport,
+			want: map[string]Severity{
+				"CVE-2024-0001": SeverityCritical,
+				"CVE-2024-0004": SeverityUnknown,
+			},
+		},
+		{
+			name:   "Trivy JSON report",
+			report: trivyJSONReport,
+			want: map[string]Severity{
+				"CVE-2024-0001": SeverityCritical,
+				"CVE-2024-0005": SeverityLow,
+			},
+		},
+		{
+			name:   "Grype JSON report",
+			report: grypeJSONReport,
+			want: map[string]Severity{
+				"GHSA-aaaa-bbbb-cccc": SeverityMedium,
+				"CVE-2024-0006":       SeverityLow,
+			},
+		},
+		{
+			name:    "Unsupported format",
+			report:  `{"spdxVersion": "SPDX-2.3"}`,
+			wantErr: "unsupported vulnerability report format",
+		},
+		{
+			name:    "Invalid JSON",
+			report:  `not json`,
+			wantErr: "failed to parse vulnerability report",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vulns, err := parseVulnerabilityReport([]byte(tt.report))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, vulns)
+		})
+	}
+}
+
+func Test_VulnerabilityPolicy_Check(t *testing.T) {
+	report := &VulnerabilityReport{Counts: map[Severity]int{SeverityCritical: 2, SeverityHigh: 5, SeverityLow: 10}, Reports: 1}
+	assert.Equal(t, "2 critical, 5 high, 10 low", report.String())
+
+	t.Run("Within limits", func(t *testing.T) {
+		vp := &VulnerabilityPolicy{MaxCount: map[Severity]int{SeverityCritical: 2, SeverityMedium: 0}}
+		assert.NoError(t, vp.Check(report))
+	})
+
+	t.Run("Limits exceeded", func(t *testing.T) {
+		vp := &VulnerabilityPolicy{MaxCount: map[Severity]int{SeverityCritical: 0, SeverityHigh: 5, SeverityLow: 3}}
+		err := vp.Check(report)
+		var vulnErr *VulnerabilityError
+		require.ErrorAs(t, err, &vulnErr)
+		assert.Equal(t, []Severity{SeverityCritical, SeverityLow}, vulnErr.Exceeded)
+		assert.EqualError(t, err, "too many vulnerabilities: 2 critical (max 0), 10 low (max 3)")
+	})
+
+	t.Run("No limits", func(t *testing.T) {
+		vp := &VulnerabilityPolicy{}
+		assert.NoError(t, vp.Check(report))
+	})
+
+	assert.Equal(t, "no vulnerabilities", (&VulnerabilityReport{}).String())
+}
+
+// reportFetcher returns a fetcher serving each report blob as a referrer of
+// imgDigest with the given artifact type
+func reportFetcher(imgDigest string, reports map[string]string) *mockFetcher {
+	fetcher := &mockFetcher{
+		referrers: map[string][]distribution.Descriptor{},
+		manifests: map[string]distribution.Manifest{},
+		blobs:     map[string][]byte{},
+	}
+	for blob, artifactType := range reports {
+		dgst := godigest.FromString(blob)
+		artifact := godigest.FromString("artifact-" + dgst.String())
+		fetcher.referrers[imgDigest] = append(fetcher.referrers[imgDigest], distribution.Descriptor{
+			MediaType:    "application/vnd.oci.image.manifest.v1+json",
+			ArtifactType: artifactType,
+			Digest:       artifact,
+		})
+		fetcher.manifests[artifact.String()] = &ocischema.DeserializedManifest{
+			Manifest: ocischema.Manifest{
+				Layers: []distribution.Descriptor{{MediaType: artifactType, Digest: dgst, Size: int64(len(blob))}},
+			},
+		}
+		fetcher.blobs[dgst.String()] = []byte(blob)
+	}
+	return fetcher
+}
+
+// vulnAttestationBundle returns a sigstore bundle holding a cosign
+// vulnerability attestation with the given scanner result
+func vulnAttestationBundle(t *testing.T, imgDigest, result string) string {
+	t.Helper()
+	statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"%s",`+
+		`"subject":[{"digest":{"sha256":"%s"}}],"predicate":{"scanner":{"uri":"pkg:github/aquasecurity/trivy","result":%s}}}`,
+		cosignVulnPredicateType, godigest.Digest(imgDigest).Encoded(), result)
+	blob, err := json.Marshal(sigstoreBundle{
+		DSSEEnvelope: &dsseEnvelope{
+			Payload:     base64.StdEncoding.EncodeToString([]byte(statement)),
+			PayloadType: inTotoPayloadType,
+		},
+	})
+	require.NoError(t, err)
+	return string(blob)
+}
+
+func Test_FetchVulnerabilityReport(t *testing.T) {
+	ctx := context.Background()
+	const imgManifestDigest = "sha256:ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234ccdd1234"
+
+	t.Run("Reports are aggregated by vulnerability", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		fetcher := reportFetcher(imgManifestDigest, map[string]string{
+			trivySARIFReport:   sarifArtifactType,
+			cycloneDXVEXReport: cycloneDXArtifactType,
+			vulnAttestationBundle(t, imgManifestDigest, grypeJSONReport): sigstoreBundleType,
+		})
+		report, err := FetchVulnerabilityReport(ctx, img, fetcher)
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Reports)
+		assert.Equal(t, map[Severity]int{
+			SeverityCritical: 1,
+			SeverityHigh:     1,
+			SeverityMedium:   2,
+			SeverityLow:      1,
+			SeverityUnknown:  1,
+		}, report.Counts)
+	})
+
+	t.Run("Signature bundles are ignored", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		kp := newTestKeyPair(t)
+		_, _, signature := makeDSSEBundle(t, kp.priv, imgManifestDigest)
+		fetcher := reportFetcher(imgManifestDigest, map[string]string{
+			string(signature): sigstoreBundleType,
+			trivyJSONReport:   cycloneDXArtifactType,
+		})
+		report, err := FetchVulnerabilityReport(ctx, img, fetcher)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Reports)
+		assert.Equal(t, map[Severity]int{SeverityCritical: 1, SeverityLow: 1}, report.Counts)
+	})
+
+	t.Run("Unreadable reports are skipped", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		fetcher := reportFetcher(imgManifestDigest, map[string]string{
+			`{"runs": "invalid"}`: sarifArtifactType,
+			grypeJSONReport:       sarifArtifactType,
+		})
+		report, err := FetchVulnerabilityReport(ctx, img, fetcher)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Reports)
+	})
+
+	t.Run("No report", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		_, err := FetchVulnerabilityReport(ctx, img, &mockFetcher{})
+		assert.ErrorIs(t, err, ErrNoVulnerabilityReport)
+	})
+
+	t.Run("Referrers error is propagated", func(t *testing.T) {
+		img := newTestImageTag("1.0.21", imgManifestDigest)
+		fetcher := &mockFetcher{
+			referrerErrors: map[string]error{imgManifestDigest: fmt.Errorf("registry timeout")},
+		}
+		_, err := FetchVulnerabilityReport(ctx, img, fetcher)
+		assert.ErrorContains(t, err, "registry timeout")
+		assert.NotErrorIs(t, err, ErrNoVulnerabilityReport)
+	})
+}
@@ -2,6 +2,7 @@ package options
 
 import (
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -11,10 +12,11 @@ import (
 
 // ManifestOptions define some options when retrieving image manifests
 type ManifestOptions struct {
-	platforms map[string]bool
-	mutex     sync.RWMutex
-	metadata  bool
-	logger    *logrus.Entry
+	platforms    map[string]bool
+	mutex        sync.RWMutex
+	metadata     bool
+	allPlatforms bool
+	logger       *logrus.Entry
 }
 
 // NewManifestOptions returns an initialized ManifestOptions struct
@@ -92,6 +94,58 @@ func (o *ManifestOptions) WithMetadata(val bool) *ManifestOptions {
 	return o
 }
 
+// WantsAllPlatforms returns true if a manifest list must provide each of the
+// platforms set in options
+func (o *ManifestOptions) WantsAllPlatforms() bool {
+	return o.allPlatforms
+}
+
+// WithAllPlatforms sets whether a manifest list must provide each of the
+// platforms set in options, rather than at least one of them
+func (o *ManifestOptions) WithAllPlatforms(val bool) *ManifestOptions {
+	o.allPlatforms = val
+	return o
+}
+
+// ForReferencedManifest returns a copy of the options for the manifest of a
+// single platform referenced by a manifest list. Such a manifest only has to
+// provide one of the platforms set in options, as the manifest list is
+// checked for providing all of them.
+func (o *ManifestOptions) ForReferencedManifest() *ManifestOptions {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	platforms := make(map[string]bool, len(o.platforms))
+	for k, v := range o.platforms {
+		platforms[k] = v
+	}
+	return &ManifestOptions{
+		platforms: platforms,
+		metadata:  o.metadata,
+		logger:    o.logger,
+	}
+}
+
+// MissingPlatforms returns the platforms set in options that none of the
+// provided platform keys match, in sorted order. A platform without variant
+// is matched by any of its variants.
+func (o *ManifestOptions) MissingPlatforms(provided ...string) []string {
+	matched := map[string]bool{}
+	for _, key := range provided {
+		matched[key] = true
+		// A variant, e.g. linux/arm64/v8, also provides linux/arm64
+		if parts := strings.SplitN(key, "/", 3); len(parts) == 3 {
+			matched[PlatformKey(parts[0], parts[1], "")] = true
+		}
+	}
+	missing := []string{}
+	for _, platform := range o.Platforms() {
+		if !matched[platform] {
+			missing = append(missing, platform)
+		}
+	}
+	return missing
+}
+
 // WithLogger sets the logrus entry to use for the given manifest options.
 func (o *ManifestOptions) WithLogger(logger *logrus.Entry) *ManifestOptions {
 	o.logger = logger
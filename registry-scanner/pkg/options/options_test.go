@@ -72,6 +72,37 @@ func Test_WantsMetadata(t *testing.T) {
 	})
 }
 
+func Test_AllPlatforms(t *testing.T) {
+	opts := NewManifestOptions().
+		WithPlatform("linux", "amd64", "").
+		WithPlatform("linux", "arm64", "")
+	t.Run("Empty options", func(t *testing.T) {
+		assert.False(t, opts.WantsAllPlatforms())
+	})
+	t.Run("Wants all platforms", func(t *testing.T) {
+		opts = opts.WithAllPlatforms(true)
+		assert.True(t, opts.WantsAllPlatforms())
+	})
+	t.Run("All platforms provided", func(t *testing.T) {
+		assert.Empty(t, opts.MissingPlatforms("linux/amd64", "linux/arm64/v8", "unknown/unknown"))
+	})
+	t.Run("Platform missing", func(t *testing.T) {
+		assert.Equal(t, []string{"linux/arm64"}, opts.MissingPlatforms("linux/amd64", "linux/arm/v7"))
+		assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, opts.MissingPlatforms())
+	})
+	t.Run("Referenced manifest only provides one platform", func(t *testing.T) {
+		refOpts := opts.WithMetadata(true).ForReferencedManifest()
+		assert.False(t, refOpts.WantsAllPlatforms())
+		assert.True(t, refOpts.WantsMetadata())
+		assert.Equal(t, opts.Platforms(), refOpts.Platforms())
+		assert.True(t, opts.WantsAllPlatforms())
+	})
+	t.Run("Variant is not provided by its platform", func(t *testing.T) {
+		opts := NewManifestOptions().WithPlatform("linux", "arm", "v7")
+		assert.Equal(t, []string{"linux/arm/v7"}, opts.MissingPlatforms("linux/arm"))
+	})
+}
+
 func Test_Platforms(t *testing.T) {
 	opts := NewManifestOptions()
 	t.Run("Empty platforms returns empty array", func(t *testing.T) {
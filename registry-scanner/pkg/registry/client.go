@@ -106,20 +106,30 @@ func (c credentials) SetRefreshToken(realm *url.URL, service, token string) {
 }
 
 // rateLimitTransport encapsulates our custom HTTP round tripper with rate
-// limiter from the endpoint.
+// limiter from the endpoint, and with the quota the registry reports for the
+// credentials in use, if any.
 type rateLimitTransport struct {
 	limiter   ratelimit.Limiter
 	transport http.RoundTripper
 	endpoint  *RegistryEndpoint
+	quota     *registryQuota
 }
 
 // RoundTrip is a custom RoundTrip method with rate-limiter
 func (rlt *rateLimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
 	logCtx := log.LoggerFromContext(r.Context())
 
+	if rlt.quota != nil {
+		if err := rlt.quota.wait(r.Context()); err != nil {
+			return nil, err
+		}
+	}
 	rlt.limiter.Take()
 	logCtx.Tracef("Performing HTTP %s %s", r.Method, r.URL)
 	resp, err := rlt.transport.RoundTrip(r)
+	if err == nil && rlt.quota != nil {
+		rlt.quota.update(resp.Header)
+	}
 	return resp, err
 }
 
@@ -157,10 +167,14 @@ func (clt *registryClient) NewRepository(ctx context.Context, nameInRepository s
 			auth.NewTokenHandler(clt.endpoint.GetTransport(ctx), clt.creds, nameInRepository, actions...),
 			auth.NewBasicHandler(clt.creds)))
 
-	rlt := &rateLimitTransport{
+	var rlt http.RoundTripper = &rateLimitTransport{
 		limiter:   clt.endpoint.Limiter,
 		transport: authTransport,
 		endpoint:  clt.endpoint,
+		quota:     quotaFor(clt.endpoint.RegistryAPI, clt.creds.username),
+	}
+	if clt.endpoint.Retry.Enabled() {
+		rlt = newRetryTransport(clt.endpoint, rlt)
 	}
 
 	named, err := reference.WithName(nameInRepository)
@@ -191,10 +205,24 @@ func NewClient(endpoint *RegistryEndpoint, username, password string) (RegistryC
 		username: username,
 		password: password,
 	}
-	return &registryClient{
+	clt := &registryClient{
 		creds:    creds,
 		endpoint: endpoint,
-	}, nil
+	}
+	if len(endpoint.Mirrors) == 0 {
+		return clt, nil
+	}
+
+	// Mirrors are accessed with their own credentials only
+	clients := []RegistryClient{clt}
+	for _, mirror := range endpoint.Mirrors {
+		mirrorClient, err := NewClient(mirror, "", "")
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, mirrorClient)
+	}
+	return newFailoverClient(endpoint.endpoints(), clients), nil
 }
 
 // Tags returns a list of tags for given name in repository
@@ -330,6 +358,14 @@ func (clt *registryClient) TagMetadata(ctx context.Context, manifest distributio
 			return nil, nil
 		}
 
+		// A single manifest only provides all of the requested platforms if
+		// there is no more than one of them.
+		if opts.WantsAllPlatforms() && len(opts.MissingPlatforms(options.PlatformKey(info.OS, info.Arch, info.Variant))) > 0 {
+			logCtx.Debugf("ignoring v2 manifest %v. Manifest platform: %s, required platforms: %s",
+				ti.EncodedDigest(), options.PlatformKey(info.OS, info.Arch, info.Variant), strings.Join(opts.Platforms(), ","))
+			return nil, nil
+		}
+
 		if ti.CreatedAt, err = time.Parse(time.RFC3339Nano, info.Created); err != nil {
 			return nil, err
 		}
@@ -363,6 +399,14 @@ func (clt *registryClient) TagMetadata(ctx context.Context, manifest distributio
 			return nil, nil
 		}
 
+		// A single manifest only provides all of the requested platforms if
+		// there is no more than one of them.
+		if opts.WantsAllPlatforms() && len(opts.MissingPlatforms(options.PlatformKey(info.OS, info.Arch, info.Variant))) > 0 {
+			logCtx.Debugf("ignoring OCI manifest %v. Manifest platform: %s, required platforms: %s",
+				ti.EncodedDigest(), options.PlatformKey(info.OS, info.Arch, info.Variant), strings.Join(opts.Platforms(), ","))
+			return nil, nil
+		}
+
 		if ti.CreatedAt, err = time.Parse(time.RFC3339Nano, info.Created); err != nil {
 			return nil, err
 		}
@@ -408,6 +452,16 @@ func TagInfoFromReferences(ctx context.Context, client *registryClient, opts *op
 		return nil, nil
 	}
 
+	// A manifest list that is still missing some of the requested platforms,
+	// e.g. because their builds have not yet been pushed, is not yet ready.
+	if opts.WantsAllPlatforms() {
+		if missing := opts.MissingPlatforms(platforms...); len(missing) > 0 {
+			logCtx.Debugf("Manifest list is not yet ready, as it does not contain platforms: (%s), platforms included: (%s)",
+				strings.Join(missing, ","), strings.Join(platforms, ","))
+			return nil, nil
+		}
+	}
+
 	// For some strategies, we do not need to fetch metadata for further
 	// processing.
 	if !opts.WantsMetadata() {
@@ -416,6 +470,7 @@ func TagInfoFromReferences(ctx context.Context, client *registryClient, opts *op
 
 	// Loop through all referenced manifests to get their metadata. We only
 	// consider manifests for platforms we are interested in.
+	refOpts := opts.ForReferencedManifest()
 	for _, ref := range ml {
 		logCtx.Tracef("Inspecting metadata of reference: %v", ref.Digest)
 
@@ -424,7 +479,7 @@ func TagInfoFromReferences(ctx context.Context, client *registryClient, opts *op
 			return nil, fmt.Errorf("could not fetch manifest %v: %v", ref.Digest, err)
 		}
 
-		cti, err := client.TagMetadata(ctx, man, opts)
+		cti, err := client.TagMetadata(ctx, man, refOpts)
 		if err != nil {
 			return nil, fmt.Errorf("could not fetch metadata for manifest %v: %v", ref.Digest, err)
 		}
@@ -543,6 +598,18 @@ func (clt *registryClient) Referrers(ctx context.Context, dgst digest.Digest) ([
 	return result.Manifests, nil
 }
 
+// pingStatusError is returned by ping when the registry responds with an
+// unexpected HTTP status code
+type pingStatusError struct {
+	endpoint   string
+	url        string
+	statusCode int
+}
+
+func (e *pingStatusError) Error() string {
+	return fmt.Sprintf("endpoint %s does not seem to be a valid v2 Docker Registry API (received HTTP code %d for GET %s)", e.endpoint, e.statusCode, e.url)
+}
+
 // Implementation of ping method to initialize the challenge list
 // Without this, tokenHandler and AuthorizationHandler won't work
 func ping(ctx context.Context, manager challenge.Manager, endpoint *RegistryEndpoint, versionHeader string) ([]auth.APIVersion, error) {
@@ -559,7 +626,7 @@ func ping(ctx context.Context, manager challenge.Manager, endpoint *RegistryEndp
 	defer resp.Body.Close()
 	// Let's consider only HTTP 200 and 401 valid responses for the initial request
 	if resp.StatusCode != 200 && resp.StatusCode != 401 {
-		return nil, fmt.Errorf("endpoint %s does not seem to be a valid v2 Docker Registry API (received HTTP code %d for GET %s)", endpoint.RegistryAPI, resp.StatusCode, url)
+		return nil, &pingStatusError{endpoint: endpoint.RegistryAPI, url: url, statusCode: resp.StatusCode}
 	}
 
 	if err := manager.AddResponse(resp); err != nil {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path"
 	"testing"
 	"time"
 
@@ -485,6 +486,31 @@ func TestTagInfoFromReferences(t *testing.T) {
 		_, err := TagInfoFromReferences(context.Background(), &client, opts, tagInfo, descriptor)
 		require.Error(t, err)
 	})
+	t.Run("Manifest list missing a platform is not ready when all platforms are required", func(t *testing.T) {
+		client := registryClient{
+			regClient: new(mocks.Repository),
+		}
+		tagInfo := &tag.TagInfo{CreatedAt: time.Now()}
+		opts := options.NewManifestOptions().
+			WithPlatform("linux", "amd64", "").
+			WithPlatform("linux", "arm64", "")
+		descriptor := []distribution.Descriptor{
+			{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}},
+			{Platform: &v1.Platform{OS: "unknown", Architecture: "unknown"}},
+		}
+		ti, err := TagInfoFromReferences(context.Background(), &client, opts, tagInfo, descriptor)
+		require.NoError(t, err)
+		assert.Equal(t, tagInfo, ti)
+
+		ti, err = TagInfoFromReferences(context.Background(), &client, opts.WithAllPlatforms(true), tagInfo, descriptor)
+		require.NoError(t, err)
+		assert.Nil(t, ti)
+
+		descriptor = append(descriptor, distribution.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}})
+		ti, err = TagInfoFromReferences(context.Background(), &client, opts, tagInfo, descriptor)
+		require.NoError(t, err)
+		assert.Equal(t, tagInfo, ti)
+	})
 }
 
 func Test_TagMetadata(t *testing.T) {
@@ -603,6 +629,74 @@ func Test_TagMetadata(t *testing.T) {
 		assert.Equal(t, "abc123", tagInfo.Labels["org.opencontainers.image.revision"])
 	})
 
+	t.Run("Check metadata of manifest list is filled when all platforms are required", func(t *testing.T) {
+		ts := time.Now().Truncate(time.Second)
+		blobs := map[godigest.Digest][]byte{}
+		manifests := map[godigest.Digest][]byte{}
+		var descriptors []manifestlist.ManifestDescriptor
+		for i, arch := range []string{"amd64", "arm64"} {
+			config := []byte(fmt.Sprintf(`{"created":%q,"os":"linux","architecture":%q,"config":{"Labels":{"arch":%q}}}`,
+				ts.Add(time.Duration(i)*time.Minute).Format(time.RFC3339Nano), arch, arch))
+			configDigest := godigest.FromBytes(config)
+			blobs[configDigest] = config
+			m, err := schema2.FromStruct(schema2.Manifest{
+				Versioned: specs.Versioned{SchemaVersion: 2},
+				MediaType: schema2.MediaTypeManifest,
+				Config:    distribution.Descriptor{MediaType: schema2.MediaTypeImageConfig, Digest: configDigest, Size: int64(len(config))},
+			})
+			require.NoError(t, err)
+			_, payload, err := m.Payload()
+			require.NoError(t, err)
+			manifestDigest := godigest.FromBytes(payload)
+			manifests[manifestDigest] = payload
+			descriptors = append(descriptors, manifestlist.ManifestDescriptor{
+				Descriptor: distribution.Descriptor{MediaType: schema2.MediaTypeManifest, Digest: manifestDigest, Size: int64(len(payload))},
+				Platform:   manifestlist.PlatformSpec{OS: "linux", Architecture: arch},
+			})
+		}
+		list, err := manifestlist.FromDescriptors(descriptors)
+		require.NoError(t, err)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		mux.HandleFunc("/v2/test/test/manifests/", func(w http.ResponseWriter, r *http.Request) {
+			payload, ok := manifests[godigest.Digest(path.Base(r.URL.Path))]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", schema2.MediaTypeManifest)
+			w.Header().Set("Docker-Content-Digest", path.Base(r.URL.Path))
+			_, _ = w.Write(payload)
+		})
+		mux.HandleFunc("/v2/test/test/blobs/", func(w http.ResponseWriter, r *http.Request) {
+			blob, ok := blobs[godigest.Digest(path.Base(r.URL.Path))]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write(blob)
+		})
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		client := makeClient(t, server.URL)
+		opts := options.NewManifestOptions().
+			WithPlatform("linux", "amd64", "").
+			WithPlatform("linux", "arm64", "").
+			WithAllPlatforms(true).
+			WithMetadata(true)
+		tagInfo, err := client.TagMetadata(context.Background(), list, opts)
+		require.NoError(t, err)
+		require.NotNil(t, tagInfo)
+		assert.True(t, ts.Add(time.Minute).Equal(tagInfo.CreatedAt))
+		assert.Equal(t, map[string]string{"arch": "arm64"}, tagInfo.Labels)
+		assert.True(t, opts.WantsAllPlatforms())
+	})
+
 	t.Run("Check manifest without labels", func(t *testing.T) {
 		ts := time.Now().Format(time.RFC3339Nano)
 		server, configDigest := makeConfigServer(t, `{"created":"`+ts+`"}`)
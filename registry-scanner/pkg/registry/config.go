@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/cache"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
 
 	"gopkg.in/yaml.v2"
@@ -17,19 +18,53 @@ import (
 // RegistryConfiguration represents a single repository configuration for being
 // unmarshaled from YAML.
 type RegistryConfiguration struct {
-	Name        string        `yaml:"name"`
-	ApiURL      string        `yaml:"api_url"`
-	Ping        bool          `yaml:"ping,omitempty"`
-	Credentials string        `yaml:"credentials,omitempty"`
-	CredsExpire time.Duration `yaml:"credsexpire,omitempty"`
-	TagSortMode string        `yaml:"tagsortmode,omitempty"`
-	Prefix      string        `yaml:"prefix,omitempty"`
-	Insecure    bool          `yaml:"insecure,omitempty"`
-	CAFile      string        `yaml:"ca_file,omitempty"`
-	CAData      string        `yaml:"ca_data,omitempty"`
-	DefaultNS   string        `yaml:"defaultns,omitempty"`
-	Limit       int           `yaml:"limit,omitempty"`
-	IsDefault   bool          `yaml:"default,omitempty"`
+	Name            string                `yaml:"name"`
+	ApiURL          string                `yaml:"api_url"`
+	Ping            bool                  `yaml:"ping,omitempty"`
+	Credentials     string                `yaml:"credentials,omitempty"`
+	CredsExpire     time.Duration         `yaml:"credsexpire,omitempty"`
+	TagSortMode     string                `yaml:"tagsortmode,omitempty"`
+	Prefix          string                `yaml:"prefix,omitempty"`
+	Insecure        bool                  `yaml:"insecure,omitempty"`
+	CAFile          string                `yaml:"ca_file,omitempty"`
+	CAData          string                `yaml:"ca_data,omitempty"`
+	DefaultNS       string                `yaml:"defaultns,omitempty"`
+	Limit           int                   `yaml:"limit,omitempty"`
+	IsDefault       bool                  `yaml:"default,omitempty"`
+	Cache           *CacheConfig          `yaml:"cache,omitempty"`
+	CacheTTL        time.Duration         `yaml:"cachettl,omitempty"`
+	CacheMaxEntries int                   `yaml:"cachemaxentries,omitempty"`
+	Mirrors         []MirrorConfiguration `yaml:"mirrors,omitempty"`
+	Retry           *RetryConfig          `yaml:"retry,omitempty"`
+}
+
+// RetryConfig configures how throttled requests and requests that failed with
+// a transient server error are retried
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts of a request
+	MaxAttempts int `yaml:"maxattempts,omitempty"`
+	// Backoff is the delay before the first retry
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+	// MaxBackoff is the maximum delay before a retry
+	MaxBackoff time.Duration `yaml:"maxbackoff,omitempty"`
+}
+
+// MirrorConfiguration represents a mirror of a registry, to which requests are
+// failed over when the registry is unavailable
+type MirrorConfiguration struct {
+	ApiURL      string `yaml:"api_url"`
+	Credentials string `yaml:"credentials,omitempty"`
+	Insecure    bool   `yaml:"insecure,omitempty"`
+	CAFile      string `yaml:"ca_file,omitempty"`
+	CAData      string `yaml:"ca_data,omitempty"`
+}
+
+// CacheConfig configures the cache of tag metadata of a registry
+type CacheConfig struct {
+	// Type is the type of the cache, either memory (the default) or disk
+	Type string `yaml:"type,omitempty"`
+	// Path is the directory a disk cache stores its entries in
+	Path string `yaml:"path,omitempty"`
 }
 
 // RegistryList contains multiple RegistryConfiguration items
@@ -46,21 +81,57 @@ func newRegistryEndpointFromConfig(config RegistryConfiguration) (*RegistryEndpo
 	endpoint := NewRegistryEndpoint(config.Prefix, config.Name, config.ApiURL, config.Credentials, config.DefaultNS, config.Insecure, TagListSortFromString(config.TagSortMode), config.Limit, config.CredsExpire)
 	endpoint.CAFile = config.CAFile
 	endpoint.CAData = config.CAData
-	if config.Insecure {
-		return endpoint, nil
+	cacheOpts := cache.Options{TTL: config.CacheTTL, MaxEntries: config.CacheMaxEntries}
+	if config.Cache != nil && config.Cache.Type == cache.TypeDisk {
+		tagCache, err := cache.NewDiskCache(config.Cache.Path, cacheOpts)
+		if err != nil {
+			return nil, fmt.Errorf("could not configure cache for registry %s: %w", config.Name, err)
+		}
+		endpoint.Cache = tagCache
+	} else if cacheOpts != (cache.Options{}) {
+		endpoint.Cache = cache.NewBoundedMemCache(cacheOpts)
+	}
+	if config.Retry != nil {
+		endpoint.Retry = RetryPolicy{MaxAttempts: config.Retry.MaxAttempts, Backoff: config.Retry.Backoff, MaxBackoff: config.Retry.MaxBackoff}
+	}
+	if err := configureRootCAs(endpoint); err != nil {
+		return nil, fmt.Errorf("could not configure CA certificates for registry %s: %w", config.Name, err)
 	}
 
-	caFile := config.CAFile
+	// Mirrors serve the same images under the same prefix, so they share the
+	// registry's settings except for how they are accessed.
+	for i, mirrorConfig := range config.Mirrors {
+		mirror := NewRegistryEndpoint(config.Prefix, config.Name, mirrorConfig.ApiURL, mirrorConfig.Credentials, config.DefaultNS, mirrorConfig.Insecure, endpoint.TagListSort, config.Limit, config.CredsExpire)
+		mirror.CAFile = mirrorConfig.CAFile
+		mirror.CAData = mirrorConfig.CAData
+		mirror.Cache = endpoint.Cache
+		mirror.Retry = endpoint.Retry
+		if err := configureRootCAs(mirror); err != nil {
+			return nil, fmt.Errorf("could not configure CA certificates for mirror %d of registry %s: %w", i+1, config.Name, err)
+		}
+		endpoint.Mirrors = append(endpoint.Mirrors, mirror)
+	}
+	return endpoint, nil
+}
+
+// configureRootCAs sets the CA certificates the endpoint's TLS connections are
+// verified with, unless the endpoint is insecure
+func configureRootCAs(endpoint *RegistryEndpoint) error {
+	if endpoint.Insecure {
+		return nil
+	}
+
+	caFile := endpoint.CAFile
 	if caFile == "" {
-		caFile = discoverArgoCDCAFile(config.ApiURL)
+		caFile = discoverArgoCDCAFile(endpoint.RegistryAPI)
 	}
-	rootCAs, err := loadRootCAs(caFile, config.CAData)
+	rootCAs, err := loadRootCAs(caFile, endpoint.CAData)
 	if err != nil {
-		return nil, fmt.Errorf("could not configure CA certificates for registry %s: %w", config.Name, err)
+		return err
 	}
 	endpoint.CAFile = caFile
 	endpoint.rootCAs = rootCAs
-	return endpoint, nil
+	return nil
 }
 
 func discoverArgoCDCAFile(apiURL string) string {
@@ -193,6 +264,45 @@ func ParseRegistryConfiguration(yamlSource string) (RegistryList, error) {
 				err = fmt.Errorf("unknown tag sort mode for registry %s: %s", registry.Name, registry.TagSortMode)
 			}
 		}
+
+		if err == nil {
+			if registry.CacheTTL < 0 {
+				err = fmt.Errorf("cache TTL must not be negative for registry %s", registry.Name)
+			} else if registry.CacheMaxEntries < 0 {
+				err = fmt.Errorf("maximum number of cache entries must not be negative for registry %s", registry.Name)
+			}
+		}
+
+		if err == nil && registry.Cache != nil {
+			switch registry.Cache.Type {
+			case "", cache.TypeMemory:
+			case cache.TypeDisk:
+				if registry.Cache.Path == "" {
+					err = fmt.Errorf("cache path must be specified for registry %s", registry.Name)
+				}
+			default:
+				err = fmt.Errorf("unknown cache type for registry %s: %s", registry.Name, registry.Cache.Type)
+			}
+		}
+
+		if err == nil && registry.Retry != nil {
+			if registry.Retry.MaxAttempts < 0 {
+				err = fmt.Errorf("maximum number of retry attempts must not be negative for registry %s", registry.Name)
+			} else if registry.Retry.Backoff < 0 || registry.Retry.MaxBackoff < 0 {
+				err = fmt.Errorf("retry backoff must not be negative for registry %s", registry.Name)
+			} else if registry.Retry.MaxBackoff > 0 && registry.Retry.MaxBackoff < registry.Retry.Backoff {
+				err = fmt.Errorf("maximum retry backoff must not be less than the backoff for registry %s", registry.Name)
+			}
+		}
+
+		if err == nil {
+			for i, mirror := range registry.Mirrors {
+				if mirror.ApiURL == "" {
+					err = fmt.Errorf("API URL must be specified for mirror %d of registry %s", i+1, registry.Name)
+					break
+				}
+			}
+		}
 	}
 
 	if err != nil {
@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/cache"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/image"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/test/fixture"
 
 	"github.com/stretchr/testify/assert"
@@ -105,6 +107,221 @@ registries:
 		assert.Len(t, regList.Items, 0)
 	})
 
+	t.Run("Parse disk cache from valid YAML", func(t *testing.T) {
+		registries := `
+registries:
+- name: Docker Hub
+  api_url: https://registry-1.docker.io
+  prefix: docker.io
+  cache:
+    type: disk
+    path: /var/cache/argocd-image-updater/docker.io
+`
+		regList, err := ParseRegistryConfiguration(registries)
+		require.NoError(t, err)
+		require.Len(t, regList.Items, 1)
+		assert.Equal(t, &CacheConfig{Type: "disk", Path: "/var/cache/argocd-image-updater/docker.io"}, regList.Items[0].Cache)
+	})
+
+	t.Run("Parse cache bounds from valid YAML", func(t *testing.T) {
+		registries := `
+registries:
+- name: Docker Hub
+  api_url: https://registry-1.docker.io
+  prefix: docker.io
+  cachettl: 24h
+  cachemaxentries: 10000
+`
+		regList, err := ParseRegistryConfiguration(registries)
+		require.NoError(t, err)
+		require.Len(t, regList.Items, 1)
+		assert.Equal(t, 24*time.Hour, regList.Items[0].CacheTTL)
+		assert.Equal(t, 10000, regList.Items[0].CacheMaxEntries)
+	})
+
+	t.Run("Parse from invalid YAML: negative cache bound", func(t *testing.T) {
+		registries := `
+registries:
+- name: Foobar Registry
+  api_url: https://foobar.io
+  cachemaxentries: -1
+`
+		regList, err := ParseRegistryConfiguration(registries)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must not be negative")
+		assert.Len(t, regList.Items, 0)
+	})
+
+	t.Run("Parse from invalid YAML: disk cache without path", func(t *testing.T) {
+		registries := `
+registries:
+- name: Foobar Registry
+  api_url: https://foobar.io
+  cache:
+    type: disk
+`
+		regList, err := ParseRegistryConfiguration(registries)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cache path must be specified")
+		assert.Len(t, regList.Items, 0)
+	})
+
+	t.Run("Parse from invalid YAML: invalid cache type", func(t *testing.T) {
+		registries := `
+registries:
+- name: Foobar Registry
+  api_url: https://foobar.io
+  cache:
+    type: redis
+`
+		regList, err := ParseRegistryConfiguration(registries)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown cache type")
+		assert.Len(t, regList.Items, 0)
+	})
+
+	t.Run("Parse mirrors from valid YAML", func(t *testing.T) {
+		registries := `
+registries:
+- name: Foobar Registry
+  api_url: https://foobar.io
+  prefix: foobar.io
+  mirrors:
+  - api_url: https://mirror-1.foobar.io
+    credentials: secret:foo/bar#creds
+  - api_url: https://mirror-2.foobar.io
+    insecure: true
+`
+		regList, err := ParseRegistryConfiguration(registries)
+		require.NoError(t, err)
+		require.Len(t, regList.Items, 1)
+		assert.Equal(t, []MirrorConfiguration{
+			{ApiURL: "https://mirror-1.foobar.io", Credentials: "secret:foo/bar#creds"},
+			{ApiURL: "https://mirror-2.foobar.io", Insecure: true},
+		}, regList.Items[0].Mirrors)
+	})
+
+	t.Run("Parse from invalid YAML: mirror without API URL", func(t *testing.T) {
+		registries := `
+registries:
+- name: Foobar Registry
+  api_url: https://foobar.io
+  mirrors:
+  - credentials: secret:foo/bar#creds
+`
+		regList, err := ParseRegistryConfiguration(registries)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "API URL must be specified for mirror 1")
+		assert.Len(t, regList.Items, 0)
+	})
+
+	t.Run("Parse retry policy from valid YAML", func(t *testing.T) {
+		registries := `
+registries:
+- name: Docker Hub
+  api_url: https://registry-1.docker.io
+  prefix: docker.io
+  retry:
+    maxattempts: 5
+    backoff: 2s
+    maxbackoff: 1m
+`
+		regList, err := ParseRegistryConfiguration(registries)
+		require.NoError(t, err)
+		require.Len(t, regList.Items, 1)
+		assert.Equal(t, &RetryConfig{MaxAttempts: 5, Backoff: 2 * time.Second, MaxBackoff: time.Minute}, regList.Items[0].Retry)
+	})
+
+	t.Run("Parse from invalid YAML: invalid retry policy", func(t *testing.T) {
+		for retry, message := range map[string]string{
+			"{maxattempts: -1}":              "must not be negative",
+			"{backoff: -1s}":                 "must not be negative",
+			"{backoff: 10s, maxbackoff: 5s}": "must not be less than the backoff",
+		} {
+			registries := `
+registries:
+- name: Foobar Registry
+  api_url: https://foobar.io
+  retry: ` + retry
+			regList, err := ParseRegistryConfiguration(registries)
+			require.Error(t, err, retry)
+			assert.Contains(t, err.Error(), message)
+			assert.Len(t, regList.Items, 0)
+		}
+	})
+}
+
+func Test_newRegistryEndpointFromConfig_Cache(t *testing.T) {
+	t.Run("Memory cache by default", func(t *testing.T) {
+		ep, err := newRegistryEndpointFromConfig(RegistryConfiguration{Name: "Foobar", ApiURL: "https://foobar.io", Prefix: "foobar.io"})
+		require.NoError(t, err)
+		assert.IsType(t, &cache.MemCache{}, ep.Cache)
+	})
+
+	t.Run("Bounded memory cache", func(t *testing.T) {
+		ep, err := newRegistryEndpointFromConfig(RegistryConfiguration{Name: "Foobar", ApiURL: "https://foobar.io", Prefix: "foobar.io", CacheMaxEntries: 1})
+		require.NoError(t, err)
+		assert.IsType(t, &cache.MemCache{}, ep.Cache)
+		ep.Cache.SetTag("foo/bar", tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+		ep.Cache.SetTag("foo/bar", tag.NewImageTag("v1.0.1", time.Unix(0, 0), ""))
+		assert.Equal(t, 1, ep.Cache.NumEntries())
+	})
+
+	t.Run("Disk cache", func(t *testing.T) {
+		path := t.TempDir()
+		ep, err := newRegistryEndpointFromConfig(RegistryConfiguration{
+			Name:   "Foobar",
+			ApiURL: "https://foobar.io",
+			Prefix: "foobar.io",
+			Cache:  &CacheConfig{Type: cache.TypeDisk, Path: path},
+		})
+		require.NoError(t, err)
+		assert.IsType(t, &cache.DiskCache{}, ep.Cache)
+		ep.Cache.SetTag("foo/bar", tag.NewImageTag("v1.0.0", time.Unix(0, 0), ""))
+
+		ep, err = newRegistryEndpointFromConfig(RegistryConfiguration{
+			Name:   "Foobar",
+			ApiURL: "https://foobar.io",
+			Prefix: "foobar.io",
+			Cache:  &CacheConfig{Type: cache.TypeDisk, Path: path},
+		})
+		require.NoError(t, err)
+		assert.True(t, ep.Cache.HasTag("foo/bar", "v1.0.0"))
+	})
+}
+
+func Test_newRegistryEndpointFromConfig_Mirrors(t *testing.T) {
+	ep, err := newRegistryEndpointFromConfig(RegistryConfiguration{
+		Name:      "Foobar",
+		ApiURL:    "https://foobar.io",
+		Prefix:    "foobar.io",
+		DefaultNS: "library",
+		Retry:     &RetryConfig{MaxAttempts: 3},
+		Mirrors: []MirrorConfiguration{
+			{ApiURL: "https://mirror.foobar.io/", Credentials: "env:MIRROR_CREDS", Insecure: true},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, ep.Mirrors, 1)
+	mirror := ep.Mirrors[0]
+	assert.Equal(t, "https://mirror.foobar.io", mirror.RegistryAPI)
+	assert.Equal(t, "foobar.io", mirror.RegistryPrefix)
+	assert.Equal(t, "library", mirror.DefaultNS)
+	assert.Equal(t, "env:MIRROR_CREDS", mirror.Credentials)
+	assert.True(t, mirror.Insecure)
+	assert.False(t, ep.Insecure)
+	assert.Same(t, ep.Cache, mirror.Cache)
+	assert.Equal(t, RetryPolicy{MaxAttempts: 3}, ep.Retry)
+	assert.Equal(t, ep.Retry, mirror.Retry)
+
+	_, err = newRegistryEndpointFromConfig(RegistryConfiguration{
+		Name:    "Foobar",
+		ApiURL:  "https://foobar.io",
+		Prefix:  "foobar.io",
+		Mirrors: []MirrorConfiguration{{ApiURL: "https://mirror.foobar.io", CAData: "invalid"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mirror 1 of registry Foobar")
 }
 
 func Test_LoadRegistryConfiguration(t *testing.T) {
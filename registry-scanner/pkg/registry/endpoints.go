@@ -94,9 +94,13 @@ type RegistryEndpoint struct {
 	Cache          cache.ImageTagCache
 	Limiter        ratelimit.Limiter
 	IsDefault      bool
+	Mirrors        []*RegistryEndpoint
+	Retry          RetryPolicy
 	lock           sync.RWMutex
 	limit          int
 	rootCAs        *x509.CertPool
+	health         endpointHealth
+	retries        retryCounters
 }
 
 // registryTweaks should contain a list of registries whose settings cannot be
@@ -256,6 +260,76 @@ func GetRegistryEndpoint(ctx context.Context, img *image.ContainerImage) (*Regis
 	}
 }
 
+// InvalidateCachedTags evicts the cached metadata of the tag tagName of img,
+// or of all of its tags if tagName is empty, e.g. because the tag was pushed
+// again. Images of registries that have not been used yet have no cached tags,
+// and are ignored.
+func InvalidateCachedTags(ctx context.Context, img *image.ContainerImage, tagName string) {
+	log := log.LoggerFromContext(ctx)
+
+	var ep *RegistryEndpoint
+	if img.RegistryURL == "" {
+		ep = defaultRegistry
+	} else {
+		registryLock.RLock()
+		ep = registries[img.RegistryURL]
+		registryLock.RUnlock()
+		if ep == nil {
+			ep = findRegistryEndpointByImage(ctx, img)
+		}
+	}
+	if ep == nil {
+		return
+	}
+
+	nameInRegistry := ep.nameInRegistry(img)
+	if tagName == "" {
+		log.Debugf("Invalidating cached tags of %s in registry %s", nameInRegistry, ep.RegistryName)
+		ep.Cache.DeleteImage(nameInRegistry)
+	} else {
+		log.Debugf("Invalidating cached tag %s:%s in registry %s", nameInRegistry, tagName, ep.RegistryName)
+		ep.Cache.DeleteTag(nameInRegistry, tagName)
+	}
+}
+
+// CacheStats returns the statistics of the tag caches of all registry
+// endpoints, by registry prefix
+func CacheStats() map[string]cache.Stats {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	stats := make(map[string]cache.Stats, len(registries))
+	for prefix, ep := range registries {
+		stats[prefix] = ep.Cache.Stats()
+	}
+	return stats
+}
+
+// MirrorStats returns the statistics of the endpoints of all registries that
+// have mirrors, by registry prefix
+func MirrorStats() map[string][]EndpointStats {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	stats := make(map[string][]EndpointStats)
+	for prefix, ep := range registries {
+		if len(ep.Mirrors) > 0 {
+			stats[prefix] = ep.EndpointStats()
+		}
+	}
+	return stats
+}
+
+// RegistryRetryStats returns the counters of the retries of the requests to
+// all registries, by registry prefix
+func RegistryRetryStats() map[string]RetryStats {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	stats := make(map[string]RetryStats, len(registries))
+	for prefix, ep := range registries {
+		stats[prefix] = ep.RetryStats()
+	}
+	return stats
+}
+
 // SetDefaultRegistry sets a given registry endpoint as the default
 func SetDefaultRegistry(ctx context.Context, ep *RegistryEndpoint) {
 	logCtx := log.LoggerFromContext(ctx)
@@ -327,6 +401,13 @@ func (ep *RegistryEndpoint) DeepCopy() *RegistryEndpoint {
 	newEp.IsDefault = ep.IsDefault
 	newEp.limit = ep.limit
 	newEp.rootCAs = ep.rootCAs
+	newEp.Retry = ep.Retry
+	// Mirrors serve the same tags as the registry, and share its cache
+	for _, mirror := range ep.Mirrors {
+		mirrorEp := mirror.DeepCopy()
+		mirrorEp.Cache = newEp.Cache
+		newEp.Mirrors = append(newEp.Mirrors, mirrorEp)
+	}
 	ep.lock.RUnlock()
 	return newEp
 }
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/image"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -291,6 +292,17 @@ func Test_DeepCopy(t *testing.T) {
 		assert.Equal(t, ep.Username, newEp.Username)
 		assert.Equal(t, ep.Ping, newEp.Ping)
 	})
+
+	t.Run("DeepCopy endpoint object with mirrors", func(t *testing.T) {
+		ep := NewRegistryEndpoint("foobar.io", "Foobar", "https://foobar.io", "", "", false, TagListSortUnsorted, 0, 0)
+		ep.Mirrors = append(ep.Mirrors, NewRegistryEndpoint("foobar.io", "Foobar", "https://mirror.foobar.io", "env:MIRROR_CREDS", "", false, TagListSortUnsorted, 0, 0))
+		newEp := ep.DeepCopy()
+		require.Len(t, newEp.Mirrors, 1)
+		assert.NotSame(t, ep.Mirrors[0], newEp.Mirrors[0])
+		assert.Equal(t, "https://mirror.foobar.io", newEp.Mirrors[0].RegistryAPI)
+		assert.Equal(t, "env:MIRROR_CREDS", newEp.Mirrors[0].Credentials)
+		assert.Same(t, newEp.Cache, newEp.Mirrors[0].Cache)
+	})
 }
 
 func Test_GetTagListSortFromString(t *testing.T) {
@@ -582,3 +594,54 @@ func TestIsTransportValid(t *testing.T) {
 		assert.False(t, isTransportValid(transport), "Transport with invalid settings should be invalid")
 	})
 }
+
+func Test_InvalidateCachedTags(t *testing.T) {
+	ctx := context.Background()
+	RestoreDefaultRegistryConfiguration(ctx)
+	defer RestoreDefaultRegistryConfiguration(ctx)
+
+	require.NoError(t, AddRegistryEndpoint(ctx, NewRegistryEndpoint("quay.io", "Quay", "https://quay.io", "", "", false, TagListSortUnsorted, 0, 0)))
+	quay, err := GetRegistryEndpoint(ctx, &image.ContainerImage{RegistryURL: "quay.io"})
+	require.NoError(t, err)
+	dockerHub := GetDefaultRegistry(ctx)
+	for _, tagName := range []string{"1.0", "1.1"} {
+		quay.Cache.SetTag("jannfis/foobar", tag.NewImageTag(tagName, time.Unix(0, 0), ""))
+		dockerHub.Cache.SetTag("library/nginx", tag.NewImageTag(tagName, time.Unix(0, 0), ""))
+	}
+
+	t.Run("Single tag", func(t *testing.T) {
+		InvalidateCachedTags(ctx, &image.ContainerImage{RegistryURL: "quay.io", ImageName: "jannfis/foobar"}, "1.0")
+		assert.False(t, quay.Cache.HasTag("jannfis/foobar", "1.0"))
+		assert.True(t, quay.Cache.HasTag("jannfis/foobar", "1.1"))
+	})
+
+	t.Run("All tags in the default namespace of the default registry", func(t *testing.T) {
+		InvalidateCachedTags(ctx, &image.ContainerImage{ImageName: "nginx"}, "")
+		assert.Equal(t, 0, dockerHub.Cache.NumEntries())
+	})
+
+	t.Run("Unknown registry", func(t *testing.T) {
+		InvalidateCachedTags(ctx, &image.ContainerImage{RegistryURL: "registry.example.com", ImageName: "jannfis/foobar"}, "")
+		assert.NotContains(t, ConfiguredEndpoints(), "registry.example.com")
+		assert.Equal(t, uint64(1), CacheStats()["quay.io"].Invalidated)
+	})
+}
+
+func Test_MirrorStats(t *testing.T) {
+	ctx := context.Background()
+	RestoreDefaultRegistryConfiguration(ctx)
+	defer RestoreDefaultRegistryConfiguration(ctx)
+
+	ep := NewRegistryEndpoint("quay.io", "Quay", "https://quay.io", "", "", false, TagListSortUnsorted, 0, 0)
+	ep.Mirrors = append(ep.Mirrors, NewRegistryEndpoint("quay.io", "Quay", "https://mirror.example.com", "", "", false, TagListSortUnsorted, 0, 0))
+	require.NoError(t, AddRegistryEndpoint(ctx, ep))
+	ep.health.failure()
+	ep.Mirrors[0].health.success()
+
+	stats := MirrorStats()
+	assert.NotContains(t, stats, "docker.io")
+	assert.Equal(t, []EndpointStats{
+		{Endpoint: "https://quay.io", Failed: 1},
+		{Endpoint: "https://mirror.example.com", Served: 1},
+	}, stats["quay.io"])
+}
@@ -0,0 +1,310 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/options"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry/internal/client"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
+)
+
+const (
+	// circuitBreakerThreshold is the number of consecutive failures after
+	// which requests are no longer sent to an endpoint
+	circuitBreakerThreshold = 3
+	// circuitBreakerCooldown is the time for which no requests are sent to an
+	// endpoint after its circuit breaker opened
+	circuitBreakerCooldown = 30 * time.Second
+)
+
+// endpointHealth tracks the availability of a registry endpoint that has
+// mirrors, or is one. It acts as circuit breaker, which opens after the
+// endpoint failed repeatedly, and counts the requests the endpoint served and
+// failed. Its zero value is ready for use.
+type endpointHealth struct {
+	lock      sync.Mutex
+	now       func() time.Time
+	failures  int
+	openUntil time.Time
+
+	served atomic.Uint64
+	failed atomic.Uint64
+}
+
+// clock returns the current time
+func (h *endpointHealth) clock() time.Time {
+	if h.now == nil {
+		return time.Now()
+	}
+	return h.now()
+}
+
+// available returns false while the circuit breaker is open. Once the
+// cooldown has passed, requests are sent to the endpoint again, and a single
+// further failure re-opens the circuit breaker.
+func (h *endpointHealth) available() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return !h.clock().Before(h.openUntil)
+}
+
+// isOpen returns true if the circuit breaker is open
+func (h *endpointHealth) isOpen() bool {
+	return !h.available()
+}
+
+// success records a request the endpoint served, and closes the circuit
+// breaker
+func (h *endpointHealth) success() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.failures = 0
+	h.openUntil = time.Time{}
+	h.served.Add(1)
+}
+
+// failure records a request the endpoint failed, and returns true if the
+// circuit breaker opened because of it
+func (h *endpointHealth) failure() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.failed.Add(1)
+	h.failures++
+	if h.failures < circuitBreakerThreshold {
+		return false
+	}
+	now := h.clock()
+	wasOpen := now.Before(h.openUntil)
+	h.openUntil = now.Add(circuitBreakerCooldown)
+	return !wasOpen
+}
+
+// EndpointStats are the statistics of a single endpoint of a registry that
+// has mirrors
+type EndpointStats struct {
+	// Endpoint is the API URL of the endpoint
+	Endpoint string
+	// Served is the number of requests the endpoint served
+	Served uint64
+	// Failed is the number of requests the endpoint failed, and that were
+	// retried at the next endpoint
+	Failed uint64
+	// CircuitOpen is true while no requests are sent to the endpoint
+	CircuitOpen bool
+}
+
+// EndpointStats returns the statistics of the endpoints of the registry. The
+// primary endpoint comes first, followed by the mirrors in order.
+func (ep *RegistryEndpoint) EndpointStats() []EndpointStats {
+	endpoints := ep.endpoints()
+	stats := make([]EndpointStats, 0, len(endpoints))
+	for _, e := range endpoints {
+		stats = append(stats, EndpointStats{
+			Endpoint:    e.RegistryAPI,
+			Served:      e.health.served.Load(),
+			Failed:      e.health.failed.Load(),
+			CircuitOpen: e.health.isOpen(),
+		})
+	}
+	return stats
+}
+
+// endpoints returns the primary endpoint of the registry, followed by its
+// mirrors
+func (ep *RegistryEndpoint) endpoints() []*RegistryEndpoint {
+	return append([]*RegistryEndpoint{ep}, ep.Mirrors...)
+}
+
+// failoverClient is the RegistryClient of a registry that has mirrors. It
+// sends each request to the first available one of the registry's endpoints,
+// starting with the primary one, and fails over to the next one when an
+// endpoint cannot be reached or responds with a server error.
+type failoverClient struct {
+	endpoints []*RegistryEndpoint
+	clients   []RegistryClient
+
+	lock             sync.Mutex
+	nameInRepository string
+	ready            []bool
+}
+
+// newFailoverClient returns a failoverClient for endpoints, where clients
+// holds the client for each of the endpoints
+func newFailoverClient(endpoints []*RegistryEndpoint, clients []RegistryClient) *failoverClient {
+	return &failoverClient{
+		endpoints: endpoints,
+		clients:   clients,
+		ready:     make([]bool, len(clients)),
+	}
+}
+
+// candidates returns the indices of the endpoints to try, in order. If the
+// circuit breakers of all endpoints are open, all of them are tried anyway.
+func (fc *failoverClient) candidates() []int {
+	var available, all []int
+	for i, ep := range fc.endpoints {
+		all = append(all, i)
+		if ep.health.available() {
+			available = append(available, i)
+		}
+	}
+	if len(available) == 0 {
+		return all
+	}
+	return available
+}
+
+// prepare sets up the repository of the client at index i, unless it has been
+// set up already
+func (fc *failoverClient) prepare(ctx context.Context, i int) error {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+	if fc.ready[i] {
+		return nil
+	}
+	if err := fc.clients[i].NewRepository(ctx, fc.nameInRepository); err != nil {
+		return err
+	}
+	fc.ready[i] = true
+	return nil
+}
+
+// withFailover performs op at the first available endpoint of fc that serves
+// it. Errors other than connection and server errors are returned as is,
+// without failing over.
+func withFailover[T any](ctx context.Context, fc *failoverClient, op string, fn func(RegistryClient) (T, error)) (T, error) {
+	logCtx := log.LoggerFromContext(ctx)
+	var result T
+	var err error
+	for n, i := range fc.candidates() {
+		ep := fc.endpoints[i]
+		if n > 0 {
+			logCtx.Infof("Failing over %s for %s to %s", op, fc.nameInRepository, ep.RegistryAPI)
+		}
+		if err = fc.prepare(ctx, i); err == nil {
+			result, err = fn(fc.clients[i])
+		}
+		if err == nil {
+			ep.health.success()
+			if i > 0 {
+				logCtx.Debugf("%s for %s served by mirror %s", op, fc.nameInRepository, ep.RegistryAPI)
+			}
+			return result, nil
+		}
+		// Client errors, e.g. an unknown tag, are not a sign of the endpoint's
+		// health, and leave its circuit breaker as it is.
+		if !isFailoverError(ctx, err) {
+			return result, err
+		}
+		logCtx.Warnf("Registry endpoint %s failed %s for %s: %v", ep.RegistryAPI, op, fc.nameInRepository, err)
+		if ep.health.failure() {
+			logCtx.Warnf("Registry endpoint %s failed repeatedly, not using it for %s", ep.RegistryAPI, circuitBreakerCooldown)
+		}
+	}
+	return result, err
+}
+
+// NewRepository sets up the repository at the first available endpoint
+func (fc *failoverClient) NewRepository(ctx context.Context, nameInRepository string) error {
+	fc.lock.Lock()
+	fc.nameInRepository = nameInRepository
+	fc.ready = make([]bool, len(fc.clients))
+	fc.lock.Unlock()
+	_, err := withFailover(ctx, fc, "repository setup", func(RegistryClient) (struct{}, error) {
+		return struct{}{}, nil
+	})
+	return err
+}
+
+// Tags implements RegistryClient.Tags
+func (fc *failoverClient) Tags(ctx context.Context) ([]string, error) {
+	return withFailover(ctx, fc, "tag list", func(clt RegistryClient) ([]string, error) {
+		return clt.Tags(ctx)
+	})
+}
+
+// ManifestForTag implements RegistryClient.ManifestForTag
+func (fc *failoverClient) ManifestForTag(ctx context.Context, tagStr string) (distribution.Manifest, error) {
+	return withFailover(ctx, fc, "manifest fetch", func(clt RegistryClient) (distribution.Manifest, error) {
+		return clt.ManifestForTag(ctx, tagStr)
+	})
+}
+
+// ManifestForDigest implements RegistryClient.ManifestForDigest
+func (fc *failoverClient) ManifestForDigest(ctx context.Context, dgst digest.Digest) (distribution.Manifest, error) {
+	return withFailover(ctx, fc, "manifest fetch", func(clt RegistryClient) (distribution.Manifest, error) {
+		return clt.ManifestForDigest(ctx, dgst)
+	})
+}
+
+// TagMetadata implements RegistryClient.TagMetadata
+func (fc *failoverClient) TagMetadata(ctx context.Context, manifest distribution.Manifest, opts *options.ManifestOptions) (*tag.TagInfo, error) {
+	return withFailover(ctx, fc, "metadata fetch", func(clt RegistryClient) (*tag.TagInfo, error) {
+		return clt.TagMetadata(ctx, manifest, opts)
+	})
+}
+
+// Referrers implements RegistryClient.Referrers
+func (fc *failoverClient) Referrers(ctx context.Context, dgst digest.Digest) ([]distribution.Descriptor, error) {
+	return withFailover(ctx, fc, "referrers fetch", func(clt RegistryClient) ([]distribution.Descriptor, error) {
+		return clt.Referrers(ctx, dgst)
+	})
+}
+
+// BlobContent implements RegistryClient.BlobContent
+func (fc *failoverClient) BlobContent(ctx context.Context, dgst digest.Digest) ([]byte, error) {
+	return withFailover(ctx, fc, "blob fetch", func(clt RegistryClient) ([]byte, error) {
+		return clt.BlobContent(ctx, dgst)
+	})
+}
+
+// isFailoverError reports whether err means that an endpoint is unavailable,
+// i.e. it could not be reached or responded with a server error, so that the
+// request should be retried at a mirror. Errors caused by the cancellation of
+// ctx are not, and neither are other errors of a request, such as a failed
+// TLS verification or authentication, or an exhausted quota.
+func isFailoverError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	// The HTTP client wraps all errors of a request in a url.Error, which is
+	// a net.Error itself, so only the error it wraps tells whether the
+	// endpoint could not be reached.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		var netErr net.Error
+		return errors.As(urlErr.Err, &netErr)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var statusErr *client.UnexpectedHTTPStatusError
+	if errors.As(err, &statusErr) {
+		code, _, _ := strings.Cut(statusErr.Status, " ")
+		statusCode, convErr := strconv.Atoi(code)
+		return convErr == nil && statusCode >= http.StatusInternalServerError
+	}
+	var responseErr *client.UnexpectedHTTPResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode >= http.StatusInternalServerError
+	}
+	var pingErr *pingStatusError
+	if errors.As(err, &pingErr) {
+		return pingErr.statusCode >= http.StatusInternalServerError
+	}
+	return false
+}
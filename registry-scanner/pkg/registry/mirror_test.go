@@ -0,0 +1,232 @@
+package registry
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry/internal/client"
+)
+
+// newTestRegistry returns a server that serves the tags of any repository
+// with status, and counts the requests it received in requests
+func newTestRegistry(t *testing.T, status int, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests != nil {
+			requests.Add(1)
+		}
+		if r.URL.Path == "/v2/" && status < http.StatusInternalServerError {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, _ = fmt.Fprintf(w, `{"name":"foo/bar","tags":["%s"]}`, r.Host)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestMirroredEndpoint returns an endpoint for primary, with mirrors
+func newTestMirroredEndpoint(primary string, mirrors ...string) *RegistryEndpoint {
+	ep := NewRegistryEndpoint("example.com", "Example", primary, "", "", false, TagListSortUnsorted, 0, 0)
+	for _, mirror := range mirrors {
+		ep.Mirrors = append(ep.Mirrors, NewRegistryEndpoint("example.com", "Example", mirror, "", "", false, TagListSortUnsorted, 0, 0))
+	}
+	return ep
+}
+
+func hostOf(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	return u.Host
+}
+
+func Test_FailoverClient(t *testing.T) {
+	t.Run("Endpoint without mirrors uses plain client", func(t *testing.T) {
+		clt, err := NewClient(newTestMirroredEndpoint("https://example.com"), "", "")
+		require.NoError(t, err)
+		assert.IsType(t, &registryClient{}, clt)
+	})
+
+	t.Run("Primary endpoint serves requests while available", func(t *testing.T) {
+		primary := newTestRegistry(t, http.StatusOK, nil)
+		var mirrorRequests atomic.Int32
+		mirror := newTestRegistry(t, http.StatusOK, &mirrorRequests)
+		ep := newTestMirroredEndpoint(primary.URL, mirror.URL)
+		clt, err := NewClient(ep, "", "")
+		require.NoError(t, err)
+		require.NoError(t, clt.NewRepository(context.Background(), "foo/bar"))
+		tags, err := clt.Tags(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{hostOf(t, primary)}, tags)
+		assert.Equal(t, int32(0), mirrorRequests.Load())
+	})
+
+	t.Run("Server errors fail over to mirror", func(t *testing.T) {
+		primary := newTestRegistry(t, http.StatusServiceUnavailable, nil)
+		mirror := newTestRegistry(t, http.StatusOK, nil)
+		ep := newTestMirroredEndpoint(primary.URL, mirror.URL)
+		clt, err := NewClient(ep, "", "")
+		require.NoError(t, err)
+		require.NoError(t, clt.NewRepository(context.Background(), "foo/bar"))
+		tags, err := clt.Tags(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{hostOf(t, mirror)}, tags)
+
+		stats := ep.EndpointStats()
+		require.Len(t, stats, 2)
+		assert.Equal(t, EndpointStats{Endpoint: primary.URL, Failed: 2}, stats[0])
+		assert.Equal(t, EndpointStats{Endpoint: mirror.URL, Served: 2}, stats[1])
+	})
+
+	t.Run("Connection errors fail over to the next mirror", func(t *testing.T) {
+		unreachable := newTestRegistry(t, http.StatusOK, nil)
+		unreachable.Close()
+		failing := newTestRegistry(t, http.StatusBadGateway, nil)
+		mirror := newTestRegistry(t, http.StatusOK, nil)
+		ep := newTestMirroredEndpoint(unreachable.URL, failing.URL, mirror.URL)
+		clt, err := NewClient(ep, "", "")
+		require.NoError(t, err)
+		require.NoError(t, clt.NewRepository(context.Background(), "foo/bar"))
+		tags, err := clt.Tags(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{hostOf(t, mirror)}, tags)
+	})
+
+	t.Run("Client errors do not fail over", func(t *testing.T) {
+		primary := newTestRegistry(t, http.StatusNotFound, nil)
+		var mirrorRequests atomic.Int32
+		mirror := newTestRegistry(t, http.StatusOK, &mirrorRequests)
+		ep := newTestMirroredEndpoint(primary.URL, mirror.URL)
+		clt, err := NewClient(ep, "", "")
+		require.NoError(t, err)
+		require.NoError(t, clt.NewRepository(context.Background(), "foo/bar"))
+		_, err = clt.Tags(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, int32(0), mirrorRequests.Load())
+
+		stats := ep.EndpointStats()
+		assert.Equal(t, EndpointStats{Endpoint: primary.URL, Served: 1}, stats[0])
+	})
+
+	t.Run("Client errors leave the circuit breaker as it is", func(t *testing.T) {
+		primary := newTestRegistry(t, http.StatusNotFound, nil)
+		mirror := newTestRegistry(t, http.StatusOK, nil)
+		ep := newTestMirroredEndpoint(primary.URL, mirror.URL)
+		clt, err := NewClient(ep, "", "")
+		require.NoError(t, err)
+		require.NoError(t, clt.NewRepository(context.Background(), "foo/bar"))
+		for range circuitBreakerThreshold - 1 {
+			ep.health.failure()
+		}
+		_, err = clt.Tags(context.Background())
+		require.Error(t, err)
+		assert.True(t, ep.health.failure())
+		assert.True(t, ep.EndpointStats()[0].CircuitOpen)
+	})
+
+	t.Run("Unavailable endpoint is skipped while its circuit breaker is open", func(t *testing.T) {
+		var primaryRequests atomic.Int32
+		primary := newTestRegistry(t, http.StatusInternalServerError, &primaryRequests)
+		mirror := newTestRegistry(t, http.StatusOK, nil)
+		ep := newTestMirroredEndpoint(primary.URL, mirror.URL)
+		clt, err := NewClient(ep, "", "")
+		require.NoError(t, err)
+		require.NoError(t, clt.NewRepository(context.Background(), "foo/bar"))
+		for range circuitBreakerThreshold {
+			_, err = clt.Tags(context.Background())
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(circuitBreakerThreshold), primaryRequests.Load())
+		assert.True(t, ep.EndpointStats()[0].CircuitOpen)
+
+		_, err = clt.Tags(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(circuitBreakerThreshold), primaryRequests.Load())
+	})
+
+	t.Run("All endpoints are tried when all circuit breakers are open", func(t *testing.T) {
+		var primaryRequests atomic.Int32
+		primary := newTestRegistry(t, http.StatusInternalServerError, &primaryRequests)
+		mirror := newTestRegistry(t, http.StatusInternalServerError, nil)
+		ep := newTestMirroredEndpoint(primary.URL, mirror.URL)
+		clt, err := NewClient(ep, "", "")
+		require.NoError(t, err)
+		for range circuitBreakerThreshold {
+			require.Error(t, clt.NewRepository(context.Background(), "foo/bar"))
+		}
+		err = clt.NewRepository(context.Background(), "foo/bar")
+		require.Error(t, err)
+		assert.ErrorContains(t, err, mirror.URL)
+		assert.Equal(t, int32(circuitBreakerThreshold+1), primaryRequests.Load())
+	})
+}
+
+func Test_EndpointHealth(t *testing.T) {
+	now := time.Unix(1000, 0)
+	h := &endpointHealth{now: func() time.Time { return now }}
+
+	for range circuitBreakerThreshold - 1 {
+		assert.False(t, h.failure())
+	}
+	assert.True(t, h.available())
+	assert.True(t, h.failure())
+	assert.False(t, h.available())
+
+	now = now.Add(circuitBreakerCooldown)
+	assert.True(t, h.available())
+	assert.True(t, h.failure())
+	assert.False(t, h.failure())
+	assert.False(t, h.available())
+
+	h.success()
+	assert.True(t, h.available())
+	assert.False(t, h.failure())
+	assert.Equal(t, uint64(1), h.served.Load())
+	assert.Equal(t, uint64(circuitBreakerThreshold+3), h.failed.Load())
+}
+
+func Test_IsFailoverError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		failover bool
+	}{
+		{"No error", context.Background(), nil, false},
+		{"Connection refused", context.Background(), &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"Request connection error", context.Background(), fmt.Errorf("fetching tags: %w", &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}), true},
+		{"Request TLS error", context.Background(), &url.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}}, false},
+		{"Request quota error", context.Background(), &url.Error{Op: "Get", URL: "https://example.com", Err: ErrQuotaExhausted}, false},
+		{"Server error", context.Background(), &client.UnexpectedHTTPStatusError{Status: "503 Service Unavailable"}, true},
+		{"Unparseable server error", context.Background(), &client.UnexpectedHTTPResponseError{ParseErr: errors.New("invalid"), StatusCode: http.StatusBadGateway}, true},
+		{"Ping server error", context.Background(), &pingStatusError{statusCode: http.StatusInternalServerError}, true},
+		{"Ping client error", context.Background(), &pingStatusError{statusCode: http.StatusNotFound}, false},
+		{"Other error", context.Background(), errors.New("manifest unknown"), false},
+		{"Canceled request", canceled, &url.Error{Op: "Get", URL: "https://example.com", Err: context.Canceled}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.failover, isFailoverError(tt.ctx, tt.err))
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+)
+
+const (
+	// quotaMaxDelay is the maximum time a request is delayed to stay within
+	// the quota of a registry. A request that would have to wait longer for
+	// an exhausted quota to be reset fails with ErrQuotaExhausted.
+	quotaMaxDelay = time.Minute
+	// resetAsTimestamp is the smallest value of a RateLimit-Reset header that
+	// is taken as a Unix timestamp rather than as a number of seconds
+	resetAsTimestamp = 1_000_000_000
+	// quotaResetSpacing is the time between the requests that waited for an
+	// exhausted quota to be reset, if the registry did not report the limit
+	// and window of its quota
+	quotaResetSpacing = time.Second
+)
+
+// ErrQuotaExhausted is returned for requests to a registry whose quota is
+// exhausted, and will not be reset soon
+var ErrQuotaExhausted = errors.New("registry quota exhausted")
+
+// registryQuota tracks the request quota a registry reports for a set of
+// credentials in the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers of its responses. Once less than half of the quota remains, requests
+// are paced to spread the remaining quota until it is reset.
+type registryQuota struct {
+	registry string
+	username string
+
+	lock      sync.Mutex
+	now       func() time.Time
+	known     bool
+	limit     int
+	remaining int
+	window    time.Duration
+	resetAt   time.Time
+	next      time.Time
+	spacing   time.Duration
+}
+
+// QuotaStats is the last known quota of a registry for a set of credentials
+type QuotaStats struct {
+	// Registry is the API URL of the registry
+	Registry string
+	// Username is the user name of the credentials, or empty for anonymous
+	// access
+	Username string
+	// Limit is the number of requests allowed in the quota's window, or 0 if
+	// the registry did not report it
+	Limit int
+	// Remaining is the number of requests remaining in the quota
+	Remaining int
+}
+
+// quotas holds the quotas of all registries, by API URL and user name, so
+// that all endpoints using the same credentials share their quota
+var (
+	quotas    = make(map[string]*registryQuota)
+	quotaLock sync.Mutex
+)
+
+// quotaFor returns the quota of the registry at apiURL for username
+func quotaFor(apiURL, username string) *registryQuota {
+	key := apiURL + "\x00" + username
+	quotaLock.Lock()
+	defer quotaLock.Unlock()
+	q, ok := quotas[key]
+	if !ok {
+		q = &registryQuota{registry: apiURL, username: username, now: time.Now}
+		quotas[key] = q
+	}
+	return q
+}
+
+// RegistryQuotaStats returns the last known quotas of all registries that
+// reported one, ordered by registry and user name
+func RegistryQuotaStats() []QuotaStats {
+	quotaLock.Lock()
+	all := make([]*registryQuota, 0, len(quotas))
+	for _, q := range quotas {
+		all = append(all, q)
+	}
+	quotaLock.Unlock()
+
+	var stats []QuotaStats
+	for _, q := range all {
+		if s, ok := q.stats(); ok {
+			stats = append(stats, s)
+		}
+	}
+	slices.SortFunc(stats, func(a, b QuotaStats) int {
+		return strings.Compare(a.Registry+"\x00"+a.Username, b.Registry+"\x00"+b.Username)
+	})
+	return stats
+}
+
+// stats returns the last known quota, if there is one
+func (q *registryQuota) stats() (QuotaStats, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.expire()
+	return QuotaStats{Registry: q.registry, Username: q.username, Limit: q.limit, Remaining: q.remaining}, q.known
+}
+
+// expire forgets the quota once it has been reset. Requests that have been
+// scheduled already keep their place.
+func (q *registryQuota) expire() {
+	if q.known && !q.resetAt.IsZero() && !q.now().Before(q.resetAt) {
+		q.known = false
+	}
+}
+
+// update records the quota reported in the headers of a response. Responses
+// that do not report the remaining quota are ignored.
+func (q *registryQuota) update(header http.Header) {
+	remaining, window, ok := parseQuotaHeader(header.Get("RateLimit-Remaining"))
+	if !ok {
+		return
+	}
+	limit, limitWindow, _ := parseQuotaHeader(header.Get("RateLimit-Limit"))
+	if window == 0 {
+		window = limitWindow
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	now := q.now()
+	q.known = true
+	q.remaining = remaining
+	q.limit = limit
+	q.window = window
+	if reset, _, ok := parseQuotaHeader(header.Get("RateLimit-Reset")); ok {
+		if reset >= resetAsTimestamp {
+			q.resetAt = time.Unix(int64(reset), 0)
+		} else {
+			q.resetAt = now.Add(time.Duration(reset) * time.Second)
+		}
+	} else if window > 0 {
+		// Without a reset time, the quota is assumed to be spread over a
+		// window that starts now.
+		q.resetAt = now.Add(window)
+	} else {
+		q.resetAt = time.Time{}
+	}
+}
+
+// reserve reserves the next request within the quota, and returns how long
+// to wait before sending it
+func (q *registryQuota) reserve() (time.Duration, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.expire()
+	now := q.now()
+	if !q.known || q.resetAt.IsZero() {
+		return q.queued(now), nil
+	}
+
+	untilReset := q.resetAt.Sub(now)
+	if q.remaining <= 0 {
+		if untilReset > quotaMaxDelay {
+			return 0, fmt.Errorf("%w for %s, resets in %s", ErrQuotaExhausted, q.registry, untilReset.Round(time.Second))
+		}
+		// Requests waiting for the reset are sent one after the other once
+		// the quota has been reset, rather than all at once.
+		q.spacing = quotaResetSpacing
+		if q.limit > 0 && q.window > 0 {
+			q.spacing = min(q.window/time.Duration(q.limit), quotaMaxDelay)
+		}
+		start := q.resetAt
+		if q.next.After(start) {
+			start = q.next
+		}
+		if start.Sub(now) > quotaMaxDelay {
+			return 0, fmt.Errorf("%w for %s, too many requests are waiting for its reset", ErrQuotaExhausted, q.registry)
+		}
+		q.next = start.Add(q.spacing)
+		return start.Sub(now), nil
+	}
+	if q.limit > 0 && q.remaining > q.limit/2 {
+		q.remaining--
+		return 0, nil
+	}
+
+	// Spread the remaining requests evenly until the quota is reset
+	interval := min(untilReset/time.Duration(q.remaining), quotaMaxDelay)
+	start := now
+	if q.next.After(now) {
+		start = q.next
+	}
+	q.next = start.Add(interval)
+	q.spacing = interval
+	q.remaining--
+	return start.Sub(now), nil
+}
+
+// queued returns how long to wait before sending a request while earlier
+// requests are still scheduled to be sent, and schedules it after them
+func (q *registryQuota) queued(now time.Time) time.Duration {
+	if !q.next.After(now) {
+		return 0
+	}
+	delay := q.next.Sub(now)
+	q.next = q.next.Add(q.spacing)
+	return delay
+}
+
+// wait waits until the next request may be sent within the quota
+func (q *registryQuota) wait(ctx context.Context) error {
+	delay, err := q.reserve()
+	if err != nil || delay <= 0 {
+		return err
+	}
+	log.LoggerFromContext(ctx).Debugf("Delaying request to %s by %s to stay within its quota", q.registry, delay)
+	return sleepContext(ctx, delay)
+}
+
+// parseQuotaHeader parses the value of a RateLimit header, which is a number
+// optionally followed by parameters, e.g. "76;w=21600" as sent by Docker Hub.
+// It returns the number, and the window given by the w parameter, if any.
+func parseQuotaHeader(value string) (int, time.Duration, bool) {
+	if value == "" {
+		return 0, 0, false
+	}
+	parts := strings.Split(value, ";")
+	n, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || n < 0 {
+		return 0, 0, false
+	}
+	var window time.Duration
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(strings.TrimSpace(param), "="); ok && k == "w" {
+			if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+				window = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return n, window, true
+}
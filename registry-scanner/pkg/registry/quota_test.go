@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestQuota returns a quota whose clock only advances when told to
+func newTestQuota(clock *fakeClock) *registryQuota {
+	return &registryQuota{registry: "https://registry.example.com", now: clock.Now}
+}
+
+// fakeClock is a clock that only advances when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func quotaHeader(limit, remaining, reset string) http.Header {
+	header := http.Header{}
+	for k, v := range map[string]string{"RateLimit-Limit": limit, "RateLimit-Remaining": remaining, "RateLimit-Reset": reset} {
+		if v != "" {
+			header.Set(k, v)
+		}
+	}
+	return header
+}
+
+func Test_RegistryQuota(t *testing.T) {
+	t.Run("No delay without a known quota", func(t *testing.T) {
+		q := newTestQuota(&fakeClock{now: time.Unix(1000, 0)})
+		q.update(http.Header{})
+		delay, err := q.reserve()
+		require.NoError(t, err)
+		assert.Zero(t, delay)
+		_, known := q.stats()
+		assert.False(t, known)
+	})
+
+	t.Run("No delay while more than half of the quota remains", func(t *testing.T) {
+		q := newTestQuota(&fakeClock{now: time.Unix(1000, 0)})
+		q.update(quotaHeader("100", "60", "60"))
+		for range 10 {
+			delay, err := q.reserve()
+			require.NoError(t, err)
+			assert.Zero(t, delay)
+		}
+		stats, known := q.stats()
+		assert.True(t, known)
+		assert.Equal(t, QuotaStats{Registry: "https://registry.example.com", Limit: 100, Remaining: 50}, stats)
+	})
+
+	t.Run("Requests are paced as the quota drops", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		q := newTestQuota(clock)
+		q.update(quotaHeader("100", "10", "100"))
+		var delays []time.Duration
+		for range 3 {
+			delay, err := q.reserve()
+			require.NoError(t, err)
+			delays = append(delays, delay)
+		}
+		assert.Equal(t, []time.Duration{0, 10 * time.Second, 10*time.Second + 100*time.Second/9}, delays)
+	})
+
+	t.Run("Pacing delay is bounded", func(t *testing.T) {
+		q := newTestQuota(&fakeClock{now: time.Unix(1000, 0)})
+		q.update(quotaHeader("100", "2", "3600"))
+		_, err := q.reserve()
+		require.NoError(t, err)
+		delay, err := q.reserve()
+		require.NoError(t, err)
+		assert.Equal(t, quotaMaxDelay, delay)
+	})
+
+	t.Run("Exhausted quota waits for a reset that is close", func(t *testing.T) {
+		q := newTestQuota(&fakeClock{now: time.Unix(1000, 0)})
+		q.update(quotaHeader("100", "0", "20"))
+		delay, err := q.reserve()
+		require.NoError(t, err)
+		assert.Equal(t, 20*time.Second, delay)
+	})
+
+	t.Run("Requests waiting for a reset are sent one after the other", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		q := newTestQuota(clock)
+		q.update(quotaHeader("100;w=200", "0;w=200", "20"))
+
+		var wg sync.WaitGroup
+		delays := make([]time.Duration, 5)
+		for i := range delays {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				delay, err := q.reserve()
+				assert.NoError(t, err)
+				delays[i] = delay
+			}()
+		}
+		wg.Wait()
+		slices.Sort(delays)
+		assert.Equal(t, []time.Duration{20 * time.Second, 22 * time.Second, 24 * time.Second, 26 * time.Second, 28 * time.Second}, delays)
+
+		// Requests made after the reset queue up behind the waiting ones
+		clock.Advance(21 * time.Second)
+		delay, err := q.reserve()
+		require.NoError(t, err)
+		assert.Equal(t, 9*time.Second, delay)
+	})
+
+	t.Run("Requests waiting for a reset fail once they would wait too long", func(t *testing.T) {
+		q := newTestQuota(&fakeClock{now: time.Unix(1000, 0)})
+		q.update(quotaHeader("", "0", "50"))
+		for range 11 {
+			_, err := q.reserve()
+			require.NoError(t, err)
+		}
+		_, err := q.reserve()
+		require.ErrorIs(t, err, ErrQuotaExhausted)
+	})
+
+	t.Run("Exhausted quota fails requests until a reset that is far", func(t *testing.T) {
+		q := newTestQuota(&fakeClock{now: time.Unix(1000, 0)})
+		q.update(quotaHeader("100", "0", "3600"))
+		_, err := q.reserve()
+		require.ErrorIs(t, err, ErrQuotaExhausted)
+		assert.ErrorContains(t, err, "resets in 1h0m0s")
+	})
+
+	t.Run("Quota is forgotten once it is reset", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1000, 0)}
+		q := newTestQuota(clock)
+		q.update(quotaHeader("100", "0", "3600"))
+		clock.Advance(time.Hour)
+		delay, err := q.reserve()
+		require.NoError(t, err)
+		assert.Zero(t, delay)
+		_, known := q.stats()
+		assert.False(t, known)
+	})
+
+	t.Run("Reset given as timestamp", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(2_000_000_000, 0)}
+		q := newTestQuota(clock)
+		q.update(quotaHeader("", "0", "2000000030"))
+		delay, err := q.reserve()
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, delay)
+	})
+
+	t.Run("Docker Hub quota without reset", func(t *testing.T) {
+		q := newTestQuota(&fakeClock{now: time.Unix(1000, 0)})
+		q.update(quotaHeader("100;w=21600", "0;w=21600", ""))
+		_, err := q.reserve()
+		require.ErrorIs(t, err, ErrQuotaExhausted)
+		assert.ErrorContains(t, err, "resets in 6h0m0s")
+	})
+}
+
+func Test_QuotaIsSharedByCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("RateLimit-Limit", "100;w=21600")
+		w.Header().Set("RateLimit-Remaining", "42;w=21600")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"foo/bar","tags":["v1.0.0"]}`))
+	}))
+	defer srv.Close()
+
+	ep := NewRegistryEndpoint("example.com", "Example", srv.URL, "", "", false, TagListSortUnsorted, 0, 0)
+	clt, err := NewClient(ep, "user", "password")
+	require.NoError(t, err)
+	require.NoError(t, clt.NewRepository(context.Background(), "foo/bar"))
+	_, err = clt.Tags(context.Background())
+	require.NoError(t, err)
+
+	assert.Contains(t, RegistryQuotaStats(), QuotaStats{Registry: srv.URL, Username: "user", Limit: 100, Remaining: 42})
+	assert.Same(t, quotaFor(srv.URL, "user"), quotaFor(ep.DeepCopy().RegistryAPI, "user"))
+	assert.NotSame(t, quotaFor(srv.URL, "user"), quotaFor(srv.URL, "other"))
+	_, known := quotaFor(srv.URL, "other").stats()
+	assert.False(t, known)
+}
+
+func Test_ParseQuotaHeader(t *testing.T) {
+	tests := []struct {
+		value  string
+		n      int
+		window time.Duration
+		ok     bool
+	}{
+		{"", 0, 0, false},
+		{"42", 42, 0, true},
+		{"76;w=21600", 76, 6 * time.Hour, true},
+		{"76; w=21600; comment=\"pulls\"", 76, 6 * time.Hour, true},
+		{"-1", 0, 0, false},
+		{"many", 0, 0, false},
+	}
+	for _, tt := range tests {
+		n, window, ok := parseQuotaHeader(tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.n, n, tt.value)
+		assert.Equal(t, tt.window, window, tt.value)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/image"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/kube"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/options"
 	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/tag"
 )
 
@@ -31,6 +32,16 @@ const (
 // a new registry client, and retry GetTags.
 var ErrCredentialsInvalid = fmt.Errorf("registry credentials invalid (401/403)")
 
+// nameInRegistry returns the name of img in the registry. Some registries have
+// a default namespace that is used when the image name doesn't specify one.
+// For example at Docker Hub, this is 'library'.
+func (ep *RegistryEndpoint) nameInRegistry(img *image.ContainerImage) string {
+	if len(strings.Split(img.ImageName, "/")) == 1 && ep.DefaultNS != "" {
+		return ep.DefaultNS + "/" + img.ImageName
+	}
+	return img.ImageName
+}
+
 // GetTags returns a list of available tags for the given image.
 // usingEndpointCreds must be true when the RegistryClient was built from
 // endpoint-cached credentials (i.e. no per-image pull secret was provided).
@@ -41,14 +52,9 @@ func (ep *RegistryEndpoint) GetTags(ctx context.Context, img *image.ContainerIma
 
 	logCtx := log.LoggerFromContext(ctx)
 
-	// Some registries have a default namespace that is used when the image name
-	// doesn't specify one. For example at Docker Hub, this is 'library'.
-	var nameInRegistry string
-	if len := len(strings.Split(img.ImageName, "/")); len == 1 && ep.DefaultNS != "" {
-		nameInRegistry = ep.DefaultNS + "/" + img.ImageName
+	nameInRegistry := ep.nameInRegistry(img)
+	if nameInRegistry != img.ImageName {
 		logCtx.Debugf("Using canonical image name '%s' for image '%s'", nameInRegistry, img.ImageName)
-	} else {
-		nameInRegistry = img.ImageName
 	}
 	err = regClient.NewRepository(ctx, nameInRegistry)
 	if err != nil {
@@ -99,9 +105,13 @@ func (ep *RegistryEndpoint) GetTags(ctx context.Context, img *image.ContainerIma
 	// - We use an update strategy other than latest or digest
 	// - The registry doesn't provide meta data and has tags sorted already
 	//
-	// We just create a dummy time stamp according to the registry's sort mode, if
-	// set.
-	if (vc.Strategy != image.StrategyNewestBuild && vc.Strategy != image.StrategyDigest) || ep.TagListSort.IsTimeSorted() {
+	// In both cases, filtering tags by their metadata, e.g. by a minimum tag
+	// age, or sorting them by their labels requires the real metadata. So does
+	// requiring all platforms, which needs each tag's manifest list.
+	// Otherwise, we just create a dummy time stamp according to the registry's
+	// sort mode, if set.
+	requiresAllPlatforms := vc.Options != nil && vc.Options.WantsAllPlatforms()
+	if !vc.FiltersOnMetadata() && !requiresAllPlatforms && vc.Strategy != image.StrategyLabel && ((vc.Strategy != image.StrategyNewestBuild && vc.Strategy != image.StrategyDigest) || ep.TagListSort.IsTimeSorted()) {
 		for i, tagStr := range tags {
 			var ts int
 			if ep.TagListSort == TagListSortLatestFirst {
@@ -128,8 +138,9 @@ func (ep *RegistryEndpoint) GetTags(ctx context.Context, img *image.ContainerIma
 		i += 1
 		// Look into the cache first and re-use any found item. If GetTag() returns
 		// an error, we treat it as a cache miss and just go ahead to invalidate
-		// the entry.
-		if vc.Strategy.IsCacheable() {
+		// the entry. Cached tags do not record the platforms of their manifest
+		// list, so the cache is bypassed when all platforms are required.
+		if vc.Strategy.IsCacheable() && !requiresAllPlatforms {
 			imgTag, err := ep.Cache.GetTag(nameInRegistry, tagStr)
 			if err != nil {
 				logCtx.Warnf("invalid entry for %s:%s in cache, invalidating.", nameInRegistry, imgTag.TagName)
@@ -210,6 +221,25 @@ func (ep *RegistryEndpoint) GetTags(ctx context.Context, img *image.ContainerIma
 	return tagList, err
 }
 
+// GetTagDigest resolves the digest of the manifest that tagStr currently points
+// to, in the form "sha256:<hex>". For multi-arch images, this is the digest of
+// the manifest list or image index. The repository of regClient must already
+// have been set up, e.g. by a previous call to GetTags.
+func GetTagDigest(ctx context.Context, regClient RegistryClient, tagStr string, opts *options.ManifestOptions) (string, error) {
+	ml, err := regClient.ManifestForTag(ctx, tagStr)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch manifest for tag %s: %w", tagStr, err)
+	}
+	ti, err := regClient.TagMetadata(ctx, ml, opts)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch metadata for tag %s: %w", tagStr, err)
+	}
+	if ti == nil || ti.Digest == ([32]byte{}) {
+		return "", fmt.Errorf("no digest found for tag %s", tagStr)
+	}
+	return fmt.Sprintf("sha256:%x", ti.Digest), nil
+}
+
 // credsExpiredByTime returns true when cached creds are past their validity window.
 func (ep *RegistryEndpoint) credsExpiredByTime() bool {
 	return ep.Credentials != "" && !ep.CredsUpdated.IsZero() && ep.CredsExpire > 0 && time.Since(ep.CredsUpdated) >= ep.CredsExpire
@@ -242,6 +272,14 @@ func (ep *RegistryEndpoint) SetEndpointCredentials(ctx context.Context, kubeClie
 	if err != nil {
 		return nil, err
 	}
+
+	// Mirrors always use their own credentials. A mirror whose credentials
+	// cannot be fetched is still tried, but will likely fail.
+	for _, mirror := range ep.Mirrors {
+		if _, err := mirror.SetEndpointCredentials(ctx, kubeClient, ""); err != nil {
+			log.LoggerFromContext(ctx).Warnf("Could not set credentials for mirror %s of registry %s: %v", mirror.RegistryAPI, ep.RegistryAPI, err)
+		}
+	}
 	return result.(*image.Credential), nil
 }
 
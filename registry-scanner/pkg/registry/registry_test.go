@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/distribution/distribution/v3"
 	"github.com/distribution/distribution/v3/manifest/schema2"
 
 	distclient "github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/registry/internal/client"
@@ -115,6 +116,149 @@ func Test_GetTags(t *testing.T) {
 		require.Equal(t, "1.2.1", cachedTag.TagName)
 	})
 
+	t.Run("Check for metadata being fetched with semver sort and a minimum age", func(t *testing.T) {
+		meta1 := &schema2.DeserializedManifest{}
+		created := time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC)
+		ctx := context.Background()
+		regClient := mocks.RegistryClient{}
+		regClient.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+		regClient.On("Tags", mock.Anything).Return([]string{"1.2.0", "1.2.1"}, nil)
+		regClient.On("ManifestForTag", mock.Anything, mock.Anything).Return(meta1, nil)
+		regClient.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{CreatedAt: created}, nil)
+		ep, err := GetRegistryEndpoint(ctx, &image.ContainerImage{RegistryURL: ""})
+		require.NoError(t, err)
+		ep.Cache.ClearCache()
+
+		img := image.NewFromIdentifier("foo/bar:1.2.0")
+		tl, err := ep.GetTags(ctx, img, &regClient, &image.VersionConstraint{Strategy: image.StrategySemVer, MinAge: time.Hour, Options: options.NewManifestOptions()}, true)
+		require.NoError(t, err)
+		require.Len(t, tl.Tags(), 2)
+		for _, it := range tl.SortAlphabetically() {
+			assert.Equal(t, created, *it.TagDate)
+		}
+		regClient.AssertNumberOfCalls(t, "TagMetadata", 2)
+	})
+
+	t.Run("Check for metadata being fetched with semver sort and a CEL expression on labels", func(t *testing.T) {
+		meta1 := &schema2.DeserializedManifest{}
+		ctx := context.Background()
+		regClient := mocks.RegistryClient{}
+		regClient.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+		regClient.On("Tags", mock.Anything).Return([]string{"1.2.0", "1.2.1"}, nil)
+		regClient.On("ManifestForTag", mock.Anything, mock.Anything).Return(meta1, nil)
+		regClient.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{Labels: map[string]string{"team": "a"}}, nil)
+		ep, err := GetRegistryEndpoint(ctx, &image.ContainerImage{RegistryURL: ""})
+		require.NoError(t, err)
+		ep.Cache.ClearCache()
+
+		img := image.NewFromIdentifier("foo/bar:1.2.0")
+		vc := &image.VersionConstraint{Strategy: image.StrategySemVer, Options: options.NewManifestOptions()}
+		vc.MatchFunc, vc.MatchArgs = img.ParseMatch(ctx, `cel:labels["team"] == "a"`)
+		tl, err := ep.GetTags(ctx, img, &regClient, vc, true)
+		require.NoError(t, err)
+		require.Len(t, tl.Tags(), 2)
+		for _, it := range tl.SortAlphabetically() {
+			assert.Equal(t, map[string]string{"team": "a"}, it.Labels)
+		}
+		regClient.AssertNumberOfCalls(t, "TagMetadata", 2)
+	})
+
+	t.Run("Check for metadata being fetched with semver sort and a label policy", func(t *testing.T) {
+		meta1 := &schema2.DeserializedManifest{}
+		ctx := context.Background()
+		regClient := mocks.RegistryClient{}
+		regClient.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+		regClient.On("Tags", mock.Anything).Return([]string{"1.2.0", "1.2.1"}, nil)
+		regClient.On("ManifestForTag", mock.Anything, mock.Anything).Return(meta1, nil)
+		regClient.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{Labels: map[string]string{"com.example.qa": "passed"}}, nil)
+		ep, err := GetRegistryEndpoint(ctx, &image.ContainerImage{RegistryURL: ""})
+		require.NoError(t, err)
+		ep.Cache.ClearCache()
+
+		img := image.NewFromIdentifier("foo/bar:1.2.0")
+		lp, err := image.ParseLabelPolicy([]image.LabelMatch{{Key: "com.example.qa", Value: "passed"}}, nil)
+		require.NoError(t, err)
+		vc := &image.VersionConstraint{Strategy: image.StrategySemVer, LabelPolicy: lp, Options: options.NewManifestOptions()}
+		tl, err := ep.GetTags(ctx, img, &regClient, vc, true)
+		require.NoError(t, err)
+		require.Len(t, tl.Tags(), 2)
+		for _, it := range tl.SortAlphabetically() {
+			assert.Equal(t, "passed", it.Labels["com.example.qa"])
+		}
+		regClient.AssertNumberOfCalls(t, "TagMetadata", 2)
+	})
+
+	t.Run("Check for tags missing a platform being skipped with semver sort", func(t *testing.T) {
+		complete := &schema2.DeserializedManifest{Manifest: schema2.Manifest{Config: distribution.Descriptor{Digest: "sha256:1111"}}}
+		incomplete := &schema2.DeserializedManifest{Manifest: schema2.Manifest{Config: distribution.Descriptor{Digest: "sha256:2222"}}}
+		ctx := context.Background()
+		regClient := mocks.RegistryClient{}
+		regClient.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+		regClient.On("Tags", mock.Anything).Return([]string{"1.2.0", "1.2.1"}, nil)
+		regClient.On("ManifestForTag", mock.Anything, "1.2.0").Return(complete, nil)
+		regClient.On("ManifestForTag", mock.Anything, "1.2.1").Return(incomplete, nil)
+		regClient.On("TagMetadata", mock.Anything, complete, mock.Anything).Return(&tag.TagInfo{}, nil)
+		regClient.On("TagMetadata", mock.Anything, incomplete, mock.Anything).Return(nil, nil)
+		ep, err := GetRegistryEndpoint(ctx, &image.ContainerImage{RegistryURL: ""})
+		require.NoError(t, err)
+		ep.Cache.ClearCache()
+
+		img := image.NewFromIdentifier("foo/bar:1.2.0")
+		opts := options.NewManifestOptions().WithPlatform("linux", "amd64", "").WithPlatform("linux", "arm64", "").WithAllPlatforms(true)
+		tl, err := ep.GetTags(ctx, img, &regClient, &image.VersionConstraint{Strategy: image.StrategySemVer, Options: opts}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.2.0"}, tl.Tags())
+		regClient.AssertNumberOfCalls(t, "TagMetadata", 2)
+
+		cachedTag, err := ep.Cache.GetTag("foo/bar", "1.2.1")
+		require.NoError(t, err)
+		assert.Nil(t, cachedTag)
+	})
+
+	t.Run("Check for cached tags being ignored when all platforms are required", func(t *testing.T) {
+		incomplete := &schema2.DeserializedManifest{}
+		ctx := context.Background()
+		regClient := mocks.RegistryClient{}
+		regClient.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+		regClient.On("Tags", mock.Anything).Return([]string{"1.2.1"}, nil)
+		regClient.On("ManifestForTag", mock.Anything, "1.2.1").Return(incomplete, nil)
+		regClient.On("TagMetadata", mock.Anything, incomplete, mock.Anything).Return(nil, nil)
+		ep, err := GetRegistryEndpoint(ctx, &image.ContainerImage{RegistryURL: ""})
+		require.NoError(t, err)
+		ep.Cache.ClearCache()
+		ep.Cache.SetTag("foo/bar", tag.NewImageTag("1.2.1", time.Unix(1, 0), ""))
+
+		img := image.NewFromIdentifier("foo/bar:1.2.0")
+		opts := options.NewManifestOptions().WithPlatform("linux", "amd64", "").WithPlatform("linux", "arm64", "").WithAllPlatforms(true)
+		tl, err := ep.GetTags(ctx, img, &regClient, &image.VersionConstraint{Strategy: image.StrategySemVer, Options: opts}, true)
+		require.NoError(t, err)
+		assert.Empty(t, tl.Tags())
+		regClient.AssertNumberOfCalls(t, "TagMetadata", 1)
+	})
+
+	t.Run("Check for metadata being fetched with label sort", func(t *testing.T) {
+		meta1 := &schema2.DeserializedManifest{}
+		ctx := context.Background()
+		regClient := mocks.RegistryClient{}
+		regClient.On("NewRepository", mock.Anything, mock.Anything).Return(nil)
+		regClient.On("Tags", mock.Anything).Return([]string{"build-1", "build-2"}, nil)
+		regClient.On("ManifestForTag", mock.Anything, mock.Anything).Return(meta1, nil)
+		regClient.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(&tag.TagInfo{Labels: map[string]string{image.DefaultVersionLabel: "1.2.3"}}, nil)
+		ep, err := GetRegistryEndpoint(ctx, &image.ContainerImage{RegistryURL: ""})
+		require.NoError(t, err)
+		ep.Cache.ClearCache()
+
+		img := image.NewFromIdentifier("foo/bar:build-1")
+		vc := &image.VersionConstraint{Strategy: image.StrategyLabel, Options: options.NewManifestOptions()}
+		tl, err := ep.GetTags(ctx, img, &regClient, vc, true)
+		require.NoError(t, err)
+		require.Len(t, tl.Tags(), 2)
+		for _, it := range tl.SortAlphabetically() {
+			assert.Equal(t, "1.2.3", it.Labels[image.DefaultVersionLabel])
+		}
+		regClient.AssertNumberOfCalls(t, "TagMetadata", 2)
+	})
+
 	t.Run("ManifestDigest is populated from TagInfo.EncodedDigest for StrategyNewestBuild", func(t *testing.T) {
 		// This test targets the line:
 		//   imgTag.ManifestDigest = ti.EncodedDigest()
@@ -284,6 +428,37 @@ func Test_GetTags(t *testing.T) {
 	})
 }
 
+func Test_GetTagDigest(t *testing.T) {
+	t.Run("Resolve digest of a tag", func(t *testing.T) {
+		meta1 := &schema2.DeserializedManifest{}
+		regClient := mocks.RegistryClient{}
+		regClient.On("ManifestForTag", mock.Anything, "1.2.1").Return(meta1, nil)
+		regClient.On("TagMetadata", mock.Anything, meta1, mock.Anything).Return(&tag.TagInfo{Digest: [32]byte{0xab, 0xcd}}, nil)
+
+		digest, err := GetTagDigest(context.Background(), &regClient, "1.2.1", options.NewManifestOptions())
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:abcd"+strings.Repeat("0", 60), digest)
+	})
+
+	t.Run("Error on failure to fetch manifest", func(t *testing.T) {
+		regClient := mocks.RegistryClient{}
+		regClient.On("ManifestForTag", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+
+		_, err := GetTagDigest(context.Background(), &regClient, "1.2.1", options.NewManifestOptions())
+		assert.ErrorContains(t, err, "could not fetch manifest for tag 1.2.1")
+	})
+
+	t.Run("Error on missing metadata", func(t *testing.T) {
+		meta1 := &schema2.DeserializedManifest{}
+		regClient := mocks.RegistryClient{}
+		regClient.On("ManifestForTag", mock.Anything, mock.Anything).Return(meta1, nil)
+		regClient.On("TagMetadata", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+		_, err := GetTagDigest(context.Background(), &regClient, "1.2.1", options.NewManifestOptions())
+		assert.ErrorContains(t, err, "no digest found for tag 1.2.1")
+	})
+}
+
 func Test_ExpireCredentials(t *testing.T) {
 	epYAML := `
 registries:
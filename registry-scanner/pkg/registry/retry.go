@@ -0,0 +1,189 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+)
+
+const (
+	// RetryBackoffDefault is the default delay before the first retry
+	RetryBackoffDefault = time.Second
+	// RetryMaxBackoffDefault is the default maximum delay before a retry
+	RetryMaxBackoffDefault = 30 * time.Second
+)
+
+// RetryPolicy configures how requests to a registry that were throttled or
+// failed with a transient server error are retried
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request, including
+	// the first one. Requests are not retried if it is 1 or less.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, which is doubled for every
+	// further retry
+	Backoff time.Duration
+	// MaxBackoff is the maximum delay before a retry. Requests whose
+	// Retry-After header asks for a longer delay are not retried.
+	MaxBackoff time.Duration
+}
+
+// Enabled returns true if requests are retried
+func (p RetryPolicy) Enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// withDefaults returns the policy with the default delays for those that are
+// not set
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Backoff <= 0 {
+		p.Backoff = RetryBackoffDefault
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = max(RetryMaxBackoffDefault, p.Backoff)
+	}
+	return p
+}
+
+// backoff returns the delay before the given retry, starting at 1. The delay
+// grows exponentially, and is jittered to avoid retrying in lockstep.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxBackoff)
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// RetryStats are the counters of the retries of the requests to a registry
+type RetryStats struct {
+	// Retries is the number of requests that were retried
+	Retries uint64
+	// Throttled is the number of responses asking to retry later (HTTP 429)
+	Throttled uint64
+}
+
+// retryCounters counts the retries of the requests to an endpoint
+type retryCounters struct {
+	retries   atomic.Uint64
+	throttled atomic.Uint64
+}
+
+// RetryStats returns the counters of the retries of the requests to the
+// registry and its mirrors
+func (ep *RegistryEndpoint) RetryStats() RetryStats {
+	var stats RetryStats
+	for _, e := range ep.endpoints() {
+		stats.Retries += e.retries.retries.Load()
+		stats.Throttled += e.retries.throttled.Load()
+	}
+	return stats
+}
+
+// retryTransport is an HTTP round tripper that retries requests that were
+// throttled or failed with a transient server error, according to the retry
+// policy of the endpoint. Each attempt goes through transport, and thus
+// through the endpoint's rate limiter.
+type retryTransport struct {
+	policy    RetryPolicy
+	transport http.RoundTripper
+	endpoint  *RegistryEndpoint
+}
+
+// newRetryTransport returns a retryTransport for the endpoint, wrapping transport
+func newRetryTransport(endpoint *RegistryEndpoint, transport http.RoundTripper) *retryTransport {
+	return &retryTransport{
+		policy:    endpoint.Retry.withDefaults(),
+		transport: transport,
+		endpoint:  endpoint,
+	}
+}
+
+// RoundTrip performs the request, and retries it while it is throttled or
+// fails with a transient server error, until the policy's maximum number of
+// attempts is reached. Only GET and HEAD requests are retried.
+func (rt *retryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	logCtx := log.LoggerFromContext(r.Context())
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return rt.transport.RoundTrip(r)
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := rt.transport.RoundTrip(r)
+		if err != nil || !isRetryableStatus(resp.StatusCode) {
+			return resp, err
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			rt.endpoint.retries.throttled.Add(1)
+		}
+		if attempt >= rt.policy.MaxAttempts {
+			return resp, nil
+		}
+
+		delay := rt.policy.backoff(attempt)
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if retryAfter > rt.policy.MaxBackoff {
+				logCtx.Debugf("Not retrying HTTP %s %s, registry asked to retry after %s", r.Method, r.URL, retryAfter)
+				return resp, nil
+			}
+			delay = retryAfter
+		}
+
+		// The body of the response is discarded so that the connection can
+		// be reused for the retry.
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		rt.endpoint.retries.retries.Add(1)
+		logCtx.Debugf("Registry %s responded with HTTP %d to %s %s, retrying in %s (attempt %d/%d)", rt.endpoint.RegistryAPI, resp.StatusCode, r.Method, r.URL, delay, attempt+1, rt.policy.MaxAttempts)
+		if err := sleepContext(r.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isRetryableStatus returns true if a request that received the given HTTP
+// status code may succeed when it is retried
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter returns the delay requested by the value of a Retry-After
+// header, which is either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,188 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyRegistry returns a server that responds to the first failures
+// requests with status and header, and with the tags of any repository
+// afterwards. It counts the requests it received in requests.
+func newFlakyRegistry(t *testing.T, failures int32, status int, header http.Header, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if requests.Add(1) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"foo/bar","tags":["v1.0.0"]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newRetryingEndpoint returns an endpoint for apiURL that retries requests
+func newRetryingEndpoint(apiURL string, policy RetryPolicy) *RegistryEndpoint {
+	ep := NewRegistryEndpoint("example.com", "Example", apiURL, "", "", false, TagListSortUnsorted, 0, 0)
+	ep.Retry = policy
+	return ep
+}
+
+func fetchTags(t *testing.T, ctx context.Context, ep *RegistryEndpoint) ([]string, error) {
+	t.Helper()
+	clt, err := NewClient(ep, "", "")
+	require.NoError(t, err)
+	require.NoError(t, clt.NewRepository(ctx, "foo/bar"))
+	return clt.Tags(ctx)
+}
+
+func Test_RetryTransport(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	t.Run("Requests are not retried by default", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := newFlakyRegistry(t, 1, http.StatusServiceUnavailable, nil, &requests)
+		_, err := fetchTags(t, context.Background(), newRetryingEndpoint(srv.URL, RetryPolicy{}))
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("Transient server errors are retried", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := newFlakyRegistry(t, 2, http.StatusBadGateway, nil, &requests)
+		ep := newRetryingEndpoint(srv.URL, policy)
+		tags, err := fetchTags(t, context.Background(), ep)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v1.0.0"}, tags)
+		assert.Equal(t, int32(3), requests.Load())
+		assert.Equal(t, RetryStats{Retries: 2}, ep.RetryStats())
+	})
+
+	t.Run("Requests are retried up to the maximum number of attempts", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := newFlakyRegistry(t, 3, http.StatusTooManyRequests, nil, &requests)
+		ep := newRetryingEndpoint(srv.URL, policy)
+		_, err := fetchTags(t, context.Background(), ep)
+		require.Error(t, err)
+		assert.Equal(t, int32(3), requests.Load())
+		assert.Equal(t, RetryStats{Retries: 2, Throttled: 3}, ep.RetryStats())
+	})
+
+	t.Run("Client errors are not retried", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := newFlakyRegistry(t, 1, http.StatusNotFound, nil, &requests)
+		_, err := fetchTags(t, context.Background(), newRetryingEndpoint(srv.URL, policy))
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("Retry-After is honoured", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := newFlakyRegistry(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": []string{"1"}}, &requests)
+		ep := newRetryingEndpoint(srv.URL, RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond, MaxBackoff: 2 * time.Second})
+		start := time.Now()
+		_, err := fetchTags(t, context.Background(), ep)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("Retry-After longer than the maximum backoff is not waited for", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := newFlakyRegistry(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": []string{"3600"}}, &requests)
+		ep := newRetryingEndpoint(srv.URL, policy)
+		_, err := fetchTags(t, context.Background(), ep)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+		assert.Equal(t, RetryStats{Throttled: 1}, ep.RetryStats())
+	})
+
+	t.Run("Waiting for a retry is canceled with the request", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := newFlakyRegistry(t, 1, http.StatusServiceUnavailable, http.Header{"Retry-After": []string{"10"}}, &requests)
+		ep := newRetryingEndpoint(srv.URL, RetryPolicy{MaxAttempts: 2, MaxBackoff: time.Minute})
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := fetchTags(t, ctx, ep)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("Requests other than GET and HEAD are not retried", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := newFlakyRegistry(t, 1, http.StatusServiceUnavailable, nil, &requests)
+		ep := newRetryingEndpoint(srv.URL, policy)
+		rt := newRetryTransport(ep, http.DefaultTransport)
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/v2/foo/bar/blobs/uploads/", strings.NewReader("blob"))
+		require.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
+
+func Test_RetryPolicy(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		p := RetryPolicy{MaxAttempts: 3}.withDefaults()
+		assert.Equal(t, RetryBackoffDefault, p.Backoff)
+		assert.Equal(t, RetryMaxBackoffDefault, p.MaxBackoff)
+
+		p = RetryPolicy{MaxAttempts: 3, Backoff: time.Minute}.withDefaults()
+		assert.Equal(t, time.Minute, p.MaxBackoff)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		assert.False(t, RetryPolicy{}.Enabled())
+		assert.False(t, RetryPolicy{MaxAttempts: 1}.Enabled())
+		assert.True(t, RetryPolicy{MaxAttempts: 2}.Enabled())
+	})
+
+	t.Run("Backoff grows exponentially up to the maximum", func(t *testing.T) {
+		p := RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+		for retry, expected := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 100: 5 * time.Second} {
+			delay := p.backoff(retry)
+			assert.GreaterOrEqual(t, delay, expected/2)
+			assert.LessOrEqual(t, delay, expected)
+		}
+	})
+}
+
+func Test_ParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"30", 30 * time.Second, true},
+		{"-1", 0, false},
+		{"Wed, 01 May 2024 12:01:00 GMT", time.Minute, true},
+		{"Wed, 01 May 2024 11:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		delay, ok := parseRetryAfter(tt.value, now)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.expected, delay, tt.value)
+	}
+}
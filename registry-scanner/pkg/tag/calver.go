@@ -0,0 +1,279 @@
+package tag
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+)
+
+// DefaultCalVerLayout is the layout used by the calver strategy when no
+// layout has been configured.
+const DefaultCalVerLayout = "YYYY.0M.0D"
+
+// calverToken describes a single component of a calendar versioning layout,
+// as specified on https://calver.org.
+type calverToken struct {
+	name    string
+	pattern string
+}
+
+// calverTokens holds all supported layout components. The order is important
+// for tokenizing, since longer tokens must be matched before shorter ones.
+var calverTokens = []calverToken{
+	{"MICRO", `\d+`},
+	{"YYYY", `\d{4}`},
+	{"YY", `\d{1,3}`},
+	{"0Y", `\d{2,3}`},
+	{"MM", `[1-9]|1[0-2]`},
+	{"0M", `0[1-9]|1[0-2]`},
+	{"WW", `[1-9]|[1-4][0-9]|5[0-3]`},
+	{"0W", `0[1-9]|[1-4][0-9]|5[0-3]`},
+	{"DD", `[1-9]|[12][0-9]|3[01]`},
+	{"0D", `0[1-9]|[12][0-9]|3[01]`},
+}
+
+// CalVerLayout is a parsed calendar versioning layout, such as YYYY.0M.0D or
+// YY.0M-MICRO. Use ParseCalVerLayout to initialize a new object.
+type CalVerLayout struct {
+	layout string
+	re     *regexp.Regexp
+	// tokens holds the name of the token for each capture group in re
+	tokens []string
+}
+
+// CalVersion is a version parsed from a tag according to a CalVerLayout.
+// Components that are not part of the layout are zero.
+type CalVersion struct {
+	Year  int
+	Month int
+	Week  int
+	Day   int
+	Micro int
+}
+
+// ParseCalVerLayout parses a calendar versioning layout. The layout consists
+// of the tokens YYYY, YY, 0Y, MM, 0M, WW, 0W, DD, 0D and MICRO, separated by
+// arbitrary literal characters. A year token is mandatory.
+func ParseCalVerLayout(layout string) (*CalVerLayout, error) {
+	if layout == "" {
+		return nil, fmt.Errorf("calver layout must not be empty")
+	}
+
+	cl := &CalVerLayout{layout: layout}
+	seen := map[byte]bool{}
+	pattern := strings.Builder{}
+	pattern.WriteString("^")
+	literal := strings.Builder{}
+
+	for rest := layout; rest != ""; {
+		var token *calverToken
+		for i := range calverTokens {
+			if strings.HasPrefix(rest, calverTokens[i].name) {
+				token = &calverTokens[i]
+				break
+			}
+		}
+		if token == nil {
+			literal.WriteByte(rest[0])
+			rest = rest[1:]
+			continue
+		}
+
+		// All year tokens share the same kind, as do all month, week and day
+		// tokens. Each kind must only appear once in a layout.
+		kind := token.name[len(token.name)-1]
+		if seen[kind] {
+			return nil, fmt.Errorf("invalid calver layout %q: duplicate component %s", layout, token.name)
+		}
+		seen[kind] = true
+
+		pattern.WriteString(regexp.QuoteMeta(literal.String()))
+		literal.Reset()
+		pattern.WriteString("(" + token.pattern + ")")
+		cl.tokens = append(cl.tokens, token.name)
+		rest = rest[len(token.name):]
+	}
+	pattern.WriteString(regexp.QuoteMeta(literal.String()))
+	pattern.WriteString("$")
+
+	switch {
+	case !seen['Y']:
+		return nil, fmt.Errorf("invalid calver layout %q: a year component is required", layout)
+	case seen['M'] && seen['W']:
+		return nil, fmt.Errorf("invalid calver layout %q: month and week components are mutually exclusive", layout)
+	case seen['D'] && !seen['M']:
+		return nil, fmt.Errorf("invalid calver layout %q: a day component requires a month component", layout)
+	}
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid calver layout %q: %w", layout, err)
+	}
+	cl.re = re
+
+	return cl, nil
+}
+
+// String returns the layout in its original notation
+func (cl *CalVerLayout) String() string {
+	return cl.layout
+}
+
+// Parse parses tagName according to the layout. Returns an error if the tag
+// does not match the layout.
+func (cl *CalVerLayout) Parse(tagName string) (*CalVersion, error) {
+	m := cl.re.FindStringSubmatch(tagName)
+	if m == nil {
+		return nil, fmt.Errorf("tag %s does not match calver layout %s", tagName, cl.layout)
+	}
+
+	cv := &CalVersion{}
+	for i, token := range cl.tokens {
+		val, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s component of tag %s: %w", token, tagName, err)
+		}
+		switch token {
+		case "YYYY":
+			cv.Year = val
+		case "YY", "0Y":
+			cv.Year = 2000 + val
+		case "MM", "0M":
+			cv.Month = val
+		case "WW", "0W":
+			cv.Week = val
+		case "DD", "0D":
+			cv.Day = val
+		case "MICRO":
+			cv.Micro = val
+		}
+	}
+
+	return cv, nil
+}
+
+// HasMonth returns true if the layout contains a month component
+func (cl *CalVerLayout) HasMonth() bool {
+	return cl.hasToken("MM", "0M")
+}
+
+func (cl *CalVerLayout) hasToken(names ...string) bool {
+	for _, t := range cl.tokens {
+		for _, n := range names {
+			if t == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Compare compares cv to other, returning -1, 0 or 1 if cv is older than,
+// equal to or newer than other.
+func (cv *CalVersion) Compare(other *CalVersion) int {
+	for _, p := range [][2]int{
+		{cv.Year, other.Year},
+		{cv.Month, other.Month},
+		{cv.Week, other.Week},
+		{cv.Day, other.Day},
+		{cv.Micro, other.Micro},
+	} {
+		if p[0] < p[1] {
+			return -1
+		} else if p[0] > p[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Date returns the first day of the period that is denoted by the version's
+// date components, e.g. the 1st of January for a version that only carries a
+// year, or the Monday of the ISO week for a version that carries a week.
+func (cv *CalVersion) Date() time.Time {
+	switch {
+	case cv.Week > 0:
+		// January 4th is always part of the first ISO week of a year
+		jan4 := time.Date(cv.Year, time.January, 4, 0, 0, 0, 0, time.UTC)
+		monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, (cv.Week-1)*7)
+	case cv.Month > 0:
+		day := cv.Day
+		if day == 0 {
+			day = 1
+		}
+		return time.Date(cv.Year, time.Month(cv.Month), day, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(cv.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// End returns the first day after the period that is denoted by the version's
+// date components.
+func (cv *CalVersion) End() time.Time {
+	switch {
+	case cv.Week > 0:
+		return cv.Date().AddDate(0, 0, 7)
+	case cv.Day > 0:
+		return cv.Date().AddDate(0, 0, 1)
+	case cv.Month > 0:
+		return cv.Date().AddDate(0, 1, 0)
+	default:
+		return cv.Date().AddDate(1, 0, 0)
+	}
+}
+
+// calverCollection is a sortable list of tags along with their parsed
+// calendar versions. Ties are broken through a lexical comparison of the
+// tag names, to yield deterministic results.
+type calverCollection struct {
+	tags     SortableImageTagList
+	versions []*CalVersion
+}
+
+func (c calverCollection) Len() int {
+	return len(c.tags)
+}
+
+func (c calverCollection) Less(i, j int) bool {
+	comp := c.versions[i].Compare(c.versions[j])
+	if comp != 0 {
+		return comp < 0
+	}
+	return c.tags[i].TagName < c.tags[j].TagName
+}
+
+func (c calverCollection) Swap(i, j int) {
+	c.tags[i], c.tags[j] = c.tags[j], c.tags[i]
+	c.versions[i], c.versions[j] = c.versions[j], c.versions[i]
+}
+
+// SortByCalVer returns a SortableImageTagList, sorted by the calendar version
+// of each tag according to layout. Tags not matching the layout are dropped.
+func (il *ImageTagList) SortByCalVer(ctx context.Context, layout *CalVerLayout) SortableImageTagList {
+	log := log.LoggerFromContext(ctx)
+	il.lock.RLock()
+	defer il.lock.RUnlock()
+
+	c := calverCollection{}
+	for _, v := range il.items {
+		cv, err := layout.Parse(v.TagName)
+		if err != nil {
+			log.Debugf("could not parse input tag %s as calver: %v", v.TagName, err)
+			continue
+		}
+		c.tags = append(c.tags, v)
+		c.versions = append(c.versions, cv)
+	}
+	sort.Sort(c)
+	if c.tags == nil {
+		return SortableImageTagList{}
+	}
+	return c.tags
+}
@@ -0,0 +1,104 @@
+package tag
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseCalVerLayout(t *testing.T) {
+	t.Run("Valid layouts", func(t *testing.T) {
+		for _, layout := range []string{"YYYY.MM.DD", "YY.0M.MICRO", "YYYY0M0D", "0Y.0W", "vYYYY.MICRO", "YYYY"} {
+			cl, err := ParseCalVerLayout(layout)
+			require.NoError(t, err, layout)
+			assert.Equal(t, layout, cl.String())
+		}
+	})
+
+	t.Run("Invalid layouts", func(t *testing.T) {
+		tests := map[string]string{
+			"":                 "must not be empty",
+			"MM.DD":            "year component is required",
+			"YYYY.MM.0M":       "duplicate component",
+			"YYYY.YY":          "duplicate component",
+			"YYYY.MM.WW":       "mutually exclusive",
+			"YYYY.DD":          "requires a month component",
+			"YYYY.MICRO.MICRO": "duplicate component",
+		}
+		for layout, msg := range tests {
+			_, err := ParseCalVerLayout(layout)
+			assert.ErrorContains(t, err, msg, layout)
+		}
+	})
+}
+
+func Test_CalVerLayout_Parse(t *testing.T) {
+	tests := []struct {
+		layout string
+		tag    string
+		want   *CalVersion
+	}{
+		{"YYYY.MM.DD", "2024.11.3", &CalVersion{Year: 2024, Month: 11, Day: 3}},
+		{"YYYY.0M.0D", "2024.01.03", &CalVersion{Year: 2024, Month: 1, Day: 3}},
+		{"YY.0M-MICRO", "24.04-1", &CalVersion{Year: 2024, Month: 4, Micro: 1}},
+		{"YYYY0M0D", "20241103", &CalVersion{Year: 2024, Month: 11, Day: 3}},
+		{"0Y.0W", "06.52", &CalVersion{Year: 2006, Week: 52}},
+		{"vYYYY.MICRO", "v2024.42", &CalVersion{Year: 2024, Micro: 42}},
+		{"YYYY.0M.0D", "2024.1.3", nil},
+		{"YYYY.MM.DD", "2024.13.3", nil},
+		{"YYYY.MM.DD", "2024.11.3-rc1", nil},
+		{"YY.0M-MICRO", "latest", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout+"/"+tt.tag, func(t *testing.T) {
+			cl, err := ParseCalVerLayout(tt.layout)
+			require.NoError(t, err)
+			cv, err := cl.Parse(tt.tag)
+			if tt.want == nil {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cv)
+		})
+	}
+}
+
+func Test_CalVersion_Compare(t *testing.T) {
+	assert.Equal(t, 0, (&CalVersion{Year: 2024, Month: 4}).Compare(&CalVersion{Year: 2024, Month: 4}))
+	assert.Equal(t, -1, (&CalVersion{Year: 2023, Month: 12}).Compare(&CalVersion{Year: 2024, Month: 1}))
+	assert.Equal(t, 1, (&CalVersion{Year: 2024, Month: 4, Micro: 10}).Compare(&CalVersion{Year: 2024, Month: 4, Micro: 2}))
+}
+
+func Test_CalVersion_Date(t *testing.T) {
+	cv := &CalVersion{Year: 2024, Month: 11, Day: 3}
+	assert.Equal(t, time.Date(2024, time.November, 3, 0, 0, 0, 0, time.UTC), cv.Date())
+	assert.Equal(t, time.Date(2024, time.November, 4, 0, 0, 0, 0, time.UTC), cv.End())
+
+	cv = &CalVersion{Year: 2024, Month: 11}
+	assert.Equal(t, time.Date(2024, time.November, 1, 0, 0, 0, 0, time.UTC), cv.Date())
+	assert.Equal(t, time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC), cv.End())
+
+	// ISO week 1 of 2025 starts on Monday, December 30th 2024
+	cv = &CalVersion{Year: 2025, Week: 1}
+	assert.Equal(t, time.Date(2024, time.December, 30, 0, 0, 0, 0, time.UTC), cv.Date())
+	assert.Equal(t, time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC), cv.End())
+
+	cv = &CalVersion{Year: 2024}
+	assert.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), cv.End())
+}
+
+func Test_SortByCalVer(t *testing.T) {
+	names := []string{"24.04-10", "24.04-2", "latest", "23.12-1", "24.10-1", "24.04-1", "v1.0.0"}
+	il := NewImageTagList()
+	for _, name := range names {
+		il.Add(NewImageTag(name, time.Now(), ""))
+	}
+	cl, err := ParseCalVerLayout("YY.0M-MICRO")
+	require.NoError(t, err)
+	sil := il.SortByCalVer(context.Background(), cl)
+	assert.Equal(t, []string{"23.12-1", "24.04-1", "24.04-2", "24.04-10", "24.10-1"}, sil.Tags())
+}
@@ -0,0 +1,173 @@
+package tag
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/argoproj-labs/argocd-image-updater/registry-scanner/pkg/log"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// relaxedVersionRegexp matches a version with an optional prefix such as "v"
+// or "release-", one to four numeric components, and an optional remainder
+// holding pre-release information, a suffix and build metadata.
+var relaxedVersionRegexp = regexp.MustCompile(`^(?:[A-Za-z]+[-_])*[vV]?(\d+(?:\.\d+){0,3})((?:[-+].*)?)$`)
+
+// relaxedPrereleaseRegexp matches the identifiers that denote a pre-release
+// in a relaxed version. All other identifiers are considered a suffix, e.g.
+// a platform or variant of the image.
+var relaxedPrereleaseRegexp = regexp.MustCompile(`^(?i:alpha|beta|rc|pre|preview|dev|snapshot|canary|nightly)\.?\d*(?:\.\d+)*$`)
+
+// RelaxedVersion is a version parsed from a tag that does not necessarily
+// adhere to semantic versioning. Use ParseRelaxedVersion to initialize a new
+// object.
+type RelaxedVersion struct {
+	// Segments holds the up to four numeric components of the version.
+	// Components missing in the tag are zero.
+	Segments [4]uint64
+	// Prerelease holds the pre-release identifier, e.g. "rc.1"
+	Prerelease string
+	// Suffix holds everything following the version that is not a pre-release
+	// or build metadata, e.g. "windowsservercore-ltsc2022"
+	Suffix string
+
+	original string
+	semver   *semver.Version
+}
+
+// ParseRelaxedVersion parses tagName as a relaxed version. Compared to a
+// semantic version, a relaxed version
+//
+//   - may have any prefix of letters followed by "-" or "_", and a "v", e.g.
+//     "release-1.2.3" or "v1.2.3"
+//   - may have up to four numeric components, e.g. "1.2.3.4"
+//   - only treats identifiers starting with alpha, beta, rc, pre, preview, dev,
+//     snapshot, canary or nightly as a pre-release, and all other identifiers
+//     following a "-" as a suffix, e.g. "1.2.3-alpine"
+func ParseRelaxedVersion(tagName string) (*RelaxedVersion, error) {
+	m := relaxedVersionRegexp.FindStringSubmatch(tagName)
+	if m == nil {
+		return nil, fmt.Errorf("tag %s is not a relaxed version", tagName)
+	}
+
+	rv := &RelaxedVersion{original: tagName}
+	for i, s := range strings.Split(m[1], ".") {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse version component %s of tag %s: %w", s, tagName, err)
+		}
+		rv.Segments[i] = n
+	}
+
+	// Build metadata carries no meaning for the order of versions
+	rest, _, _ := strings.Cut(m[2], "+")
+	if rest != "" {
+		ids := strings.Split(rest[1:], "-")
+		if relaxedPrereleaseRegexp.MatchString(ids[0]) {
+			rv.Prerelease = ids[0]
+			ids = ids[1:]
+		}
+		rv.Suffix = strings.Join(ids, "-")
+	}
+
+	ver := fmt.Sprintf("%d.%d.%d", rv.Segments[0], rv.Segments[1], rv.Segments[2])
+	if rv.Prerelease != "" {
+		ver += "-" + rv.Prerelease
+	}
+	sv, err := semver.StrictNewVersion(ver)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse tag %s as version %s: %w", tagName, ver, err)
+	}
+	rv.semver = sv
+
+	return rv, nil
+}
+
+// Original returns the tag name the version was parsed from
+func (rv *RelaxedVersion) Original() string {
+	return rv.original
+}
+
+// SemVer returns the semantic version made up of the first three components
+// and the pre-release of the version. The fourth component and the suffix are
+// not part of the semantic version.
+func (rv *RelaxedVersion) SemVer() *semver.Version {
+	return rv.semver
+}
+
+// Compare compares rv to other by their numeric components first, and their
+// pre-release second. Returns -1, 0 or 1 if rv is lower than, equal to or
+// higher than other. The suffix is not considered.
+func (rv *RelaxedVersion) Compare(other *RelaxedVersion) int {
+	for i := range rv.Segments {
+		if rv.Segments[i] < other.Segments[i] {
+			return -1
+		} else if rv.Segments[i] > other.Segments[i] {
+			return 1
+		}
+	}
+	// A version without pre-release has precedence over one with pre-release
+	switch {
+	case rv.Prerelease == other.Prerelease:
+		return 0
+	case rv.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+	return rv.semver.Compare(other.semver)
+}
+
+// relaxedCollection is a sortable list of tags along with their parsed
+// relaxed versions. Ties are broken through a lexical comparison of the
+// tag names, to yield deterministic results.
+type relaxedCollection struct {
+	tags     SortableImageTagList
+	versions []*RelaxedVersion
+}
+
+func (c relaxedCollection) Len() int {
+	return len(c.tags)
+}
+
+func (c relaxedCollection) Less(i, j int) bool {
+	comp := c.versions[i].Compare(c.versions[j])
+	if comp != 0 {
+		return comp < 0
+	}
+	return c.tags[i].TagName < c.tags[j].TagName
+}
+
+func (c relaxedCollection) Swap(i, j int) {
+	c.tags[i], c.tags[j] = c.tags[j], c.tags[i]
+	c.versions[i], c.versions[j] = c.versions[j], c.versions[i]
+}
+
+// SortByRelaxedSemVer returns a SortableImageTagList, sorted by the relaxed
+// version of each tag. Tags that are not a relaxed version are dropped.
+func (il *ImageTagList) SortByRelaxedSemVer(ctx context.Context) SortableImageTagList {
+	log := log.LoggerFromContext(ctx)
+	il.lock.RLock()
+	defer il.lock.RUnlock()
+
+	c := relaxedCollection{}
+	for _, v := range il.items {
+		rv, err := ParseRelaxedVersion(v.TagName)
+		if err != nil {
+			log.Debugf("could not parse input tag %s as relaxed semver: %v", v.TagName, err)
+			continue
+		}
+		c.tags = append(c.tags, v)
+		c.versions = append(c.versions, rv)
+	}
+	sort.Sort(c)
+	if c.tags == nil {
+		return SortableImageTagList{}
+	}
+	return c.tags
+}
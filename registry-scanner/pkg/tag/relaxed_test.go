@@ -0,0 +1,85 @@
+package tag
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseRelaxedVersion(t *testing.T) {
+	tests := []struct {
+		tag        string
+		segments   [4]uint64
+		prerelease string
+		suffix     string
+		semver     string
+	}{
+		{"1.2.3", [4]uint64{1, 2, 3, 0}, "", "", "1.2.3"},
+		{"v1.2", [4]uint64{1, 2, 0, 0}, "", "", "1.2.0"},
+		{"release-1.2.3", [4]uint64{1, 2, 3, 0}, "", "", "1.2.3"},
+		{"release-v1.2.3", [4]uint64{1, 2, 3, 0}, "", "", "1.2.3"},
+		{"1.2.3.4", [4]uint64{1, 2, 3, 4}, "", "", "1.2.3"},
+		{"v1.2.3-windowsservercore-ltsc2022", [4]uint64{1, 2, 3, 0}, "", "windowsservercore-ltsc2022", "1.2.3"},
+		{"1.2.3-rc.1", [4]uint64{1, 2, 3, 0}, "rc.1", "", "1.2.3-rc.1"},
+		{"1.2.3.4-beta2-alpine", [4]uint64{1, 2, 3, 4}, "beta2", "alpine", "1.2.3-beta2"},
+		{"1.2.3+build.5", [4]uint64{1, 2, 3, 0}, "", "", "1.2.3"},
+		{"1.2.3-alpine+build.5", [4]uint64{1, 2, 3, 0}, "", "alpine", "1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			rv, err := ParseRelaxedVersion(tt.tag)
+			require.NoError(t, err)
+			assert.Equal(t, tt.segments, rv.Segments)
+			assert.Equal(t, tt.prerelease, rv.Prerelease)
+			assert.Equal(t, tt.suffix, rv.Suffix)
+			assert.Equal(t, tt.semver, rv.SemVer().String())
+			assert.Equal(t, tt.tag, rv.Original())
+		})
+	}
+
+	t.Run("Invalid versions", func(t *testing.T) {
+		for _, tag := range []string{"latest", "1.2.3.4.5", "v", "1.2.3.windows", "release-", "99999999999999999999.0"} {
+			_, err := ParseRelaxedVersion(tag)
+			assert.Error(t, err, tag)
+		}
+	})
+}
+
+func Test_RelaxedVersion_Compare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "v1.2.3-alpine", 0},
+		{"1.2.3.4", "1.2.3.10", -1},
+		{"1.2.3", "1.2.3.1", -1},
+		{"1.2.4", "1.2.3.9", 1},
+		{"1.2.3-rc.1", "1.2.3", -1},
+		{"1.2.3-rc.2", "1.2.3-rc.1", 1},
+		{"1.2.3-alpha", "1.2.3-beta", -1},
+		{"2.0.0-rc.1", "1.9.9.9", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			a, err := ParseRelaxedVersion(tt.a)
+			require.NoError(t, err)
+			b, err := ParseRelaxedVersion(tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, a.Compare(b))
+		})
+	}
+}
+
+func Test_SortByRelaxedSemVer(t *testing.T) {
+	names := []string{"1.2.3.10", "latest", "v1.2.3.9", "1.2.3", "release-1.2.4", "1.2.3-rc.1", "1.2.3.2-ltsc2022", "1.10.0"}
+	il := NewImageTagList()
+	for _, name := range names {
+		il.Add(NewImageTag(name, time.Now(), ""))
+	}
+	sil := il.SortByRelaxedSemVer(context.Background())
+	assert.Equal(t, []string{"1.2.3-rc.1", "1.2.3", "1.2.3.2-ltsc2022", "v1.2.3.9", "1.2.3.10", "release-1.2.4", "1.10.0"}, sil.Tags())
+}
@@ -21,6 +21,22 @@ type TagSignature struct {
 	// PayloadDigest is the hex-encoded sha256 of the simple signing JSON payload —
 	// the exact bytes that were signed with the private key.
 	PayloadDigest string
+	// EnvelopePayloadDigest is the hex-encoded sha256 of the DSSE envelope
+	// payload, which the transparency log entry of a keyless signature records.
+	// Empty for signatures that were not stored in a sigstore bundle.
+	EnvelopePayloadDigest string
+	// VerificationMaterial is the raw JSON verification material of the
+	// sigstore bundle, holding the signing certificate and transparency log
+	// entries of a keyless signature. Empty for signatures that were not
+	// stored in a sigstore bundle.
+	VerificationMaterial []byte
+	// PayloadType is the type of the signed DSSE envelope payload, e.g.
+	// "application/vnd.in-toto+json" for attestations. Empty for signatures
+	// that were not stored in a sigstore bundle.
+	PayloadType string
+	// Payload is the signed DSSE envelope payload. Empty for signatures that
+	// were not stored in a sigstore bundle.
+	Payload []byte
 }
 
 // ImageTag is a representation of an image tag with metadata.
@@ -119,6 +135,11 @@ func (tag *ImageTag) IsDigest() bool {
 	return tag.TagDigest != ""
 }
 
+// HasDate returns true if the creation date of the tag is known
+func (tag *ImageTag) HasDate() bool {
+	return tag.TagDate != nil && !tag.TagDate.IsZero()
+}
+
 // Equals checks whether two tags are equal. Will consider any digest set for
 // either tag with precedence, otherwise uses the tag names.
 func (tag *ImageTag) Equals(aTag *ImageTag) bool {